@@ -69,6 +69,47 @@ type AlbConfigList struct {
 type AlbConfigSpec struct {
 	LoadBalancer *LoadBalancerSpec `json:"config" protobuf:"bytes,1,rep,name=config"`
 	Listeners    []*ListenerSpec   `json:"listeners" protobuf:"bytes,2,rep,name=listeners"`
+	// Quota overrides the default quotas of the edition of the ALB instance, for example after the quotas
+	// are raised in Quota Center. The model is checked against the quotas before it is applied.
+	// +optional
+	Quota *QuotaSpec `json:"quota,omitempty" protobuf:"bytes,3,opt,name=quota"`
+	// Sharding splits the Ingress group across several ALB instances by host when the forwarding rules
+	// exceed the quota of one instance. It is disabled by default.
+	// +optional
+	Sharding *ShardingSpec `json:"sharding,omitempty" protobuf:"bytes,4,opt,name=sharding"`
+	// DefaultBackend is the Service the listeners forward the requests matching no forwarding rule to, unless
+	// an Ingress of the group sets spec.defaultBackend. It applies to every Ingress of the IngressClass.
+	// +optional
+	DefaultBackend *DefaultBackendSpec `json:"defaultBackend,omitempty" protobuf:"bytes,5,opt,name=defaultBackend"`
+}
+
+// DefaultBackendSpec references the Service port of a default backend.
+type DefaultBackendSpec struct {
+	// Namespace of the Service. Defaults to the namespace of the AlbConfig, or kube-system if the AlbConfig
+	// is cluster scoped.
+	// +optional
+	Namespace   string `json:"namespace,omitempty" protobuf:"bytes,1,opt,name=namespace"`
+	ServiceName string `json:"serviceName" protobuf:"bytes,2,opt,name=serviceName"`
+	ServicePort int32  `json:"servicePort" protobuf:"varint,3,opt,name=servicePort"`
+}
+
+// QuotaSpec is the quotas of an ALB instance, the zero values use the defaults of the edition.
+type QuotaSpec struct {
+	// ListenerRules is the max number of forwarding rules of a listener.
+	// +optional
+	ListenerRules int `json:"listenerRules,omitempty" protobuf:"varint,1,opt,name=listenerRules"`
+	// ServerGroups is the max number of server groups used by an ALB instance.
+	// +optional
+	ServerGroups int `json:"serverGroups,omitempty" protobuf:"varint,2,opt,name=serverGroups"`
+}
+
+// ShardingSpec configures how an Ingress group is split across several ALB instances.
+type ShardingSpec struct {
+	Enabled bool `json:"enabled,omitempty" protobuf:"varint,1,opt,name=enabled"`
+	// MaxShards is the max number of ALB instances of the group, including the ALB instance of the AlbConfig.
+	// Defaults to 5.
+	// +optional
+	MaxShards int `json:"maxShards,omitempty" protobuf:"varint,2,opt,name=maxShards"`
 }
 
 // IngressStatus describe the current state of the AckIngress.
@@ -76,8 +117,100 @@ type IngressStatus struct {
 	// LoadBalancer contains the current status of the load-balancer.
 	// +optional
 	LoadBalancer LoadBalancerStatus `json:"loadBalancer,omitempty" protobuf:"bytes,1,opt,name=loadBalancer"`
+
+	// ObservedGeneration is the most recent generation of the AlbConfig processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,2,opt,name=observedGeneration"`
+
+	// Conditions describe the current state of the AlbConfig, see AlbConfigConditionType.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,3,rep,name=conditions"`
+
+	// LastError is the error message of the last failed reconcile, it is cleared once a reconcile succeeds.
+	// +optional
+	LastError string `json:"lastError,omitempty" protobuf:"bytes,4,opt,name=lastError"`
+
+	// LastRequestId is the RequestId of the cloud API call which caused LastError, if any.
+	// +optional
+	LastRequestId string `json:"lastRequestId,omitempty" protobuf:"bytes,5,opt,name=lastRequestId"`
+
+	// Shards are the ALB instances serving the Ingress group when it is sharded, the first one is the ALB
+	// instance of the AlbConfig. The hosts stay on their shard as long as the shard is within the quota.
+	// +optional
+	Shards []ShardStatus `json:"shards,omitempty" protobuf:"bytes,6,rep,name=shards"`
+
+	// SecretCertificates are the certificates uploaded to CAS from the TLS Secrets of the Ingresses and the CA
+	// Secrets of the listeners, they are deleted from CAS once no AlbConfig uses them.
+	// +optional
+	SecretCertificates []SecretCertificateStatus `json:"secretCertificates,omitempty" protobuf:"bytes,7,rep,name=secretCertificates"`
 }
 
+// SecretCertificateStatus is a certificate uploaded to CAS from a TLS or CA Secret.
+type SecretCertificateStatus struct {
+	CertificateId string `json:"certificateId" protobuf:"bytes,1,opt,name=certificateId"`
+	// Secret is the namespace/name of the Secret.
+	Secret string `json:"secret,omitempty" protobuf:"bytes,2,opt,name=secret"`
+	// CA is true if the certificate is a CA certificate uploaded from the CA bundle of a Secret.
+	CA bool `json:"ca,omitempty" protobuf:"varint,3,opt,name=ca"`
+}
+
+// ShardStatus is the status of an ALB instance serving a part of the hosts of a sharded Ingress group.
+type ShardStatus struct {
+	Name    string   `json:"name" protobuf:"bytes,1,opt,name=name"`
+	Id      string   `json:"id,omitempty" protobuf:"bytes,2,opt,name=id"`
+	DNSName string   `json:"dnsname,omitempty" protobuf:"bytes,3,opt,name=dnsname"`
+	Hosts   []string `json:"hosts,omitempty" protobuf:"bytes,4,rep,name=hosts"`
+}
+
+// AlbConfigConditionType is the type of the conditions in IngressStatus.
+type AlbConfigConditionType string
+
+const (
+	// AlbConfigConditionReady is true when the spec is synced and the load balancer serves traffic.
+	AlbConfigConditionReady AlbConfigConditionType = "Ready"
+	// AlbConfigConditionSynced is true when the last reconcile of the current generation succeeded.
+	AlbConfigConditionSynced AlbConfigConditionType = "Synced"
+	// AlbConfigConditionLoadBalancerProvisioned is true when the ALB instance exists and has a DNS name.
+	AlbConfigConditionLoadBalancerProvisioned AlbConfigConditionType = "LoadBalancerProvisioned"
+	// AlbConfigConditionListenersReady is true when all the listeners in the spec are applied.
+	AlbConfigConditionListenersReady AlbConfigConditionType = "ListenersReady"
+	// AlbConfigConditionDegraded is true when the last reconcile failed but the load balancer still serves
+	// traffic with the previously applied configuration.
+	AlbConfigConditionDegraded AlbConfigConditionType = "Degraded"
+	// AlbConfigConditionRulesReachable is false when a listener rule is duplicated or shadowed by a rule of
+	// higher priority, so that it never matches any request.
+	AlbConfigConditionRulesReachable AlbConfigConditionType = "RulesReachable"
+	// AlbConfigConditionWithinQuota is false when the model exceeds the quotas of the ALB instance, it is
+	// checked before the model is applied.
+	AlbConfigConditionWithinQuota AlbConfigConditionType = "WithinQuota"
+)
+
+// AlbConfigConditionReason is the reason of the conditions in IngressStatus.
+type AlbConfigConditionReason string
+
+const (
+	AlbConfigReasonReconciled            AlbConfigConditionReason = "Reconciled"
+	AlbConfigReasonProvisioning          AlbConfigConditionReason = "Provisioning"
+	AlbConfigReasonProvisioned           AlbConfigConditionReason = "Provisioned"
+	AlbConfigReasonListenersApplied      AlbConfigConditionReason = "ListenersApplied"
+	AlbConfigReasonInvalidSpec           AlbConfigConditionReason = "InvalidSpec"
+	AlbConfigReasonLoadGroupFailed       AlbConfigConditionReason = "LoadGroupFailed"
+	AlbConfigReasonBuildModelFailed      AlbConfigConditionReason = "BuildModelFailed"
+	AlbConfigReasonApplyModelFailed      AlbConfigConditionReason = "ApplyModelFailed"
+	AlbConfigReasonUpdateFinalizerFailed AlbConfigConditionReason = "UpdateFinalizerFailed"
+	AlbConfigReasonCleanupFailed         AlbConfigConditionReason = "CleanupFailed"
+	AlbConfigReasonSyncFailed            AlbConfigConditionReason = "SyncFailed"
+	AlbConfigReasonIngressIsolated       AlbConfigConditionReason = "IngressIsolated"
+	AlbConfigReasonNotDegraded           AlbConfigConditionReason = "NotDegraded"
+	AlbConfigReasonNoRuleConflict        AlbConfigConditionReason = "NoRuleConflict"
+	AlbConfigReasonRuleConflict          AlbConfigConditionReason = "RuleConflict"
+	AlbConfigReasonWithinQuota           AlbConfigConditionReason = "WithinQuota"
+	AlbConfigReasonQuotaExceeded         AlbConfigConditionReason = "QuotaExceeded"
+	AlbConfigReasonSharded               AlbConfigConditionReason = "Sharded"
+)
+
 // LoadBalancer is a nested struct in alb response
 type LoadBalancerSpec struct {
 	Id                           string                       `json:"id" protobuf:"bytes,1,opt,name=id"`
@@ -185,6 +318,11 @@ type ListenerSpec struct {
 	LogConfig           LogConfig           `json:"logConfig" protobuf:"bytes,15,opt,name=logConfig"`
 	RequestTimeout      int                 `json:"requestTimeout" protobuf:"bytes,16,opt,name=requestTimeout"`
 	AclConfig           AclConfig           `json:"aclConfig" protobuf:"bytes,17,opt,name=aclConfig"`
+	// CaCertificateSecret is the namespace/name of a Secret holding the CA bundle in ca.crt, which is uploaded to
+	// CAS and bound as the CA certificate of the HTTPS listener to verify the client certificates. The Secret is
+	// in the namespace of the AlbConfig, or kube-system for a cluster scoped AlbConfig, if only its name is given.
+	// +optional
+	CaCertificateSecret string `json:"caCertificateSecret,omitempty" protobuf:"bytes,18,opt,name=caCertificateSecret"`
 }
 type Action struct {
 	Type string `json:"actionType" protobuf:"bytes,1,opt,name=actionType"`
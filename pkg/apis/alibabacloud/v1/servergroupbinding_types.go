@@ -0,0 +1,128 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func init() {
+	SchemeBuilder.Register(&ServerGroupBinding{}, &ServerGroupBindingList{})
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServerGroupBinding registers the endpoints of a Service into an existing ALB or NLB server group. The server
+// group and its load balancer are managed out of the cluster, the cluster only keeps the servers in sync with
+// the endpoints.
+type ServerGroupBinding struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Spec is the server group and the Service to bind.
+	// +optional
+	Spec ServerGroupBindingSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+
+	// Status is the servers registered by the binding.
+	// +optional
+	Status ServerGroupBindingStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// ServerGroupType is the type of the load balancer of a bound server group.
+type ServerGroupType string
+
+const (
+	ServerGroupTypeALB ServerGroupType = "ALB"
+	ServerGroupTypeNLB ServerGroupType = "NLB"
+)
+
+// ServerGroupBindingSpec is the server group and the Service of a ServerGroupBinding.
+type ServerGroupBindingSpec struct {
+	// ServerGroupId is the ID of the existing server group. A server group is bound by one binding of a cluster,
+	// the binding only manages the servers registered with its description, so the server group can be shared
+	// with the bindings of other clusters and the servers added by other means are kept.
+	ServerGroupId string `json:"serverGroupId" protobuf:"bytes,1,opt,name=serverGroupId"`
+
+	// ServerGroupType is the type of the load balancer of the server group, ALB or NLB.
+	ServerGroupType ServerGroupType `json:"serverGroupType" protobuf:"bytes,2,opt,name=serverGroupType"`
+
+	// ServiceName is the name of the Service in the namespace of the binding.
+	ServiceName string `json:"serviceName" protobuf:"bytes,3,opt,name=serviceName"`
+
+	// ServicePort is the port number or the port name of the Service.
+	ServicePort intstr.IntOrString `json:"servicePort" protobuf:"bytes,4,opt,name=servicePort"`
+
+	// Weight is the weight of every server, from 0 to 100. The servers are weighted as in the load balancers
+	// of the Service if it is not set.
+	// +optional
+	Weight *int32 `json:"weight,omitempty" protobuf:"varint,5,opt,name=weight"`
+
+	// TrafficPolicy is how the endpoints are registered: ENI registers the pods, Local registers the nodes
+	// of the pods and Cluster registers all the nodes. It is read from the Service if it is not set.
+	// +optional
+	TrafficPolicy string `json:"trafficPolicy,omitempty" protobuf:"bytes,6,opt,name=trafficPolicy"`
+}
+
+// ServerGroupBindingStatus is the servers registered by a ServerGroupBinding.
+type ServerGroupBindingStatus struct {
+	// ObservedGeneration is the most recent generation of the binding processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,1,opt,name=observedGeneration"`
+
+	// Backends are the servers registered into the server group by the last successful sync.
+	// +optional
+	Backends []ServerGroupBindingBackend `json:"backends,omitempty" protobuf:"bytes,2,rep,name=backends"`
+
+	// Conditions describe the current state of the binding, see ServerGroupBindingConditionType.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,3,rep,name=conditions"`
+
+	// LastError is the error message of the last failed sync, it is cleared once a sync succeeds.
+	// +optional
+	LastError string `json:"lastError,omitempty" protobuf:"bytes,4,opt,name=lastError"`
+}
+
+// ServerGroupBindingBackend is a server registered by a ServerGroupBinding.
+type ServerGroupBindingBackend struct {
+	ServerId   string `json:"serverId" protobuf:"bytes,1,opt,name=serverId"`
+	ServerIp   string `json:"serverIp,omitempty" protobuf:"bytes,2,opt,name=serverIp"`
+	ServerType string `json:"serverType" protobuf:"bytes,3,opt,name=serverType"`
+	Port       int32  `json:"port" protobuf:"varint,4,opt,name=port"`
+	Weight     int32  `json:"weight" protobuf:"varint,5,opt,name=weight"`
+}
+
+// ServerGroupBindingConditionType is the type of the conditions in ServerGroupBindingStatus.
+type ServerGroupBindingConditionType string
+
+const (
+	// ServerGroupBindingConditionReady is true when the servers of the server group match the endpoints.
+	ServerGroupBindingConditionReady ServerGroupBindingConditionType = "Ready"
+)
+
+// ServerGroupBindingConditionReason is the reason of the conditions in ServerGroupBindingStatus.
+type ServerGroupBindingConditionReason string
+
+const (
+	ServerGroupBindingReasonSynced          ServerGroupBindingConditionReason = "Synced"
+	ServerGroupBindingReasonInvalidSpec     ServerGroupBindingConditionReason = "InvalidSpec"
+	ServerGroupBindingReasonServiceNotFound ServerGroupBindingConditionReason = "ServiceNotFound"
+	ServerGroupBindingReasonSyncFailed      ServerGroupBindingConditionReason = "SyncFailed"
+	ServerGroupBindingReasonConflict        ServerGroupBindingConditionReason = "Conflict"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServerGroupBindingList is a collection of ServerGroupBinding.
+type ServerGroupBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Items is the list of ServerGroupBinding.
+	Items []ServerGroupBinding `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
@@ -0,0 +1,73 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	SchemeBuilder.Register(&ServiceReferenceGrant{}, &ServiceReferenceGrantList{})
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceReferenceGrant allows the Ingresses and the AlbConfigs of other namespaces to forward requests to the
+// Services in the namespace of the grant. A Service in another namespace can only be used as a backend if a
+// grant in its namespace allows it.
+type ServiceReferenceGrant struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Spec is the resources allowed to reference the Services.
+	// +optional
+	Spec ServiceReferenceGrantSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+}
+
+// ServiceReferenceGrantSpec is the referencing resources and the referenced Services of a ServiceReferenceGrant.
+type ServiceReferenceGrantSpec struct {
+	// From is the resources that may reference the Services.
+	From []ServiceReferenceGrantFrom `json:"from" protobuf:"bytes,1,rep,name=from"`
+
+	// To is the Services that may be referenced, all the Services of the namespace if it is empty.
+	// +optional
+	To []ServiceReferenceGrantTo `json:"to,omitempty" protobuf:"bytes,2,rep,name=to"`
+}
+
+// ServiceReferenceGrantFromKind is the kind of a resource referencing a Service.
+type ServiceReferenceGrantFromKind string
+
+const (
+	ServiceReferenceGrantFromIngress   ServiceReferenceGrantFromKind = "Ingress"
+	ServiceReferenceGrantFromAlbConfig ServiceReferenceGrantFromKind = "AlbConfig"
+)
+
+// ServiceReferenceGrantFrom is the resources allowed to reference the Services.
+type ServiceReferenceGrantFrom struct {
+	// Kind is Ingress or AlbConfig.
+	Kind ServiceReferenceGrantFromKind `json:"kind" protobuf:"bytes,1,opt,name=kind"`
+
+	// Namespace is the namespace of the Ingresses. It is ignored for AlbConfig, which is cluster scoped.
+	// +optional
+	Namespace string `json:"namespace,omitempty" protobuf:"bytes,2,opt,name=namespace"`
+}
+
+// ServiceReferenceGrantTo is a Service allowed to be referenced.
+type ServiceReferenceGrantTo struct {
+	// Name is the name of the Service.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceReferenceGrantList is a collection of ServiceReferenceGrant.
+type ServiceReferenceGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// Items is the list of ServiceReferenceGrant.
+	Items []ServiceReferenceGrant `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
@@ -29,6 +29,8 @@ const (
 	flagRouteReconciliationPeriod      = "route-reconciliation-period"
 	flagNodeMonitorPeriod              = "node-monitor-period"
 	flagNetwork                        = "network"
+	flagDefaultLoadBalancerClass       = "default-load-balancer-class"
+	flagCertificateExpiryWarningWindow = "certificate-expiry-warning-window"
 
 	defaultCloudProvider             = "alibabacloud"
 	defaultClusterName               = "kubernetes"
@@ -38,6 +40,8 @@ const (
 	defaultRouteReconciliationPeriod = 5 * time.Minute
 	defaultNodeMonitorPeriod         = 5 * time.Minute
 	defaultNetwork                   = "vpc"
+	defaultLoadBalancerClass         = "alibabacloud.com/clb"
+	defaultCertificateExpiryWindow   = 30 * 24 * time.Hour
 )
 
 var ControllerCFG = &ControllerConfig{
@@ -54,6 +58,10 @@ type ControllerConfig struct {
 	LogLevel                       int
 	DryRun                         bool
 	NetWork                        string
+	DefaultLoadBalancerClass       string
+	// CertificateExpiryWarningWindow is how long before the expiry of a certificate bound to a listener the
+	// Warning events are emitted
+	CertificateExpiryWarningWindow time.Duration
 
 	RuntimeConfig RuntimeConfig
 	CloudConfig   *CloudConfig
@@ -73,8 +81,12 @@ func (cfg *ControllerConfig) BindFlags(fs *pflag.FlagSet) {
 		"Maximum number of concurrently running reconcile loops for service")
 	fs.BoolVar(&cfg.DryRun, flagDryRun, false, "whether to perform a dry run")
 	fs.StringVar(&cfg.NetWork, flagNetwork, defaultNetwork, "Set network type for controller.")
+	fs.StringVar(&cfg.DefaultLoadBalancerClass, flagDefaultLoadBalancerClass, defaultLoadBalancerClass,
+		"The load balancer class of services which do not set spec.loadBalancerClass. Set it to empty to leave these services to the cloud-controller-manager.")
 	fs.DurationVar(&cfg.RouteReconciliationPeriod.Duration, flagRouteReconciliationPeriod, defaultRouteReconciliationPeriod,
 		"The period for reconciling routes created for nodes by cloud provider. The minimum value is 1 minute")
+	fs.DurationVar(&cfg.CertificateExpiryWarningWindow, flagCertificateExpiryWarningWindow, defaultCertificateExpiryWindow,
+		"Emit Warning events for the certificates bound to the listeners which expire within the window.")
 	fs.DurationVar(&cfg.NodeMonitorPeriod.Duration, flagNodeMonitorPeriod, defaultNodeMonitorPeriod, "The period for syncing NodeStatus in NodeController.")
 	fs.StringVar(&cfg.FeatureGates, flagFeatureGates, "", "A set of key=value pairs that describe feature gates for alpha/experimental features.")
 	fs.BoolVar(&cfg.AllowUntaggedCloud, "allow-untagged-cloud", false, "Allow the cluster to run without the cluster-id on cloud instances. This is a legacy mode of operation and a cluster-id will be required in the future.")
@@ -20,6 +20,8 @@ const (
 	flagLeaderElectResourceNamespace = "leader-elect-resource-namespace"
 	flagLeaderElectRetryPeriod       = "leader-elect-retry-period"
 	flagSyncPeriod                   = "sync-period"
+	flagWebhookBindPort              = "webhook-bind-port"
+	flagWebhookCertDir               = "webhook-cert-dir"
 
 	defaultMetricsAddr                  = ":8080"
 	defaultHealthProbeBindAddress       = ":10258"
@@ -33,6 +35,8 @@ const (
 	defaultSyncPeriod                   = 60 * time.Minute
 	defaultQPS                          = 20.0
 	defaultBurst                        = 30
+	defaultWebhookBindPort              = 9443
+	defaultWebhookCertDir               = "/tmp/k8s-webhook-server/serving-certs"
 )
 
 // RuntimeConfig stores the configuration for controller-runtime
@@ -49,6 +53,8 @@ type RuntimeConfig struct {
 	SyncPeriod                   time.Duration
 	QPS                          float32
 	Burst                        int
+	WebhookBindPort              int
+	WebhookCertDir               string
 }
 
 func (c *RuntimeConfig) BindFlags(fs *pflag.FlagSet) {
@@ -77,6 +83,9 @@ func (c *RuntimeConfig) BindFlags(fs *pflag.FlagSet) {
 		"The namespace of resource object that is used for locking during leader election.")
 	fs.DurationVar(&c.SyncPeriod, flagSyncPeriod, defaultSyncPeriod,
 		"Period at which the controller forces the repopulation of its local object stores.")
+	fs.IntVar(&c.WebhookBindPort, flagWebhookBindPort, defaultWebhookBindPort, "The TCP port the webhook server serves at.")
+	fs.StringVar(&c.WebhookCertDir, flagWebhookCertDir, defaultWebhookCertDir,
+		"The directory that contains the tls.crt and tls.key of the webhook server.")
 
 }
 
@@ -92,5 +101,7 @@ func BuildRuntimeOptions(rtCfg RuntimeConfig) manager.Options {
 		RenewDeadline:              &rtCfg.LeaderElectRenewDeadline,
 		RetryPeriod:                &rtCfg.LeaderElectRetryPeriod,
 		SyncPeriod:                 &rtCfg.SyncPeriod,
+		Port:                       rtCfg.WebhookBindPort,
+		CertDir:                    rtCfg.WebhookCertDir,
 	}
 }
@@ -4,15 +4,23 @@ import (
 	"fmt"
 
 	"k8s.io/alibaba-load-balancer-controller/pkg/context/shared"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/gateway"
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/servergroupbinding"
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/clb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 func init() {
 	controllerMap = map[string]func(manager.Manager, *shared.SharedContext) error{
-		"ingress": ingress.Add,
-		"service": service.Add,
+		"ingress":            ingress.Add,
+		"service":            service.Add,
+		"clb":                clb.Add,
+		"gateway":            gateway.Add,
+		"servergroupbinding": servergroupbinding.Add,
+		"webhook":            webhook.Add,
 	}
 }
 
@@ -0,0 +1,240 @@
+package gateway
+
+import (
+	"context"
+
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gwv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/go-logr/logr"
+)
+
+func NewEnqueueRequestsForGatewayEvent(k8sClient client.Client, eventRecorder record.EventRecorder, logger logr.Logger) *enqueueRequestsForGatewayEvent {
+	return &enqueueRequestsForGatewayEvent{
+		k8sClient:     k8sClient,
+		eventRecorder: eventRecorder,
+		logger:        logger,
+	}
+}
+
+var _ handler.EventHandler = (*enqueueRequestsForGatewayEvent)(nil)
+
+type enqueueRequestsForGatewayEvent struct {
+	k8sClient     client.Client
+	eventRecorder record.EventRecorder
+	logger        logr.Logger
+}
+
+func (h *enqueueRequestsForGatewayEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	gw, ok := e.Object.(*gwv1beta1.Gateway)
+	if ok {
+		h.logger.Info("controller: gateway Create event", "gateway", util.Key(gw))
+		enqueueGateway(queue, gw)
+	}
+}
+
+func (h *enqueueRequestsForGatewayEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	gwOld := e.ObjectOld.(*gwv1beta1.Gateway)
+	gwNew := e.ObjectNew.(*gwv1beta1.Gateway)
+
+	// status updates made by the controller itself are skipped
+	if !loadBalancerAnnotationsChanged(gwOld.Annotations, gwNew.Annotations) &&
+		equality.Semantic.DeepEqual(gwOld.Spec, gwNew.Spec) &&
+		equality.Semantic.DeepEqual(gwOld.DeletionTimestamp.IsZero(), gwNew.DeletionTimestamp.IsZero()) {
+		return
+	}
+
+	h.logger.Info("controller: gateway Update event", "gateway", util.Key(gwNew))
+	enqueueGateway(queue, gwNew)
+}
+
+func (h *enqueueRequestsForGatewayEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+}
+
+func (h *enqueueRequestsForGatewayEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	gw, ok := e.Object.(*gwv1beta1.Gateway)
+	if ok {
+		h.logger.Info("controller: gateway Generic event", "gateway", util.Key(gw))
+		enqueueGateway(queue, gw)
+	}
+}
+
+func enqueueGateway(queue workqueue.RateLimitingInterface, gw *gwv1beta1.Gateway) {
+	queue.Add(reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: gw.Namespace,
+			Name:      gw.Name,
+		},
+	})
+}
+
+func NewEnqueueRequestsForHTTPRouteEvent(k8sClient client.Client, eventRecorder record.EventRecorder, logger logr.Logger) *enqueueRequestsForHTTPRouteEvent {
+	return &enqueueRequestsForHTTPRouteEvent{
+		k8sClient:     k8sClient,
+		eventRecorder: eventRecorder,
+		logger:        logger,
+	}
+}
+
+var _ handler.EventHandler = (*enqueueRequestsForHTTPRouteEvent)(nil)
+
+type enqueueRequestsForHTTPRouteEvent struct {
+	k8sClient     client.Client
+	eventRecorder record.EventRecorder
+	logger        logr.Logger
+}
+
+func (h *enqueueRequestsForHTTPRouteEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	route, ok := e.Object.(*gwv1beta1.HTTPRoute)
+	if ok {
+		h.logger.Info("controller: httproute Create event", "httproute", util.Key(route))
+		enqueueParentGateways(queue, route)
+	}
+}
+
+func (h *enqueueRequestsForHTTPRouteEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	routeOld := e.ObjectOld.(*gwv1beta1.HTTPRoute)
+	routeNew := e.ObjectNew.(*gwv1beta1.HTTPRoute)
+
+	if equality.Semantic.DeepEqual(routeOld.Annotations, routeNew.Annotations) &&
+		equality.Semantic.DeepEqual(routeOld.Spec, routeNew.Spec) &&
+		equality.Semantic.DeepEqual(routeOld.DeletionTimestamp.IsZero(), routeNew.DeletionTimestamp.IsZero()) {
+		return
+	}
+
+	h.logger.Info("controller: httproute Update event", "httproute", util.Key(routeNew))
+	// a route detached from a gateway must be removed from it as well
+	enqueueParentGateways(queue, routeOld)
+	enqueueParentGateways(queue, routeNew)
+}
+
+func (h *enqueueRequestsForHTTPRouteEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	route, ok := e.Object.(*gwv1beta1.HTTPRoute)
+	if ok {
+		h.logger.Info("controller: httproute Delete event", "httproute", util.Key(route))
+		enqueueParentGateways(queue, route)
+	}
+}
+
+func (h *enqueueRequestsForHTTPRouteEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+}
+
+func enqueueParentGateways(queue workqueue.RateLimitingInterface, route *gwv1beta1.HTTPRoute) {
+	for _, key := range parentGatewayKeys(route) {
+		queue.Add(reconcile.Request{NamespacedName: key})
+	}
+}
+
+func parentGatewayKeys(route *gwv1beta1.HTTPRoute) []types.NamespacedName {
+	keys := make([]types.NamespacedName, 0, len(route.Spec.ParentRefs))
+	for _, ref := range route.Spec.ParentRefs {
+		if ref.Group != nil && string(*ref.Group) != gwv1beta1.SchemeGroupVersion.Group {
+			continue
+		}
+		if ref.Kind != nil && *ref.Kind != gatewayKind {
+			continue
+		}
+		ns := route.Namespace
+		if ref.Namespace != nil {
+			ns = string(*ref.Namespace)
+		}
+		keys = append(keys, types.NamespacedName{Namespace: ns, Name: string(ref.Name)})
+	}
+	return keys
+}
+
+// gatewaysForGatewayClass maps a GatewayClass to the Gateways of that class.
+func (g *gatewayReconciler) gatewaysForGatewayClass(obj client.Object) []reconcile.Request {
+	gwList := &gwv1beta1.GatewayList{}
+	if err := g.k8sClient.List(context.Background(), gwList); err != nil {
+		g.logger.Error(err, "list gateways", "gatewayclass", obj.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	for _, gw := range gwList.Items {
+		if string(gw.Spec.GatewayClassName) == obj.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// gatewaysForBackend maps a Service or its Endpoints to the Gateways whose
+// routes forward to it.
+func (g *gatewayReconciler) gatewaysForBackend(obj client.Object) []reconcile.Request {
+	routeList := &gwv1beta1.HTTPRouteList{}
+	if err := g.k8sClient.List(context.Background(), routeList, client.InNamespace(obj.GetNamespace())); err != nil {
+		g.logger.Error(err, "list httproutes", "service", util.Key(obj))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	seen := make(map[types.NamespacedName]struct{})
+	for i := range routeList.Items {
+		route := &routeList.Items[i]
+		if !routeReferencesService(route, obj.GetName()) {
+			continue
+		}
+		for _, key := range parentGatewayKeys(route) {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			requests = append(requests, reconcile.Request{NamespacedName: key})
+		}
+	}
+	return requests
+}
+
+// gatewaysForSecret maps a Secret to the Gateways whose listeners use it as
+// a certificate.
+func (g *gatewayReconciler) gatewaysForSecret(obj client.Object) []reconcile.Request {
+	gwList := &gwv1beta1.GatewayList{}
+	if err := g.k8sClient.List(context.Background(), gwList, client.InNamespace(obj.GetNamespace())); err != nil {
+		g.logger.Error(err, "list gateways", "secret", util.Key(obj))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	for _, gw := range gwList.Items {
+		if gatewayReferencesSecret(&gw, obj.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: gw.Namespace, Name: gw.Name},
+			})
+		}
+	}
+	return requests
+}
+
+func routeReferencesService(route *gwv1beta1.HTTPRoute, name string) bool {
+	for _, rule := range route.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			if string(ref.Name) == name && isResolvableBackendRef(route, ref.BackendObjectReference) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func gatewayReferencesSecret(gw *gwv1beta1.Gateway, name string) bool {
+	for _, l := range gw.Spec.Listeners {
+		if l.TLS == nil {
+			continue
+		}
+		for _, ref := range l.TLS.CertificateRefs {
+			if string(ref.Name) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
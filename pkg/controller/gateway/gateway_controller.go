@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/alibaba-load-balancer-controller/pkg/context/shared"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gwv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+const (
+	// ControllerName is the GatewayClass controllerName handled by this controller.
+	ControllerName gwv1beta1.GatewayController = "alibabacloud.com/alb"
+
+	gatewayControllerName      = "gateway-controller"
+	gatewayClassControllerName = "gatewayclass-controller"
+
+	defaultMaxConcurrentReconciles = 3
+)
+
+type gatewayController struct {
+	c     controller.Controller
+	recon *gatewayReconciler
+}
+
+func (gwC gatewayController) Start(ctx context.Context) error {
+	klog.Infof("gatewayController start")
+	go gwC.recon.Start(ctx)
+	if _, err := gwC.recon.store.WaitCache(gwC.recon.stopCh); err != nil {
+		return err
+	}
+	return gwC.c.Start(ctx)
+}
+
+// Add creates the Gateway API controllers and adds them to the Manager. The
+// Gateway API CRDs must be installed before enabling the "gateway" controller.
+func Add(mgr manager.Manager, ctx *shared.SharedContext) error {
+	if err := addGatewayClassController(mgr); err != nil {
+		return err
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gwv1beta1.HTTPRoute{},
+		routeParentGatewayIndex, indexRouteParentGateways); err != nil {
+		return err
+	}
+
+	rateLimit := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(5*time.Second, 300*time.Second),
+		// 10 qps, 100 bucket size.  This is only for retry speed and its only the overall factor (not per item)
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+	r, err := NewGatewayReconciler(mgr, ctx)
+	if err != nil {
+		return err
+	}
+	c, err := controller.NewUnmanaged(
+		gatewayControllerName, mgr,
+		controller.Options{
+			Reconciler:              r,
+			MaxConcurrentReconciles: r.maxConcurrentReconciles,
+			RateLimiter:             rateLimit,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	klog.Infof("setupWatches start")
+	if err := c.Watch(&source.Kind{Type: &gwv1beta1.Gateway{}},
+		NewEnqueueRequestsForGatewayEvent(r.k8sClient, r.eventRecorder, r.logger)); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &gwv1beta1.HTTPRoute{}},
+		NewEnqueueRequestsForHTTPRouteEvent(r.k8sClient, r.eventRecorder, r.logger)); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &gwv1beta1.GatewayClass{}},
+		handler.EnqueueRequestsFromMapFunc(r.gatewaysForGatewayClass)); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &corev1.Service{}},
+		handler.EnqueueRequestsFromMapFunc(r.gatewaysForBackend)); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &corev1.Endpoints{}},
+		handler.EnqueueRequestsFromMapFunc(r.gatewaysForBackend)); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(r.gatewaysForSecret)); err != nil {
+		return err
+	}
+
+	klog.Infof("Add start")
+	return mgr.Add(&gatewayController{c: c, recon: r})
+}
+
+func addGatewayClassController(mgr manager.Manager) error {
+	r := newGatewayClassReconciler(mgr)
+	c, err := controller.New(gatewayClassControllerName, mgr, controller.Options{
+		Reconciler: r,
+	})
+	if err != nil {
+		return err
+	}
+	return c.Watch(&source.Kind{Type: &gwv1beta1.GatewayClass{}}, &handler.EnqueueRequestForObject{})
+}
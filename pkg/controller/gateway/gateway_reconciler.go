@@ -0,0 +1,352 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	sdkutils "github.com/aliyun/alibaba-cloud-sdk-go/sdk/utils"
+	"github.com/go-logr/logr"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/context/shared"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/applier"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/backend"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	servicemanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/service_manager"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/store"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gwv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func NewGatewayReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*gatewayReconciler, error) {
+	logger := ctrl.Log.WithName("controllers").WithName(gatewayControllerName)
+	kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	r := &gatewayReconciler{
+		cloud:               ctx.Provider(),
+		k8sClient:           mgr.GetClient(),
+		eventRecorder:       mgr.GetEventRecorderFor("gateway"),
+		stackMarshaller:     ingress.NewDefaultStackMarshaller(),
+		logger:              logger,
+		translator:          newTranslator(mgr.GetClient(), logger),
+		albconfigBuilder:    albconfigmanager.NewDefaultAlbConfigManagerBuilder(mgr.GetClient(), ctx.Provider(), logger),
+		serverApplier:       applier.NewServiceManagerApplier(mgr.GetClient(), ctx.Provider(), logger),
+		k8sFinalizerManager: helper.NewDefaultFinalizerManager(mgr.GetClient()),
+		stopCh:              make(chan struct{}),
+
+		maxConcurrentReconciles: defaultMaxConcurrentReconciles,
+	}
+	// the backend manager only reads endpoints and pods from the store, the
+	// other objects come from the manager client
+	r.store = store.NewBackendStore("", 0, kubeClient)
+	r.serverBuilder = servicemanager.NewDefaultServiceStackBuilder(backend.NewBackendManager(r.store, mgr.GetClient(), ctx.Provider(), logger))
+	r.albconfigApplier = applier.NewAlbConfigManagerApplier(r.store, mgr.GetClient(), ctx.Provider(), util.IngressTagKeyPrefix, logger)
+	return r, nil
+}
+
+type gatewayReconciler struct {
+	cloud               prvd.Provider
+	k8sClient           client.Client
+	eventRecorder       record.EventRecorder
+	stackMarshaller     ingress.StackMarshaller
+	logger              logr.Logger
+	store               store.Storer
+	translator          *translator
+	albconfigBuilder    albconfigmanager.Builder
+	albconfigApplier    applier.AlbConfigManagerApplier
+	serverBuilder       servicemanager.Builder
+	serverApplier       applier.ServiceManagerApplier
+	k8sFinalizerManager helper.FinalizerManager
+	stopCh              chan struct{}
+
+	maxConcurrentReconciles int
+}
+
+// Start runs the informers of the backend store until ctx is done.
+func (g *gatewayReconciler) Start(ctx context.Context) {
+	g.logger.Info("Starting ALB Gateway controller")
+	g.store.Run(g.stopCh)
+	<-ctx.Done()
+	close(g.stopCh)
+}
+
+func (g *gatewayReconciler) Reconcile(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+	// new context for each request
+	ctx := context.Background()
+	traceID := sdkutils.GetUUID()
+	ctx = context.WithValue(ctx, util.TraceID, traceID)
+
+	var err error
+	startTime := time.Now()
+	g.logger.Info("start reconcile",
+		"request", req.String(),
+		"traceID", traceID,
+		"startTime", startTime)
+	defer func() {
+		if rec := recover(); rec != nil {
+			perr := fmt.Errorf("panic recover: %v", rec)
+			g.logger.Error(perr, "finish reconcile",
+				"request", req.String(),
+				"traceID", traceID,
+				"elapsedTime", time.Since(startTime).Milliseconds(),
+				"panicStack", string(debug.Stack()))
+			return
+		}
+		if err != nil {
+			g.logger.Error(err, "finish reconcile",
+				"request", req.String(),
+				"traceID", traceID,
+				"elapsedTime", time.Since(startTime).Milliseconds())
+			return
+		}
+		g.logger.Info("finish reconcile",
+			"request", req.String(),
+			"traceID", traceID,
+			"elapsedTime", time.Since(startTime).Milliseconds())
+	}()
+
+	err = g.reconcile(ctx, req)
+	return reconcile.Result{}, err
+}
+
+func (g *gatewayReconciler) reconcile(ctx context.Context, request reconcile.Request) error {
+	gw := &gwv1beta1.Gateway{}
+	if err := g.k8sClient.Get(ctx, request.NamespacedName, gw); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	gwc := &gwv1beta1.GatewayClass{}
+	if err := g.k8sClient.Get(ctx, types.NamespacedName{Name: string(gw.Spec.GatewayClassName)}, gwc); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		gwc = &gwv1beta1.GatewayClass{}
+	}
+	managed := gwc.Spec.ControllerName == ControllerName
+	if !managed && !helper.HasFinalizer(gw, util.GatewayFinalizer) {
+		return nil
+	}
+
+	if !managed || !gw.DeletionTimestamp.IsZero() {
+		return g.cleanupGatewayResources(ctx, gwc, gw)
+	}
+	return g.reconcileGatewayResources(ctx, gwc, gw)
+}
+
+// cleanupGatewayResources releases the ALB resources of a Gateway that is
+// deleted or no longer belongs to this controller.
+func (g *gatewayReconciler) cleanupGatewayResources(ctx context.Context, gwc *gwv1beta1.GatewayClass, gw *gwv1beta1.Gateway) error {
+	if !helper.HasFinalizer(gw, util.GatewayFinalizer) {
+		return nil
+	}
+	deleting := gw.DeepCopy()
+	if deleting.DeletionTimestamp.IsZero() {
+		now := metav1.Now()
+		deleting.DeletionTimestamp = &now
+	}
+	model, err := g.translator.translate(ctx, gwc, deleting)
+	if err != nil {
+		return err
+	}
+	ctx = withReuseLb(ctx, model.albconfig)
+	if _, _, _, err := g.buildAndApply(ctx, gw, model); err != nil {
+		return err
+	}
+	if err := g.removeRouteParentStatus(ctx, gw); err != nil {
+		g.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedUpdateStatus, helper.GetLogMessage(err))
+		return err
+	}
+	if err := g.k8sFinalizerManager.RemoveFinalizers(ctx, gw, util.GatewayFinalizer); err != nil {
+		g.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedRemoveFinalizer, fmt.Sprintf("Failed remove finalizer due to %v", err))
+		return err
+	}
+	return nil
+}
+
+func (g *gatewayReconciler) reconcileGatewayResources(ctx context.Context, gwc *gwv1beta1.GatewayClass, gw *gwv1beta1.Gateway) error {
+	if err := g.k8sFinalizerManager.AddFinalizers(ctx, gw, util.GatewayFinalizer); err != nil {
+		g.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedAddFinalizer, helper.GetLogMessage(err))
+		return err
+	}
+	model, err := g.translator.translate(ctx, gwc, gw)
+	if err != nil {
+		return err
+	}
+	ctx = withReuseLb(ctx, model.albconfig)
+
+	_, lb, errResWithIngress, applyErr := g.buildAndApply(ctx, gw, model)
+	if err := g.updateStatus(ctx, gw, model, lb, errResWithIngress, applyErr); err != nil {
+		g.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedUpdateStatus, helper.GetLogMessage(err))
+		if applyErr == nil {
+			return err
+		}
+	}
+	if applyErr != nil {
+		return applyErr
+	}
+
+	if err := g.syncServers(ctx, model); err != nil {
+		g.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.ServiceEventReasonFailedUpdateEndpoints, helper.GetLogMessage(err))
+		return err
+	}
+	g.eventRecorder.Event(gw, corev1.EventTypeNormal, helper.IngressEventReasonSuccessfullyReconciled, "Successfully reconciled")
+	return nil
+}
+
+func withReuseLb(ctx context.Context, albconfig *v1.AlbConfig) context.Context {
+	if len(albconfig.Spec.LoadBalancer.Id) != 0 {
+		return context.WithValue(ctx, util.IsReuseLb, true)
+	}
+	return ctx
+}
+
+func (g *gatewayReconciler) buildAndApply(ctx context.Context, gw *gwv1beta1.Gateway, model *gatewayModel) (core.Manager, *albmodel.AlbLoadBalancer, map[*networking.Ingress]error, error) {
+	traceID := ctx.Value(util.TraceID)
+
+	buildStartTime := time.Now()
+	g.logger.Info("start build and apply stack",
+		"gateway", util.Key(gw),
+		"traceID", traceID,
+		"startTime", buildStartTime)
+
+	stack, lb, errResWithIngress, err := g.albconfigBuilder.Build(ctx, model.albconfig, model.group)
+	if err != nil {
+		g.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
+		return nil, nil, errResWithIngress, err
+	}
+
+	stackJSON, err := g.stackMarshaller.Marshal(stack)
+	if err != nil {
+		g.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
+		return nil, nil, nil, err
+	}
+	g.logger.Info("successfully built gateway stack",
+		"gateway", util.Key(gw),
+		"traceID", traceID,
+		"stack", stackJSON,
+		"buildElapsedTime", time.Since(buildStartTime).Milliseconds())
+
+	applyStartTime := time.Now()
+	if err := g.albconfigApplier.Apply(ctx, stack); err != nil {
+		g.eventRecorder.Event(gw, corev1.EventTypeWarning, helper.IngressEventReasonFailedApplyModel, helper.GetLogMessage(err))
+		return nil, nil, nil, err
+	}
+	g.logger.Info("successfully applied gateway stack",
+		"gateway", util.Key(gw),
+		"traceID", traceID,
+		"applyElapsedTime", time.Since(applyStartTime).Milliseconds())
+
+	return stack, lb, nil, nil
+}
+
+// syncServers registers the endpoints of every Service referenced by the
+// attached routes into the server groups created for them.
+func (g *gatewayReconciler) syncServers(ctx context.Context, model *gatewayModel) error {
+	ingressAlbConfigMap := make(map[string]string)
+	for _, ing := range model.group.Members {
+		ingressAlbConfigMap[util.Key(ing)] = model.group.ID.String()
+	}
+
+	svcKeys := make([]types.NamespacedName, 0, len(model.servicePortToIngressNames))
+	for svcKey := range model.servicePortToIngressNames {
+		svcKeys = append(svcKeys, svcKey)
+	}
+	sort.Slice(svcKeys, func(i, j int) bool {
+		return svcKeys[i].String() < svcKeys[j].String()
+	})
+
+	var errs []error
+	for _, svcKey := range svcKeys {
+		svcStackCtx := &albmodel.ServiceStackContext{
+			ClusterID:                 g.cloud.ClusterID(),
+			ServiceNamespace:          svcKey.Namespace,
+			ServiceName:               svcKey.Name,
+			ServicePortToIngressNames: model.servicePortToIngressNames[svcKey],
+			IngressAlbConfigMap:       ingressAlbConfigMap,
+		}
+		svc := &corev1.Service{}
+		if err := g.k8sClient.Get(ctx, svcKey, svc); err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, err)
+				continue
+			}
+			svcStackCtx.IsServiceNotFound = true
+		} else {
+			svcStackCtx.Service = svc
+		}
+		if err := g.buildAndApplyServers(ctx, svcStackCtx); err != nil {
+			errs = append(errs, fmt.Errorf("service %s: %w", svcKey.String(), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (g *gatewayReconciler) buildAndApplyServers(ctx context.Context, svcStackCtx *albmodel.ServiceStackContext) error {
+	traceID := ctx.Value(util.TraceID)
+
+	buildStartTime := time.Now()
+	serverStack, err := g.serverBuilder.Build(ctx, svcStackCtx)
+	if err != nil {
+		return fmt.Errorf("build service stack model error: %v", err)
+	}
+	g.logger.Info("successfully built service stack",
+		"service", svcStackCtx.ServiceNamespace+"/"+svcStackCtx.ServiceName,
+		"traceID", traceID,
+		"buildElapsedTime", time.Since(buildStartTime).Milliseconds())
+
+	applyStartTime := time.Now()
+	if err := g.serverApplier.Apply(ctx, g.cloud, serverStack); err != nil {
+		return err
+	}
+	if serverStack.ContainsPotentialReadyEndpoints {
+		return fmt.Errorf("retry potential ready endpoints")
+	}
+	g.logger.Info("successfully applied service stack",
+		"service", svcStackCtx.ServiceNamespace+"/"+svcStackCtx.ServiceName,
+		"traceID", traceID,
+		"applyElapsedTime", time.Since(applyStartTime).Milliseconds())
+	return nil
+}
+
+// loadBalancerAnnotationsChanged reports whether a Gateway or GatewayClass
+// annotation that shapes the ALB instance changed.
+func loadBalancerAnnotationsChanged(oldAnno, newAnno map[string]string) bool {
+	for _, key := range []string{
+		annotations.LoadBalancerId,
+		annotations.OverrideListener,
+		annotations.LoadBalancerName,
+		annotations.AddressType,
+		annotations.AddressAllocatedMode,
+		annotations.ChargeType,
+		annotations.LoadBalancerEdition,
+		annotations.VswitchIds,
+		annotations.AccessLog,
+	} {
+		if oldAnno[key] != newAnno[key] {
+			return true
+		}
+	}
+	return false
+}
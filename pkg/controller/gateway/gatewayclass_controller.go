@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gwv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/go-logr/logr"
+)
+
+// gatewayClassReconciler accepts the GatewayClasses handled by this controller.
+type gatewayClassReconciler struct {
+	k8sClient client.Client
+	logger    logr.Logger
+}
+
+func newGatewayClassReconciler(mgr manager.Manager) *gatewayClassReconciler {
+	return &gatewayClassReconciler{
+		k8sClient: mgr.GetClient(),
+		logger:    ctrl.Log.WithName("controllers").WithName(gatewayClassControllerName),
+	}
+}
+
+func (r *gatewayClassReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	gwc := &gwv1beta1.GatewayClass{}
+	if err := r.k8sClient.Get(ctx, req.NamespacedName, gwc); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if gwc.Spec.ControllerName != ControllerName {
+		return reconcile.Result{}, nil
+	}
+
+	cond := metav1.Condition{
+		Type:               string(gwv1beta1.GatewayClassConditionStatusAccepted),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gwv1beta1.GatewayClassReasonAccepted),
+		ObservedGeneration: gwc.Generation,
+	}
+	// load balancer settings are read from annotations, no parameters kind is supported
+	if gwc.Spec.ParametersRef != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = string(gwv1beta1.GatewayClassReasonInvalidParameters)
+		cond.Message = fmt.Sprintf("parametersRef %s/%s is not supported, use annotations instead",
+			gwc.Spec.ParametersRef.Kind, gwc.Spec.ParametersRef.Name)
+	}
+
+	updated := gwc.DeepCopy()
+	meta.SetStatusCondition(&updated.Status.Conditions, cond)
+	if equality.Semantic.DeepEqual(gwc.Status, updated.Status) {
+		return reconcile.Result{}, nil
+	}
+	if err := r.k8sClient.Status().Update(ctx, updated); err != nil {
+		r.logger.Error(err, "update gatewayclass status", "gatewayclass", gwc.Name)
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,251 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	gwv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// updateStatus reports the result of a build and apply on the Gateway and on
+// the HTTPRoutes attached to it.
+func (g *gatewayReconciler) updateStatus(ctx context.Context, gw *gwv1beta1.Gateway, model *gatewayModel, lb *albmodel.AlbLoadBalancer,
+	errResWithIngress map[*networking.Ingress]error, applyErr error) error {
+	var errs []error
+	if err := g.updateGatewayStatus(ctx, gw, model, lb, applyErr); err != nil {
+		errs = append(errs, err)
+	}
+	if err := g.updateRouteStatuses(ctx, gw, model, errResWithIngress); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (g *gatewayReconciler) updateGatewayStatus(ctx context.Context, gw *gwv1beta1.Gateway, model *gatewayModel, lb *albmodel.AlbLoadBalancer, applyErr error) error {
+	updated := gw.DeepCopy()
+
+	accepted := 0
+	for _, ls := range model.listeners {
+		if ls.accepted {
+			accepted++
+		}
+	}
+	acceptedCond := metav1.Condition{
+		Type:               string(gwv1beta1.GatewayConditionAccepted),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gwv1beta1.GatewayReasonAccepted),
+		ObservedGeneration: gw.Generation,
+	}
+	if accepted != len(model.listeners) {
+		acceptedCond.Reason = string(gwv1beta1.GatewayReasonListenersNotValid)
+		acceptedCond.Message = fmt.Sprintf("%d of %d listeners are not valid", len(model.listeners)-accepted, len(model.listeners))
+		if accepted == 0 {
+			acceptedCond.Status = metav1.ConditionFalse
+		}
+	}
+	meta.SetStatusCondition(&updated.Status.Conditions, acceptedCond)
+
+	programmedCond := metav1.Condition{
+		Type:               string(gwv1beta1.GatewayConditionProgrammed),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gwv1beta1.GatewayReasonProgrammed),
+		ObservedGeneration: gw.Generation,
+	}
+	switch {
+	case applyErr != nil:
+		programmedCond.Status = metav1.ConditionFalse
+		programmedCond.Reason = string(gwv1beta1.GatewayReasonInvalid)
+		programmedCond.Message = helper.GetLogMessage(applyErr)
+	case lb == nil || lb.Status == nil || lb.Status.DNSName == "":
+		programmedCond.Status = metav1.ConditionFalse
+		programmedCond.Reason = string(gwv1beta1.GatewayReasonAddressNotAssigned)
+		programmedCond.Message = "load balancer has no dns name yet"
+	default:
+		addressType := gwv1beta1.HostnameAddressType
+		updated.Status.Addresses = []gwv1beta1.GatewayAddress{{
+			Type:  &addressType,
+			Value: lb.Status.DNSName,
+		}}
+	}
+	meta.SetStatusCondition(&updated.Status.Conditions, programmedCond)
+
+	group := gwv1beta1.Group(gwv1beta1.SchemeGroupVersion.Group)
+	listenerStatuses := make([]gwv1beta1.ListenerStatus, 0, len(model.listeners))
+	for _, ls := range model.listeners {
+		status := gwv1beta1.ListenerStatus{
+			Name: ls.listener.Name,
+			SupportedKinds: []gwv1beta1.RouteGroupKind{{
+				Group: &group,
+				Kind:  httpRouteKind,
+			}},
+			AttachedRoutes: ls.attachedRoutes,
+		}
+		for _, old := range gw.Status.Listeners {
+			if old.Name == ls.listener.Name {
+				status.Conditions = append([]metav1.Condition{}, old.Conditions...)
+				break
+			}
+		}
+		conditions := append([]metav1.Condition{}, ls.conditions...)
+		switch {
+		case !ls.accepted:
+			conditions = append(conditions, metav1.Condition{
+				Type:   string(gwv1beta1.ListenerConditionProgrammed),
+				Status: metav1.ConditionFalse,
+				Reason: string(gwv1beta1.ListenerReasonInvalid),
+			})
+		case applyErr != nil:
+			conditions = append(conditions, metav1.Condition{
+				Type:    string(gwv1beta1.ListenerConditionProgrammed),
+				Status:  metav1.ConditionFalse,
+				Reason:  string(gwv1beta1.ListenerReasonPending),
+				Message: helper.GetLogMessage(applyErr),
+			})
+		default:
+			conditions = append(conditions, metav1.Condition{
+				Type:   string(gwv1beta1.ListenerConditionProgrammed),
+				Status: metav1.ConditionTrue,
+				Reason: string(gwv1beta1.ListenerReasonProgrammed),
+			})
+		}
+		for _, cond := range conditions {
+			cond.ObservedGeneration = gw.Generation
+			meta.SetStatusCondition(&status.Conditions, cond)
+		}
+		listenerStatuses = append(listenerStatuses, status)
+	}
+	updated.Status.Listeners = listenerStatuses
+
+	if equality.Semantic.DeepEqual(gw.Status, updated.Status) {
+		return nil
+	}
+	if err := g.k8sClient.Status().Update(ctx, updated); err != nil {
+		return fmt.Errorf("update gateway %s status: %w", util.Key(gw), err)
+	}
+	return nil
+}
+
+// updateRouteStatuses writes the parent status of the Gateway on every
+// HTTPRoute referencing it, and removes it from the routes that no longer do.
+func (g *gatewayReconciler) updateRouteStatuses(ctx context.Context, gw *gwv1beta1.Gateway, model *gatewayModel, errResWithIngress map[*networking.Ingress]error) error {
+	buildErrs := make(map[*routeState]error)
+	for ing, err := range errResWithIngress {
+		if rs, ok := model.routeByIngress[util.Key(ing)]; ok {
+			buildErrs[rs] = err
+		}
+	}
+
+	desired := make(map[string][]gwv1beta1.RouteParentStatus)
+	for _, rs := range model.routes {
+		parents := make([]gwv1beta1.RouteParentStatus, 0, len(rs.parents))
+		for _, ps := range rs.parents {
+			parents = append(parents, buildRouteParentStatus(rs, ps, buildErrs[rs]))
+		}
+		desired[util.Key(rs.route)] = parents
+	}
+	return g.patchRouteParentStatuses(ctx, gw, desired)
+}
+
+// removeRouteParentStatus removes the parent status of the Gateway from all
+// HTTPRoutes.
+func (g *gatewayReconciler) removeRouteParentStatus(ctx context.Context, gw *gwv1beta1.Gateway) error {
+	return g.patchRouteParentStatuses(ctx, gw, nil)
+}
+
+func (g *gatewayReconciler) patchRouteParentStatuses(ctx context.Context, gw *gwv1beta1.Gateway, desired map[string][]gwv1beta1.RouteParentStatus) error {
+	routeList := &gwv1beta1.HTTPRouteList{}
+	if err := g.k8sClient.List(ctx, routeList); err != nil {
+		return err
+	}
+	var errs []error
+	for i := range routeList.Items {
+		route := &routeList.Items[i]
+		parents := make([]gwv1beta1.RouteParentStatus, 0, len(route.Status.Parents))
+		existing := make(map[int]gwv1beta1.RouteParentStatus)
+		for _, p := range route.Status.Parents {
+			if p.ControllerName == ControllerName && isParentRefOfGateway(p.ParentRef, route.Namespace, gw) {
+				for j, ref := range route.Spec.ParentRefs {
+					if equality.Semantic.DeepEqual(ref, p.ParentRef) {
+						existing[j] = p
+					}
+				}
+				continue
+			}
+			parents = append(parents, p)
+		}
+		for _, p := range desired[util.Key(route)] {
+			for j, ref := range route.Spec.ParentRefs {
+				if !equality.Semantic.DeepEqual(ref, p.ParentRef) {
+					continue
+				}
+				// keep the transition time of unchanged conditions
+				if old, ok := existing[j]; ok {
+					conditions := append([]metav1.Condition{}, old.Conditions...)
+					for _, cond := range p.Conditions {
+						meta.SetStatusCondition(&conditions, cond)
+					}
+					p.Conditions = conditions
+				}
+				break
+			}
+			parents = append(parents, p)
+		}
+		if equality.Semantic.DeepEqual(route.Status.Parents, parents) {
+			continue
+		}
+		updated := route.DeepCopy()
+		updated.Status.Parents = parents
+		if err := g.k8sClient.Status().Update(ctx, updated); err != nil {
+			errs = append(errs, fmt.Errorf("update httproute %s status: %w", util.Key(route), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func buildRouteParentStatus(rs *routeState, ps *routeParentState, buildErr error) gwv1beta1.RouteParentStatus {
+	accepted := metav1.Condition{
+		Type:               string(gwv1beta1.RouteConditionAccepted),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(ps.reason),
+		Message:            ps.message,
+		ObservedGeneration: rs.route.Generation,
+	}
+	switch {
+	case !ps.accepted:
+		accepted.Status = metav1.ConditionFalse
+	case rs.unsupported != "":
+		accepted.Status = metav1.ConditionFalse
+		accepted.Reason = string(gwv1beta1.RouteReasonUnsupportedValue)
+		accepted.Message = rs.unsupported
+	case buildErr != nil:
+		accepted.Status = metav1.ConditionFalse
+		accepted.Reason = string(gwv1beta1.RouteReasonUnsupportedValue)
+		accepted.Message = helper.GetLogMessage(buildErr)
+	}
+
+	resolvedRefs := rs.resolvedRefs
+	if resolvedRefs.Type == "" {
+		resolvedRefs = metav1.Condition{
+			Type:   string(gwv1beta1.RouteConditionResolvedRefs),
+			Status: metav1.ConditionTrue,
+			Reason: string(gwv1beta1.RouteReasonResolvedRefs),
+		}
+	}
+	resolvedRefs.ObservedGeneration = rs.route.Generation
+
+	status := gwv1beta1.RouteParentStatus{
+		ParentRef:      ps.ref,
+		ControllerName: ControllerName,
+	}
+	meta.SetStatusCondition(&status.Conditions, accepted)
+	meta.SetStatusCondition(&status.Conditions, resolvedRefs)
+	return status
+}
@@ -0,0 +1,166 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gwv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestBuildRouteParentStatus(t *testing.T) {
+	cases := []struct {
+		name           string
+		rs             *routeState
+		ps             *routeParentState
+		buildErr       error
+		acceptedStatus metav1.ConditionStatus
+		acceptedReason gwv1beta1.RouteConditionReason
+		resolvedStatus metav1.ConditionStatus
+	}{
+		{
+			name:           "accepted",
+			rs:             &routeState{route: &gwv1beta1.HTTPRoute{}},
+			ps:             &routeParentState{accepted: true, reason: gwv1beta1.RouteReasonAccepted},
+			acceptedStatus: metav1.ConditionTrue,
+			acceptedReason: gwv1beta1.RouteReasonAccepted,
+			resolvedStatus: metav1.ConditionTrue,
+		},
+		{
+			name:           "not allowed by listeners",
+			rs:             &routeState{route: &gwv1beta1.HTTPRoute{}},
+			ps:             &routeParentState{reason: gwv1beta1.RouteReasonNotAllowedByListeners},
+			acceptedStatus: metav1.ConditionFalse,
+			acceptedReason: gwv1beta1.RouteReasonNotAllowedByListeners,
+			resolvedStatus: metav1.ConditionTrue,
+		},
+		{
+			name:           "unsupported",
+			rs:             &routeState{route: &gwv1beta1.HTTPRoute{}, unsupported: "filter type X is not supported"},
+			ps:             &routeParentState{accepted: true, reason: gwv1beta1.RouteReasonAccepted},
+			acceptedStatus: metav1.ConditionFalse,
+			acceptedReason: gwv1beta1.RouteReasonUnsupportedValue,
+			resolvedStatus: metav1.ConditionTrue,
+		},
+		{
+			name:           "build error",
+			rs:             &routeState{route: &gwv1beta1.HTTPRoute{}},
+			ps:             &routeParentState{accepted: true, reason: gwv1beta1.RouteReasonAccepted},
+			buildErr:       fmt.Errorf("bad annotation"),
+			acceptedStatus: metav1.ConditionFalse,
+			acceptedReason: gwv1beta1.RouteReasonUnsupportedValue,
+			resolvedStatus: metav1.ConditionTrue,
+		},
+		{
+			name: "unresolved refs",
+			rs: &routeState{route: &gwv1beta1.HTTPRoute{}, resolvedRefs: metav1.Condition{
+				Type:   string(gwv1beta1.RouteConditionResolvedRefs),
+				Status: metav1.ConditionFalse,
+				Reason: string(gwv1beta1.RouteReasonBackendNotFound),
+			}},
+			ps:             &routeParentState{accepted: true, reason: gwv1beta1.RouteReasonAccepted},
+			acceptedStatus: metav1.ConditionTrue,
+			acceptedReason: gwv1beta1.RouteReasonAccepted,
+			resolvedStatus: metav1.ConditionFalse,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status := buildRouteParentStatus(c.rs, c.ps, c.buildErr)
+			assert.Equal(t, ControllerName, status.ControllerName)
+			accepted := meta.FindStatusCondition(status.Conditions, string(gwv1beta1.RouteConditionAccepted))
+			assert.NotNil(t, accepted)
+			assert.Equal(t, c.acceptedStatus, accepted.Status)
+			assert.Equal(t, string(c.acceptedReason), accepted.Reason)
+			resolved := meta.FindStatusCondition(status.Conditions, string(gwv1beta1.RouteConditionResolvedRefs))
+			assert.NotNil(t, resolved)
+			assert.Equal(t, c.resolvedStatus, resolved.Status)
+		})
+	}
+}
+
+func TestUpdateGatewayStatus(t *testing.T) {
+	readyLb := &albmodel.AlbLoadBalancer{Status: &albmodel.LoadBalancerStatus{DNSName: "alb.example.com"}}
+	cases := []struct {
+		name             string
+		listeners        []*listenerState
+		lb               *albmodel.AlbLoadBalancer
+		applyErr         error
+		acceptedStatus   metav1.ConditionStatus
+		programmedStatus metav1.ConditionStatus
+		programmedReason gwv1beta1.GatewayConditionReason
+		addresses        int
+	}{
+		{
+			name:             "programmed",
+			listeners:        []*listenerState{{listener: gwv1beta1.Listener{Name: "http"}, accepted: true}},
+			lb:               readyLb,
+			acceptedStatus:   metav1.ConditionTrue,
+			programmedStatus: metav1.ConditionTrue,
+			programmedReason: gwv1beta1.GatewayReasonProgrammed,
+			addresses:        1,
+		},
+		{
+			name:             "no dns name",
+			listeners:        []*listenerState{{listener: gwv1beta1.Listener{Name: "http"}, accepted: true}},
+			lb:               &albmodel.AlbLoadBalancer{},
+			acceptedStatus:   metav1.ConditionTrue,
+			programmedStatus: metav1.ConditionFalse,
+			programmedReason: gwv1beta1.GatewayReasonAddressNotAssigned,
+		},
+		{
+			name:             "apply failed",
+			listeners:        []*listenerState{{listener: gwv1beta1.Listener{Name: "http"}, accepted: true}},
+			applyErr:         fmt.Errorf("quota exceeded"),
+			acceptedStatus:   metav1.ConditionTrue,
+			programmedStatus: metav1.ConditionFalse,
+			programmedReason: gwv1beta1.GatewayReasonInvalid,
+		},
+		{
+			name:             "no valid listener",
+			listeners:        []*listenerState{{listener: gwv1beta1.Listener{Name: "tcp"}}},
+			lb:               readyLb,
+			acceptedStatus:   metav1.ConditionFalse,
+			programmedStatus: metav1.ConditionTrue,
+			programmedReason: gwv1beta1.GatewayReasonProgrammed,
+			addresses:        1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gw := testGateway()
+			scheme := runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(scheme)
+			_ = gwv1beta1.SchemeBuilder.AddToScheme(scheme)
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gw).Build()
+			g := &gatewayReconciler{
+				k8sClient:     k8sClient,
+				eventRecorder: record.NewFakeRecorder(10),
+				logger:        logr.Discard(),
+			}
+
+			model := &gatewayModel{listeners: c.listeners}
+			assert.Nil(t, g.updateGatewayStatus(context.TODO(), gw, model, c.lb, c.applyErr))
+
+			updated := &gwv1beta1.Gateway{}
+			assert.Nil(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(gw), updated))
+			accepted := meta.FindStatusCondition(updated.Status.Conditions, string(gwv1beta1.GatewayConditionAccepted))
+			assert.Equal(t, c.acceptedStatus, accepted.Status)
+			programmed := meta.FindStatusCondition(updated.Status.Conditions, string(gwv1beta1.GatewayConditionProgrammed))
+			assert.Equal(t, c.programmedStatus, programmed.Status)
+			assert.Equal(t, string(c.programmedReason), programmed.Reason)
+			assert.Equal(t, c.addresses, len(updated.Status.Addresses))
+			assert.Equal(t, len(c.listeners), len(updated.Status.Listeners))
+		})
+	}
+}
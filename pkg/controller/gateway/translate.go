@@ -0,0 +1,1241 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/configcache"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gwv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// albConfigNamePrefix prefixes the in-memory AlbConfig synthesized for a
+	// Gateway, so that its stack never collides with a real AlbConfig.
+	albConfigNamePrefix = "gw-"
+	// routeIngressNamePrefix prefixes the in-memory Ingresses synthesized for
+	// an HTTPRoute. The name ends up in the server group tags.
+	routeIngressNamePrefix = "httproute-"
+	// routeParentGatewayIndex indexes the HTTPRoutes by the namespace/name of
+	// the Gateways in their parentRefs.
+	routeParentGatewayIndex = "spec.parentRefs.gateway"
+	// useAnnotationPortName makes the ingress builder take the forward action
+	// from the actions annotation instead of the path backend.
+	useAnnotationPortName = "use-annotation"
+
+	insertHeaderValueTypeUserDefined = "UserDefined"
+	defaultRedirectStatusCode        = 302
+	unresolvedBackendStatusCode      = "500"
+	maxForwardWeight                 = 100
+)
+
+var (
+	gatewayKind   = gwv1beta1.Kind("Gateway")
+	httpRouteKind = gwv1beta1.Kind("HTTPRoute")
+	serviceKind   = gwv1beta1.Kind("Service")
+	secretKind    = gwv1beta1.Kind("Secret")
+)
+
+// gatewayModel is the ALB view of a Gateway: the AlbConfig and ingress group
+// fed to the albconfig manager builder, plus the bookkeeping needed to map the
+// build and apply results back to Gateway and HTTPRoute status.
+type gatewayModel struct {
+	albconfig *v1.AlbConfig
+	group     *albconfigmanager.Group
+
+	listeners []*listenerState
+	routes    []*routeState
+
+	// routeByIngress maps a synthesized ingress key to its route.
+	routeByIngress map[string]*routeState
+	// servicePortToIngressNames lists, per backend Service and port, the
+	// synthesized ingresses whose server groups carry its endpoints.
+	servicePortToIngressNames map[types.NamespacedName]map[int32][]string
+}
+
+type listenerState struct {
+	listener gwv1beta1.Listener
+	// accepted is false when the listener cannot be programmed on the ALB.
+	accepted       bool
+	conditions     []metav1.Condition
+	attachedRoutes int32
+	// hosts collects the hostnames of the routes attached to the listener,
+	// used to cover them with the listener certificates.
+	hosts []string
+}
+
+type routeState struct {
+	route     *gwv1beta1.HTTPRoute
+	parents   []*routeParentState
+	ingresses []*networking.Ingress
+
+	attachments []*routeAttachment
+	paths       []routePath
+	anno        map[string]string
+
+	resolvedRefs metav1.Condition
+	// unsupported is the reason the route cannot be translated, if any.
+	unsupported string
+}
+
+// routeAttachment is a route attached to listeners sharing the same
+// hostnames, it is translated to one or more ingresses.
+type routeAttachment struct {
+	route *routeState
+	key   string
+	name  string
+	hosts []string
+	ports []map[string]int32
+}
+
+type routeParentState struct {
+	ref       gwv1beta1.ParentReference
+	accepted  bool
+	reason    gwv1beta1.RouteConditionReason
+	message   string
+	listeners []*listenerState
+}
+
+type translator struct {
+	k8sClient client.Client
+	logger    logr.Logger
+}
+
+func newTranslator(k8sClient client.Client, logger logr.Logger) *translator {
+	return &translator{
+		k8sClient: k8sClient,
+		logger:    logger,
+	}
+}
+
+func albConfigName(gw *gwv1beta1.Gateway) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: gw.Namespace,
+		Name:      albConfigNamePrefix + gw.Name,
+	}
+}
+
+func (t *translator) translate(ctx context.Context, gwc *gwv1beta1.GatewayClass, gw *gwv1beta1.Gateway) (*gatewayModel, error) {
+	m := &gatewayModel{
+		routeByIngress:            make(map[string]*routeState),
+		servicePortToIngressNames: make(map[types.NamespacedName]map[int32][]string),
+	}
+	m.albconfig = t.buildAlbConfig(gwc, gw)
+	m.group = &albconfigmanager.Group{
+		ID: albconfigmanager.GroupID(albConfigName(gw)),
+	}
+
+	if err := t.buildListeners(ctx, gw, m); err != nil {
+		return nil, err
+	}
+	if !gw.DeletionTimestamp.IsZero() {
+		return m, nil
+	}
+	if err := t.buildRoutes(ctx, gw, m); err != nil {
+		return nil, err
+	}
+	t.buildCertificateIngresses(gw, m)
+	return m, nil
+}
+
+// buildAlbConfig synthesizes the AlbConfig of a Gateway. The load balancer
+// spec is read from the GatewayClass annotations and overridden by the
+// Gateway annotations, using the same keys as an Ingress.
+func (t *translator) buildAlbConfig(gwc *gwv1beta1.GatewayClass, gw *gwv1beta1.Gateway) *v1.AlbConfig {
+	anno := make(map[string]string)
+	for k, v := range gwc.Annotations {
+		anno[k] = v
+	}
+	for k, v := range gw.Annotations {
+		anno[k] = v
+	}
+	holder := &networking.Ingress{}
+	holder.Annotations = anno
+
+	id, _ := annotations.GetStringAnnotation(annotations.LoadBalancerId, holder)
+	albForceOverride := false
+	if override, err := annotations.GetStringAnnotation(annotations.OverrideListener, holder); err == nil && override == "true" {
+		albForceOverride = true
+	}
+	name, _ := annotations.GetStringAnnotation(annotations.LoadBalancerName, holder)
+	addressType, err := annotations.GetStringAnnotation(annotations.AddressType, holder)
+	if err != nil {
+		addressType = util.LoadBalancerAddressTypeInternet
+	}
+	addressAllocatedMode, err := annotations.GetStringAnnotation(annotations.AddressAllocatedMode, holder)
+	if err != nil {
+		addressAllocatedMode = util.LoadBalancerAddressAllocatedModeDynamic
+	}
+	chargeType, err := annotations.GetStringAnnotation(annotations.ChargeType, holder)
+	if err != nil {
+		chargeType = util.LoadBalancerPayTypePostPay
+	}
+	edition, err := annotations.GetStringAnnotation(annotations.LoadBalancerEdition, holder)
+	if err != nil {
+		edition = util.LoadBalancerEditionStandard
+	}
+	var al albmodel.AccessLog
+	if accessLog, err := annotations.GetStringAnnotation(annotations.AccessLog, holder); err == nil {
+		if err := json.Unmarshal([]byte(accessLog), &al); err != nil {
+			t.logger.Error(err, "Unmarshal access log annotation", "gateway", util.Key(gw))
+		}
+	}
+
+	key := albConfigName(gw)
+	albconfig := &v1.AlbConfig{}
+	albconfig.Namespace = key.Namespace
+	albconfig.Name = key.Name
+	albconfig.DeletionTimestamp = gw.DeletionTimestamp
+	deletionProtectionEnabled := true
+	albconfig.Spec.LoadBalancer = &v1.LoadBalancerSpec{
+		Id:                        id,
+		ForceOverride:             &albForceOverride,
+		Name:                      name,
+		AddressAllocatedMode:      addressAllocatedMode,
+		AddressType:               addressType,
+		DeletionProtectionEnabled: &deletionProtectionEnabled,
+		BillingConfig: v1.BillingConfig{
+			PayType: chargeType,
+		},
+		Edition: edition,
+		AccessLogConfig: v1.AccessLogConfig{
+			LogStore:   al.LogStore,
+			LogProject: al.LogProject,
+		},
+	}
+	if vswitchIds, err := annotations.GetStringAnnotation(annotations.VswitchIds, holder); err == nil && vswitchIds != "" {
+		zoneMappings := make([]v1.ZoneMapping, 0)
+		for _, vSwitchId := range strings.Split(vswitchIds, ",") {
+			zoneMappings = append(zoneMappings, v1.ZoneMapping{
+				VSwitchId: strings.TrimSpace(vSwitchId),
+			})
+		}
+		albconfig.Spec.LoadBalancer.ZoneMappings = zoneMappings
+	}
+	return albconfig
+}
+
+func (t *translator) buildListeners(ctx context.Context, gw *gwv1beta1.Gateway, m *gatewayModel) error {
+	protocolsByPort := make(map[gwv1beta1.PortNumber]map[gwv1beta1.ProtocolType]struct{})
+	for _, l := range gw.Spec.Listeners {
+		if _, ok := protocolsByPort[l.Port]; !ok {
+			protocolsByPort[l.Port] = make(map[gwv1beta1.ProtocolType]struct{})
+		}
+		protocolsByPort[l.Port][l.Protocol] = struct{}{}
+	}
+
+	albListeners := make(map[string]*v1.ListenerSpec)
+	for _, l := range gw.Spec.Listeners {
+		ls := &listenerState{listener: l}
+		m.listeners = append(m.listeners, ls)
+
+		if l.Protocol != gwv1beta1.HTTPProtocolType && l.Protocol != gwv1beta1.HTTPSProtocolType {
+			ls.setCondition(gw, gwv1beta1.ListenerConditionAccepted, metav1.ConditionFalse, gwv1beta1.ListenerReasonUnsupportedProtocol,
+				fmt.Sprintf("protocol %s is not supported, must be HTTP or HTTPS", l.Protocol))
+			continue
+		}
+		if len(protocolsByPort[l.Port]) > 1 {
+			ls.setCondition(gw, gwv1beta1.ListenerConditionConflicted, metav1.ConditionTrue, gwv1beta1.ListenerReasonProtocolConflict,
+				fmt.Sprintf("port %d is used by listeners with different protocols", l.Port))
+			ls.setCondition(gw, gwv1beta1.ListenerConditionAccepted, metav1.ConditionFalse, gwv1beta1.ListenerReasonProtocolConflict,
+				fmt.Sprintf("port %d is used by listeners with different protocols", l.Port))
+			continue
+		}
+		ls.setCondition(gw, gwv1beta1.ListenerConditionConflicted, metav1.ConditionFalse, gwv1beta1.ListenerReasonNoConflicts, "")
+
+		if err := validateAllowedRoutes(l); err != nil {
+			ls.setCondition(gw, gwv1beta1.ListenerConditionAccepted, metav1.ConditionFalse, gwv1beta1.ListenerReasonInvalid, err.Error())
+			continue
+		}
+
+		if l.Protocol == gwv1beta1.HTTPSProtocolType {
+			reason, msg, err := t.checkListenerCertificates(ctx, gw, l)
+			if err != nil {
+				return err
+			}
+			if reason != "" {
+				ls.setCondition(gw, gwv1beta1.ListenerConditionResolvedRefs, metav1.ConditionFalse, reason, msg)
+				ls.setCondition(gw, gwv1beta1.ListenerConditionAccepted, metav1.ConditionFalse, gwv1beta1.ListenerReasonInvalid, msg)
+				continue
+			}
+		}
+		ls.setCondition(gw, gwv1beta1.ListenerConditionResolvedRefs, metav1.ConditionTrue, gwv1beta1.ListenerReasonResolvedRefs, "")
+		ls.setCondition(gw, gwv1beta1.ListenerConditionAccepted, metav1.ConditionTrue, gwv1beta1.ListenerReasonAccepted, "")
+		ls.accepted = true
+
+		portAndProtocol := fmt.Sprintf("%d/%s", l.Port, l.Protocol)
+		if _, ok := albListeners[portAndProtocol]; !ok {
+			albListeners[portAndProtocol] = &v1.ListenerSpec{
+				Port:     intstr.FromInt(int(l.Port)),
+				Protocol: string(l.Protocol),
+			}
+		}
+	}
+
+	lss := make([]*v1.ListenerSpec, 0, len(albListeners))
+	for _, ls := range albListeners {
+		lss = append(lss, ls)
+	}
+	sort.Slice(lss, func(i, j int) bool {
+		return lss[i].Port.IntValue() < lss[j].Port.IntValue()
+	})
+	m.albconfig.Spec.Listeners = lss
+	return nil
+}
+
+// checkListenerCertificates returns a non-empty reason when the listener
+// certificate references cannot be resolved.
+func (t *translator) checkListenerCertificates(ctx context.Context, gw *gwv1beta1.Gateway, l gwv1beta1.Listener) (gwv1beta1.ListenerConditionReason, string, error) {
+	if l.TLS == nil || len(l.TLS.CertificateRefs) == 0 {
+		return gwv1beta1.ListenerReasonInvalidCertificateRef, "HTTPS listener requires at least one certificateRef", nil
+	}
+	if l.TLS.Mode != nil && *l.TLS.Mode != gwv1beta1.TLSModeTerminate {
+		return gwv1beta1.ListenerReasonInvalid, fmt.Sprintf("tls mode %s is not supported", *l.TLS.Mode), nil
+	}
+	for _, ref := range l.TLS.CertificateRefs {
+		if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != secretKind) {
+			return gwv1beta1.ListenerReasonInvalidCertificateRef, fmt.Sprintf("certificateRef %s must be a core Secret", ref.Name), nil
+		}
+		if ref.Namespace != nil && string(*ref.Namespace) != gw.Namespace {
+			return gwv1beta1.ListenerReasonRefNotPermitted, fmt.Sprintf("certificateRef %s/%s is in another namespace", *ref.Namespace, ref.Name), nil
+		}
+		secret := &corev1.Secret{}
+		err := t.k8sClient.Get(ctx, types.NamespacedName{Namespace: gw.Namespace, Name: string(ref.Name)}, secret)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return gwv1beta1.ListenerReasonInvalidCertificateRef, fmt.Sprintf("secret %s/%s not found", gw.Namespace, ref.Name), nil
+			}
+			return "", "", err
+		}
+	}
+	return "", "", nil
+}
+
+func (t *translator) buildRoutes(ctx context.Context, gw *gwv1beta1.Gateway, m *gatewayModel) error {
+	routeList := &gwv1beta1.HTTPRouteList{}
+	if err := t.k8sClient.List(ctx, routeList, client.MatchingFields{routeParentGatewayIndex: util.Key(gw)}); err != nil {
+		return err
+	}
+	routes := make([]*gwv1beta1.HTTPRoute, 0)
+	for i := range routeList.Items {
+		route := &routeList.Items[i]
+		if !route.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if len(parentRefsForGateway(route, gw)) == 0 {
+			continue
+		}
+		routes = append(routes, route)
+	}
+	// oldest route wins, same tie-break as the Gateway API conflict resolution
+	sort.Slice(routes, func(i, j int) bool {
+		if !routes[i].CreationTimestamp.Equal(&routes[j].CreationTimestamp) {
+			return routes[i].CreationTimestamp.Before(&routes[j].CreationTimestamp)
+		}
+		return util.Key(routes[i]) < util.Key(routes[j])
+	})
+
+	for _, route := range routes {
+		rs := &routeState{route: route}
+		m.routes = append(m.routes, rs)
+
+		for _, ref := range parentRefsForGateway(route, gw) {
+			ps, err := t.attachRoute(ctx, gw, route, ref, m.listeners)
+			if err != nil {
+				return err
+			}
+			rs.parents = append(rs.parents, ps)
+		}
+		t.buildRouteAttachments(ctx, rs)
+	}
+	buildRouteMembers(m)
+	return nil
+}
+
+// indexRouteParentGateways returns the keys of the Gateways an HTTPRoute
+// refers to, so that a Gateway lists only its own routes.
+func indexRouteParentGateways(obj client.Object) []string {
+	route, ok := obj.(*gwv1beta1.HTTPRoute)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(route.Spec.ParentRefs))
+	for _, ref := range route.Spec.ParentRefs {
+		if ref.Group != nil && string(*ref.Group) != gwv1beta1.SchemeGroupVersion.Group {
+			continue
+		}
+		if ref.Kind != nil && *ref.Kind != gatewayKind {
+			continue
+		}
+		ns := route.Namespace
+		if ref.Namespace != nil {
+			ns = string(*ref.Namespace)
+		}
+		key := types.NamespacedName{Namespace: ns, Name: string(ref.Name)}.String()
+		if !containsString(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func parentRefsForGateway(route *gwv1beta1.HTTPRoute, gw *gwv1beta1.Gateway) []gwv1beta1.ParentReference {
+	refs := make([]gwv1beta1.ParentReference, 0)
+	for _, ref := range route.Spec.ParentRefs {
+		if isParentRefOfGateway(ref, route.Namespace, gw) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+func isParentRefOfGateway(ref gwv1beta1.ParentReference, routeNamespace string, gw *gwv1beta1.Gateway) bool {
+	if ref.Group != nil && string(*ref.Group) != gwv1beta1.SchemeGroupVersion.Group {
+		return false
+	}
+	if ref.Kind != nil && *ref.Kind != gatewayKind {
+		return false
+	}
+	ns := routeNamespace
+	if ref.Namespace != nil {
+		ns = string(*ref.Namespace)
+	}
+	return ns == gw.Namespace && string(ref.Name) == gw.Name
+}
+
+func (t *translator) attachRoute(ctx context.Context, gw *gwv1beta1.Gateway, route *gwv1beta1.HTTPRoute, ref gwv1beta1.ParentReference, listeners []*listenerState) (*routeParentState, error) {
+	ps := &routeParentState{ref: ref}
+	matchedSection := false
+	for _, ls := range listeners {
+		if ref.SectionName != nil && *ref.SectionName != ls.listener.Name {
+			continue
+		}
+		if ref.Port != nil && *ref.Port != ls.listener.Port {
+			continue
+		}
+		matchedSection = true
+		if !ls.accepted {
+			continue
+		}
+		allowed, err := t.isRouteNamespaceAllowed(ctx, gw, ls.listener, route.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+		if _, ok := intersectHostnames(ls.listener.Hostname, route.Spec.Hostnames); !ok {
+			continue
+		}
+		ps.listeners = append(ps.listeners, ls)
+	}
+
+	switch {
+	case !matchedSection:
+		ps.reason = gwv1beta1.RouteReasonNoMatchingParent
+		ps.message = "no listener matches the parentRef sectionName or port"
+	case len(ps.listeners) == 0:
+		ps.reason = gwv1beta1.RouteReasonNotAllowedByListeners
+		ps.message = "no listener accepts the route namespace or hostnames"
+	default:
+		ps.accepted = true
+		ps.reason = gwv1beta1.RouteReasonAccepted
+	}
+	return ps, nil
+}
+
+func (t *translator) isRouteNamespaceAllowed(ctx context.Context, gw *gwv1beta1.Gateway, l gwv1beta1.Listener, routeNamespace string) (bool, error) {
+	from := gwv1beta1.NamespacesFromSame
+	var selector *metav1.LabelSelector
+	if l.AllowedRoutes != nil && l.AllowedRoutes.Namespaces != nil {
+		if l.AllowedRoutes.Namespaces.From != nil {
+			from = *l.AllowedRoutes.Namespaces.From
+		}
+		selector = l.AllowedRoutes.Namespaces.Selector
+	}
+	switch from {
+	case gwv1beta1.NamespacesFromAll:
+		return true, nil
+	case gwv1beta1.NamespacesFromSame:
+		return routeNamespace == gw.Namespace, nil
+	case gwv1beta1.NamespacesFromSelector:
+		if selector == nil {
+			return false, nil
+		}
+		s, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return false, fmt.Errorf("listener %s: invalid namespace selector: %w", l.Name, err)
+		}
+		ns := &corev1.Namespace{}
+		if err := t.k8sClient.Get(ctx, types.NamespacedName{Name: routeNamespace}, ns); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return s.Matches(labels.Set(ns.Labels)), nil
+	}
+	return false, nil
+}
+
+// validateAllowedRoutes checks the namespace selector of a listener, so that a
+// listener whose routes cannot be selected is reported instead of accepted.
+func validateAllowedRoutes(l gwv1beta1.Listener) error {
+	if l.AllowedRoutes == nil || l.AllowedRoutes.Namespaces == nil || l.AllowedRoutes.Namespaces.From == nil ||
+		*l.AllowedRoutes.Namespaces.From != gwv1beta1.NamespacesFromSelector {
+		return nil
+	}
+	if l.AllowedRoutes.Namespaces.Selector == nil {
+		return fmt.Errorf("allowedRoutes.namespaces.selector is required when from is %s", gwv1beta1.NamespacesFromSelector)
+	}
+	if _, err := metav1.LabelSelectorAsSelector(l.AllowedRoutes.Namespaces.Selector); err != nil {
+		return fmt.Errorf("invalid allowedRoutes.namespaces.selector: %v", err)
+	}
+	return nil
+}
+
+// intersectHostnames returns the hostnames a route serves on a listener.
+// An empty result with ok set means the route matches any host.
+func intersectHostnames(listenerHostname *gwv1beta1.Hostname, routeHostnames []gwv1beta1.Hostname) ([]string, bool) {
+	if listenerHostname == nil || *listenerHostname == "" {
+		hosts := make([]string, 0, len(routeHostnames))
+		for _, h := range routeHostnames {
+			hosts = append(hosts, string(h))
+		}
+		return hosts, true
+	}
+	if len(routeHostnames) == 0 {
+		return []string{string(*listenerHostname)}, true
+	}
+	hosts := make([]string, 0)
+	for _, h := range routeHostnames {
+		if host, ok := intersectHostname(string(*listenerHostname), string(h)); ok {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, len(hosts) != 0
+}
+
+func intersectHostname(a, b string) (string, bool) {
+	if a == b {
+		return a, true
+	}
+	if strings.HasPrefix(a, "*.") && strings.HasSuffix(b, a[1:]) {
+		return b, true
+	}
+	if strings.HasPrefix(b, "*.") && strings.HasSuffix(a, b[1:]) {
+		return a, true
+	}
+	return "", false
+}
+
+// buildRouteAttachments collects the listeners a route is attached to, by
+// distinct hostname set, along with the paths and the actions and conditions
+// annotations of the route. The ingresses are built later by
+// buildRouteMembers, which orders the paths of all routes together.
+func (t *translator) buildRouteAttachments(ctx context.Context, rs *routeState) {
+	attachments := make(map[string]*routeAttachment)
+	attached := make(map[*listenerState]struct{})
+	for _, ps := range rs.parents {
+		for _, ls := range ps.listeners {
+			if _, ok := attached[ls]; ok {
+				continue
+			}
+			attached[ls] = struct{}{}
+			hosts, _ := intersectHostnames(ls.listener.Hostname, rs.route.Spec.Hostnames)
+			sort.Strings(hosts)
+			key := strings.Join(hosts, ",")
+			att, ok := attachments[key]
+			if !ok {
+				att = &routeAttachment{route: rs, key: key, hosts: hosts}
+				attachments[key] = att
+				rs.attachments = append(rs.attachments, att)
+			}
+			att.ports = append(att.ports, map[string]int32{string(ls.listener.Protocol): int32(ls.listener.Port)})
+			ls.attachedRoutes++
+			ls.hosts = append(ls.hosts, hosts...)
+		}
+	}
+	if len(rs.attachments) == 0 {
+		return
+	}
+
+	paths, anno, unsupported := t.buildRoutePaths(ctx, rs)
+	if unsupported != "" {
+		rs.unsupported = unsupported
+		rs.attachments = nil
+		return
+	}
+	rs.paths = paths
+	rs.anno = anno
+	for _, att := range rs.attachments {
+		att.name = routeIngressNamePrefix + rs.route.Name
+		if len(rs.attachments) > 1 {
+			att.name = fmt.Sprintf("%s-%.8s", att.name, hashString(att.key))
+		}
+	}
+}
+
+// conflictsWith reports whether the rules of two attachments can match the
+// same request, that is they share a listener port and a hostname.
+func (a *routeAttachment) conflictsWith(b *routeAttachment) bool {
+	sharePort := false
+	for _, pa := range a.ports {
+		for _, pb := range b.ports {
+			for _, port := range pa {
+				for _, other := range pb {
+					if port == other {
+						sharePort = true
+					}
+				}
+			}
+		}
+	}
+	if !sharePort {
+		return false
+	}
+	if len(a.hosts) == 0 || len(b.hosts) == 0 {
+		return true
+	}
+	for _, ha := range a.hosts {
+		for _, hb := range b.hosts {
+			if _, ok := intersectHostname(ha, hb); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type routeChunk struct {
+	att   *routeAttachment
+	seq   int
+	paths []routePath
+}
+
+// buildRouteMembers builds the group members of the attached routes. The
+// builder gives rules their ALB priority from the member order, then from the
+// path order, so the paths of all routes are sorted together by match
+// precedence, the route creation time only breaking ties. Consecutive paths
+// of a route share an ingress, a new one is started when a path of a
+// conflicting route has to be placed in between.
+func buildRouteMembers(m *gatewayModel) {
+	type entry struct {
+		att  *routeAttachment
+		path routePath
+	}
+	entries := make([]entry, 0)
+	for _, rs := range m.routes {
+		for _, att := range rs.attachments {
+			for _, rp := range rs.paths {
+				entries = append(entries, entry{att: att, path: rp})
+			}
+		}
+	}
+	// routes are already sorted by creation time and paths by rule index
+	sort.SliceStable(entries, func(i, j int) bool {
+		return matchPrecedes(entries[i].path.match, entries[j].path.match)
+	})
+
+	chunks := make([]*routeChunk, 0)
+	current := make(map[*routeAttachment]int)
+	seqs := make(map[*routeAttachment]int)
+	for _, e := range entries {
+		idx, ok := current[e.att]
+		if ok {
+			for _, c := range chunks[idx+1:] {
+				if c.att != e.att && c.att.conflictsWith(e.att) {
+					ok = false
+					break
+				}
+			}
+		}
+		if !ok {
+			idx = len(chunks)
+			current[e.att] = idx
+			chunks = append(chunks, &routeChunk{att: e.att, seq: seqs[e.att]})
+			seqs[e.att]++
+		}
+		chunks[idx].paths = append(chunks[idx].paths, e.path)
+	}
+
+	for _, c := range chunks {
+		rs := c.att.route
+		ing := &networking.Ingress{}
+		ing.Namespace = rs.route.Namespace
+		ing.Name = c.att.name
+		if c.seq > 0 {
+			ing.Name = fmt.Sprintf("%s-%d", ing.Name, c.seq)
+		}
+		ing.Annotations = make(map[string]string)
+		// server group settings (health check, scheduler...) use the
+		// same annotations as an Ingress
+		for k, v := range rs.route.Annotations {
+			if strings.HasPrefix(k, annotations.AnnotationLoadBalancerPrefix) {
+				ing.Annotations[k] = v
+			}
+		}
+		for k, v := range rs.anno {
+			ing.Annotations[k] = v
+		}
+		listenPorts, _ := json.Marshal(c.att.ports)
+		ing.Annotations[annotations.ListenPorts] = string(listenPorts)
+
+		paths := make([]networking.HTTPIngressPath, 0, len(c.paths))
+		ruleIdxs := make(map[int]struct{})
+		for _, rp := range c.paths {
+			paths = append(paths, rp.path)
+			ruleIdxs[rp.ruleIdx] = struct{}{}
+		}
+		hosts := c.att.hosts
+		if len(hosts) == 0 {
+			hosts = []string{""}
+		}
+		for _, host := range hosts {
+			ing.Spec.Rules = append(ing.Spec.Rules, networking.IngressRule{
+				Host: host,
+				IngressRuleValue: networking.IngressRuleValue{
+					HTTP: &networking.HTTPIngressRuleValue{
+						Paths: paths,
+					},
+				},
+			})
+		}
+		rs.ingresses = append(rs.ingresses, ing)
+		m.group.Members = append(m.group.Members, ing)
+		m.routeByIngress[util.Key(ing)] = rs
+
+		// only the services forwarded to by the paths of the ingress get a server group
+		for ri, rule := range rs.route.Spec.Rules {
+			if _, ok := ruleIdxs[ri]; !ok {
+				continue
+			}
+			for _, ref := range rule.BackendRefs {
+				if !isResolvableBackendRef(rs.route, ref.BackendObjectReference) {
+					continue
+				}
+				svcKey := types.NamespacedName{Namespace: rs.route.Namespace, Name: string(ref.Name)}
+				if _, ok := m.servicePortToIngressNames[svcKey]; !ok {
+					m.servicePortToIngressNames[svcKey] = make(map[int32][]string)
+				}
+				port := int32(*ref.Port)
+				if !containsString(m.servicePortToIngressNames[svcKey][port], ing.Name) {
+					m.servicePortToIngressNames[svcKey][port] = append(m.servicePortToIngressNames[svcKey][port], ing.Name)
+				}
+			}
+		}
+	}
+}
+
+type routePath struct {
+	path     networking.HTTPIngressPath
+	match    gwv1beta1.HTTPRouteMatch
+	ruleIdx  int
+	matchIdx int
+}
+
+// buildRoutePaths returns the ingress paths of a route along with the
+// actions and conditions annotations they refer to. A non-empty unsupported
+// message means the route uses a feature ALB cannot express.
+func (t *translator) buildRoutePaths(ctx context.Context, rs *routeState) ([]routePath, map[string]string, string) {
+	anno := make(map[string]string)
+	rps := make([]routePath, 0)
+	var refErrors []string
+	refReason := gwv1beta1.RouteReasonResolvedRefs
+
+	for ri, rule := range rs.route.Spec.Rules {
+		actions, reason, refErrs, unsupported := t.buildRuleActions(ctx, rs.route, rule)
+		if unsupported != "" {
+			return nil, nil, fmt.Sprintf("rules[%d]: %s", ri, unsupported)
+		}
+		if len(refErrs) != 0 {
+			refReason = reason
+			refErrors = append(refErrors, refErrs...)
+		}
+		actionsJSON, _ := json.Marshal(actions)
+
+		matches := rule.Matches
+		if len(matches) == 0 {
+			matches = []gwv1beta1.HTTPRouteMatch{{}}
+		}
+		for mi, match := range matches {
+			backendKey := fmt.Sprintf("rule-%d-%d", ri, mi)
+			path, pathType, err := convertPathMatch(match.Path)
+			if err != nil {
+				return nil, nil, fmt.Sprintf("rules[%d].matches[%d]: %s", ri, mi, err.Error())
+			}
+			conditions, err := convertMatchConditions(match)
+			if err != nil {
+				return nil, nil, fmt.Sprintf("rules[%d].matches[%d]: %s", ri, mi, err.Error())
+			}
+			if len(conditions) != 0 {
+				conditionsJSON, _ := json.Marshal(conditions)
+				anno[fmt.Sprintf(annotations.INGRESS_ALB_CONDITIONS_ANNOTATIONS, backendKey)] = string(conditionsJSON)
+			}
+			anno[fmt.Sprintf(annotations.INGRESS_ALB_ACTIONS_ANNOTATIONS, backendKey)] = string(actionsJSON)
+			rps = append(rps, routePath{
+				path: networking.HTTPIngressPath{
+					Path:     path,
+					PathType: &pathType,
+					Backend: networking.IngressBackend{
+						Service: &networking.IngressServiceBackend{
+							Name: backendKey,
+							Port: networking.ServiceBackendPort{
+								Name: useAnnotationPortName,
+							},
+						},
+					},
+				},
+				match:    match,
+				ruleIdx:  ri,
+				matchIdx: mi,
+			})
+		}
+	}
+
+	if len(refErrors) != 0 {
+		rs.resolvedRefs = metav1.Condition{
+			Type:    string(gwv1beta1.RouteConditionResolvedRefs),
+			Status:  metav1.ConditionFalse,
+			Reason:  string(refReason),
+			Message: strings.Join(refErrors, "; "),
+		}
+	} else {
+		rs.resolvedRefs = metav1.Condition{
+			Type:   string(gwv1beta1.RouteConditionResolvedRefs),
+			Status: metav1.ConditionTrue,
+			Reason: string(gwv1beta1.RouteReasonResolvedRefs),
+		}
+	}
+
+	return rps, anno, ""
+}
+
+// matchPrecedes orders matches as the Gateway API requires: exact paths,
+// then longer prefixes, then method, header and query param matches.
+func matchPrecedes(a, b gwv1beta1.HTTPRouteMatch) bool {
+	aType, aValue := pathMatchTypeAndValue(a.Path)
+	bType, bValue := pathMatchTypeAndValue(b.Path)
+	if (aType == gwv1beta1.PathMatchExact) != (bType == gwv1beta1.PathMatchExact) {
+		return aType == gwv1beta1.PathMatchExact
+	}
+	if len(aValue) != len(bValue) {
+		return len(aValue) > len(bValue)
+	}
+	if (a.Method != nil) != (b.Method != nil) {
+		return a.Method != nil
+	}
+	if len(a.Headers) != len(b.Headers) {
+		return len(a.Headers) > len(b.Headers)
+	}
+	return len(a.QueryParams) > len(b.QueryParams)
+}
+
+func pathMatchTypeAndValue(p *gwv1beta1.HTTPPathMatch) (gwv1beta1.PathMatchType, string) {
+	pathType, value := gwv1beta1.PathMatchPathPrefix, "/"
+	if p == nil {
+		return pathType, value
+	}
+	if p.Type != nil {
+		pathType = *p.Type
+	}
+	if p.Value != nil {
+		value = *p.Value
+	}
+	return pathType, value
+}
+
+func convertPathMatch(p *gwv1beta1.HTTPPathMatch) (string, networking.PathType, error) {
+	pathType, value := pathMatchTypeAndValue(p)
+	switch pathType {
+	case gwv1beta1.PathMatchPathPrefix:
+		return value, networking.PathTypePrefix, nil
+	case gwv1beta1.PathMatchExact:
+		return value, networking.PathTypeExact, nil
+	case gwv1beta1.PathMatchRegularExpression:
+		return "~*" + value, networking.PathTypeImplementationSpecific, nil
+	}
+	return "", "", fmt.Errorf("path match type %s is not supported", pathType)
+}
+
+func convertMatchConditions(match gwv1beta1.HTTPRouteMatch) ([]configcache.Condition, error) {
+	conditions := make([]configcache.Condition, 0)
+	for _, h := range match.Headers {
+		if h.Type != nil && *h.Type != gwv1beta1.HeaderMatchExact {
+			return nil, fmt.Errorf("header match type %s is not supported", *h.Type)
+		}
+		conditions = append(conditions, configcache.Condition{
+			Type: util.RuleConditionFieldHeader,
+			HeaderConfig: configcache.HeaderConfig{
+				Key:    string(h.Name),
+				Values: []string{h.Value},
+			},
+		})
+	}
+	for _, q := range match.QueryParams {
+		if q.Type != nil && *q.Type != gwv1beta1.QueryParamMatchExact {
+			return nil, fmt.Errorf("query param match type %s is not supported", *q.Type)
+		}
+		conditions = append(conditions, configcache.Condition{
+			Type: util.RuleConditionFieldQueryString,
+			QueryStringConfig: configcache.QueryStringConfig{
+				Values: []configcache.Value{{
+					Key:   q.Name,
+					Value: q.Value,
+				}},
+			},
+		})
+	}
+	if match.Method != nil {
+		conditions = append(conditions, configcache.Condition{
+			Type: util.RuleConditionFieldMethod,
+			MethodConfig: configcache.MethodConfig{
+				Values: []string{string(*match.Method)},
+			},
+		})
+	}
+	return conditions, nil
+}
+
+// buildRuleActions converts the filters and backendRefs of a route rule to
+// custom actions. It returns the references that could not be resolved and
+// the reason for them, or a non-empty unsupported message.
+func (t *translator) buildRuleActions(ctx context.Context, route *gwv1beta1.HTTPRoute, rule gwv1beta1.HTTPRouteRule) ([]configcache.Action, gwv1beta1.RouteConditionReason, []string, string) {
+	actions := make([]configcache.Action, 0)
+	redirect := false
+	for _, f := range rule.Filters {
+		switch f.Type {
+		case gwv1beta1.HTTPRouteFilterRequestHeaderModifier:
+			if f.RequestHeaderModifier == nil {
+				return nil, "", nil, "requestHeaderModifier is empty"
+			}
+			for _, h := range f.RequestHeaderModifier.Set {
+				actions = append(actions, buildInsertHeaderAction(h, true))
+			}
+			for _, h := range f.RequestHeaderModifier.Add {
+				actions = append(actions, buildInsertHeaderAction(h, false))
+			}
+			for _, name := range f.RequestHeaderModifier.Remove {
+				actions = append(actions, configcache.Action{
+					Type: util.RuleActionTypeRemoveHeader,
+					RemoveHeaderConfig: &configcache.RemoveHeaderConfig{
+						Key: name,
+					},
+				})
+			}
+		case gwv1beta1.HTTPRouteFilterRequestRedirect:
+			action, err := buildRedirectAction(f.RequestRedirect)
+			if err != nil {
+				return nil, "", nil, err.Error()
+			}
+			actions = append(actions, action)
+			redirect = true
+		case gwv1beta1.HTTPRouteFilterURLRewrite:
+			action, err := buildRewriteAction(f.URLRewrite)
+			if err != nil {
+				return nil, "", nil, err.Error()
+			}
+			actions = append(actions, action)
+		default:
+			return nil, "", nil, fmt.Sprintf("filter type %s is not supported", f.Type)
+		}
+	}
+	if redirect {
+		return actions, "", nil, ""
+	}
+
+	reason := gwv1beta1.RouteReasonResolvedRefs
+	refErrors := make([]string, 0)
+	serverGroups := make([]configcache.ServerGroupTuple, 0)
+	weights := make([]int32, 0)
+	for _, ref := range rule.BackendRefs {
+		if len(ref.Filters) != 0 {
+			return nil, "", nil, "backendRef filters are not supported"
+		}
+		r, msg, err := t.resolveBackendRef(ctx, route, ref.BackendObjectReference)
+		if err != nil {
+			// lookup errors other than NotFound are transient, surface them as unresolved
+			r, msg = gwv1beta1.RouteReasonBackendNotFound, err.Error()
+		}
+		if r != "" {
+			reason = r
+			refErrors = append(refErrors, msg)
+			continue
+		}
+		weight := int32(1)
+		if ref.Weight != nil {
+			weight = *ref.Weight
+		}
+		serverGroups = append(serverGroups, configcache.ServerGroupTuple{
+			ServiceName: string(ref.Name),
+			ServicePort: int(*ref.Port),
+		})
+		weights = append(weights, weight)
+	}
+
+	normalized := normalizeWeights(weights)
+	for i := range serverGroups {
+		serverGroups[i].Weight = normalized[i]
+	}
+	if len(serverGroups) == 0 || sumWeights(normalized) == 0 {
+		// requests to a rule without any usable backend get a 500
+		actions = append(actions, configcache.Action{
+			Type: util.RuleActionTypeFixedResponse,
+			FixedResponseConfig: &configcache.FixedResponseConfig{
+				ContentType: "text/plain",
+				HttpCode:    unresolvedBackendStatusCode,
+			},
+		})
+		return actions, reason, refErrors, ""
+	}
+	actions = append(actions, configcache.Action{
+		Type: util.RuleActionTypeForward,
+		ForwardConfig: &configcache.ForwardActionConfig{
+			ServerGroups: serverGroups,
+		},
+	})
+	return actions, reason, refErrors, ""
+}
+
+func buildInsertHeaderAction(h gwv1beta1.HTTPHeader, cover bool) configcache.Action {
+	return configcache.Action{
+		Type: util.RuleActionTypeInsertHeader,
+		InsertHeaderConfig: &configcache.InsertHeaderConfig{
+			CoverEnabled: cover,
+			Key:          string(h.Name),
+			Value:        h.Value,
+			ValueType:    insertHeaderValueTypeUserDefined,
+		},
+	}
+}
+
+func buildRedirectAction(f *gwv1beta1.HTTPRequestRedirectFilter) (configcache.Action, error) {
+	if f == nil {
+		return configcache.Action{}, fmt.Errorf("requestRedirect is empty")
+	}
+	cfg := &configcache.RedirectConfig{
+		Host:     "${host}",
+		HttpCode: strconv.Itoa(defaultRedirectStatusCode),
+		Path:     "${path}",
+		Port:     "${port}",
+		Protocol: "${protocol}",
+		Query:    "${query}",
+	}
+	if f.Scheme != nil {
+		cfg.Protocol = strings.ToUpper(*f.Scheme)
+		switch cfg.Protocol {
+		case util.ListenerProtocolHTTP:
+			cfg.Port = "80"
+		case util.ListenerProtocolHTTPS:
+			cfg.Port = "443"
+		}
+	}
+	if f.Hostname != nil {
+		cfg.Host = string(*f.Hostname)
+	}
+	if f.Port != nil {
+		cfg.Port = strconv.Itoa(int(*f.Port))
+	}
+	if f.StatusCode != nil {
+		cfg.HttpCode = strconv.Itoa(*f.StatusCode)
+	}
+	if f.Path != nil {
+		p, err := fullPathModifier(f.Path)
+		if err != nil {
+			return configcache.Action{}, err
+		}
+		cfg.Path = p
+	}
+	return configcache.Action{
+		Type:           util.RuleActionTypeRedirect,
+		RedirectConfig: cfg,
+	}, nil
+}
+
+func buildRewriteAction(f *gwv1beta1.HTTPURLRewriteFilter) (configcache.Action, error) {
+	if f == nil {
+		return configcache.Action{}, fmt.Errorf("urlRewrite is empty")
+	}
+	cfg := &configcache.RewriteConfig{
+		Host:  "${host}",
+		Path:  "${path}",
+		Query: "${query}",
+	}
+	if f.Hostname != nil {
+		cfg.Host = string(*f.Hostname)
+	}
+	if f.Path != nil {
+		p, err := fullPathModifier(f.Path)
+		if err != nil {
+			return configcache.Action{}, err
+		}
+		cfg.Path = p
+	}
+	return configcache.Action{
+		Type:          util.RuleActionTypeRewrite,
+		RewriteConfig: cfg,
+	}, nil
+}
+
+// fullPathModifier returns the replacement path. ALB rewrites and redirects
+// replace the whole path, so prefix replacement is not supported.
+func fullPathModifier(p *gwv1beta1.HTTPPathModifier) (string, error) {
+	if p.Type != gwv1beta1.FullPathHTTPPathModifier || p.ReplaceFullPath == nil {
+		return "", fmt.Errorf("path modifier %s is not supported", p.Type)
+	}
+	return *p.ReplaceFullPath, nil
+}
+
+func isResolvableBackendRef(route *gwv1beta1.HTTPRoute, ref gwv1beta1.BackendObjectReference) bool {
+	if ref.Group != nil && *ref.Group != "" {
+		return false
+	}
+	if ref.Kind != nil && *ref.Kind != serviceKind {
+		return false
+	}
+	if ref.Namespace != nil && string(*ref.Namespace) != route.Namespace {
+		return false
+	}
+	return ref.Port != nil
+}
+
+// resolveBackendRef returns a non-empty reason when the backend cannot be
+// used by the route.
+func (t *translator) resolveBackendRef(ctx context.Context, route *gwv1beta1.HTTPRoute, ref gwv1beta1.BackendObjectReference) (gwv1beta1.RouteConditionReason, string, error) {
+	if (ref.Group != nil && *ref.Group != "") || (ref.Kind != nil && *ref.Kind != serviceKind) {
+		return gwv1beta1.RouteReasonInvalidKind, fmt.Sprintf("backendRef %s must be a core Service", ref.Name), nil
+	}
+	if ref.Namespace != nil && string(*ref.Namespace) != route.Namespace {
+		return gwv1beta1.RouteReasonRefNotPermitted, fmt.Sprintf("backendRef %s/%s is in another namespace", *ref.Namespace, ref.Name), nil
+	}
+	if ref.Port == nil {
+		return gwv1beta1.RouteReasonUnsupportedValue, fmt.Sprintf("backendRef %s must specify a port", ref.Name), nil
+	}
+	svc := &corev1.Service{}
+	if err := t.k8sClient.Get(ctx, types.NamespacedName{Namespace: route.Namespace, Name: string(ref.Name)}, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return gwv1beta1.RouteReasonBackendNotFound, fmt.Sprintf("service %s/%s not found", route.Namespace, ref.Name), nil
+		}
+		return "", "", err
+	}
+	return "", "", nil
+}
+
+// normalizeWeights scales Gateway API weights into the ALB forward group
+// weight range, keeping every non-zero weight above zero.
+func normalizeWeights(weights []int32) []int {
+	ret := make([]int, len(weights))
+	var sum int64
+	for _, w := range weights {
+		sum += int64(w)
+	}
+	if sum == 0 {
+		return ret
+	}
+	for i, w := range weights {
+		if w == 0 {
+			continue
+		}
+		scaled := int(int64(w) * maxForwardWeight / sum)
+		if scaled == 0 {
+			scaled = 1
+		}
+		ret[i] = scaled
+	}
+	return ret
+}
+
+func sumWeights(weights []int) int {
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	return sum
+}
+
+// buildCertificateIngresses adds one TLS-only ingress per HTTPS port so the
+// builder uploads the listener certificates, covering the hostnames of the
+// listeners and of the routes attached to them.
+func (t *translator) buildCertificateIngresses(gw *gwv1beta1.Gateway, m *gatewayModel) {
+	ingByPort := make(map[gwv1beta1.PortNumber]*networking.Ingress)
+	ports := make([]gwv1beta1.PortNumber, 0)
+	for _, ls := range m.listeners {
+		l := ls.listener
+		if !ls.accepted || l.Protocol != gwv1beta1.HTTPSProtocolType {
+			continue
+		}
+		ing, ok := ingByPort[l.Port]
+		if !ok {
+			ing = &networking.Ingress{}
+			ing.Namespace = gw.Namespace
+			ing.Name = fmt.Sprintf("%s%s-tls-%d", albConfigNamePrefix, gw.Name, l.Port)
+			listenPorts, _ := json.Marshal([]map[string]int32{{string(l.Protocol): int32(l.Port)}})
+			ing.Annotations = map[string]string{
+				annotations.ListenPorts: string(listenPorts),
+			}
+			ingByPort[l.Port] = ing
+			ports = append(ports, l.Port)
+		}
+		hosts := make([]string, 0)
+		if l.Hostname != nil && *l.Hostname != "" {
+			hosts = append(hosts, string(*l.Hostname))
+		}
+		for _, h := range ls.hosts {
+			if h != "" && !containsString(hosts, h) {
+				hosts = append(hosts, h)
+			}
+		}
+		for _, ref := range l.TLS.CertificateRefs {
+			ing.Spec.TLS = append(ing.Spec.TLS, networking.IngressTLS{
+				Hosts:      hosts,
+				SecretName: string(ref.Name),
+			})
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	for _, port := range ports {
+		m.group.Members = append(m.group.Members, ingByPort[port])
+	}
+}
+
+func (ls *listenerState) setCondition(gw *gwv1beta1.Gateway, t gwv1beta1.ListenerConditionType, status metav1.ConditionStatus, reason gwv1beta1.ListenerConditionReason, message string) {
+	meta.SetStatusCondition(&ls.conditions, metav1.Condition{
+		Type:               string(t),
+		Status:             status,
+		Reason:             string(reason),
+		Message:            message,
+		ObservedGeneration: gw.Generation,
+	})
+}
+
+func containsString(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+	return false
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,509 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gwv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func newTestTranslator(objs ...client.Object) *translator {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = gwv1beta1.SchemeBuilder.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithIndex(&gwv1beta1.HTTPRoute{}, routeParentGatewayIndex, indexRouteParentGateways).Build()
+	return newTranslator(c, logr.Discard())
+}
+
+func hostnamePtr(h string) *gwv1beta1.Hostname {
+	hostname := gwv1beta1.Hostname(h)
+	return &hostname
+}
+
+func pathMatch(t gwv1beta1.PathMatchType, v string) gwv1beta1.HTTPRouteMatch {
+	return gwv1beta1.HTTPRouteMatch{
+		Path: &gwv1beta1.HTTPPathMatch{Type: &t, Value: &v},
+	}
+}
+
+func backendRef(name string, port int32, weight *int32) gwv1beta1.HTTPBackendRef {
+	p := gwv1beta1.PortNumber(port)
+	return gwv1beta1.HTTPBackendRef{
+		BackendRef: gwv1beta1.BackendRef{
+			BackendObjectReference: gwv1beta1.BackendObjectReference{
+				Name: gwv1beta1.ObjectName(name),
+				Port: &p,
+			},
+			Weight: weight,
+		},
+	}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func testGateway(listeners ...gwv1beta1.Listener) *gwv1beta1.Gateway {
+	gw := &gwv1beta1.Gateway{}
+	gw.Namespace = "default"
+	gw.Name = "gw"
+	gw.Spec.GatewayClassName = "alb"
+	gw.Spec.Listeners = listeners
+	return gw
+}
+
+func testRoute(name string, created time.Time, hostnames []string, rules ...gwv1beta1.HTTPRouteRule) *gwv1beta1.HTTPRoute {
+	route := &gwv1beta1.HTTPRoute{}
+	route.Namespace = "default"
+	route.Name = name
+	route.CreationTimestamp = metav1.NewTime(created)
+	route.Spec.ParentRefs = []gwv1beta1.ParentReference{{Name: "gw"}}
+	for _, h := range hostnames {
+		route.Spec.Hostnames = append(route.Spec.Hostnames, gwv1beta1.Hostname(h))
+	}
+	route.Spec.Rules = rules
+	return route
+}
+
+func testService(name string) *corev1.Service {
+	svc := &corev1.Service{}
+	svc.Namespace = "default"
+	svc.Name = name
+	return svc
+}
+
+func TestIntersectHostnames(t *testing.T) {
+	cases := []struct {
+		name     string
+		listener *gwv1beta1.Hostname
+		route    []gwv1beta1.Hostname
+		hosts    []string
+		ok       bool
+	}{
+		{name: "no hostnames", hosts: []string{}, ok: true},
+		{name: "route only", route: []gwv1beta1.Hostname{"a.example.com"}, hosts: []string{"a.example.com"}, ok: true},
+		{name: "listener only", listener: hostnamePtr("a.example.com"), hosts: []string{"a.example.com"}, ok: true},
+		{name: "same host", listener: hostnamePtr("a.example.com"), route: []gwv1beta1.Hostname{"a.example.com"}, hosts: []string{"a.example.com"}, ok: true},
+		{name: "listener wildcard", listener: hostnamePtr("*.example.com"), route: []gwv1beta1.Hostname{"a.example.com", "b.other.com"}, hosts: []string{"a.example.com"}, ok: true},
+		{name: "route wildcard", listener: hostnamePtr("a.example.com"), route: []gwv1beta1.Hostname{"*.example.com"}, hosts: []string{"a.example.com"}, ok: true},
+		{name: "no intersection", listener: hostnamePtr("a.example.com"), route: []gwv1beta1.Hostname{"b.example.com"}, hosts: []string{}, ok: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			hosts, ok := intersectHostnames(c.listener, c.route)
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.hosts, hosts)
+		})
+	}
+}
+
+func TestMatchPrecedes(t *testing.T) {
+	get := gwv1beta1.HTTPMethod("GET")
+	withMethod := pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")
+	withMethod.Method = &get
+	withHeader := pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")
+	withHeader.Headers = []gwv1beta1.HTTPHeaderMatch{{Name: "x-env", Value: "canary"}}
+	withQuery := pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")
+	withQuery.QueryParams = []gwv1beta1.HTTPQueryParamMatch{{Name: "env", Value: "canary"}}
+
+	cases := []struct {
+		name string
+		a, b gwv1beta1.HTTPRouteMatch
+	}{
+		{name: "exact over prefix", a: pathMatch(gwv1beta1.PathMatchExact, "/"), b: pathMatch(gwv1beta1.PathMatchPathPrefix, "/api/v1")},
+		{name: "longer prefix", a: pathMatch(gwv1beta1.PathMatchPathPrefix, "/api/v1"), b: pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")},
+		{name: "prefix over default", a: pathMatch(gwv1beta1.PathMatchPathPrefix, "/api"), b: gwv1beta1.HTTPRouteMatch{}},
+		{name: "method", a: withMethod, b: pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")},
+		{name: "headers", a: withHeader, b: withQuery},
+		{name: "query params", a: withQuery, b: pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.True(t, matchPrecedes(c.a, c.b))
+			assert.False(t, matchPrecedes(c.b, c.a))
+		})
+	}
+}
+
+func TestNormalizeWeights(t *testing.T) {
+	cases := []struct {
+		name    string
+		weights []int32
+		want    []int
+	}{
+		{name: "single", weights: []int32{1}, want: []int{100}},
+		{name: "even", weights: []int32{1, 1}, want: []int{50, 50}},
+		{name: "uneven", weights: []int32{3, 1}, want: []int{75, 25}},
+		{name: "small weight kept", weights: []int32{1000, 1}, want: []int{99, 1}},
+		{name: "zero weight", weights: []int32{0, 5}, want: []int{0, 100}},
+		{name: "all zero", weights: []int32{0, 0}, want: []int{0, 0}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, normalizeWeights(c.weights))
+		})
+	}
+}
+
+func TestBuildListeners(t *testing.T) {
+	fromSelector := gwv1beta1.NamespacesFromSelector
+	cases := []struct {
+		name      string
+		listeners []gwv1beta1.Listener
+		accepted  []bool
+		albPorts  []int
+	}{
+		{
+			name: "http listeners share a port",
+			listeners: []gwv1beta1.Listener{
+				{Name: "a", Port: 80, Protocol: gwv1beta1.HTTPProtocolType, Hostname: hostnamePtr("a.example.com")},
+				{Name: "b", Port: 80, Protocol: gwv1beta1.HTTPProtocolType, Hostname: hostnamePtr("b.example.com")},
+			},
+			accepted: []bool{true, true},
+			albPorts: []int{80},
+		},
+		{
+			name: "protocol conflict",
+			listeners: []gwv1beta1.Listener{
+				{Name: "http", Port: 80, Protocol: gwv1beta1.HTTPProtocolType},
+				{Name: "https", Port: 80, Protocol: gwv1beta1.HTTPSProtocolType},
+				{Name: "other", Port: 8080, Protocol: gwv1beta1.HTTPProtocolType},
+			},
+			accepted: []bool{false, false, true},
+			albPorts: []int{8080},
+		},
+		{
+			name: "unsupported protocol",
+			listeners: []gwv1beta1.Listener{
+				{Name: "tcp", Port: 9000, Protocol: "TCP"},
+			},
+			accepted: []bool{false},
+			albPorts: []int{},
+		},
+		{
+			name: "invalid namespace selector",
+			listeners: []gwv1beta1.Listener{
+				{Name: "selector", Port: 80, Protocol: gwv1beta1.HTTPProtocolType, AllowedRoutes: &gwv1beta1.AllowedRoutes{
+					Namespaces: &gwv1beta1.RouteNamespaces{From: &fromSelector, Selector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Bogus"}},
+					}},
+				}},
+				{Name: "missing", Port: 8080, Protocol: gwv1beta1.HTTPProtocolType, AllowedRoutes: &gwv1beta1.AllowedRoutes{
+					Namespaces: &gwv1beta1.RouteNamespaces{From: &fromSelector},
+				}},
+			},
+			accepted: []bool{false, false},
+			albPorts: []int{},
+		},
+		{
+			name: "https without certificate",
+			listeners: []gwv1beta1.Listener{
+				{Name: "https", Port: 443, Protocol: gwv1beta1.HTTPSProtocolType},
+			},
+			accepted: []bool{false},
+			albPorts: []int{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := newTestTranslator()
+			gw := testGateway(c.listeners...)
+			m := &gatewayModel{}
+			m.albconfig = tr.buildAlbConfig(&gwv1beta1.GatewayClass{}, gw)
+			assert.Nil(t, tr.buildListeners(context.TODO(), gw, m))
+
+			accepted := make([]bool, 0)
+			for _, ls := range m.listeners {
+				accepted = append(accepted, ls.accepted)
+			}
+			assert.Equal(t, c.accepted, accepted)
+			ports := make([]int, 0)
+			for _, ls := range m.albconfig.Spec.Listeners {
+				ports = append(ports, ls.Port.IntValue())
+			}
+			assert.Equal(t, c.albPorts, ports)
+		})
+	}
+}
+
+func TestBuildRuleActions(t *testing.T) {
+	scheme := "https"
+	rewritePath := "/new"
+	cases := []struct {
+		name        string
+		rule        gwv1beta1.HTTPRouteRule
+		actionTypes []string
+		refErrors   int
+		unsupported bool
+	}{
+		{
+			name:        "forward",
+			rule:        gwv1beta1.HTTPRouteRule{BackendRefs: []gwv1beta1.HTTPBackendRef{backendRef("svc-a", 80, nil)}},
+			actionTypes: []string{util.RuleActionTypeForward},
+		},
+		{
+			name:        "missing backend",
+			rule:        gwv1beta1.HTTPRouteRule{BackendRefs: []gwv1beta1.HTTPBackendRef{backendRef("missing", 80, nil)}},
+			actionTypes: []string{util.RuleActionTypeFixedResponse},
+			refErrors:   1,
+		},
+		{
+			name: "header modifier",
+			rule: gwv1beta1.HTTPRouteRule{
+				Filters: []gwv1beta1.HTTPRouteFilter{{
+					Type: gwv1beta1.HTTPRouteFilterRequestHeaderModifier,
+					RequestHeaderModifier: &gwv1beta1.HTTPHeaderFilter{
+						Set:    []gwv1beta1.HTTPHeader{{Name: "x-a", Value: "1"}},
+						Remove: []string{"x-b"},
+					},
+				}},
+				BackendRefs: []gwv1beta1.HTTPBackendRef{backendRef("svc-a", 80, nil)},
+			},
+			actionTypes: []string{util.RuleActionTypeInsertHeader, util.RuleActionTypeRemoveHeader, util.RuleActionTypeForward},
+		},
+		{
+			name: "redirect drops backends",
+			rule: gwv1beta1.HTTPRouteRule{
+				Filters: []gwv1beta1.HTTPRouteFilter{{
+					Type:            gwv1beta1.HTTPRouteFilterRequestRedirect,
+					RequestRedirect: &gwv1beta1.HTTPRequestRedirectFilter{Scheme: &scheme},
+				}},
+				BackendRefs: []gwv1beta1.HTTPBackendRef{backendRef("svc-a", 80, nil)},
+			},
+			actionTypes: []string{util.RuleActionTypeRedirect},
+		},
+		{
+			name: "rewrite",
+			rule: gwv1beta1.HTTPRouteRule{
+				Filters: []gwv1beta1.HTTPRouteFilter{{
+					Type: gwv1beta1.HTTPRouteFilterURLRewrite,
+					URLRewrite: &gwv1beta1.HTTPURLRewriteFilter{
+						Path: &gwv1beta1.HTTPPathModifier{Type: gwv1beta1.FullPathHTTPPathModifier, ReplaceFullPath: &rewritePath},
+					},
+				}},
+				BackendRefs: []gwv1beta1.HTTPBackendRef{backendRef("svc-a", 80, nil)},
+			},
+			actionTypes: []string{util.RuleActionTypeRewrite, util.RuleActionTypeForward},
+		},
+		{
+			name: "prefix rewrite is unsupported",
+			rule: gwv1beta1.HTTPRouteRule{
+				Filters: []gwv1beta1.HTTPRouteFilter{{
+					Type: gwv1beta1.HTTPRouteFilterURLRewrite,
+					URLRewrite: &gwv1beta1.HTTPURLRewriteFilter{
+						Path: &gwv1beta1.HTTPPathModifier{Type: gwv1beta1.PrefixMatchHTTPPathModifier},
+					},
+				}},
+			},
+			unsupported: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := newTestTranslator(testService("svc-a"))
+			route := testRoute("route", time.Now(), nil, c.rule)
+			actions, _, refErrs, unsupported := tr.buildRuleActions(context.TODO(), route, c.rule)
+			assert.Equal(t, c.unsupported, unsupported != "")
+			if c.unsupported {
+				return
+			}
+			assert.Equal(t, c.refErrors, len(refErrs))
+			types := make([]string, 0)
+			for _, a := range actions {
+				types = append(types, a.Type)
+			}
+			assert.Equal(t, c.actionTypes, types)
+		})
+	}
+}
+
+func TestBuildRoutesPrecedence(t *testing.T) {
+	now := time.Now()
+	svc := func(name string) []gwv1beta1.HTTPBackendRef {
+		return []gwv1beta1.HTTPBackendRef{backendRef(name, 80, nil)}
+	}
+	cases := []struct {
+		name   string
+		routes []*gwv1beta1.HTTPRoute
+		// paths of the members, in order
+		paths   [][]string
+		members []string
+	}{
+		{
+			name: "newer exact path precedes older prefix",
+			routes: []*gwv1beta1.HTTPRoute{
+				testRoute("old", now.Add(-time.Hour), []string{"a.example.com"}, gwv1beta1.HTTPRouteRule{
+					Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchPathPrefix, "/")},
+					BackendRefs: svc("svc-a"),
+				}),
+				testRoute("new", now, []string{"a.example.com"}, gwv1beta1.HTTPRouteRule{
+					Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchExact, "/api")},
+					BackendRefs: svc("svc-b"),
+				}),
+			},
+			members: []string{"httproute-new", "httproute-old"},
+			paths:   [][]string{{"/api"}, {"/"}},
+		},
+		{
+			name: "creation time breaks ties",
+			routes: []*gwv1beta1.HTTPRoute{
+				testRoute("b", now, []string{"a.example.com"}, gwv1beta1.HTTPRouteRule{
+					Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")},
+					BackendRefs: svc("svc-b"),
+				}),
+				testRoute("a", now.Add(-time.Hour), []string{"a.example.com"}, gwv1beta1.HTTPRouteRule{
+					Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")},
+					BackendRefs: svc("svc-a"),
+				}),
+			},
+			members: []string{"httproute-a", "httproute-b"},
+			paths:   [][]string{{"/api"}, {"/api"}},
+		},
+		{
+			name: "interleaved routes are split",
+			routes: []*gwv1beta1.HTTPRoute{
+				testRoute("old", now.Add(-time.Hour), []string{"a.example.com"}, gwv1beta1.HTTPRouteRule{
+					Matches: []gwv1beta1.HTTPRouteMatch{
+						pathMatch(gwv1beta1.PathMatchPathPrefix, "/"),
+						pathMatch(gwv1beta1.PathMatchExact, "/login"),
+					},
+					BackendRefs: svc("svc-a"),
+				}),
+				testRoute("new", now, []string{"a.example.com"}, gwv1beta1.HTTPRouteRule{
+					Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")},
+					BackendRefs: svc("svc-b"),
+				}),
+			},
+			members: []string{"httproute-old", "httproute-new", "httproute-old-1"},
+			paths:   [][]string{{"/login"}, {"/api"}, {"/"}},
+		},
+		{
+			name: "routes on other hosts are not split",
+			routes: []*gwv1beta1.HTTPRoute{
+				testRoute("old", now.Add(-time.Hour), []string{"a.example.com"}, gwv1beta1.HTTPRouteRule{
+					Matches: []gwv1beta1.HTTPRouteMatch{
+						pathMatch(gwv1beta1.PathMatchPathPrefix, "/"),
+						pathMatch(gwv1beta1.PathMatchExact, "/login"),
+					},
+					BackendRefs: svc("svc-a"),
+				}),
+				testRoute("new", now, []string{"b.example.com"}, gwv1beta1.HTTPRouteRule{
+					Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")},
+					BackendRefs: svc("svc-b"),
+				}),
+			},
+			members: []string{"httproute-old", "httproute-new"},
+			paths:   [][]string{{"/login", "/"}, {"/api"}},
+		},
+		{
+			name: "routes of other gateways are ignored",
+			routes: []*gwv1beta1.HTTPRoute{
+				testRoute("mine", now, []string{"a.example.com"}, gwv1beta1.HTTPRouteRule{
+					Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchPathPrefix, "/")},
+					BackendRefs: svc("svc-a"),
+				}),
+				func() *gwv1beta1.HTTPRoute {
+					route := testRoute("other", now, []string{"a.example.com"}, gwv1beta1.HTTPRouteRule{
+						Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchPathPrefix, "/")},
+						BackendRefs: svc("svc-b"),
+					})
+					route.Spec.ParentRefs = []gwv1beta1.ParentReference{{Name: "other-gw"}}
+					return route
+				}(),
+			},
+			members: []string{"httproute-mine"},
+			paths:   [][]string{{"/"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			objs := []client.Object{testService("svc-a"), testService("svc-b")}
+			for _, r := range c.routes {
+				objs = append(objs, r)
+			}
+			tr := newTestTranslator(objs...)
+			gw := testGateway(gwv1beta1.Listener{Name: "http", Port: 80, Protocol: gwv1beta1.HTTPProtocolType})
+			m, err := tr.translate(context.TODO(), &gwv1beta1.GatewayClass{}, gw)
+			assert.Nil(t, err)
+
+			members := make([]string, 0)
+			paths := make([][]string, 0)
+			for _, ing := range m.group.Members {
+				members = append(members, ing.Name)
+				paths = append(paths, ingressPaths(ing))
+			}
+			assert.Equal(t, c.members, members)
+			assert.Equal(t, c.paths, paths)
+			for _, ing := range m.group.Members {
+				assert.Equal(t, `[{"HTTP":80}]`, ing.Annotations[annotations.ListenPorts])
+			}
+		})
+	}
+}
+
+func TestBuildRoutesServerGroups(t *testing.T) {
+	now := time.Now()
+	routes := []client.Object{
+		testRoute("old", now.Add(-time.Hour), nil,
+			gwv1beta1.HTTPRouteRule{
+				Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchPathPrefix, "/")},
+				BackendRefs: []gwv1beta1.HTTPBackendRef{backendRef("svc-a", 80, nil)},
+			},
+			gwv1beta1.HTTPRouteRule{
+				Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchExact, "/login")},
+				BackendRefs: []gwv1beta1.HTTPBackendRef{backendRef("svc-b", 8080, nil)},
+			}),
+		testRoute("new", now, nil, gwv1beta1.HTTPRouteRule{
+			Matches:     []gwv1beta1.HTTPRouteMatch{pathMatch(gwv1beta1.PathMatchPathPrefix, "/api")},
+			BackendRefs: []gwv1beta1.HTTPBackendRef{backendRef("svc-a", 80, nil)},
+		}),
+		testService("svc-a"),
+		testService("svc-b"),
+	}
+	tr := newTestTranslator(routes...)
+	gw := testGateway(gwv1beta1.Listener{Name: "http", Port: 80, Protocol: gwv1beta1.HTTPProtocolType})
+	m, err := tr.translate(context.TODO(), &gwv1beta1.GatewayClass{}, gw)
+	assert.Nil(t, err)
+
+	svcA := m.servicePortToIngressNames[util.NamespacedName(testService("svc-a"))]
+	svcB := m.servicePortToIngressNames[util.NamespacedName(testService("svc-b"))]
+	assert.Equal(t, []string{"httproute-new", "httproute-old-1"}, svcA[80])
+	assert.Equal(t, []string{"httproute-old"}, svcB[8080])
+}
+
+func TestIndexRouteParentGateways(t *testing.T) {
+	ns := gwv1beta1.Namespace("other")
+	kind := gwv1beta1.Kind("Service")
+	section := gwv1beta1.SectionName("http")
+	route := testRoute("r", time.Now(), nil)
+	route.Spec.ParentRefs = []gwv1beta1.ParentReference{
+		{Name: "gw"},
+		{Name: "gw", SectionName: &section},
+		{Name: "gw", Namespace: &ns},
+		{Name: "svc", Kind: &kind},
+	}
+	assert.Equal(t, []string{"default/gw", "other/gw"}, indexRouteParentGateways(route))
+}
+
+func ingressPaths(ing *networking.Ingress) []string {
+	paths := make([]string, 0)
+	for _, rule := range ing.Spec.Rules {
+		for _, p := range rule.HTTP.Paths {
+			paths = append(paths, p.Path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,95 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util/metric"
+)
+
+// CertificateFingerprint returns the SHA-256 fingerprint of the first certificate of the PEM chain in upper case
+// hex, the Sha2 of the certificate in CAS, or an empty string if there is no certificate.
+func CertificateFingerprint(crt string) string {
+	rest := []byte(crt)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return ""
+		}
+		if block.Type == "CERTIFICATE" {
+			sum := sha256.Sum256(block.Bytes)
+			return strings.ToUpper(hex.EncodeToString(sum[:]))
+		}
+	}
+}
+
+// ListenerCertificate is a certificate in CAS bound to a listener, the listener is in the format of port/protocol.
+type ListenerCertificate struct {
+	Listener    string
+	Certificate model.CertificateInfo
+}
+
+// NotAfter returns the expiry time of the certificate, the AfterDate of a certificate in CAS is in milliseconds.
+func (c ListenerCertificate) NotAfter() time.Time {
+	return time.UnixMilli(c.Certificate.AfterDate)
+}
+
+func (c ListenerCertificate) ExpiryMessage() string {
+	return fmt.Sprintf("certificate %s (%s) of listener %s expires at %s", c.Certificate.CertIdentifier,
+		c.Certificate.CertName, c.Listener, c.NotAfter().UTC().Format(time.RFC3339))
+}
+
+// BuildListenerCertificates looks up the certificates bound to the listeners in CAS, the certificates not found in
+// CAS are skipped.
+func BuildListenerCertificates(certIDsByListener map[string][]string, sdkCerts []model.CertificateInfo) []ListenerCertificate {
+	sdkCertsByID := make(map[string]model.CertificateInfo, len(sdkCerts))
+	for _, cert := range sdkCerts {
+		sdkCertsByID[cert.CertIdentifier] = cert
+	}
+	var certs []ListenerCertificate
+	for listener, certIDs := range certIDsByListener {
+		for _, certID := range certIDs {
+			if cert, ok := sdkCertsByID[certID]; ok {
+				certs = append(certs, ListenerCertificate{Listener: listener, Certificate: cert})
+			}
+		}
+	}
+	sort.Slice(certs, func(i, j int) bool {
+		if certs[i].Listener != certs[j].Listener {
+			return certs[i].Listener < certs[j].Listener
+		}
+		return certs[i].Certificate.CertIdentifier < certs[j].Certificate.CertIdentifier
+	})
+	return certs
+}
+
+// RecordCertificateExpiry exports the expiry time of the certificates bound to the listeners of an object in place
+// of the ones exported before, and returns the certificates expiring within the window.
+func RecordCertificateExpiry(kind, namespace, name string, certs []ListenerCertificate, window time.Duration, now time.Time) []ListenerCertificate {
+	ForgetCertificateExpiry(kind, namespace, name)
+	var expiring []ListenerCertificate
+	for _, cert := range certs {
+		if cert.Certificate.AfterDate == 0 {
+			continue
+		}
+		metric.CertificateExpiry.WithLabelValues(kind, namespace, name, cert.Listener, cert.Certificate.CertIdentifier).
+			Set(float64(cert.NotAfter().Unix()))
+		if cert.NotAfter().Before(now.Add(window)) {
+			expiring = append(expiring, cert)
+		}
+	}
+	return expiring
+}
+
+// ForgetCertificateExpiry stops exporting the expiry time of the certificates bound to the listeners of an object.
+func ForgetCertificateExpiry(kind, namespace, name string) {
+	metric.CertificateExpiry.DeletePartialMatch(prometheus.Labels{"kind": kind, "namespace": namespace, "name": name})
+}
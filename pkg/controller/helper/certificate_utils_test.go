@@ -0,0 +1,65 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util/metric"
+)
+
+// certificateExpirySeries returns the exported expiry time by the certificate ids
+func certificateExpirySeries(t *testing.T) map[string]float64 {
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, registry.Register(metric.CertificateExpiry))
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	series := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "certificate_id" {
+					series[label.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return series
+}
+
+func TestBuildListenerCertificates(t *testing.T) {
+	sdkCerts := []model.CertificateInfo{{CertIdentifier: "cert-a"}, {CertIdentifier: "cert-b"}}
+	certs := BuildListenerCertificates(map[string][]string{
+		"443/HTTPS": {"cert-b", "cert-gone"},
+		"1443/QUIC": {"cert-a"},
+	}, sdkCerts)
+	assert.Equal(t, []ListenerCertificate{
+		{Listener: "1443/QUIC", Certificate: sdkCerts[0]},
+		{Listener: "443/HTTPS", Certificate: sdkCerts[1]},
+	}, certs)
+}
+
+func TestRecordCertificateExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	soon := ListenerCertificate{Listener: "443/HTTPS", Certificate: model.CertificateInfo{
+		CertIdentifier: "cert-soon", CertName: "soon", AfterDate: now.Add(24 * time.Hour).UnixMilli()}}
+	later := ListenerCertificate{Listener: "443/HTTPS", Certificate: model.CertificateInfo{
+		CertIdentifier: "cert-later", AfterDate: now.Add(90 * 24 * time.Hour).UnixMilli()}}
+	unknown := ListenerCertificate{Listener: "443/HTTPS", Certificate: model.CertificateInfo{CertIdentifier: "cert-unknown"}}
+
+	expiring := RecordCertificateExpiry("AlbConfig", "", "alb", []ListenerCertificate{soon, later, unknown}, 30*24*time.Hour, now)
+	assert.Equal(t, []ListenerCertificate{soon}, expiring)
+	assert.Equal(t, "certificate cert-soon (soon) of listener 443/HTTPS expires at 2024-01-02T00:00:00Z", expiring[0].ExpiryMessage())
+	assert.Equal(t, map[string]float64{
+		"cert-soon":  float64(now.Add(24 * time.Hour).Unix()),
+		"cert-later": float64(now.Add(90 * 24 * time.Hour).Unix()),
+	}, certificateExpirySeries(t))
+
+	// the certificates no longer bound are not exported anymore
+	RecordCertificateExpiry("AlbConfig", "", "alb", []ListenerCertificate{later}, 30*24*time.Hour, now)
+	assert.Equal(t, map[string]float64{"cert-later": float64(now.Add(90 * 24 * time.Hour).Unix())}, certificateExpirySeries(t))
+	ForgetCertificateExpiry("AlbConfig", "", "alb")
+	assert.Empty(t, certificateExpirySeries(t))
+}
@@ -10,6 +10,7 @@ const (
 	FailedRemoveHash       = "FailedRemoveHash"
 	FailedUpdateStatus     = "FailedUpdateStatus"
 	UnAvailableBackends    = "UnAvailableLoadBalancer"
+	UnservedBackendZones   = "UnservedBackendZones"
 	FailedSyncLB           = "SyncLoadBalancerFailed"
 	SucceedCleanLB         = "CleanLoadBalancer"
 	FailedCleanLB          = "CleanLoadBalancerFailed"
@@ -18,6 +19,7 @@ const (
 	TypeChanged            = "TypeChanged"
 	SpecChanged            = "ServiceSpecChanged"
 	DeleteTimestampChanged = "DeleteTimestampChanged"
+	ClassChangeForbidden   = "LoadBalancerClassChangeForbidden"
 )
 
 // NodeEventReason
@@ -38,6 +40,8 @@ const (
 
 var re = regexp.MustCompile(".*(Message:.*)")
 
+var requestIdRe = regexp.MustCompile(`(?i)request\s*id:\s*([\w-]+)`)
+
 func GetLogMessage(err error) string {
 	if err == nil {
 		return ""
@@ -52,6 +56,18 @@ func GetLogMessage(err error) string {
 	return message
 }
 
+// GetRequestId returns the RequestId of the cloud API call in the error message, or "" if there is none.
+func GetRequestId(err error) string {
+	if err == nil {
+		return ""
+	}
+	sub := requestIdRe.FindStringSubmatch(err.Error())
+	if len(sub) > 1 {
+		return sub[1]
+	}
+	return ""
+}
+
 const (
 	// Ingress events
 	IngressEventReasonFailedAddFinalizer     = "FailedAddFinalizer"
@@ -60,10 +76,18 @@ const (
 	IngressEventReasonFailedBuildModel       = "FailedBuildModel"
 	IngressEventReasonFailedApplyModel       = "FailedApplyModel"
 	IngressEventReasonSuccessfullyReconciled = "SuccessfullyReconciled"
+	IngressEventReasonRuleConflict           = "RuleConflict"
+	IngressEventReasonQuotaExceeded          = "QuotaExceeded"
+	IngressEventReasonDefaultBackendConflict = "DefaultBackendConflict"
+	IngressEventReasonFailedDeleteCert       = "FailedDeleteCertificate"
+	IngressEventReasonCertificateExpiring    = "CertificateExpiring"
+	IngressEventReasonHostNotCovered         = "CertificateHostNotCovered"
+	IngressEventReasonFailedSaveLastSynced   = "FailedSaveLastSynced"
 
 	// Service events
 	ServiceEventReasonFailedUpdateEndpoints  = "FailedUpdateEndpoints"
 	ServiceEventReasonSuccessfullyReconciled = "SuccessfullyReconciled"
+	ServiceEventReasonCertificateExpiring    = "CertificateExpiring"
 )
 
 // EventType type of event associated with an informer
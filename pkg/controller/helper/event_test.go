@@ -0,0 +1,34 @@
+package helper
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRequestId(t *testing.T) {
+	cases := []struct {
+		name      string
+		err       error
+		requestId string
+	}{
+		{name: "nil", err: nil},
+		{name: "no request id", err: fmt.Errorf("quota exceeded")},
+		{
+			name:      "sdk error",
+			err:       fmt.Errorf("SDK.ServerError\nErrorCode: QuotaExceeded\nRecommend: \nRequestId: 3B7A1B2C-0D4E-4F5A-8B6C-7D8E9F0A1B2C\nMessage: quota exceeded"),
+			requestId: "3B7A1B2C-0D4E-4F5A-8B6C-7D8E9F0A1B2C",
+		},
+		{
+			name:      "case insensitive",
+			err:       fmt.Errorf("create listener failed, request id: abc-123"),
+			requestId: "abc-123",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.requestId, GetRequestId(c.err))
+		})
+	}
+}
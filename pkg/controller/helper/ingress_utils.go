@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"reflect"
 	"strings"
 
 	alibabacloudv1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
@@ -10,11 +11,55 @@ import (
 
 const (
 	LabelAlbHash = "alb.ingress.kubernetes.io/hash"
+	// AnnotationIngressSyncStatus is the sync status of the ingress in its AlbConfig group, written by the controller
+	AnnotationIngressSyncStatus = "alb.ingress.kubernetes.io/sync-status"
+	// AnnotationIngressLastSynced is the hash and generation of the last version of the ingress applied to the load
+	// balancer, written by the controller
+	AnnotationIngressLastSynced = "alb.ingress.kubernetes.io/last-synced"
 )
 
+// IsIngressSyncAnnotation returns true if the annotation is written by the controller to report the sync result,
+// such annotations are not part of the ingress configuration.
+func IsIngressSyncAnnotation(key string) bool {
+	return key == AnnotationIngressSyncStatus || key == AnnotationIngressLastSynced
+}
+
+// GetIngressUserAnnotations returns the annotations of the ingress without the ones written by the controller.
+func GetIngressUserAnnotations(ing *networkingv1.Ingress) map[string]string {
+	found := false
+	for k := range ing.Annotations {
+		if IsIngressSyncAnnotation(k) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ing.Annotations
+	}
+	anno := make(map[string]string, len(ing.Annotations))
+	for k, v := range ing.Annotations {
+		if !IsIngressSyncAnnotation(k) {
+			anno[k] = v
+		}
+	}
+	return anno
+}
+
+// IsIngressConfigChanged returns false if only the metadata or the annotations written by the controller are changed.
+func IsIngressConfigChanged(old, cur *networkingv1.Ingress) bool {
+	if !reflect.DeepEqual(old.Spec, cur.Spec) {
+		return true
+	}
+	oldAnno, curAnno := GetIngressUserAnnotations(old), GetIngressUserAnnotations(cur)
+	if len(oldAnno) == 0 && len(curAnno) == 0 {
+		return false
+	}
+	return !reflect.DeepEqual(oldAnno, curAnno)
+}
+
 func GetIngressHash(ing *networkingv1.Ingress) string {
 	var op []interface{}
-	op = append(op, ing.Spec, ing.Annotations, ing.DeletionTimestamp)
+	op = append(op, ing.Spec, GetIngressUserAnnotations(ing), ing.DeletionTimestamp)
 	return hash.HashObject(op)
 }
 
@@ -0,0 +1,41 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+func TestGetIngressHash(t *testing.T) {
+	ing := &networkingv1.Ingress{}
+	ing.Annotations = map[string]string{"alb.ingress.kubernetes.io/rewrite-target": "/"}
+	hash := GetIngressHash(ing)
+
+	synced := ing.DeepCopy()
+	synced.Annotations[AnnotationIngressSyncStatus] = `{"synced":true}`
+	synced.Annotations[AnnotationIngressLastSynced] = `{"hash":"x"}`
+	assert.Equal(t, hash, GetIngressHash(synced))
+	assert.False(t, IsIngressConfigChanged(ing, synced))
+
+	changed := synced.DeepCopy()
+	changed.Annotations["alb.ingress.kubernetes.io/rewrite-target"] = "/v2"
+	assert.NotEqual(t, hash, GetIngressHash(changed))
+	assert.True(t, IsIngressConfigChanged(synced, changed))
+
+	// the hash of an ingress without sync annotations is kept
+	empty := &networkingv1.Ingress{}
+	statusOnly := empty.DeepCopy()
+	statusOnly.Annotations = map[string]string{AnnotationIngressSyncStatus: `{"synced":true}`}
+	assert.False(t, IsIngressConfigChanged(empty, statusOnly))
+
+	labeled := ing.DeepCopy()
+	labeled.Labels = map[string]string{LabelAlbHash: hash}
+	labeled.Finalizers = []string{"ingress.k8s.alibaba/resources"}
+	assert.False(t, IsIngressConfigChanged(ing, labeled))
+	assert.False(t, IsIngressHashChanged(labeled))
+
+	spec := ing.DeepCopy()
+	spec.Spec.Rules = []networkingv1.IngressRule{{Host: "example.com"}}
+	assert.True(t, IsIngressConfigChanged(ing, spec))
+}
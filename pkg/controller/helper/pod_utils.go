@@ -1,19 +1,45 @@
 package helper
 
 import (
+	"encoding/json"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Prefix for TargetHealth pod condition type.
 const TargetHealthPodConditionTypePrefix = "target-health.alb.k8s.alibabacloud"
 
+// NLBTargetHealthPodConditionType is the readiness gate of the pods used as the ENI backends of NLB Services,
+// the pods are ready once the NLB reports them healthy.
+const NLBTargetHealthPodConditionType = "target-health.nlb.k8s.alibabacloud"
+
+// ReadinessGateInjectLabel is the namespace label to inject the TargetHealth readiness gate into the pods
+// of the Services used by ALB Ingresses, its value must be ReadinessGateInjectEnabled.
+const (
+	ReadinessGateInjectLabel   = "alb.ingress.kubernetes.io/readiness-gate-inject"
+	ReadinessGateInjectEnabled = "enabled"
+)
+
 // BuildTargetHealthPodConditionType constructs the condition type for TargetHealth pod condition.
 func BuildReadinessGatePodConditionType() corev1.PodConditionType {
 	return corev1.PodConditionType(TargetHealthPodConditionTypePrefix)
 }
 
 func IsPodHasReadinessGate(pod *corev1.Pod) bool {
-	conditionType := BuildReadinessGatePodConditionType()
+	return hasReadinessGate(pod, BuildReadinessGatePodConditionType())
+}
+
+// IsPodHasNLBReadinessGate returns whether the pod waits for the NLB to report it healthy
+func IsPodHasNLBReadinessGate(pod *corev1.Pod) bool {
+	return hasReadinessGate(pod, NLBTargetHealthPodConditionType)
+}
+
+func hasReadinessGate(pod *corev1.Pod, conditionType corev1.PodConditionType) bool {
 	for _, rg := range pod.Spec.ReadinessGates {
 		if rg.ConditionType == conditionType {
 			return true
@@ -28,6 +54,20 @@ func IsPodContainersReady(pod *corev1.Pod) bool {
 	return containersReadyCond != nil && containersReadyCond.Status == corev1.ConditionTrue
 }
 
+// IsPodDraining returns whether the connections to the terminating pod are still drained at the time. The drain
+// starts once the pod is requested to terminate and lasts for the timeout in seconds.
+func IsPodDraining(pod *corev1.Pod, timeout int, now time.Time) bool {
+	if pod == nil || pod.DeletionTimestamp == nil || timeout <= 0 {
+		return false
+	}
+	// the deletion timestamp of a pod is the deadline of its graceful termination
+	start := pod.DeletionTimestamp.Time
+	if pod.DeletionGracePeriodSeconds != nil {
+		start = start.Add(-time.Duration(*pod.DeletionGracePeriodSeconds) * time.Second)
+	}
+	return now.Before(start.Add(time.Duration(timeout) * time.Second))
+}
+
 // GetPodCondition will get pointer to Pod's existing condition.
 // returns nil if no matching condition found.
 func GetPodCondition(pod *corev1.Pod, conditionType corev1.PodConditionType) *corev1.PodCondition {
@@ -48,3 +88,30 @@ func UpdatePodCondition(pod *corev1.Pod, condition corev1.PodCondition) {
 	}
 	pod.Status.Conditions = append(pod.Status.Conditions, condition)
 }
+
+// BuildPodConditionPatch builds the patch of the pod status to set the condition, the uid of the pod is used as
+// the precondition of the patch.
+func BuildPodConditionPatch(pod *corev1.Pod, condition corev1.PodCondition) (client.Patch, error) {
+	oldData, err := json.Marshal(corev1.Pod{
+		Status: corev1.PodStatus{
+			Conditions: nil,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	newData, err := json.Marshal(corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{UID: pod.UID}, // only put the uid in the new object to ensure it appears in the patch as a precondition
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{condition},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, corev1.Pod{})
+	if err != nil {
+		return nil, err
+	}
+	return client.RawPatch(types.StrategicMergePatchType, patchBytes), nil
+}
@@ -20,6 +20,7 @@ import (
 const (
 	ServiceFinalizer = "service.k8s.alibaba/resources"
 	NLBFinalizer     = "service.k8s.alibaba/nlb"
+	CLBFinalizer     = "service.k8s.alibaba/clb"
 )
 
 // annotation
@@ -29,7 +30,16 @@ const (
 )
 
 // load balancer class
-const NLBClass = "alibabacloud.com/nlb"
+const (
+	NLBClass = "alibabacloud.com/nlb"
+	CLBClass = "alibabacloud.com/clb"
+)
+
+// classFinalizers maps each load balancer class to the finalizer of the controller which claims it
+var classFinalizers = map[string]string{
+	NLBClass: NLBFinalizer,
+	CLBClass: CLBFinalizer,
+}
 
 // label
 const (
@@ -51,6 +61,8 @@ const (
 	ClusterTrafficPolicy = TrafficPolicy("Cluster")
 	// ENITrafficPolicy is forwarded to pod directly
 	ENITrafficPolicy = TrafficPolicy("ENI")
+	// IPTrafficPolicy is forwarded to the addresses of a Service without selector or of an ExternalName Service
+	IPTrafficPolicy = TrafficPolicy("IP")
 )
 
 func GetServiceTrafficPolicy(svc *v1.Service) (TrafficPolicy, error) {
@@ -66,6 +78,15 @@ func GetServiceTrafficPolicy(svc *v1.Service) (TrafficPolicy, error) {
 	return ClusterTrafficPolicy, nil
 }
 
+// GetALBServiceTrafficPolicy returns the traffic policy of a Service backing an ALB server group. The addresses of
+// a Service without pods are registered to an Ip server group directly.
+func GetALBServiceTrafficPolicy(svc *v1.Service) (TrafficPolicy, error) {
+	if IsIPBackendService(svc) {
+		return IPTrafficPolicy, nil
+	}
+	return GetServiceTrafficPolicy(svc)
+}
+
 func IsLocalModeService(svc *v1.Service) bool {
 	return svc.Spec.ExternalTrafficPolicy == v1.ServiceExternalTrafficPolicyTypeLocal
 }
@@ -82,6 +103,12 @@ func IsENIBackendType(svc *v1.Service) bool {
 	return ctrlCfg.CloudCFG.Global.ServiceBackendType == model.ENIBackendType
 }
 
+// IsIPBackendService returns true if the backends of the service are not pods of the cluster, but the addresses of
+// its manually managed Endpoints or of its external name.
+func IsIPBackendService(svc *v1.Service) bool {
+	return svc.Spec.Type == v1.ServiceTypeExternalName || len(svc.Spec.Selector) == 0
+}
+
 func IsClusterIPService(svc *v1.Service) bool {
 	return svc.Spec.Type == v1.ServiceTypeClusterIP
 }
@@ -90,18 +117,56 @@ func NeedDeleteLoadBalancer(svc *v1.Service) bool {
 	return svc.DeletionTimestamp != nil || svc.Spec.Type != v1.ServiceTypeLoadBalancer
 }
 
+// GetLoadBalancerClass returns the load balancer class of the service.
+// spec.loadBalancerClass takes precedence over the class annotation. Services declaring neither
+// belong to the default load balancer class of the controller.
+func GetLoadBalancerClass(service *v1.Service) string {
+	if service.Spec.LoadBalancerClass != nil {
+		return *service.Spec.LoadBalancerClass
+	}
+	if class := service.Annotations[LoadBalancerClass]; class != "" {
+		return class
+	}
+	return ctrlCfg.ControllerCFG.DefaultLoadBalancerClass
+}
+
 func NeedCLB(service *v1.Service) bool {
+	return service.Spec.Type == v1.ServiceTypeLoadBalancer && GetLoadBalancerClass(service) == CLBClass
+}
+
+// NeedNLB returns true if spec.loadBalancerClass, or the default class when the service declares no class,
+// is NLB. The class annotation never selects NLB: services annotated before the NLB controller read
+// the annotation are not claimed by it on upgrade.
+func NeedNLB(service *v1.Service) bool {
 	if service.Spec.Type != v1.ServiceTypeLoadBalancer {
 		return false
 	}
-	if service.Spec.LoadBalancerClass != nil {
+	if service.Spec.LoadBalancerClass == nil && service.Annotations[LoadBalancerClass] != "" {
 		return false
 	}
-	return service.Annotations[LoadBalancerClass] == ""
+	return GetLoadBalancerClass(service) == NLBClass
 }
 
-func NeedNLB(service *v1.Service) bool {
-	return service.Spec.Type == v1.ServiceTypeLoadBalancer && service.Spec.LoadBalancerClass != nil && *service.Spec.LoadBalancerClass == NLBClass
+// IsLoadBalancerClassChanged returns true if the load balancer of a live service is held by the
+// controller of one class while the service declares another class now.
+// Changing type or deleting the service is not a class change, the load balancer is cleaned up as usual.
+func IsLoadBalancerClassChanged(service *v1.Service) bool {
+	return GetLoadBalancerClassHolder(service) != ""
+}
+
+// GetLoadBalancerClassHolder returns the finalizer of the controller which holds the load balancer of a
+// live service whose class changed, or an empty string if the class did not change.
+func GetLoadBalancerClassHolder(service *v1.Service) string {
+	if NeedDeleteLoadBalancer(service) {
+		return ""
+	}
+	class := GetLoadBalancerClass(service)
+	for c, finalizer := range classFinalizers {
+		if c != class && HasFinalizer(service, finalizer) {
+			return finalizer
+		}
+	}
+	return ""
 }
 
 func GetServiceHash(svc *v1.Service) string {
@@ -0,0 +1,100 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newClassService(specClass *string, annoClass string, finalizers ...string) *v1.Service {
+	svc := &v1.Service{}
+	svc.Namespace = "default"
+	svc.Name = "svc"
+	svc.Spec.Type = v1.ServiceTypeLoadBalancer
+	svc.Spec.LoadBalancerClass = specClass
+	if annoClass != "" {
+		svc.Annotations = map[string]string{LoadBalancerClass: annoClass}
+	}
+	svc.Finalizers = finalizers
+	return svc
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestGetLoadBalancerClass(t *testing.T) {
+	defaultClass := ctrlCfg.ControllerCFG.DefaultLoadBalancerClass
+	defer func() { ctrlCfg.ControllerCFG.DefaultLoadBalancerClass = defaultClass }()
+	ctrlCfg.ControllerCFG.DefaultLoadBalancerClass = CLBClass
+
+	cases := []struct {
+		name    string
+		svc     *v1.Service
+		class   string
+		needCLB bool
+		needNLB bool
+	}{
+		{name: "default class", svc: newClassService(nil, ""), class: CLBClass, needCLB: true},
+		{name: "annotation", svc: newClassService(nil, CLBClass), class: CLBClass, needCLB: true},
+		{name: "nlb annotation", svc: newClassService(nil, NLBClass), class: NLBClass},
+		{name: "nlb spec over annotation", svc: newClassService(stringPtr(NLBClass), CLBClass), class: NLBClass, needNLB: true},
+		{name: "spec", svc: newClassService(stringPtr(NLBClass), ""), class: NLBClass, needNLB: true},
+		{name: "spec over annotation", svc: newClassService(stringPtr(CLBClass), NLBClass), class: CLBClass, needCLB: true},
+		{name: "other class", svc: newClassService(stringPtr("example.com/lb"), ""), class: "example.com/lb"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.class, GetLoadBalancerClass(c.svc))
+			assert.Equal(t, c.needCLB, NeedCLB(c.svc))
+			assert.Equal(t, c.needNLB, NeedNLB(c.svc))
+
+			c.svc.Spec.Type = v1.ServiceTypeNodePort
+			assert.False(t, NeedCLB(c.svc))
+			assert.False(t, NeedNLB(c.svc))
+		})
+	}
+
+	ctrlCfg.ControllerCFG.DefaultLoadBalancerClass = NLBClass
+	assert.True(t, NeedNLB(newClassService(nil, "")))
+	assert.False(t, NeedCLB(newClassService(nil, "")))
+	// the annotation does not fall back to the default class
+	assert.False(t, NeedNLB(newClassService(nil, "example.com/lb")))
+}
+
+func TestIsLoadBalancerClassChanged(t *testing.T) {
+	defaultClass := ctrlCfg.ControllerCFG.DefaultLoadBalancerClass
+	defer func() { ctrlCfg.ControllerCFG.DefaultLoadBalancerClass = defaultClass }()
+	ctrlCfg.ControllerCFG.DefaultLoadBalancerClass = CLBClass
+
+	deleting := newClassService(stringPtr(NLBClass), "", CLBFinalizer)
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	nodePort := newClassService(stringPtr(NLBClass), "", CLBFinalizer)
+	nodePort.Spec.Type = v1.ServiceTypeNodePort
+
+	cases := []struct {
+		name   string
+		svc    *v1.Service
+		holder string
+	}{
+		{name: "no finalizer", svc: newClassService(stringPtr(NLBClass), "")},
+		{name: "same class", svc: newClassService(stringPtr(NLBClass), "", NLBFinalizer)},
+		{name: "default class held by clb", svc: newClassService(nil, "", CLBFinalizer)},
+		{name: "clb to nlb by spec", svc: newClassService(stringPtr(NLBClass), "", CLBFinalizer), holder: CLBFinalizer},
+		{name: "nlb to clb by annotation", svc: newClassService(nil, CLBClass, NLBFinalizer), holder: NLBFinalizer},
+		{name: "nlb to default class", svc: newClassService(nil, "", NLBFinalizer), holder: NLBFinalizer},
+		{name: "unrelated finalizer", svc: newClassService(stringPtr(NLBClass), "", ServiceFinalizer)},
+		{name: "deleting", svc: deleting},
+		{name: "type changed", svc: nodePort},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.holder, GetLoadBalancerClassHolder(c.svc))
+			assert.Equal(t, c.holder != "", IsLoadBalancerClassChanged(c.svc))
+		})
+	}
+}
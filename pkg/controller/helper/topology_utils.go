@@ -0,0 +1,72 @@
+package helper
+
+import (
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// GetNodeZone returns the zone of the node by its topology.kubernetes.io/zone label, or by the deprecated
+// failure-domain.beta.kubernetes.io/zone label of the older nodes
+func GetNodeZone(node *corev1.Node) string {
+	if node == nil {
+		return ""
+	}
+	if zone := node.Labels[corev1.LabelTopologyZone]; zone != "" {
+		return zone
+	}
+	return node.Labels[corev1.LabelFailureDomainBetaZone]
+}
+
+// BalanceWeightsByZone scales the weights so that every zone receives the same share of the traffic, and the
+// backends of a zone share it in proportion to their original weights. The backends of unknown zone are grouped
+// together, the backends with weight 0 are left untouched. The largest weight becomes maxWeight.
+func BalanceWeightsByZone(weights []int, zones []string, maxWeight int) []int {
+	zoneTotal := make(map[string]int)
+	for i, w := range weights {
+		if w > 0 {
+			zoneTotal[zones[i]] += w
+		}
+	}
+	if len(zoneTotal) < 2 {
+		return weights
+	}
+
+	balanced := make([]float64, len(weights))
+	max := 0.0
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		balanced[i] = float64(w) / float64(zoneTotal[zones[i]])
+		max = math.Max(max, balanced[i])
+	}
+	// the largest weight is scaled to maxWeight to keep the precision of the small weights
+	scale := float64(maxWeight) / max
+
+	ret := make([]int, len(weights))
+	for i, w := range weights {
+		if w <= 0 {
+			ret[i] = w
+			continue
+		}
+		ret[i] = int(math.Round(balanced[i] * scale))
+		if ret[i] < 1 {
+			ret[i] = 1
+		}
+	}
+	return ret
+}
+
+// UnservedZones returns the sorted zones of the backends which are not served by the load balancer, the unknown
+// zones are ignored
+func UnservedZones(backendZones []string, servedZones sets.String) []string {
+	unserved := sets.NewString()
+	for _, zone := range backendZones {
+		if zone != "" && !servedZones.Has(zone) {
+			unserved.Insert(zone)
+		}
+	}
+	return unserved.List()
+}
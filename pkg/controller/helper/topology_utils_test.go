@@ -0,0 +1,59 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestBalanceWeightsByZone(t *testing.T) {
+	cases := []struct {
+		name     string
+		weights  []int
+		zones    []string
+		balanced []int
+	}{
+		{
+			name:     "single zone",
+			weights:  []int{100, 100},
+			zones:    []string{"a", "a"},
+			balanced: []int{100, 100},
+		},
+		{
+			name:     "more pods in one zone",
+			weights:  []int{100, 100, 100, 100},
+			zones:    []string{"a", "b", "b", "b"},
+			balanced: []int{100, 33, 33, 33},
+		},
+		{
+			name:     "node weights by pod number",
+			weights:  []int{3, 1, 2},
+			zones:    []string{"a", "b", "b"},
+			balanced: []int{100, 33, 67},
+		},
+		{
+			name:     "uneven node weights",
+			weights:  []int{2, 1, 1, 2},
+			zones:    []string{"a", "b", "b", "c"},
+			balanced: []int{100, 50, 50, 100},
+		},
+		{
+			name:     "zero weights are kept",
+			weights:  []int{10, 0, 10, 10},
+			zones:    []string{"a", "a", "b", "b"},
+			balanced: []int{100, 0, 50, 50},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.balanced, BalanceWeightsByZone(c.weights, c.zones, 100))
+		})
+	}
+}
+
+func TestUnservedZones(t *testing.T) {
+	served := sets.NewString("a", "b")
+	assert.Equal(t, []string{"c", "d"}, UnservedZones([]string{"d", "a", "", "c", "d"}, served))
+	assert.Empty(t, UnservedZones([]string{"a", "b"}, served))
+}
@@ -40,9 +40,11 @@ import (
 	networking "k8s.io/api/networking/v1"
 	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apiext "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -76,6 +78,7 @@ func NewAlbConfigReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*al
 	n := &albconfigReconciler{
 		cloud:            ctx.Provider(),
 		k8sClient:        mgr.GetClient(),
+		apiReader:        mgr.GetAPIReader(),
 		groupLoader:      albconfigmanager.NewDefaultGroupLoader(mgr.GetClient(), mgr.GetCache(), extc, annotations.NewSuffixAnnotationParser(annotations.DefaultAnnotationsPrefix)),
 		referenceIndexer: helper.NewDefaultReferenceIndexer(),
 		eventRecorder:    mgr.GetEventRecorderFor("ingress"),
@@ -128,6 +131,7 @@ type Configuration struct {
 type albconfigReconciler struct {
 	cloud                prvd.Provider
 	k8sClient            client.Client
+	apiReader            client.Reader // reads the ConfigMaps of the last synced ingresses, which are not watched
 	kubeClientCache      cache.Cache
 	groupLoader          albconfigmanager.GroupLoader
 	referenceIndexer     helper.ReferenceIndexer
@@ -345,7 +349,7 @@ func (g *albconfigReconciler) reconcileServers(ctx context.Context, svc *corev1.
 	}
 
 	ings := g.store.ListIngresses()
-	if len(ings) == 0 {
+	if len(ings) == 0 && len(g.store.ListDefaultBackendAlbConfigs(util.Key(svc))) == 0 {
 		g.logger.Info("service not used by ingress, skip", "key", svc.Name)
 		return nil
 	}
@@ -362,6 +366,7 @@ func (g *albconfigReconciler) reconcileServers(ctx context.Context, svc *corev1.
 		if err != nil {
 			return err
 		}
+		svcStackContext.IngressConnectionDrainTimeout = buildIngressConnectionDrainTimeout(ings)
 
 		if err = g.buildAndApplyServers(ctx, svcStackContext); err != nil {
 			return err
@@ -452,8 +457,8 @@ func (g *albconfigReconciler) getServicePortToIngressNames(ctx context.Context,
 		}
 		ingressAlbConfigMap[ing.Namespace+"/"+ing.Name] = ingGroup.String()
 
-		if ing.Spec.DefaultBackend != nil {
-			if ing.Spec.DefaultBackend.Service.Name == request.Name {
+		if ing.Spec.DefaultBackend != nil && ing.Spec.DefaultBackend.Service != nil {
+			if request.Namespace == ing.Namespace && ing.Spec.DefaultBackend.Service.Name == request.Name {
 				processIngressBackend(*ing.Spec.DefaultBackend, ing.Name)
 			}
 		}
@@ -529,17 +534,23 @@ func (g *albconfigReconciler) getServicePortToIngressNames(ctx context.Context,
 						actType := strings.ToLower(action.Type)
 						if actType == strings.ToLower(util.RuleActionTypeForward) {
 							for _, sg := range action.ForwardConfig.ServerGroups {
-								if sg.ServiceName == request.Name {
+								if sg.ServiceKey(ing.Namespace) == request.NamespacedName {
 									g.logger.Info("processIngressBackend", "ServiceName", path.Backend.Service.Name, request.Name)
 									b := networking.IngressBackend{
 										Service: &networking.IngressServiceBackend{
-											Name: sg.ServiceName,
+											Name: request.Name,
 											Port: networking.ServiceBackendPort{
 												Number: intstr.FromInt(sg.ServicePort).IntVal,
 											},
 										},
 									}
-									processIngressBackend(b, ing.Name)
+									ingName := ing.Name
+									if request.Namespace != ing.Namespace {
+										// the server group is owned by the ingress in the namespace of the service
+										ingName = albconfigmanager.CrossNamespaceIngressName(&ing.Ingress)
+										ingressAlbConfigMap[request.Namespace+"/"+ingName] = ingGroup.String()
+									}
+									processIngressBackend(b, ingName)
 								}
 							}
 						}
@@ -550,6 +561,32 @@ func (g *albconfigReconciler) getServicePortToIngressNames(ctx context.Context,
 		}
 	}
 
+	for _, key := range g.store.ListDefaultBackendAlbConfigs(request.NamespacedName.String()) {
+		albconfig, err := g.getAlbConfigByKey(ctx, key)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return map[int32][]string{}, ingressAlbConfigMap, err
+		}
+		svcKey := albconfigmanager.AlbConfigDefaultBackendService(albconfig)
+		if svcKey == nil || *svcKey != request.NamespacedName {
+			continue
+		}
+		ingName := albconfigmanager.AlbConfigDefaultBackendIngressName(albconfig)
+		groupID := albconfigmanager.GroupID{Namespace: albconfig.Namespace, Name: albconfig.Name}
+		if groupID.Namespace == "" {
+			groupID.Namespace = albconfigmanager.ALBConfigNamespace
+		}
+		ingressAlbConfigMap[request.Namespace+"/"+ingName] = groupID.String()
+		processIngressBackend(networking.IngressBackend{
+			Service: &networking.IngressServiceBackend{
+				Name: svcKey.Name,
+				Port: networking.ServiceBackendPort{Number: albconfig.Spec.DefaultBackend.ServicePort},
+			},
+		}, ingName)
+	}
+
 	var servicePortToIngressNameList = make(map[int32][]string)
 	for servicePort, ingressNames := range servicePortToIngressNames {
 		for ingressName := range ingressNames {
@@ -560,6 +597,47 @@ func (g *albconfigReconciler) getServicePortToIngressNames(ctx context.Context,
 	return servicePortToIngressNameList, ingressAlbConfigMap, nil
 }
 
+// updateAlbConfigDefaultBackendRef records the Service of spec.defaultBackend of the albconfig, so that the servers
+// of its server group are synced when the endpoints of the Service change.
+func (g *albconfigReconciler) updateAlbConfigDefaultBackendRef(albconfig *v1.AlbConfig) {
+	svcKey := ""
+	if backend := albconfigmanager.AlbConfigDefaultBackendService(albconfig); backend != nil && albconfig.DeletionTimestamp.IsZero() {
+		svcKey = backend.String()
+	}
+	g.store.SetAlbConfigDefaultBackend(util.Key(albconfig), svcKey)
+}
+
+// getAlbConfigByKey gets the albconfig by its namespace/name key, the namespace is empty if it is cluster scoped
+func (g *albconfigReconciler) getAlbConfigByKey(ctx context.Context, key string) (*v1.AlbConfig, error) {
+	namespace, name := "", key
+	if i := strings.Index(key, "/"); i >= 0 {
+		namespace, name = key[:i], key[i+1:]
+	}
+	albconfig := &v1.AlbConfig{}
+	if err := g.k8sClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, albconfig); err != nil {
+		return nil, err
+	}
+	return albconfig, nil
+}
+
+// buildIngressConnectionDrainTimeout returns the connection drain timeout of the ingresses enabling the connection
+// drain by namespace/name key, including the ingresses owning the server groups of the Services in other namespaces
+func buildIngressConnectionDrainTimeout(ings []*store.Ingress) map[string]int {
+	drainTimeout := make(map[string]int)
+	for _, ing := range ings {
+		if conf := albconfigmanager.BuildServerGroupConnectionDrainConfig(&ing.Ingress); conf.ConnectionDrainEnabled {
+			drainTimeout[ing.Namespace+"/"+ing.Name] = conf.ConnectionDrainTimeout
+			_, sgps := store.CheckAnnotationForwardAction(ing.Ingress)
+			for _, sgp := range sgps {
+				if svcKey := sgp.ServiceKey(ing.Namespace); svcKey.Namespace != ing.Namespace {
+					drainTimeout[svcKey.Namespace+"/"+albconfigmanager.CrossNamespaceIngressName(&ing.Ingress)] = conf.ConnectionDrainTimeout
+				}
+			}
+		}
+	}
+	return drainTimeout
+}
+
 func (g *albconfigReconciler) buildServiceStackContext(ctx context.Context, request reconcile.Request, serverPortToIngressNames map[int32][]string, ingressAlbConfigMap map[string]string) (*albmodel.ServiceStackContext, error) {
 	var svcStackContext = &albmodel.ServiceStackContext{
 		ClusterID:                 g.cloud.ClusterID(),
@@ -568,6 +646,11 @@ func (g *albconfigReconciler) buildServiceStackContext(ctx context.Context, requ
 		ServicePortToIngressNames: serverPortToIngressNames,
 		IngressAlbConfigMap:       ingressAlbConfigMap,
 	}
+	albconfigShards, err := g.buildAlbConfigShards(ctx, ingressAlbConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	svcStackContext.AlbConfigShards = albconfigShards
 
 	svc := &corev1.Service{}
 	if err := g.k8sClient.Get(ctx, request.NamespacedName, svc); err != nil {
@@ -598,6 +681,7 @@ func (s *albconfigReconciler) buildAndApplyServers(ctx context.Context, svcStack
 	if err != nil {
 		return fmt.Errorf("build service stack model error: %v", err)
 	}
+	s.warnUnservedBackendZones(ctx, svcStackCtx, serverStack)
 
 	serviceStackJson, err := json.Marshal(serverStack)
 	if err != nil {
@@ -627,6 +711,53 @@ func (s *albconfigReconciler) buildAndApplyServers(ctx context.Context, svcStack
 	return nil
 }
 
+// warnUnservedBackendZones records an event if the backends of the Service enabling the topology aware weight sit
+// in the zones which the zone mappings of its albconfigs do not include. The zones are unknown unless all the zone
+// mappings of the albconfigs set the zoneId.
+func (s *albconfigReconciler) warnUnservedBackendZones(ctx context.Context, svcStackCtx *albmodel.ServiceStackContext, serverStack *albmodel.ServiceManager) {
+	svc := svcStackCtx.Service
+	if svc == nil || svc.Annotations[annotations.AlbTopologyAwareWeight] != "true" {
+		return
+	}
+	albconfigs := sets.NewString()
+	for _, key := range svcStackCtx.IngressAlbConfigMap {
+		albconfigs.Insert(key)
+	}
+	served := sets.NewString()
+	for _, key := range albconfigs.List() {
+		albconfig, err := s.getAlbConfigByKey(ctx, key)
+		if err != nil {
+			s.logger.Info("get albconfig failed, skip checking the zones of backends", "albconfig", key, "error", err.Error())
+			return
+		}
+		if len(albconfig.Spec.LoadBalancer.ZoneMappings) == 0 {
+			return
+		}
+		for _, zm := range albconfig.Spec.LoadBalancer.ZoneMappings {
+			if zm.ZoneId == "" {
+				return
+			}
+			served.Insert(zm.ZoneId)
+		}
+	}
+	if served.Len() == 0 {
+		return
+	}
+
+	var zones []string
+	for _, sg := range serverStack.PortToServerGroup {
+		for _, b := range sg.Backends {
+			if !b.Draining {
+				zones = append(zones, b.Zone)
+			}
+		}
+	}
+	if unserved := helper.UnservedZones(zones, served); len(unserved) != 0 {
+		s.eventRecorder.Event(svc, corev1.EventTypeWarning, helper.UnservedBackendZones,
+			fmt.Sprintf("Backends in zones %v are not served by the zone mappings %v of the albconfigs", unserved, served.List()))
+	}
+}
+
 func (g *albconfigReconciler) makeAlbConfig(ctx context.Context, groupName string, ing *networking.Ingress) *v1.AlbConfig {
 	id, _ := annotations.GetStringAnnotation(annotations.LoadBalancerId, ing)
 	albForceOverride := false
@@ -744,8 +875,12 @@ func (g *albconfigReconciler) Reconcile(_ context.Context, req reconcile.Request
 func (g *albconfigReconciler) reconcile(ctx context.Context, request reconcile.Request) error {
 	albconfig := &v1.AlbConfig{}
 	if err := g.k8sClient.Get(ctx, request.NamespacedName, albconfig); err != nil {
+		if errors.IsNotFound(err) {
+			g.store.SetAlbConfigDefaultBackend(request.NamespacedName.String(), "")
+		}
 		return client.IgnoreNotFound(err)
 	}
+	g.updateAlbConfigDefaultBackendRef(albconfig)
 	ings := g.store.ListIngresses()
 	if request.NamespacedName.Namespace == "" {
 		request.NamespacedName.Namespace = albconfigmanager.ALBConfigNamespace
@@ -755,11 +890,14 @@ func (g *albconfigReconciler) reconcile(ctx context.Context, request reconcile.R
 		if errIngress != nil {
 			g.recordIngressSingleEvent(ctx, albconfig, errIngress, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
 		}
+		g.updateAlbConfigFailedStatus(ctx, albconfig, newAlbConfigSyncError(v1.AlbConfigReasonLoadGroupFailed, err))
 		return err
 	}
 
 	if albconfig.Spec.LoadBalancer == nil {
-		return fmt.Errorf("does not exist albconfig.spec.config")
+		err := fmt.Errorf("does not exist albconfig.spec.config")
+		g.updateAlbConfigFailedStatus(ctx, albconfig, newAlbConfigSyncError(v1.AlbConfigReasonInvalidSpec, err))
+		return err
 	}
 
 	// reuse loadBalancer
@@ -768,10 +906,12 @@ func (g *albconfigReconciler) reconcile(ctx context.Context, request reconcile.R
 	}
 	if !albconfig.DeletionTimestamp.IsZero() {
 		if err := g.cleanupAlbLoadBalancerResources(ctx, albconfig, ingGroup); err != nil {
+			g.updateAlbConfigFailedStatus(ctx, albconfig, err)
 			return err
 		}
 	} else {
 		if err := g.reconcileAlbLoadBalancerResources(ctx, albconfig, ingGroup); err != nil {
+			g.updateAlbConfigFailedStatus(ctx, albconfig, err)
 			if len(ingGroup.InactiveMembers) != 0 {
 				if err := g.groupFinalizerManager.RemoveGroupFinalizer(ctx, ingGroup.InactiveMembers); err != nil {
 					g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedRemoveFinalizer, helper.GetLogMessage(err))
@@ -797,22 +937,28 @@ func (g *albconfigReconciler) reconcile(ctx context.Context, request reconcile.R
 func (g *albconfigReconciler) cleanupAlbLoadBalancerResources(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group) error {
 	gwFinalizer := albconfigmanager.GetIngressFinalizer()
 	if helper.HasFinalizer(albconfig, gwFinalizer) {
-		_, _, err := g.buildAndApply(ctx, albconfig, ingGroup)
+		_, _, _, err := g.buildAndApply(ctx, albconfig, ingGroup)
 		if err != nil {
 			return err
 		}
+		helper.ForgetCertificateExpiry(albConfigKind, albconfig.Namespace, albconfig.Name)
+		g.collectSecretCertificates(ctx, albconfig, nil)
+		if len(albconfig.Status.SecretCertificates) != 0 {
+			return newAlbConfigSyncError(v1.AlbConfigReasonCleanupFailed,
+				fmt.Errorf("failed delete %d certificates uploaded from secrets", len(albconfig.Status.SecretCertificates)))
+		}
 		if err := g.removeAlbConfigLabel(albconfig); err != nil {
 			g.eventRecorder.Event(albconfig, corev1.EventTypeWarning, helper.IngressEventReasonFailedUpdateStatus, fmt.Sprintf("Failed remove labels due to %s", err))
-			return err
+			return newAlbConfigSyncError(v1.AlbConfigReasonCleanupFailed, err)
 		}
 		if err := g.k8sFinalizerManager.RemoveFinalizers(ctx, albconfig, gwFinalizer); err != nil {
 			g.eventRecorder.Event(albconfig, corev1.EventTypeWarning, helper.IngressEventReasonFailedRemoveFinalizer, fmt.Sprintf("Failed remove finalizer due to %v", err))
-			return err
+			return newAlbConfigSyncError(v1.AlbConfigReasonUpdateFinalizerFailed, err)
 		}
 		if len(ingGroup.Members) != 0 {
 			if err := g.groupFinalizerManager.RemoveGroupFinalizer(ctx, ingGroup.Members); err != nil {
 				g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedRemoveFinalizer, fmt.Sprintf("Failed remove finalizer due to %v", err))
-				return err
+				return newAlbConfigSyncError(v1.AlbConfigReasonUpdateFinalizerFailed, err)
 			}
 		}
 	}
@@ -823,27 +969,50 @@ func (g *albconfigReconciler) reconcileAlbLoadBalancerResources(ctx context.Cont
 	gwFinalizer := albconfigmanager.GetIngressFinalizer()
 	if err := g.k8sFinalizerManager.AddFinalizers(ctx, albconfig, gwFinalizer); err != nil {
 		g.eventRecorder.Event(albconfig, corev1.EventTypeWarning, helper.IngressEventReasonFailedRemoveFinalizer, helper.GetLogMessage(err))
-		return err
+		return newAlbConfigSyncError(v1.AlbConfigReasonUpdateFinalizerFailed, err)
 	}
-	stack, lb, err := g.buildAndApply(ctx, albconfig, ingGroup)
+	stack, lb, shards, err := g.buildAndApply(ctx, albconfig, ingGroup)
 	if err != nil {
+		g.updateIngressSyncStatus(ctx, albconfig, ingGroup, nil, nil, err)
 		return err
 	}
+	stacks := []core.Manager{stack}
+	if len(shards) != 0 {
+		stacks = make([]core.Manager, 0, len(shards))
+		for _, shard := range shards {
+			stacks = append(stacks, shard.stack)
+		}
+	}
+	var conflicts []albconfigmanager.RuleConflict
+	for _, s := range stacks {
+		conflicts = append(conflicts, albconfigmanager.AnalyzeListenerRules(s)...)
+	}
+	g.recordRuleConflictEvents(ctx, albconfig, ingGroup, conflicts)
+	g.recordDefaultBackendConflictEvents(ctx, albconfig, ingGroup)
+	setAlbConfigRuleConflictCondition(albconfig, conflicts)
+	setAlbConfigQuotaCondition(albconfig, len(shards))
+	albconfig.Status.Shards = buildShardStatuses(shards)
+	g.collectSecretCertificates(ctx, albconfig, stacks)
+	g.checkCertificateExpiry(ctx, albconfig, ingGroup, stacks)
+	g.updateIngressSyncStatus(ctx, albconfig, ingGroup, stacks, conflicts, nil)
 	//if err := g.groupFinalizerManager.AddGroupFinalizer(ctx, ingGroup.Members); err != nil {
 	//	g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedAddFinalizer, fmt.Sprintf("Failed add finalizer due to %v", err))
 	//	return err
 	//}
 	if lb.Status == nil || lb.Status.DNSName == "" {
+		setAlbConfigSyncedStatus(albconfig, nil, isolatedMemberKeys(ingGroup))
+		if err := g.k8sClient.Status().Update(ctx, albconfig); err != nil {
+			g.logger.Error(err, "LB Status Update %s, error: %s", albconfig.Name)
+			return err
+		}
 		return nil
 	}
-	for _, ing := range ingGroup.Members {
-		if ing.Status.LoadBalancer.Ingress != nil && len(ing.Status.LoadBalancer.Ingress) > 0 && ing.Status.LoadBalancer.Ingress[0].Hostname == lb.Status.DNSName {
+	for _, ing := range syncedMembers(ingGroup) {
+		lbIngresses := buildIngressLoadBalancerStatus(ing, lb.Status.DNSName, shards)
+		if equality.Semantic.DeepEqual(ing.Status.LoadBalancer.Ingress, lbIngresses) {
 			continue
 		}
-		lbi := networking.IngressLoadBalancerIngress{
-			Hostname: lb.Status.DNSName,
-		}
-		ing.Status.LoadBalancer.Ingress = []networking.IngressLoadBalancerIngress{lbi}
+		ing.Status.LoadBalancer.Ingress = lbIngresses
 		err = g.k8sClient.Status().Update(ctx, ing)
 		if err != nil {
 			g.logger.Error(err, "Ingress Status Update %s, error: %s", ing.Name)
@@ -876,7 +1045,7 @@ func (g *albconfigReconciler) reconcileAlbLoadBalancerResources(ctx context.Cont
 		DNSName:   lb.Status.DNSName,
 		Listeners: listenerStatus,
 	}
-	albconfig.Status.LoadBalancer = status
+	setAlbConfigSyncedStatus(albconfig, &status, isolatedMemberKeys(ingGroup))
 	err = g.k8sClient.Status().Update(ctx, albconfig)
 	if err != nil {
 		g.logger.Error(err, "LB Status Update %s, error: %s", albconfig.Name)
@@ -884,7 +1053,7 @@ func (g *albconfigReconciler) reconcileAlbLoadBalancerResources(ctx context.Cont
 	}
 
 	// update ingress labels if necessary
-	for _, ing := range ingGroup.Members {
+	for _, ing := range syncedMembers(ingGroup) {
 		rawIng, err := g.store.GetIngress(util.Key(ing))
 		if err != nil {
 			g.logger.Error(err, "Error get ingress from store", "ingress", util.Key(ing))
@@ -907,7 +1076,10 @@ func (g *albconfigReconciler) reconcileAlbLoadBalancerResources(ctx context.Cont
 	return nil
 }
 
-func (g *albconfigReconciler) buildAndApply(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group) (core.Manager, *albmodel.AlbLoadBalancer, error) {
+// buildAndApply builds and applies the stack of the group. When the stack exceeds the quotas of the ALB instance,
+// the group is split across several ALB instances if sharding is enabled, the shards are returned with the stack
+// and the load balancer of the first shard, otherwise the stack is not applied.
+func (g *albconfigReconciler) buildAndApply(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group) (core.Manager, *albmodel.AlbLoadBalancer, []*albShard, error) {
 	traceID := ctx.Value(util.TraceID)
 
 	buildStartTime := time.Now()
@@ -922,7 +1094,7 @@ func (g *albconfigReconciler) buildAndApply(ctx context.Context, albconfig *v1.A
 		"traceID", traceID,
 		"startTime", buildStartTime)
 
-	stack, lb, errResWithIngress, err := g.albconfigBuilder.Build(ctx, albconfig, ingGroup)
+	stack, lb, errResWithIngress, err := g.buildWithIsolation(ctx, albconfig, ingGroup)
 	if err != nil {
 		for errIngress, errMsg := range errResWithIngress {
 			g.recordIngressSingleEvent(ctx, albconfig, errIngress, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(errMsg))
@@ -930,37 +1102,74 @@ func (g *albconfigReconciler) buildAndApply(ctx context.Context, albconfig *v1.A
 		if len(errResWithIngress) == 0 {
 			g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
 		}
-		return nil, nil, err
+		return nil, nil, nil, newAlbConfigSyncError(v1.AlbConfigReasonBuildModelFailed, err)
 	}
+	g.recordIsolatedMemberEvents(ctx, albconfig, ingGroup)
 
-	stackJSON, err := g.stackMarshaller.Marshal(stack)
-	if err != nil {
-		g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
-		return nil, nil, err
+	quota := albconfigmanager.GetQuota(albconfig)
+	violations := albconfigmanager.CheckQuota(stack, quota)
+	if albconfig.DeletionTimestamp.IsZero() && albconfigmanager.IsShardingEnabled(albconfig) &&
+		(len(violations) != 0 || albconfigmanager.IsSharded(albconfig)) {
+		shards, err := g.buildAndApplyShards(ctx, albconfig, ingGroup, quota)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return shards[0].stack, shards[0].lb, shards, nil
+	}
+	if len(violations) != 0 {
+		err := newQuotaExceededError(violations)
+		g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonQuotaExceeded, helper.GetLogMessage(err))
+		return nil, nil, nil, newAlbConfigSyncError(v1.AlbConfigReasonQuotaExceeded, err)
 	}
 
 	g.logger.Info("successfully built albconfig stack",
 		"albconfig", util.NamespacedName(albconfig).String(),
-		"traceID", &traceID,
-		"stack", stackJSON,
+		"traceID", traceID,
 		"buildElapsedTime", time.Since(buildStartTime).Milliseconds())
 
+	if err := g.applyStack(ctx, albconfig, ingGroup, stack); err != nil {
+		return nil, nil, nil, err
+	}
+	// the shards are no longer needed once the group is served by the ALB instance of the albconfig only
+	if err := g.cleanupStaleShards(ctx, albconfig, ingGroup, nil); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return stack, lb, nil, nil
+}
+
+func (g *albconfigReconciler) applyStack(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group, stack core.Manager) error {
+	traceID := ctx.Value(util.TraceID)
+
+	stackJSON, err := g.stackMarshaller.Marshal(stack)
+	if err != nil {
+		g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
+		return newAlbConfigSyncError(v1.AlbConfigReasonBuildModelFailed, err)
+	}
+	g.logger.Info("applying albconfig stack",
+		"albconfig", util.NamespacedName(albconfig).String(),
+		"traceID", traceID,
+		"stack", stackJSON)
+
 	applyStartTime := time.Now()
 	if err := g.albconfigApplier.Apply(ctx, stack); err != nil {
 		g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedApplyModel, helper.GetLogMessage(err))
-		return nil, nil, err
+		return newAlbConfigSyncError(v1.AlbConfigReasonApplyModelFailed, err)
 	}
 	g.logger.Info("successfully applied albconfig stack",
 		"albconfig", util.NamespacedName(albconfig).String(),
 		"traceID", traceID,
 		"applyElapsedTime", time.Since(applyStartTime).Milliseconds())
-
-	return stack, lb, nil
+	return nil
 }
 
 func (g *albconfigReconciler) recordIngressGroupEvent(_ context.Context, albConfig *v1.AlbConfig, ingGroup *albconfigmanager.Group, eventType string, reason string, message string) {
 	g.eventRecorder.Event(albConfig, eventType, reason, message)
 	for _, member := range ingGroup.Members {
+		// the isolated members have their own events
+		if isIsolatedMember(ingGroup, member) {
+			continue
+		}
 		g.eventRecorder.Event(member, eventType, reason, message)
 	}
 }
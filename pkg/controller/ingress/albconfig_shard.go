@@ -0,0 +1,172 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// albShard is a shard of the Ingress group with its applied stack
+type albShard struct {
+	*albconfigmanager.Shard
+	stack core.Manager
+	lb    *albmodel.AlbLoadBalancer
+}
+
+func newQuotaExceededError(violations []albconfigmanager.QuotaViolation) error {
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.String())
+	}
+	return fmt.Errorf("quota exceeded: %s", strings.Join(messages, "; "))
+}
+
+// shardContext returns the context to apply a shard, the ALB instances of the shards other than the first one are
+// created by the controller, even if the albconfig reuses an existing ALB instance.
+func shardContext(ctx context.Context, shard *albconfigmanager.Shard, albconfig *v1.AlbConfig) context.Context {
+	if shard.AlbConfig == albconfig {
+		return ctx
+	}
+	return context.WithValue(ctx, util.IsReuseLb, false)
+}
+
+// buildAndApplyShards splits the group by host and applies a stack for each shard. All the shards are built and
+// checked against the quotas before any of them is applied.
+func (g *albconfigReconciler) buildAndApplyShards(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group,
+	quota albconfigmanager.Quota) ([]*albShard, error) {
+	shards, err := albconfigmanager.ShardIngressGroup(albconfig, ingGroup, quota)
+	if err != nil {
+		g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonQuotaExceeded, helper.GetLogMessage(err))
+		return nil, newAlbConfigSyncError(v1.AlbConfigReasonQuotaExceeded, err)
+	}
+
+	applied := make([]*albShard, 0, len(shards))
+	for _, shard := range shards {
+		stack, lb, _, err := g.albconfigBuilder.Build(shardContext(ctx, shard, albconfig), shard.AlbConfig, shard.Group)
+		if err != nil {
+			err = fmt.Errorf("shard %s: %w", shard.Name, err)
+			g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, helper.GetLogMessage(err))
+			return nil, newAlbConfigSyncError(v1.AlbConfigReasonBuildModelFailed, err)
+		}
+		if violations := albconfigmanager.CheckQuota(stack, quota); len(violations) != 0 {
+			err := fmt.Errorf("shard %s: %w", shard.Name, newQuotaExceededError(violations))
+			g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonQuotaExceeded, helper.GetLogMessage(err))
+			return nil, newAlbConfigSyncError(v1.AlbConfigReasonQuotaExceeded, err)
+		}
+		applied = append(applied, &albShard{Shard: shard, stack: stack, lb: lb})
+	}
+
+	g.logger.Info("shard ingress group",
+		"albconfig", util.NamespacedName(albconfig).String(),
+		"shards", len(applied),
+		"traceID", ctx.Value(util.TraceID))
+	for _, shard := range applied {
+		if err := g.applyStack(shardContext(ctx, shard.Shard, albconfig), shard.AlbConfig, ingGroup, shard.stack); err != nil {
+			return nil, err
+		}
+	}
+	if err := g.cleanupStaleShards(ctx, albconfig, ingGroup, shards); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// cleanupStaleShards deletes the ALB instances of the shards recorded in the albconfig status which are not used
+func (g *albconfigReconciler) cleanupStaleShards(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group,
+	shards []*albconfigmanager.Shard) error {
+	for _, shard := range albconfigmanager.StaleShards(albconfig, ingGroup, shards) {
+		shardCtx := shardContext(ctx, shard, albconfig)
+		stack, _, _, err := g.albconfigBuilder.Build(shardCtx, shard.AlbConfig, shard.Group)
+		if err != nil {
+			return newAlbConfigSyncError(v1.AlbConfigReasonCleanupFailed, fmt.Errorf("shard %s: %w", shard.Name, err))
+		}
+		if err := g.albconfigApplier.Apply(shardCtx, stack); err != nil {
+			g.recordIngressGroupEvent(ctx, albconfig, ingGroup, corev1.EventTypeWarning, helper.IngressEventReasonFailedApplyModel,
+				helper.GetLogMessage(err))
+			return newAlbConfigSyncError(v1.AlbConfigReasonCleanupFailed, fmt.Errorf("shard %s: %w", shard.Name, err))
+		}
+		g.logger.Info("deleted stale shard",
+			"albconfig", util.NamespacedName(albconfig).String(),
+			"shard", shard.Name,
+			"traceID", ctx.Value(util.TraceID))
+	}
+	return nil
+}
+
+// buildAlbConfigShards returns the keys of the shards other than the first one of the sharded albconfigs of the
+// ingresses, by the key of the albconfig, so that the servers of the server groups on all the shards are synced
+func (g *albconfigReconciler) buildAlbConfigShards(ctx context.Context, ingressAlbConfigMap map[string]string) (map[string][]string, error) {
+	albconfigs := sets.NewString()
+	for _, key := range ingressAlbConfigMap {
+		albconfigs.Insert(key)
+	}
+	albconfigShards := make(map[string][]string)
+	for _, key := range albconfigs.List() {
+		albconfig, err := g.getAlbConfigByKey(ctx, key)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		namespace := albconfigmanager.ALBConfigNamespace
+		if i := strings.Index(key, "/"); i >= 0 {
+			namespace = key[:i]
+		}
+		for _, id := range albconfigmanager.ShardGroupIDs(albconfig, albconfigmanager.GroupID{Namespace: namespace, Name: albconfig.Name}) {
+			albconfigShards[key] = append(albconfigShards[key], id.String())
+		}
+	}
+	return albconfigShards, nil
+}
+
+// buildShardStatuses returns the status of the shards, nil if the group is not sharded
+func buildShardStatuses(shards []*albShard) []v1.ShardStatus {
+	if len(shards) == 0 {
+		return nil
+	}
+	statuses := make([]v1.ShardStatus, 0, len(shards))
+	for _, shard := range shards {
+		status := v1.ShardStatus{Name: shard.Name, Hosts: shard.Hosts}
+		if shard.lb != nil && shard.lb.Status != nil {
+			status.Id = shard.lb.Status.LoadBalancerID
+			status.DNSName = shard.lb.Status.DNSName
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// buildIngressLoadBalancerStatus returns the DNS names serving the hosts of the ingress. A sharded ingress is served
+// by the shards of its hosts, the first shard serves the rules without host.
+func buildIngressLoadBalancerStatus(ing *networking.Ingress, dnsName string, shards []*albShard) []networking.IngressLoadBalancerIngress {
+	if len(shards) == 0 {
+		return []networking.IngressLoadBalancerIngress{{Hostname: dnsName}}
+	}
+	hosts := sets.NewString()
+	for _, rule := range ing.Spec.Rules {
+		hosts.Insert(rule.Host)
+	}
+	var lbIngresses []networking.IngressLoadBalancerIngress
+	for i, shard := range shards {
+		if shard.lb == nil || shard.lb.Status == nil || shard.lb.Status.DNSName == "" {
+			continue
+		}
+		if !hosts.HasAny(shard.Hosts...) && !(i == 0 && (hosts.Has("") || hosts.Len() == 0)) {
+			continue
+		}
+		lbIngresses = append(lbIngresses, networking.IngressLoadBalancerIngress{Hostname: shard.lb.Status.DNSName})
+	}
+	return lbIngresses
+}
@@ -0,0 +1,83 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestShard(name, dnsName string, hosts ...string) *albShard {
+	shard := &albShard{Shard: &albconfigmanager.Shard{Name: name, Hosts: hosts}, lb: &albmodel.AlbLoadBalancer{}}
+	if dnsName != "" {
+		shard.lb.Status = &albmodel.LoadBalancerStatus{LoadBalancerID: "alb-" + name, DNSName: dnsName}
+	}
+	return shard
+}
+
+func TestBuildIngressLoadBalancerStatus(t *testing.T) {
+	hostIngress := func(hosts ...string) *networking.Ingress {
+		ing := &networking.Ingress{}
+		for _, host := range hosts {
+			ing.Spec.Rules = append(ing.Spec.Rules, networking.IngressRule{Host: host})
+		}
+		return ing
+	}
+	lbIngress := func(dnsNames ...string) []networking.IngressLoadBalancerIngress {
+		var lbIngresses []networking.IngressLoadBalancerIngress
+		for _, dnsName := range dnsNames {
+			lbIngresses = append(lbIngresses, networking.IngressLoadBalancerIngress{Hostname: dnsName})
+		}
+		return lbIngresses
+	}
+	assert.Equal(t, lbIngress("alb.example.com"), buildIngressLoadBalancerStatus(hostIngress("a.com"), "alb.example.com", nil))
+
+	shards := []*albShard{
+		newTestShard("alb", "alb.example.com", "a.com"),
+		newTestShard("alb-shard-1", "shard-1.example.com", "b.com", "c.com"),
+		newTestShard("alb-shard-2", "", "d.com"),
+	}
+	assert.Equal(t, lbIngress("alb.example.com"), buildIngressLoadBalancerStatus(hostIngress("a.com"), "alb.example.com", shards))
+	assert.Equal(t, lbIngress("shard-1.example.com"), buildIngressLoadBalancerStatus(hostIngress("c.com"), "alb.example.com", shards))
+	assert.Equal(t, lbIngress("alb.example.com", "shard-1.example.com"),
+		buildIngressLoadBalancerStatus(hostIngress("", "b.com"), "alb.example.com", shards))
+	assert.Equal(t, lbIngress("alb.example.com"), buildIngressLoadBalancerStatus(hostIngress(), "alb.example.com", shards))
+	// the shard is still provisioning
+	assert.Empty(t, buildIngressLoadBalancerStatus(hostIngress("d.com"), "alb.example.com", shards))
+}
+
+func TestBuildShardStatuses(t *testing.T) {
+	assert.Nil(t, buildShardStatuses(nil))
+	assert.Equal(t, []v1.ShardStatus{
+		{Name: "alb", Id: "alb-alb", DNSName: "alb.example.com", Hosts: []string{"a.com"}},
+		{Name: "alb-shard-1", Hosts: []string{"b.com"}},
+	}, buildShardStatuses([]*albShard{
+		newTestShard("alb", "alb.example.com", "a.com"),
+		newTestShard("alb-shard-1", "", "b.com"),
+	}))
+}
+
+func TestBuildAlbConfigShards(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1.SchemeBuilder.AddToScheme(scheme)
+	sharded := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "alb"}}
+	sharded.Status.Shards = []v1.ShardStatus{{Name: "alb"}, {Name: "alb-shard-1", Hosts: []string{"b.com"}}}
+	plain := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "plain"}}
+	g := &albconfigReconciler{k8sClient: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(sharded, plain).Build()}
+
+	albconfigShards, err := g.buildAlbConfigShards(context.TODO(), map[string]string{
+		"default/a":    "default/alb",
+		"default/b":    "default/alb",
+		"default/c":    "default/plain",
+		"default/gone": "default/gone",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"default/alb": {"default/alb-shard-1"}}, albconfigShards)
+}
@@ -0,0 +1,157 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// albconfigSyncError records the condition reason of a failed reconcile step.
+type albconfigSyncError struct {
+	reason v1.AlbConfigConditionReason
+	err    error
+}
+
+func newAlbConfigSyncError(reason v1.AlbConfigConditionReason, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &albconfigSyncError{reason: reason, err: err}
+}
+
+func (e *albconfigSyncError) Error() string {
+	return e.err.Error()
+}
+
+func (e *albconfigSyncError) Unwrap() error {
+	return e.err
+}
+
+func syncErrorReason(err error) v1.AlbConfigConditionReason {
+	if e, ok := err.(*albconfigSyncError); ok {
+		return e.reason
+	}
+	return v1.AlbConfigReasonSyncFailed
+}
+
+// setAlbConfigSyncedStatus sets the status of a successful reconcile on the albconfig, without updating it.
+// The lb status is nil when the load balancer is still being provisioned. The albconfig is degraded
+// if any ingress of the group is isolated.
+func setAlbConfigSyncedStatus(albconfig *v1.AlbConfig, lbStatus *v1.LoadBalancerStatus, isolatedIngresses []string) {
+	status := &albconfig.Status
+	status.ObservedGeneration = albconfig.Generation
+	status.LastError = ""
+	status.LastRequestId = ""
+
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionSynced, metav1.ConditionTrue, v1.AlbConfigReasonReconciled, "")
+	if len(isolatedIngresses) != 0 {
+		setAlbConfigCondition(albconfig, v1.AlbConfigConditionDegraded, metav1.ConditionTrue, v1.AlbConfigReasonIngressIsolated,
+			fmt.Sprintf("ingresses failed to build and are isolated: %s", strings.Join(isolatedIngresses, ",")))
+	} else {
+		setAlbConfigCondition(albconfig, v1.AlbConfigConditionDegraded, metav1.ConditionFalse, v1.AlbConfigReasonNotDegraded, "")
+	}
+	if lbStatus == nil {
+		setAlbConfigCondition(albconfig, v1.AlbConfigConditionLoadBalancerProvisioned, metav1.ConditionFalse,
+			v1.AlbConfigReasonProvisioning, "load balancer has no dns name yet")
+		setAlbConfigCondition(albconfig, v1.AlbConfigConditionListenersReady, metav1.ConditionFalse,
+			v1.AlbConfigReasonProvisioning, "waiting for the load balancer")
+		setAlbConfigCondition(albconfig, v1.AlbConfigConditionReady, metav1.ConditionFalse,
+			v1.AlbConfigReasonProvisioning, "load balancer has no dns name yet")
+		return
+	}
+
+	status.LoadBalancer = *lbStatus
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionLoadBalancerProvisioned, metav1.ConditionTrue,
+		v1.AlbConfigReasonProvisioned, fmt.Sprintf("load balancer %s is provisioned", lbStatus.Id))
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionListenersReady, metav1.ConditionTrue,
+		v1.AlbConfigReasonListenersApplied, fmt.Sprintf("%d listeners are applied", len(albconfig.Spec.Listeners)))
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionReady, metav1.ConditionTrue, v1.AlbConfigReasonReconciled, "")
+}
+
+// setAlbConfigFailedStatus sets the status of a failed reconcile on the albconfig, without updating it.
+// The albconfig is degraded if a load balancer was provisioned by a previous reconcile, its listeners
+// are no longer ready once the model of the current spec failed to build or apply.
+func setAlbConfigFailedStatus(albconfig *v1.AlbConfig, err error) {
+	reason := syncErrorReason(err)
+	message := helper.GetLogMessage(err)
+
+	status := &albconfig.Status
+	status.ObservedGeneration = albconfig.Generation
+	status.LastError = message
+	status.LastRequestId = helper.GetRequestId(err)
+
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionSynced, metav1.ConditionFalse, reason, message)
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionReady, metav1.ConditionFalse, reason, message)
+	if reason == v1.AlbConfigReasonQuotaExceeded {
+		setAlbConfigCondition(albconfig, v1.AlbConfigConditionWithinQuota, metav1.ConditionFalse, reason, message)
+	}
+	if status.LoadBalancer.Id != "" {
+		setAlbConfigCondition(albconfig, v1.AlbConfigConditionDegraded, metav1.ConditionTrue, reason,
+			fmt.Sprintf("load balancer %s keeps the last applied configuration: %s", status.LoadBalancer.Id, message))
+		if reason == v1.AlbConfigReasonBuildModelFailed || reason == v1.AlbConfigReasonApplyModelFailed {
+			setAlbConfigCondition(albconfig, v1.AlbConfigConditionListenersReady, metav1.ConditionFalse, reason, message)
+		}
+		return
+	}
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionDegraded, metav1.ConditionFalse, v1.AlbConfigReasonNotDegraded, "")
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionLoadBalancerProvisioned, metav1.ConditionFalse, reason, message)
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionListenersReady, metav1.ConditionFalse, reason, message)
+}
+
+// setAlbConfigRuleConflictCondition reports the listener rules which never match any request.
+func setAlbConfigRuleConflictCondition(albconfig *v1.AlbConfig, conflicts []albconfigmanager.RuleConflict) {
+	if len(conflicts) == 0 {
+		setAlbConfigCondition(albconfig, v1.AlbConfigConditionRulesReachable, metav1.ConditionTrue, v1.AlbConfigReasonNoRuleConflict, "")
+		return
+	}
+	messages := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		messages = append(messages, c.String())
+	}
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionRulesReachable, metav1.ConditionFalse, v1.AlbConfigReasonRuleConflict,
+		strings.Join(messages, "; "))
+}
+
+// setAlbConfigQuotaCondition reports that the applied model is within the quotas, shards is the number of ALB
+// instances serving the group when it is sharded.
+func setAlbConfigQuotaCondition(albconfig *v1.AlbConfig, shards int) {
+	if shards > 1 {
+		setAlbConfigCondition(albconfig, v1.AlbConfigConditionWithinQuota, metav1.ConditionTrue, v1.AlbConfigReasonSharded,
+			fmt.Sprintf("the ingress group is sharded across %d load balancers", shards))
+		return
+	}
+	setAlbConfigCondition(albconfig, v1.AlbConfigConditionWithinQuota, metav1.ConditionTrue, v1.AlbConfigReasonWithinQuota, "")
+}
+
+func setAlbConfigCondition(albconfig *v1.AlbConfig, condType v1.AlbConfigConditionType, status metav1.ConditionStatus,
+	reason v1.AlbConfigConditionReason, message string) {
+	meta.SetStatusCondition(&albconfig.Status.Conditions, metav1.Condition{
+		Type:               string(condType),
+		Status:             status,
+		Reason:             string(reason),
+		Message:            message,
+		ObservedGeneration: albconfig.Generation,
+	})
+}
+
+// updateAlbConfigFailedStatus reports the error of a failed reconcile on the albconfig.
+// It never overrides the error of the reconcile, the failure of the status update is only logged.
+func (g *albconfigReconciler) updateAlbConfigFailedStatus(ctx context.Context, albconfig *v1.AlbConfig, err error) {
+	updated := albconfig.DeepCopy()
+	setAlbConfigFailedStatus(updated, err)
+	if equality.Semantic.DeepEqual(albconfig.Status, updated.Status) {
+		return
+	}
+	if uerr := g.k8sClient.Status().Patch(ctx, updated, client.MergeFrom(albconfig)); uerr != nil {
+		g.logger.Error(uerr, "failed to update albconfig status", "albconfig", util.Key(albconfig))
+	}
+}
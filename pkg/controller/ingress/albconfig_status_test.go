@@ -0,0 +1,152 @@
+package ingress
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncErrorReason(t *testing.T) {
+	assert.Equal(t, v1.AlbConfigReasonSyncFailed, syncErrorReason(fmt.Errorf("unknown")))
+	err := newAlbConfigSyncError(v1.AlbConfigReasonApplyModelFailed, fmt.Errorf("quota exceeded"))
+	assert.Equal(t, v1.AlbConfigReasonApplyModelFailed, syncErrorReason(err))
+	assert.Equal(t, "quota exceeded", err.Error())
+	assert.Nil(t, newAlbConfigSyncError(v1.AlbConfigReasonApplyModelFailed, nil))
+}
+
+func assertAlbConfigCondition(t *testing.T, albconfig *v1.AlbConfig, condType v1.AlbConfigConditionType,
+	status metav1.ConditionStatus, reason v1.AlbConfigConditionReason) {
+	cond := meta.FindStatusCondition(albconfig.Status.Conditions, string(condType))
+	if assert.NotNil(t, cond, "condition %s", condType) {
+		assert.Equal(t, status, cond.Status, "condition %s", condType)
+		assert.Equal(t, string(reason), cond.Reason, "condition %s", condType)
+		assert.Equal(t, albconfig.Generation, cond.ObservedGeneration)
+	}
+}
+
+func TestSetAlbConfigSyncedStatus(t *testing.T) {
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	albconfig.Status.LastError = "old error"
+
+	setAlbConfigSyncedStatus(albconfig, nil, nil)
+	assert.Equal(t, int64(2), albconfig.Status.ObservedGeneration)
+	assert.Equal(t, "", albconfig.Status.LastError)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionSynced, metav1.ConditionTrue, v1.AlbConfigReasonReconciled)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionLoadBalancerProvisioned, metav1.ConditionFalse, v1.AlbConfigReasonProvisioning)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionListenersReady, metav1.ConditionFalse, v1.AlbConfigReasonProvisioning)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionReady, metav1.ConditionFalse, v1.AlbConfigReasonProvisioning)
+
+	setAlbConfigSyncedStatus(albconfig, &v1.LoadBalancerStatus{Id: "alb-1", DNSName: "alb-1.example.com"}, nil)
+	assert.Equal(t, "alb-1", albconfig.Status.LoadBalancer.Id)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionLoadBalancerProvisioned, metav1.ConditionTrue, v1.AlbConfigReasonProvisioned)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionListenersReady, metav1.ConditionTrue, v1.AlbConfigReasonListenersApplied)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionReady, metav1.ConditionTrue, v1.AlbConfigReasonReconciled)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionDegraded, metav1.ConditionFalse, v1.AlbConfigReasonNotDegraded)
+
+	setAlbConfigSyncedStatus(albconfig, &v1.LoadBalancerStatus{Id: "alb-1", DNSName: "alb-1.example.com"}, []string{"default/bad"})
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionSynced, metav1.ConditionTrue, v1.AlbConfigReasonReconciled)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionDegraded, metav1.ConditionTrue, v1.AlbConfigReasonIngressIsolated)
+}
+
+func TestSetAlbConfigFailedStatus(t *testing.T) {
+	cases := []struct {
+		name            string
+		lbID            string
+		reason          v1.AlbConfigConditionReason
+		degraded        metav1.ConditionStatus
+		provisioned     metav1.ConditionStatus
+		listenersReady  metav1.ConditionStatus
+		listenersReason v1.AlbConfigConditionReason
+	}{
+		{
+			name:            "build failed without load balancer",
+			reason:          v1.AlbConfigReasonBuildModelFailed,
+			degraded:        metav1.ConditionFalse,
+			provisioned:     metav1.ConditionFalse,
+			listenersReady:  metav1.ConditionFalse,
+			listenersReason: v1.AlbConfigReasonBuildModelFailed,
+		},
+		{
+			name:            "build failed with load balancer",
+			lbID:            "alb-1",
+			reason:          v1.AlbConfigReasonBuildModelFailed,
+			degraded:        metav1.ConditionTrue,
+			provisioned:     metav1.ConditionTrue,
+			listenersReady:  metav1.ConditionFalse,
+			listenersReason: v1.AlbConfigReasonBuildModelFailed,
+		},
+		{
+			name:            "apply failed with load balancer",
+			lbID:            "alb-1",
+			reason:          v1.AlbConfigReasonApplyModelFailed,
+			degraded:        metav1.ConditionTrue,
+			provisioned:     metav1.ConditionTrue,
+			listenersReady:  metav1.ConditionFalse,
+			listenersReason: v1.AlbConfigReasonApplyModelFailed,
+		},
+		{
+			name:            "finalizer failed with load balancer",
+			lbID:            "alb-1",
+			reason:          v1.AlbConfigReasonUpdateFinalizerFailed,
+			degraded:        metav1.ConditionTrue,
+			provisioned:     metav1.ConditionTrue,
+			listenersReady:  metav1.ConditionTrue,
+			listenersReason: v1.AlbConfigReasonListenersApplied,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+			if c.lbID != "" {
+				setAlbConfigSyncedStatus(albconfig, &v1.LoadBalancerStatus{Id: c.lbID}, nil)
+			}
+
+			err := newAlbConfigSyncError(c.reason, fmt.Errorf("RequestId: req-1\nMessage: failed"))
+			setAlbConfigFailedStatus(albconfig, err)
+			assert.Equal(t, int64(3), albconfig.Status.ObservedGeneration)
+			assert.Equal(t, "Message: failed", albconfig.Status.LastError)
+			assert.Equal(t, "req-1", albconfig.Status.LastRequestId)
+			assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionSynced, metav1.ConditionFalse, c.reason)
+			assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionReady, metav1.ConditionFalse, c.reason)
+			cond := meta.FindStatusCondition(albconfig.Status.Conditions, string(v1.AlbConfigConditionDegraded))
+			assert.Equal(t, c.degraded, cond.Status)
+			cond = meta.FindStatusCondition(albconfig.Status.Conditions, string(v1.AlbConfigConditionLoadBalancerProvisioned))
+			assert.Equal(t, c.provisioned, cond.Status)
+			assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionListenersReady, c.listenersReady, c.listenersReason)
+		})
+	}
+}
+
+func TestSetAlbConfigRuleConflictCondition(t *testing.T) {
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	setAlbConfigRuleConflictCondition(albconfig, nil)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionRulesReachable, metav1.ConditionTrue, v1.AlbConfigReasonNoRuleConflict)
+
+	rule := &albmodel.ListenerRule{Spec: albmodel.ListenerRuleSpec{Ingresses: []string{"default/a"}}}
+	shadowed := &albmodel.ListenerRule{Spec: albmodel.ListenerRuleSpec{Ingresses: []string{"default/b"}}}
+	conflicts := []albconfigmanager.RuleConflict{{Type: albconfigmanager.RuleConflictShadowed, Listener: "80", Rule: shadowed, ShadowedBy: rule}}
+	setAlbConfigRuleConflictCondition(albconfig, conflicts)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionRulesReachable, metav1.ConditionFalse, v1.AlbConfigReasonRuleConflict)
+
+	byIngress := ruleConflictsByIngress(conflicts)
+	assert.Equal(t, 1, len(byIngress["default/a"]))
+	assert.Equal(t, 1, len(byIngress["default/b"]))
+}
+
+func TestSetAlbConfigQuotaCondition(t *testing.T) {
+	albconfig := &v1.AlbConfig{}
+	setAlbConfigFailedStatus(albconfig, newAlbConfigSyncError(v1.AlbConfigReasonQuotaExceeded, fmt.Errorf("quota exceeded")))
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionWithinQuota, metav1.ConditionFalse, v1.AlbConfigReasonQuotaExceeded)
+
+	setAlbConfigQuotaCondition(albconfig, 0)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionWithinQuota, metav1.ConditionTrue, v1.AlbConfigReasonWithinQuota)
+
+	setAlbConfigQuotaCondition(albconfig, 3)
+	assertAlbConfigCondition(t, albconfig, v1.AlbConfigConditionWithinQuota, metav1.ConditionTrue, v1.AlbConfigReasonSharded)
+}
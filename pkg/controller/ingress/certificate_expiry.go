@@ -0,0 +1,127 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const albConfigKind = "AlbConfig"
+
+// listenerCertificateIDs returns the certificates bound to the HTTPS and QUIC listeners of the stacks by the
+// port/protocol of the listeners.
+func listenerCertificateIDs(ctx context.Context, stacks []core.Manager) map[string][]string {
+	certIDsByListener := make(map[string]sets.String)
+	for _, stack := range stacks {
+		var lss []*albmodel.Listener
+		_ = stack.ListResources(&lss)
+		for _, ls := range lss {
+			if ls.Spec.ListenerProtocol != util.ListenerProtocolHTTPS && ls.Spec.ListenerProtocol != util.ListenerProtocolQUIC {
+				continue
+			}
+			listener := fmt.Sprintf("%d/%s", ls.Spec.ListenerPort, ls.Spec.ListenerProtocol)
+			for _, cert := range ls.Spec.Certificates {
+				certID, err := cert.GetCertificateId(ctx)
+				if err != nil || certID == "" {
+					continue
+				}
+				if _, ok := certIDsByListener[listener]; !ok {
+					certIDsByListener[listener] = sets.NewString()
+				}
+				certIDsByListener[listener].Insert(certID)
+			}
+		}
+	}
+	certIDs := make(map[string][]string, len(certIDsByListener))
+	for listener, ids := range certIDsByListener {
+		certIDs[listener] = ids.List()
+	}
+	return certIDs
+}
+
+// checkTLSHosts returns the hosts in the TLS sections of the ingress which no certificate covers, and the hosts
+// covered only by the expiring certificates.
+func checkTLSHosts(ing *networking.Ingress, certs []helper.ListenerCertificate, expiringCertIDs sets.String) ([]string, []string) {
+	uncovered, expiring := sets.NewString(), sets.NewString()
+	for _, tls := range ing.Spec.TLS {
+		for _, host := range tls.Hosts {
+			covered, valid := false, false
+			for _, cert := range certs {
+				if !albconfigmanager.CertificateMatchesHost(cert.Certificate, host) {
+					continue
+				}
+				covered = true
+				if !expiringCertIDs.Has(cert.Certificate.CertIdentifier) {
+					valid = true
+					break
+				}
+			}
+			switch {
+			case !covered:
+				uncovered.Insert(host)
+			case !valid:
+				expiring.Insert(host)
+			}
+		}
+	}
+	return uncovered.List(), expiring.List()
+}
+
+func hasTLSHosts(ings []*networking.Ingress) bool {
+	for _, ing := range ings {
+		for _, tls := range ing.Spec.TLS {
+			if len(tls.Hosts) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkCertificateExpiry exports the expiry time of the certificates bound to the listeners of the albconfig, and
+// records Warning events on the albconfig and the ingresses for the certificates expiring within the window, and for
+// the TLS hosts of the ingresses which no certificate covers.
+func (g *albconfigReconciler) checkCertificateExpiry(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group, stacks []core.Manager) {
+	certIDs := listenerCertificateIDs(ctx, stacks)
+	if len(certIDs) == 0 && !hasTLSHosts(ingGroup.Members) {
+		helper.ForgetCertificateExpiry(albConfigKind, albconfig.Namespace, albconfig.Name)
+		return
+	}
+	sdkCerts, err := g.cloud.DescribeSSLCertificateList(ctx)
+	if err != nil {
+		g.logger.Error(err, "list certificates to check expiry", "albconfig", albconfig.Name)
+		return
+	}
+	window := ctrlCfg.ControllerCFG.CertificateExpiryWarningWindow
+	certs := helper.BuildListenerCertificates(certIDs, sdkCerts)
+	expiring := helper.RecordCertificateExpiry(albConfigKind, albconfig.Namespace, albconfig.Name, certs, window, time.Now())
+	expiringCertIDs := sets.NewString()
+	for _, cert := range expiring {
+		expiringCertIDs.Insert(cert.Certificate.CertIdentifier)
+		g.eventRecorder.Event(albconfig, corev1.EventTypeWarning, helper.IngressEventReasonCertificateExpiring, cert.ExpiryMessage())
+	}
+
+	for _, ing := range ingGroup.Members {
+		uncovered, expiringHosts := checkTLSHosts(ing, certs, expiringCertIDs)
+		if len(uncovered) != 0 {
+			g.eventRecorder.Eventf(ing, corev1.EventTypeWarning, helper.IngressEventReasonHostNotCovered,
+				"No certificate bound to the listeners covers the TLS hosts: %s", strings.Join(uncovered, ","))
+		}
+		if len(expiringHosts) != 0 {
+			g.eventRecorder.Eventf(ing, corev1.EventTypeWarning, helper.IngressEventReasonCertificateExpiring,
+				"The certificates of the TLS hosts expire within %s: %s", window, strings.Join(expiringHosts, ","))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+)
+
+// expiryCloud returns the certificates in CAS with their domains and expiry time
+type expiryCloud struct {
+	prvd.Provider
+	certs []model.CertificateInfo
+}
+
+func (c *expiryCloud) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	return c.certs, nil
+}
+
+func newExpiryTestStack() core.Manager {
+	stack := core.NewDefaultManager(core.StackID{Name: "alb"})
+	newListener := func(port int, protocol string, certIDs ...string) {
+		var certs []albmodel.Certificate
+		for _, id := range certIDs {
+			certs = append(certs, &albmodel.FixedCertificate{CertificateId: id})
+		}
+		albmodel.NewListener(stack, protocol, albmodel.ListenerSpec{
+			LoadBalancerID:  core.LiteralStringToken("alb-1"),
+			ALBListenerSpec: albmodel.ALBListenerSpec{ListenerPort: port, ListenerProtocol: protocol, Certificates: certs},
+		})
+	}
+	newListener(80, "HTTP")
+	newListener(443, "HTTPS", "cert-www", "cert-wildcard")
+	newListener(1443, "QUIC", "cert-www")
+	return stack
+}
+
+func TestListenerCertificateIDs(t *testing.T) {
+	assert.Empty(t, listenerCertificateIDs(context.TODO(), nil))
+	assert.Equal(t, map[string][]string{
+		"443/HTTPS": {"cert-wildcard", "cert-www"},
+		"1443/QUIC": {"cert-www"},
+	}, listenerCertificateIDs(context.TODO(), []core.Manager{newExpiryTestStack()}))
+}
+
+func TestCheckTLSHosts(t *testing.T) {
+	certs := []helper.ListenerCertificate{
+		{Listener: "443/HTTPS", Certificate: model.CertificateInfo{CertIdentifier: "cert-www", CommonName: "www.example.com"}},
+		{Listener: "443/HTTPS", Certificate: model.CertificateInfo{CertIdentifier: "cert-wildcard", Sans: "*.example.com"}},
+	}
+	ing := &networking.Ingress{Spec: networking.IngressSpec{TLS: []networking.IngressTLS{
+		{Hosts: []string{"www.example.com", "api.example.com"}},
+		{Hosts: []string{"www.example.org"}},
+	}}}
+
+	uncovered, expiring := checkTLSHosts(ing, certs, sets.NewString())
+	assert.Equal(t, []string{"www.example.org"}, uncovered)
+	assert.Empty(t, expiring)
+
+	// the host covered by another valid certificate is not expiring
+	uncovered, expiring = checkTLSHosts(ing, certs, sets.NewString("cert-wildcard"))
+	assert.Equal(t, []string{"www.example.org"}, uncovered)
+	assert.Equal(t, []string{"api.example.com"}, expiring)
+}
+
+func TestCheckCertificateExpiry(t *testing.T) {
+	defer func(window time.Duration) { ctrlCfg.ControllerCFG.CertificateExpiryWarningWindow = window }(ctrlCfg.ControllerCFG.CertificateExpiryWarningWindow)
+	ctrlCfg.ControllerCFG.CertificateExpiryWarningWindow = 7 * 24 * time.Hour
+
+	recorder := record.NewFakeRecorder(10)
+	g := &albconfigReconciler{
+		cloud: &expiryCloud{certs: []model.CertificateInfo{
+			{CertIdentifier: "cert-www", CommonName: "www.example.com", AfterDate: time.Now().Add(24 * time.Hour).UnixMilli()},
+			{CertIdentifier: "cert-wildcard", Sans: "*.example.com", AfterDate: time.Now().Add(90 * 24 * time.Hour).UnixMilli()},
+		}},
+		eventRecorder: recorder,
+		logger:        logr.Discard(),
+	}
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "alb"}}
+	ing := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"},
+		Spec: networking.IngressSpec{TLS: []networking.IngressTLS{
+			{Hosts: []string{"www.example.com", "www.example.org"}},
+		}},
+	}
+	defer helper.ForgetCertificateExpiry(albConfigKind, "", "alb")
+
+	g.checkCertificateExpiry(context.TODO(), albconfig, &albconfigmanager.Group{Members: []*networking.Ingress{ing}},
+		[]core.Manager{newExpiryTestStack()})
+	close(recorder.Events)
+	var events []string
+	for event := range recorder.Events {
+		events = append(events, event)
+	}
+	// the certificate bound to both listeners is reported for each of them
+	if assert.Len(t, events, 3) {
+		assert.Contains(t, events[0], "Warning CertificateExpiring certificate cert-www")
+		assert.Contains(t, events[0], "1443/QUIC")
+		assert.Contains(t, events[1], "443/HTTPS")
+		assert.Equal(t, "Warning CertificateHostNotCovered No certificate bound to the listeners covers the TLS hosts: www.example.org", events[2])
+	}
+}
@@ -1,11 +1,15 @@
 package ingress
 
 import (
+	"context"
+
 	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
 	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
 	"k8s.io/alibaba-load-balancer-controller/pkg/util"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -81,3 +85,43 @@ func (h *enqueueRequestsForAlbconfigEvent) enqueueAlbconfig(queue workqueue.Rate
 		NamespacedName: util.NamespacedName(albconfig),
 	})
 }
+
+// albConfigsForServiceReferenceGrant maps a ServiceReferenceGrant to all the AlbConfigs, the Ingresses of any group
+// may reference the Services of its namespace.
+func (g *albconfigReconciler) albConfigsForServiceReferenceGrant(obj client.Object) []reconcile.Request {
+	albconfigList := &v1.AlbConfigList{}
+	if err := g.k8sClient.List(context.Background(), albconfigList); err != nil {
+		g.logger.Error(err, "list albconfigs", "servicereferencegrant", util.Key(obj))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(albconfigList.Items))
+	for i := range albconfigList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: util.NamespacedName(&albconfigList.Items[i])})
+	}
+	return requests
+}
+
+// albConfigsForSecret maps a Secret to the AlbConfigs whose listeners use it as the CA certificate, so that a
+// rotated CA bundle is uploaded and bound to the listeners.
+func (g *albconfigReconciler) albConfigsForSecret(obj client.Object) []reconcile.Request {
+	albconfigList := &v1.AlbConfigList{}
+	if err := g.k8sClient.List(context.Background(), albconfigList); err != nil {
+		g.logger.Error(err, "list albconfigs", "secret", util.Key(obj))
+		return nil
+	}
+	secret := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	var requests []reconcile.Request
+	for i := range albconfigList.Items {
+		albconfig := &albconfigList.Items[i]
+		for _, ls := range albconfig.Spec.Listeners {
+			if ls == nil || len(ls.CaCertificateSecret) == 0 {
+				continue
+			}
+			if key, err := albconfigmanager.CaCertificateSecretKey(albconfig, ls.CaCertificateSecret); err == nil && key == secret {
+				requests = append(requests, reconcile.Request{NamespacedName: util.NamespacedName(albconfig)})
+				break
+			}
+		}
+	}
+	return requests
+}
@@ -6,8 +6,10 @@ import (
 
 	"golang.org/x/time/rate"
 	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"k8s.io/klog/v2"
@@ -64,6 +66,14 @@ func Add(mgr manager.Manager, ctx *shared.SharedContext) error {
 	if err := c.Watch(&source.Kind{Type: &v1.AlbConfig{}}, acEventHandler); err != nil {
 		return err
 	}
+	if err := c.Watch(&source.Kind{Type: &v1.ServiceReferenceGrant{}},
+		handler.EnqueueRequestsFromMapFunc(r.albConfigsForServiceReferenceGrant)); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &corev1.Secret{}},
+		handler.EnqueueRequestsFromMapFunc(r.albConfigsForSecret)); err != nil {
+		return err
+	}
 
 	klog.Infof("Add start")
 	return mgr.Add(&ingressController{c: c, recon: r})
@@ -0,0 +1,523 @@
+package ingress
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ingressSyncStatus is the sync status of an ingress in its AlbConfig group,
+// stored as json in the helper.AnnotationIngressSyncStatus annotation.
+type ingressSyncStatus struct {
+	AlbConfig string `json:"albConfig"`
+	Synced    bool   `json:"synced"`
+	// Isolated is true if the current version failed to build and was skipped by the group
+	Isolated bool `json:"isolated,omitempty"`
+	// ServedGeneration is the generation of the ingress which the rules are built from
+	ServedGeneration int64               `json:"servedGeneration,omitempty"`
+	Error            string              `json:"error,omitempty"`
+	RequestId        string              `json:"requestId,omitempty"`
+	Rules            []ingressRuleStatus `json:"rules,omitempty"`
+	// Conflicts are the duplicated or shadowed listener rules and the ignored default backends involving the ingress
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+type ingressRuleStatus struct {
+	Listener string `json:"listener"`
+	RuleId   string `json:"ruleId,omitempty"`
+	RuleName string `json:"ruleName,omitempty"`
+	Priority int    `json:"priority"`
+}
+
+// ingressSyncedVersion identifies the last version of an ingress which is successfully applied, stored as json in
+// the helper.AnnotationIngressLastSynced annotation. The version itself is kept in a ConfigMap, see ingressLastSynced.
+type ingressSyncedVersion struct {
+	Hash       string `json:"hash"`
+	Generation int64  `json:"generation"`
+}
+
+// ingressLastSynced is the last version of an ingress which is successfully applied, stored as json in a ConfigMap
+// owned by the ingress. It is used in place of the ingress when the current version fails to build, so that the rules
+// of the ingress are kept across restarts of the controller.
+type ingressLastSynced struct {
+	Hash        string                 `json:"hash"`
+	Generation  int64                  `json:"generation"`
+	Annotations map[string]string      `json:"annotations,omitempty"`
+	Spec        networking.IngressSpec `json:"spec"`
+}
+
+const (
+	// lastSyncedConfigMapPrefix prefixes the name of the ConfigMap keeping the last synced version of an ingress
+	lastSyncedConfigMapPrefix = "alb-last-synced-"
+	// lastSyncedConfigMapKey is the key of the last synced version in the ConfigMap
+	lastSyncedConfigMapKey = "ingress.json"
+	// maxLastSyncedSize leaves room for the metadata in the 1MiB limit of a ConfigMap
+	maxLastSyncedSize = 1000 * 1024
+)
+
+func newIngressLastSynced(ing *networking.Ingress) *ingressLastSynced {
+	annotations := make(map[string]string)
+	for k, v := range helper.GetIngressUserAnnotations(ing) {
+		annotations[k] = v
+	}
+	return &ingressLastSynced{
+		Hash:        helper.GetIngressHash(ing),
+		Generation:  ing.Generation,
+		Annotations: annotations,
+		Spec:        *ing.Spec.DeepCopy(),
+	}
+}
+
+// getIngressSyncedVersion returns the last synced version of the ingress, nil if there is none
+func getIngressSyncedVersion(ing *networking.Ingress) *ingressSyncedVersion {
+	value, ok := ing.Annotations[helper.AnnotationIngressLastSynced]
+	if !ok {
+		return nil
+	}
+	version := &ingressSyncedVersion{}
+	if err := json.Unmarshal([]byte(value), version); err != nil {
+		return nil
+	}
+	return version
+}
+
+// lastSyncedConfigMapName returns the name of the ConfigMap keeping the last synced version of the ingress
+func lastSyncedConfigMapName(ing *networking.Ingress) string {
+	name := lastSyncedConfigMapPrefix + ing.Name
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		sum := sha256.Sum256([]byte(ing.Name))
+		name = lastSyncedConfigMapPrefix + hex.EncodeToString(sum[:])
+	}
+	return name
+}
+
+// isLastSyncedConfigMapOf returns true if the ConfigMap is owned by the ingress, a ConfigMap of the same name
+// created by the user is never read nor overwritten
+func isLastSyncedConfigMapOf(cm *corev1.ConfigMap, ing *networking.Ingress) bool {
+	for _, ref := range cm.OwnerReferences {
+		if ref.UID == ing.UID && ref.Kind == "Ingress" {
+			return true
+		}
+	}
+	return false
+}
+
+// getIngressLastSynced reads the last synced version of the ingress from its ConfigMap, nil if there is none or
+// if it is not the version recorded in the annotation
+func (g *albconfigReconciler) getIngressLastSynced(ctx context.Context, ing *networking.Ingress) *ingressLastSynced {
+	version := getIngressSyncedVersion(ing)
+	if version == nil {
+		return nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := g.apiReader.Get(ctx, types.NamespacedName{Namespace: ing.Namespace, Name: lastSyncedConfigMapName(ing)}, cm); err != nil {
+		if !errors.IsNotFound(err) {
+			g.logger.Error(err, "failed to get last synced version", "ingress", util.Key(ing))
+		}
+		return nil
+	}
+	if !isLastSyncedConfigMapOf(cm, ing) {
+		return nil
+	}
+	last := &ingressLastSynced{}
+	if err := json.Unmarshal([]byte(cm.Data[lastSyncedConfigMapKey]), last); err != nil {
+		return nil
+	}
+	if last.Hash != version.Hash {
+		return nil
+	}
+	return last
+}
+
+// saveIngressLastSynced writes the last synced version of the ingress into its ConfigMap
+func (g *albconfigReconciler) saveIngressLastSynced(ctx context.Context, ing *networking.Ingress, lastSynced *ingressLastSynced) error {
+	value, err := json.Marshal(lastSynced)
+	if err != nil {
+		return err
+	}
+	if len(value) > maxLastSyncedSize {
+		return fmt.Errorf("the ingress is too large to keep its last synced version, %d bytes", len(value))
+	}
+
+	key := types.NamespacedName{Namespace: ing.Namespace, Name: lastSyncedConfigMapName(ing)}
+	cm := &corev1.ConfigMap{}
+	err = g.apiReader.Get(ctx, key, cm)
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: key.Namespace,
+				Name:      key.Name,
+				// deleted with the ingress by the garbage collector
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: networking.SchemeGroupVersion.String(),
+					Kind:       "Ingress",
+					Name:       ing.Name,
+					UID:        ing.UID,
+				}},
+			},
+			Data: map[string]string{lastSyncedConfigMapKey: string(value)},
+		}
+		return g.k8sClient.Create(ctx, cm)
+	}
+	if err != nil {
+		return err
+	}
+	if !isLastSyncedConfigMapOf(cm, ing) {
+		return fmt.Errorf("configmap %s is not owned by the ingress", key)
+	}
+	if cm.Data[lastSyncedConfigMapKey] == string(value) {
+		return nil
+	}
+	cm.Data = map[string]string{lastSyncedConfigMapKey: string(value)}
+	return g.k8sClient.Update(ctx, cm)
+}
+
+// toIngress restores the last synced version on a copy of the current ingress
+func (l *ingressLastSynced) toIngress(ing *networking.Ingress) *networking.Ingress {
+	restored := ing.DeepCopy()
+	restored.Generation = l.Generation
+	restored.Spec = *l.Spec.DeepCopy()
+	restored.Annotations = make(map[string]string, len(l.Annotations))
+	for k, v := range l.Annotations {
+		restored.Annotations[k] = v
+	}
+	for k, v := range ing.Annotations {
+		if helper.IsIngressSyncAnnotation(k) {
+			restored.Annotations[k] = v
+		}
+	}
+	return restored
+}
+
+// buildWithIsolation builds the stack of the group. When the build fails because of a member, the member is
+// isolated and replaced by its last synced version, then the group is built again.
+// The group fails if the error can't be isolated.
+func (g *albconfigReconciler) buildWithIsolation(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group) (
+	core.Manager, *albmodel.AlbLoadBalancer, map[*networking.Ingress]error, error) {
+	for {
+		stack, lb, errResWithIngress, err := g.albconfigBuilder.Build(ctx, albconfig, ingGroup)
+		if err == nil || !albconfig.DeletionTimestamp.IsZero() {
+			return stack, lb, errResWithIngress, err
+		}
+		if !isolateMembers(ingGroup, errResWithIngress, func(ing *networking.Ingress) *ingressLastSynced {
+			return g.getIngressLastSynced(ctx, ing)
+		}) {
+			return stack, lb, errResWithIngress, err
+		}
+		g.logger.Info("rebuild albconfig stack with isolated ingresses",
+			"albconfig", util.Key(albconfig),
+			"isolated", isolatedMemberKeys(ingGroup),
+			"error", err.Error())
+	}
+}
+
+// isolateMembers replaces the members which failed to build with their last synced version, loaded by lastSynced.
+// It returns false if the error can't be isolated:
+//   - the member has no last synced version, dropping it would delete its rules which are still served,
+//   - the current version of the member is the last synced one, so the error is not caused by the ingress itself,
+//   - the last synced version of the member fails as well.
+func isolateMembers(ingGroup *albconfigmanager.Group, errResWithIngress map[*networking.Ingress]error,
+	lastSynced func(*networking.Ingress) *ingressLastSynced) bool {
+	if len(errResWithIngress) == 0 {
+		return false
+	}
+	isolated := make(map[string]*albconfigmanager.IsolatedMember)
+	for errIng, buildErr := range errResWithIngress {
+		key := util.Key(errIng)
+		if _, ok := ingGroup.IsolatedMembers[key]; ok {
+			return false
+		}
+		var member *networking.Ingress
+		for _, m := range ingGroup.Members {
+			if util.Key(m) == key {
+				member = m
+				break
+			}
+		}
+		if member == nil {
+			return false
+		}
+		version := getIngressSyncedVersion(member)
+		if version == nil || version.Hash == helper.GetIngressHash(member) {
+			return false
+		}
+		last := lastSynced(member)
+		if last == nil {
+			return false
+		}
+		isolated[key] = &albconfigmanager.IsolatedMember{
+			Ingress:    member,
+			Err:        buildErr,
+			LastSynced: last.toIngress(member),
+		}
+	}
+
+	if ingGroup.IsolatedMembers == nil {
+		ingGroup.IsolatedMembers = make(map[string]*albconfigmanager.IsolatedMember)
+	}
+	for i, member := range ingGroup.Members {
+		if im, ok := isolated[util.Key(member)]; ok {
+			ingGroup.Members[i] = im.LastSynced
+		}
+	}
+	for key, im := range isolated {
+		ingGroup.IsolatedMembers[key] = im
+	}
+	return true
+}
+
+func isolatedMemberKeys(ingGroup *albconfigmanager.Group) []string {
+	keys := make([]string, 0, len(ingGroup.IsolatedMembers))
+	for key := range ingGroup.IsolatedMembers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isIsolatedMember(ingGroup *albconfigmanager.Group, ing *networking.Ingress) bool {
+	_, ok := ingGroup.IsolatedMembers[util.Key(ing)]
+	return ok
+}
+
+// syncedMembers returns the members applied with their current version
+func syncedMembers(ingGroup *albconfigmanager.Group) []*networking.Ingress {
+	members := make([]*networking.Ingress, 0, len(ingGroup.Members))
+	for _, member := range ingGroup.Members {
+		if !isIsolatedMember(ingGroup, member) {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// buildIngressRuleStatuses maps the applied listener rules in the stacks to the ingresses they are built from,
+// a sharded group has a stack for each shard.
+func buildIngressRuleStatuses(ctx context.Context, stacks ...core.Manager) map[string][]ingressRuleStatus {
+	rulesByIngress := make(map[string][]ingressRuleStatus)
+	for _, stack := range stacks {
+		if stack == nil {
+			continue
+		}
+		var resLSs []*albmodel.Listener
+		_ = stack.ListResources(&resLSs)
+		listeners := make(map[string]string)
+		for _, ls := range resLSs {
+			if ls.Status == nil {
+				continue
+			}
+			listeners[ls.Status.ListenerID] = fmt.Sprintf("%d/%s", ls.Spec.ListenerPort, ls.Spec.ListenerProtocol)
+		}
+
+		var resLRs []*albmodel.ListenerRule
+		_ = stack.ListResources(&resLRs)
+		for _, lr := range resLRs {
+			lsID, err := lr.Spec.ListenerID.Resolve(ctx)
+			if err != nil {
+				continue
+			}
+			status := ingressRuleStatus{
+				Listener: listeners[lsID],
+				RuleName: lr.Spec.RuleName,
+				Priority: lr.Spec.Priority,
+			}
+			if lr.Status != nil {
+				status.RuleId = lr.Status.RuleID
+			}
+			for _, key := range lr.Spec.Ingresses {
+				rulesByIngress[key] = append(rulesByIngress[key], status)
+			}
+		}
+	}
+	for _, rules := range rulesByIngress {
+		sort.SliceStable(rules, func(i, j int) bool {
+			if rules[i].Listener != rules[j].Listener {
+				return rules[i].Listener < rules[j].Listener
+			}
+			return rules[i].Priority < rules[j].Priority
+		})
+	}
+	return rulesByIngress
+}
+
+// updateIngressSyncStatus writes the sync status of every member of the group, including the isolated ones.
+// The stacks are nil if the group failed to build, syncErr is the error of the group. The members applied with
+// their current version are recorded as the last synced version.
+func (g *albconfigReconciler) updateIngressSyncStatus(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group,
+	stacks []core.Manager, conflicts []albconfigmanager.RuleConflict, syncErr error) {
+	rulesByIngress := buildIngressRuleStatuses(ctx, stacks...)
+	conflictsByIngress := ruleConflictsByIngress(conflicts)
+	_, backendConflicts := albconfigmanager.SelectDefaultBackend(ingGroup.Members)
+	for key, messages := range defaultBackendConflictsByIngress(backendConflicts) {
+		conflictsByIngress[key] = append(conflictsByIngress[key], messages...)
+	}
+
+	for _, member := range ingGroup.Members {
+		key := util.Key(member)
+		status := ingressSyncStatus{
+			AlbConfig:        util.Key(albconfig),
+			Synced:           syncErr == nil,
+			ServedGeneration: member.Generation,
+			Rules:            rulesByIngress[key],
+			Conflicts:        conflictsByIngress[key],
+		}
+		var lastSynced *ingressLastSynced
+		if im, ok := ingGroup.IsolatedMembers[key]; ok {
+			status.Synced = false
+			status.Isolated = true
+			status.Error = helper.GetLogMessage(im.Err)
+			status.RequestId = helper.GetRequestId(im.Err)
+		} else if syncErr == nil {
+			lastSynced = newIngressLastSynced(member)
+		}
+		if syncErr != nil {
+			status.ServedGeneration = 0
+			status.Error = helper.GetLogMessage(syncErr)
+			status.RequestId = helper.GetRequestId(syncErr)
+		}
+		if err := g.patchIngressSyncStatus(ctx, key, status, lastSynced); err != nil {
+			g.logger.Error(err, "failed to update ingress sync status", "ingress", key)
+		}
+	}
+}
+
+// patchIngressSyncStatus patches the sync annotations of the ingress, the last synced version is kept if lastSynced is nil.
+// The ConfigMap of the last synced version is only written when the version changes.
+func (g *albconfigReconciler) patchIngressSyncStatus(ctx context.Context, key string, status ingressSyncStatus, lastSynced *ingressLastSynced) error {
+	rawIng, err := g.store.GetIngress(key)
+	if err != nil {
+		return err
+	}
+	if !rawIng.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	values := make(map[string]string)
+	value, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	values[helper.AnnotationIngressSyncStatus] = string(value)
+	if lastSynced != nil {
+		version, err := json.Marshal(ingressSyncedVersion{Hash: lastSynced.Hash, Generation: lastSynced.Generation})
+		if err != nil {
+			return err
+		}
+		// an annotation holding the whole version, written before the ConfigMap was used, is moved to the ConfigMap as well
+		if rawIng.Annotations[helper.AnnotationIngressLastSynced] != string(version) {
+			if err := g.saveIngressLastSynced(ctx, rawIng, lastSynced); err != nil {
+				g.eventRecorder.Event(rawIng, corev1.EventTypeWarning, helper.IngressEventReasonFailedSaveLastSynced,
+					fmt.Sprintf("Failed to keep the last synced version, the ingress can't be isolated if it fails to build: %s", err.Error()))
+			} else {
+				values[helper.AnnotationIngressLastSynced] = string(version)
+			}
+		}
+	}
+
+	updated := rawIng.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string)
+	}
+	changed := false
+	for k, v := range values {
+		if updated.Annotations[k] != v {
+			updated.Annotations[k] = v
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := g.k8sClient.Patch(ctx, updated, client.MergeFrom(rawIng)); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("%s failed to update sync status, error: %s", key, err.Error())
+	}
+	return nil
+}
+
+// ruleConflictsByIngress maps the rule conflicts to the ingresses of both rules
+func ruleConflictsByIngress(conflicts []albconfigmanager.RuleConflict) map[string][]string {
+	conflictsByIngress := make(map[string][]string)
+	for _, c := range conflicts {
+		keys := make(map[string]bool)
+		for _, key := range append(append([]string{}, c.Rule.Spec.Ingresses...), c.ShadowedBy.Spec.Ingresses...) {
+			if !keys[key] {
+				keys[key] = true
+				conflictsByIngress[key] = append(conflictsByIngress[key], c.String())
+			}
+		}
+	}
+	return conflictsByIngress
+}
+
+// recordRuleConflictEvents records the rule conflicts on the albconfig and the ingresses of both rules
+func (g *albconfigReconciler) recordRuleConflictEvents(_ context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group,
+	conflicts []albconfigmanager.RuleConflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+	conflictsByIngress := ruleConflictsByIngress(conflicts)
+	for _, c := range conflicts {
+		g.eventRecorder.Event(albconfig, corev1.EventTypeWarning, helper.IngressEventReasonRuleConflict, c.String())
+	}
+	for _, member := range ingGroup.Members {
+		for _, message := range conflictsByIngress[util.Key(member)] {
+			g.eventRecorder.Event(member, corev1.EventTypeWarning, helper.IngressEventReasonRuleConflict, message)
+		}
+	}
+}
+
+// defaultBackendConflictsByIngress maps the default backend conflicts to the ingress ignored and the ingress selected
+func defaultBackendConflictsByIngress(conflicts []albconfigmanager.DefaultBackendConflict) map[string][]string {
+	conflictsByIngress := make(map[string][]string)
+	for _, c := range conflicts {
+		for _, ing := range []*networking.Ingress{c.Ingress, c.SelectedBy} {
+			key := util.Key(ing)
+			conflictsByIngress[key] = append(conflictsByIngress[key], c.String())
+		}
+	}
+	return conflictsByIngress
+}
+
+// recordDefaultBackendConflictEvents records the ingresses whose spec.defaultBackend is ignored on the albconfig and
+// the ingresses involved
+func (g *albconfigReconciler) recordDefaultBackendConflictEvents(_ context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group) {
+	_, conflicts := albconfigmanager.SelectDefaultBackend(ingGroup.Members)
+	if len(conflicts) == 0 {
+		return
+	}
+	for _, c := range conflicts {
+		g.eventRecorder.Event(albconfig, corev1.EventTypeWarning, helper.IngressEventReasonDefaultBackendConflict, c.String())
+	}
+	conflictsByIngress := defaultBackendConflictsByIngress(conflicts)
+	for _, member := range ingGroup.Members {
+		for _, message := range conflictsByIngress[util.Key(member)] {
+			g.eventRecorder.Event(member, corev1.EventTypeWarning, helper.IngressEventReasonDefaultBackendConflict, message)
+		}
+	}
+}
+
+// recordIsolatedMemberEvents records the build error only on the isolated ingresses instead of the whole group
+func (g *albconfigReconciler) recordIsolatedMemberEvents(ctx context.Context, albconfig *v1.AlbConfig, ingGroup *albconfigmanager.Group) {
+	for _, key := range isolatedMemberKeys(ingGroup) {
+		im := ingGroup.IsolatedMembers[key]
+		message := fmt.Sprintf("Isolated from the group, the last synced version (generation %d) is still served: %s",
+			im.LastSynced.Generation, helper.GetLogMessage(im.Err))
+		g.recordIngressSingleEvent(ctx, albconfig, im.Ingress, corev1.EventTypeWarning, helper.IngressEventReasonFailedBuildModel, message)
+	}
+}
@@ -0,0 +1,329 @@
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newSyncIngress(name, rewrite string) *networking.Ingress {
+	ing := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			UID:         types.UID(name),
+			Generation:  1,
+			Annotations: map[string]string{"alb.ingress.kubernetes.io/rewrite-target": rewrite},
+		},
+		Spec: networking.IngressSpec{
+			Rules: []networking.IngressRule{{Host: name + ".example.com"}},
+		},
+	}
+	return ing
+}
+
+// withLastSynced records the ingress as synced in synced by its hash, then changes its configuration
+func withLastSynced(synced map[string]*ingressLastSynced, ing *networking.Ingress, rewrite string) *networking.Ingress {
+	last := newIngressLastSynced(ing)
+	synced[last.Hash] = last
+	value, _ := json.Marshal(ingressSyncedVersion{Hash: last.Hash, Generation: last.Generation})
+	ing.Annotations[helper.AnnotationIngressLastSynced] = string(value)
+	if rewrite != "" {
+		ing.Generation++
+		ing.Annotations["alb.ingress.kubernetes.io/rewrite-target"] = rewrite
+	}
+	return ing
+}
+
+func TestIsolateMembers(t *testing.T) {
+	synced := make(map[string]*ingressLastSynced)
+	cases := []struct {
+		name            string
+		members         []*networking.Ingress
+		alreadyIsolated []string
+		errIngs         []string
+		isolated        []string
+	}{
+		{
+			name:    "no error",
+			members: []*networking.Ingress{newSyncIngress("a", "/")},
+		},
+		{
+			name:    "never synced",
+			members: []*networking.Ingress{newSyncIngress("a", "/"), newSyncIngress("b", "/")},
+			errIngs: []string{"default/b"},
+		},
+		{
+			name:    "current version synced",
+			members: []*networking.Ingress{newSyncIngress("a", "/"), withLastSynced(synced, newSyncIngress("b", "/"), "")},
+			errIngs: []string{"default/b"},
+		},
+		{
+			name:     "changed after synced",
+			members:  []*networking.Ingress{newSyncIngress("a", "/"), withLastSynced(synced, newSyncIngress("b", "/"), "/bad")},
+			errIngs:  []string{"default/b"},
+			isolated: []string{"default/b"},
+		},
+		{
+			name:            "last synced version fails",
+			members:         []*networking.Ingress{newSyncIngress("a", "/"), withLastSynced(synced, newSyncIngress("b", "/"), "/bad")},
+			alreadyIsolated: []string{"default/b"},
+			errIngs:         []string{"default/b"},
+		},
+		{
+			name: "one of the failed members can't be isolated",
+			members: []*networking.Ingress{
+				withLastSynced(synced, newSyncIngress("a", "/"), "/bad"),
+				newSyncIngress("b", "/"),
+			},
+			errIngs: []string{"default/a", "default/b"},
+		},
+		{
+			name:    "not a member",
+			members: []*networking.Ingress{newSyncIngress("a", "/")},
+			errIngs: []string{"default/c"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ingGroup := &albconfigmanager.Group{Members: c.members}
+			for _, key := range c.alreadyIsolated {
+				ingGroup.IsolatedMembers = map[string]*albconfigmanager.IsolatedMember{key: {}}
+			}
+			errResWithIngress := make(map[*networking.Ingress]error)
+			for _, key := range c.errIngs {
+				ns, name, _ := cache.SplitMetaNamespaceKey(key)
+				errResWithIngress[&networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name}}] = fmt.Errorf("bad annotation")
+			}
+
+			ok := isolateMembers(ingGroup, errResWithIngress, func(ing *networking.Ingress) *ingressLastSynced {
+				return synced[getIngressSyncedVersion(ing).Hash]
+			})
+			assert.Equal(t, len(c.isolated) != 0, ok)
+			assert.Equal(t, len(c.members), len(ingGroup.Members))
+			if !ok {
+				assert.Equal(t, len(c.alreadyIsolated), len(ingGroup.IsolatedMembers))
+				return
+			}
+			assert.Equal(t, c.isolated, isolatedMemberKeys(ingGroup))
+			for _, key := range c.isolated {
+				im := ingGroup.IsolatedMembers[key]
+				if assert.NotNil(t, im) {
+					assert.Equal(t, "/bad", im.Ingress.Annotations["alb.ingress.kubernetes.io/rewrite-target"])
+					assert.Equal(t, "/", im.LastSynced.Annotations["alb.ingress.kubernetes.io/rewrite-target"])
+					assert.Equal(t, int64(1), im.LastSynced.Generation)
+				}
+			}
+			for _, member := range ingGroup.Members {
+				assert.Equal(t, "/", member.Annotations["alb.ingress.kubernetes.io/rewrite-target"])
+			}
+		})
+	}
+}
+
+// fakeGroupBuilder fails the build for the members whose rewrite target starts with /bad
+type fakeGroupBuilder struct {
+	builds int
+}
+
+func (b *fakeGroupBuilder) Build(_ context.Context, _ *v1.AlbConfig, ingGroup *albconfigmanager.Group) (
+	core.Manager, *albmodel.AlbLoadBalancer, map[*networking.Ingress]error, error) {
+	b.builds++
+	errResWithIngress := make(map[*networking.Ingress]error)
+	for _, member := range ingGroup.Members {
+		if strings.HasPrefix(member.Annotations["alb.ingress.kubernetes.io/rewrite-target"], "/bad") {
+			errResWithIngress[member] = fmt.Errorf("bad rewrite target")
+		}
+	}
+	if len(errResWithIngress) != 0 {
+		return nil, nil, errResWithIngress, fmt.Errorf("build failed")
+	}
+	return core.NewDefaultManager(core.StackID{}), &albmodel.AlbLoadBalancer{}, errResWithIngress, nil
+}
+
+func newLastSyncedTestClient(synced map[string]*ingressLastSynced, members ...*networking.Ingress) client.Client {
+	objs := make([]client.Object, 0)
+	for _, ing := range members {
+		version := getIngressSyncedVersion(ing)
+		if version == nil {
+			continue
+		}
+		last := synced[version.Hash]
+		value, _ := json.Marshal(last)
+		objs = append(objs, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       ing.Namespace,
+				Name:            lastSyncedConfigMapName(ing),
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: ing.Name, UID: ing.UID}},
+			},
+			Data: map[string]string{lastSyncedConfigMapKey: string(value)},
+		})
+	}
+	return fake.NewClientBuilder().WithObjects(objs...).Build()
+}
+
+func TestBuildWithIsolation(t *testing.T) {
+	synced := make(map[string]*ingressLastSynced)
+	cases := []struct {
+		name     string
+		members  []*networking.Ingress
+		builds   int
+		isolated []string
+		fail     bool
+	}{
+		{
+			name:    "no error",
+			members: []*networking.Ingress{newSyncIngress("a", "/"), newSyncIngress("b", "/")},
+			builds:  1,
+		},
+		{
+			name:     "isolated",
+			members:  []*networking.Ingress{newSyncIngress("a", "/"), withLastSynced(synced, newSyncIngress("b", "/"), "/bad")},
+			builds:   2,
+			isolated: []string{"default/b"},
+		},
+		{
+			name:    "never synced",
+			members: []*networking.Ingress{newSyncIngress("a", "/"), newSyncIngress("b", "/bad")},
+			builds:  1,
+			fail:    true,
+		},
+		{
+			name:    "last synced version fails",
+			members: []*networking.Ingress{newSyncIngress("a", "/"), withLastSynced(synced, newSyncIngress("b", "/bad-1"), "/bad-2")},
+			builds:  2,
+			fail:    true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			builder := &fakeGroupBuilder{}
+			g := &albconfigReconciler{albconfigBuilder: builder, logger: logr.Discard(),
+				apiReader: newLastSyncedTestClient(synced, c.members...)}
+			ingGroup := &albconfigmanager.Group{Members: c.members}
+
+			stack, _, _, err := g.buildWithIsolation(context.TODO(), &v1.AlbConfig{}, ingGroup)
+			assert.Equal(t, c.fail, err != nil)
+			assert.Equal(t, c.fail, stack == nil)
+			assert.Equal(t, c.builds, builder.builds)
+			if !c.fail {
+				assert.ElementsMatch(t, c.isolated, isolatedMemberKeys(ingGroup))
+				assert.Equal(t, len(c.members)-len(c.isolated), len(syncedMembers(ingGroup)))
+			}
+		})
+	}
+}
+
+func TestSaveIngressLastSynced(t *testing.T) {
+	ing := newSyncIngress("a", "/")
+	k8sClient := newLastSyncedTestClient(nil)
+	g := &albconfigReconciler{k8sClient: k8sClient, apiReader: k8sClient, logger: logr.Discard()}
+
+	// the version not recorded in the annotation is not read
+	last := newIngressLastSynced(ing)
+	assert.NoError(t, g.saveIngressLastSynced(context.TODO(), ing, last))
+	assert.Nil(t, g.getIngressLastSynced(context.TODO(), ing))
+	value, _ := json.Marshal(ingressSyncedVersion{Hash: last.Hash, Generation: last.Generation})
+	ing.Annotations[helper.AnnotationIngressLastSynced] = string(value)
+	if got := g.getIngressLastSynced(context.TODO(), ing); assert.NotNil(t, got) {
+		assert.Equal(t, last.Hash, got.Hash)
+		assert.Equal(t, "/", got.Annotations["alb.ingress.kubernetes.io/rewrite-target"])
+	}
+
+	// the ConfigMap is updated with the new version
+	changed := ing.DeepCopy()
+	changed.Annotations["alb.ingress.kubernetes.io/rewrite-target"] = "/v2"
+	assert.NoError(t, g.saveIngressLastSynced(context.TODO(), changed, newIngressLastSynced(changed)))
+	assert.Nil(t, g.getIngressLastSynced(context.TODO(), ing))
+
+	// a ConfigMap of the same name which is not owned by the ingress is left alone
+	other := newSyncIngress("a", "/")
+	other.UID = "other"
+	assert.Error(t, g.saveIngressLastSynced(context.TODO(), other, newIngressLastSynced(other)))
+
+	large := newSyncIngress("b", "/")
+	large.Annotations["large"] = strings.Repeat("x", maxLastSyncedSize)
+	assert.Error(t, g.saveIngressLastSynced(context.TODO(), large, newIngressLastSynced(large)))
+
+	long := newSyncIngress(strings.Repeat("a", 253), "/")
+	assert.LessOrEqual(t, len(lastSyncedConfigMapName(long)), 253)
+	assert.Equal(t, "alb-last-synced-a", lastSyncedConfigMapName(ing))
+}
+
+func TestBuildIngressRuleStatuses(t *testing.T) {
+	assert.Empty(t, buildIngressRuleStatuses(context.TODO(), nil))
+
+	stack := core.NewDefaultManager(core.StackID{})
+	ls80 := albmodel.NewListener(stack, "80", albmodel.ListenerSpec{
+		LoadBalancerID:  core.LiteralStringToken("alb-1"),
+		ALBListenerSpec: albmodel.ALBListenerSpec{ListenerPort: 80, ListenerProtocol: "HTTP"},
+	})
+	ls80.SetStatus(albmodel.ListenerStatus{ListenerID: "lsn-80"})
+	ls443 := albmodel.NewListener(stack, "443", albmodel.ListenerSpec{
+		LoadBalancerID:  core.LiteralStringToken("alb-1"),
+		ALBListenerSpec: albmodel.ALBListenerSpec{ListenerPort: 443, ListenerProtocol: "HTTPS"},
+	})
+	ls443.SetStatus(albmodel.ListenerStatus{ListenerID: "lsn-443"})
+
+	newRule := func(id string, ls *albmodel.Listener, priority int, ruleID string, ingresses ...string) {
+		lr := albmodel.NewListenerRule(stack, id, albmodel.ListenerRuleSpec{
+			ListenerID:          ls.ListenerID(),
+			ALBListenerRuleSpec: albmodel.ALBListenerRuleSpec{Priority: priority, RuleName: "rule-" + id},
+			Ingresses:           ingresses,
+		})
+		if ruleID != "" {
+			lr.SetStatus(albmodel.ListenerRuleStatus{RuleID: ruleID})
+		}
+	}
+	newRule("80-2", ls80, 2, "rule-2", "default/a")
+	newRule("80-1", ls80, 1, "rule-1", "default/a", "default/a-canary")
+	newRule("443-1", ls443, 1, "", "default/a")
+	newRule("80-3", ls80, 3, "rule-3", "default/b")
+
+	rules := buildIngressRuleStatuses(context.TODO(), stack)
+	assert.Equal(t, []ingressRuleStatus{
+		{Listener: "443/HTTPS", RuleName: "rule-443-1", Priority: 1},
+		{Listener: "80/HTTP", RuleId: "rule-1", RuleName: "rule-80-1", Priority: 1},
+		{Listener: "80/HTTP", RuleId: "rule-2", RuleName: "rule-80-2", Priority: 2},
+	}, rules["default/a"])
+	assert.Equal(t, []ingressRuleStatus{
+		{Listener: "80/HTTP", RuleId: "rule-1", RuleName: "rule-80-1", Priority: 1},
+	}, rules["default/a-canary"])
+	assert.Equal(t, 1, len(rules["default/b"]))
+}
+
+func TestDefaultBackendConflictsByIngress(t *testing.T) {
+	newIngress := func(name, svcName string) *networking.Ingress {
+		return &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: networking.IngressSpec{DefaultBackend: &networking.IngressBackend{
+				Service: &networking.IngressServiceBackend{Name: svcName, Port: networking.ServiceBackendPort{Number: 80}},
+			}},
+		}
+	}
+	_, conflicts := albconfigmanager.SelectDefaultBackend([]*networking.Ingress{
+		newIngress("a", "web"), newIngress("b", "api"), newIngress("c", "web"),
+	})
+	byIngress := defaultBackendConflictsByIngress(conflicts)
+	assert.Equal(t, 1, len(byIngress["default/a"]))
+	assert.Equal(t, 1, len(byIngress["default/b"]))
+	assert.Empty(t, byIngress["default/c"])
+	assert.Contains(t, byIngress["default/b"][0], "api:80")
+}
@@ -90,8 +90,16 @@ const (
 	AlbCorsMaxAge               = AnnotationAlbPrefix + "cors-max-age"
 	AlbUseRegexPath             = AnnotationAlbPrefix + "use-regex"
 	AlbBackendKeepalive         = AnnotationAlbPrefix + "backend-keepalive"
-
-	AlbServerGroupId = AnnotationAlbPrefix + "server-group-id"
+	AlbConnectionDrainEnabled   = AnnotationAlbPrefix + "connection-drain-enabled"
+	AlbConnectionDrainTimeout   = AnnotationAlbPrefix + "connection-drain-timeout"
+	AlbSlowStartEnabled         = AnnotationAlbPrefix + "slow-start-enabled"
+	AlbSlowStartDuration        = AnnotationAlbPrefix + "slow-start-duration"
+	AlbBackendWeightPolicy      = AnnotationAlbPrefix + "backend-weight-policy"
+	AlbBackendWeight            = AnnotationAlbPrefix + "backend-weight"
+	AlbTopologyAwareWeight      = AnnotationAlbPrefix + "topology-aware-weight"
+
+	AlbServerGroupId           = AnnotationAlbPrefix + "server-group-id"
+	AlbServerGroupMultiCluster = AnnotationAlbPrefix + "server-group-multi-cluster"
 )
 
 type ParseOptions struct {
@@ -44,17 +44,22 @@ func (m *defaultConsoleServiceManagerApplier) Apply(ctx context.Context, console
 		return err
 	}
 
-	if sdkSgp.Tags[util.ClusterNameTagKey] != "" && sdkSgp.Tags[util.ClusterNameTagKey] != consoleServiceStack.ClusterID {
-		err := fmt.Errorf("ServerGroup managed by other cluster(current: %s, want: %s)", sdkSgp.Tags[util.ClusterNameTagKey], consoleServiceStack.ClusterID)
-		m.logger.Error(err, "synthesize servers failed(ServerGroup managed by other cluster)", "serverGroupID", consoleServiceStack.ServerGroupID)
-		return err
-	}
+	// a server group shared by several clusters is not tagged with any of them, every cluster only manages the
+	// servers registered with its description
+	if consoleServiceStack.ServerDescription == "" {
+		if sdkSgp.Tags[util.ClusterNameTagKey] != "" && sdkSgp.Tags[util.ClusterNameTagKey] != consoleServiceStack.ClusterID {
+			err := fmt.Errorf("ServerGroup managed by other cluster(current: %s, want: %s)", sdkSgp.Tags[util.ClusterNameTagKey], consoleServiceStack.ClusterID)
+			m.logger.Error(err, "synthesize servers failed(ServerGroup managed by other cluster)", "serverGroupID", consoleServiceStack.ServerGroupID)
+			return err
+		}
 
-	if sdkSgp.Tags[util.ClusterNameTagKey] == "" {
-		m.tagConsoleService(ctx, consoleServiceStack)
+		if sdkSgp.Tags[util.ClusterNameTagKey] == "" {
+			m.tagConsoleService(ctx, consoleServiceStack)
+		}
 	}
 
-	serverApplier := NewServerApplier(m.kubeClient, m.albProvider, sdkSgp.ServerGroupId, consoleServiceStack.Backends, consoleServiceStack.TrafficPolicy, m.logger)
+	serverApplier := NewServerApplier(m.kubeClient, m.albProvider, sdkSgp.ServerGroupId, consoleServiceStack.Backends, consoleServiceStack.TrafficPolicy, 0, m.logger)
+	serverApplier.serverDescription = consoleServiceStack.ServerDescription
 	if err := serverApplier.Apply(ctx); err != nil {
 		m.logger.Error(err, "synthesize servers failed", "serverGroupID", consoleServiceStack.ServerGroupID)
 		return err
@@ -123,35 +123,54 @@ func (s *listenerRuleApplier) applyListenerRulesOnListenerBatch(ctx context.Cont
 			"unmatchedSDKLBs", unmatchedSDKLRs,
 			"traceID", traceID)
 	}
-	/** 1. 先新增、再修改、最后执行删除
-	 *  2. 修改操作从后往前批量处理
+	/** 1. 先新增：优先级被占用的规则先使用空闲优先级创建，再随批量调整优先级移动到目标优先级
+	 *  2. 再删除不再需要的规则，释放其占用的优先级
+	 *  3. 最后批量修改规则属性与优先级
 	 */
 
-	lrStatus, err := s.albProvider.CreateALBListenerRules(ctx, unmatchedResLRs)
+	createLRs, tempLRs, deferredLRs := buildListenerRulesToCreate(unmatchedResLRs, resLRs, sdkLRs)
+	lrStatus, err := s.albProvider.CreateALBListenerRules(ctx, createLRs)
 	if err != nil {
 		return err
 	}
-	for _, resLR := range unmatchedResLRs {
-		status, ok := lrStatus[resLR.Spec.Priority]
+	resAndSDKListenerRulePairs := make([]albmodel.ResAndSDKListenerRulePair, 0)
+	for _, createLR := range createLRs {
+		status, ok := lrStatus[createLR.Spec.Priority]
 		if !ok {
-			return fmt.Errorf("failed create rule with priority: %d", resLR.Spec.Priority)
+			return fmt.Errorf("failed create rule with priority: %d", createLR.Spec.Priority)
+		}
+		resLR, ok := tempLRs[createLR]
+		if !ok {
+			createLR.SetStatus(status)
+			continue
 		}
 		resLR.SetStatus(status)
+		resAndSDKListenerRulePairs = append(resAndSDKListenerRulePairs, albmodel.ResAndSDKListenerRulePair{
+			ResLR: resLR,
+			SdkLR: &albsdk.Rule{
+				RuleId:    status.RuleID,
+				RuleName:  resLR.Spec.RuleName,
+				Priority:  createLR.Spec.Priority,
+				Direction: resLR.Spec.RuleDirection,
+			},
+		})
+	}
+
+	unmatchedSDKLRIDs := make([]string, 0)
+	for _, sdkLR := range unmatchedSDKLRs {
+		unmatchedSDKLRIDs = append(unmatchedSDKLRIDs, sdkLR.RuleId)
+	}
+	if err := s.albProvider.DeleteALBListenerRules(ctx, unmatchedSDKLRIDs); err != nil {
+		return err
 	}
 
-	resAndSDKListenerRulePairs := make([]albmodel.ResAndSDKListenerRulePair, 0)
 	for _, matchedResAndSDKLR := range matchedResAndSDKLRs {
 		resAndSDKListenerRulePairs = append(resAndSDKListenerRulePairs, albmodel.ResAndSDKListenerRulePair{
 			ResLR: matchedResAndSDKLR.ResLR,
 			SdkLR: matchedResAndSDKLR.SdkLR,
 		})
 	}
-	if len(unmatchedSDKLRs) > 0 {
-		// reverse if delete
-		sort.Sort(sort.Reverse(albmodel.ResAndSDKListenerRulePairArray(resAndSDKListenerRulePairs)))
-	} else {
-		sort.Sort(albmodel.ResAndSDKListenerRulePairArray(resAndSDKListenerRulePairs))
-	}
+	sortListenerRulePairsForReorder(resAndSDKListenerRulePairs)
 	err = s.albProvider.UpdateALBListenerRules(ctx, resAndSDKListenerRulePairs)
 	if err != nil {
 		return err
@@ -162,17 +181,112 @@ func (s *listenerRuleApplier) applyListenerRulesOnListenerBatch(ctx context.Cont
 		})
 	}
 
-	unmatchedSDKLRIDs := make([]string, 0)
-	for _, sdkLR := range unmatchedSDKLRs {
-		unmatchedSDKLRIDs = append(unmatchedSDKLRIDs, sdkLR.RuleId)
-	}
-	if err := s.albProvider.DeleteALBListenerRules(ctx, unmatchedSDKLRIDs); err != nil {
-		return err
+	if len(deferredLRs) != 0 {
+		s.logger.Info("no free priority to move rules, create them after the update",
+			"listener", lsID, "rules", len(deferredLRs), "traceID", traceID)
+		lrStatus, err := s.albProvider.CreateALBListenerRules(ctx, deferredLRs)
+		if err != nil {
+			return err
+		}
+		for _, deferredLR := range deferredLRs {
+			status, ok := lrStatus[deferredLR.Spec.Priority]
+			if !ok {
+				return fmt.Errorf("failed create rule with priority: %d", deferredLR.Spec.Priority)
+			}
+			deferredLR.SetStatus(status)
+		}
 	}
 
 	return nil
 }
 
+// maxListenerRulePriority is the largest priority of a listener rule accepted by ALB, priorities start from 1
+const maxListenerRulePriority = 10000
+
+// buildListenerRulesToCreate returns the rules to create. A rule whose priority is still taken by an existing rule
+// is created with a copy at a free priority in the valid range, preferably after all the rules, the returned map holds
+// the rule of each copy, and the copy is moved to the priority of the rule by the batched update. When no priority is
+// free, the rule is returned in deferredLRs, to be created once the existing rules are deleted and moved.
+func buildListenerRulesToCreate(unmatchedResLRs, resLRs []*albmodel.ListenerRule, sdkLRs []albsdk.Rule) (
+	createLRs []*albmodel.ListenerRule, tempLRs map[*albmodel.ListenerRule]*albmodel.ListenerRule, deferredLRs []*albmodel.ListenerRule) {
+	takenPriorities := sets.NewString()
+	maxPriority := 0
+	for _, sdkLR := range sdkLRs {
+		takenPriorities.Insert(strconv.Itoa(sdkLR.Priority) + sdkLR.Direction)
+		if sdkLR.Priority > maxPriority {
+			maxPriority = sdkLR.Priority
+		}
+	}
+	// the priorities of the rules are taken once the update is done
+	targetPriorities := sets.NewString()
+	for _, resLR := range resLRs {
+		targetPriorities.Insert(strconv.Itoa(resLR.Spec.Priority) + resLR.Spec.RuleDirection)
+		if resLR.Spec.Priority > maxPriority {
+			maxPriority = resLR.Spec.Priority
+		}
+	}
+	freePriority := func(direction string) (int, bool) {
+		for i := 0; i < maxListenerRulePriority; i++ {
+			priority := (maxPriority+i)%maxListenerRulePriority + 1
+			key := strconv.Itoa(priority) + direction
+			if !takenPriorities.Has(key) && !targetPriorities.Has(key) {
+				takenPriorities.Insert(key)
+				return priority, true
+			}
+		}
+		return 0, false
+	}
+
+	createLRs = make([]*albmodel.ListenerRule, 0, len(unmatchedResLRs))
+	tempLRs = make(map[*albmodel.ListenerRule]*albmodel.ListenerRule)
+	for _, resLR := range unmatchedResLRs {
+		if !takenPriorities.Has(strconv.Itoa(resLR.Spec.Priority) + resLR.Spec.RuleDirection) {
+			createLRs = append(createLRs, resLR)
+			continue
+		}
+		priority, ok := freePriority(resLR.Spec.RuleDirection)
+		if !ok {
+			deferredLRs = append(deferredLRs, resLR)
+			continue
+		}
+		tempLR := &albmodel.ListenerRule{
+			ResourceMeta: resLR.ResourceMeta,
+			Spec:         resLR.Spec,
+		}
+		tempLR.Spec.Priority = priority
+		createLRs = append(createLRs, tempLR)
+		tempLRs[tempLR] = resLR
+	}
+	return createLRs, tempLRs, deferredLRs
+}
+
+// sortListenerRulePairsForReorder sorts the rules for the batched update. The batches are sent from the end of the
+// pairs, so the rules moving to a larger priority are moved first, starting from the last one, then the rules moving
+// to a smaller priority, starting from the first one, which makes every rule move to a priority already released
+// when the update is split into several batches.
+func sortListenerRulePairsForReorder(pairs []albmodel.ResAndSDKListenerRulePair) {
+	moveRank := func(pair albmodel.ResAndSDKListenerRulePair) int {
+		switch {
+		case pair.ResLR.Spec.Priority > pair.SdkLR.Priority:
+			return 2
+		case pair.ResLR.Spec.Priority < pair.SdkLR.Priority:
+			return 1
+		default:
+			return 0
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		rankI, rankJ := moveRank(pairs[i]), moveRank(pairs[j])
+		if rankI != rankJ {
+			return rankI < rankJ
+		}
+		if rankI == 1 {
+			return pairs[i].SdkLR.Priority > pairs[j].SdkLR.Priority
+		}
+		return pairs[i].SdkLR.Priority < pairs[j].SdkLR.Priority
+	})
+}
+
 func (s *listenerRuleApplier) findSDKListenersRulesOnLS(ctx context.Context, lsID string) ([]albsdk.Rule, error) {
 	rules, err := s.albProvider.ListALBListenerRules(ctx, lsID)
 	if err != nil {
@@ -181,13 +295,45 @@ func (s *listenerRuleApplier) findSDKListenersRulesOnLS(ctx context.Context, lsI
 	return rules, nil
 }
 
+// matchResAndSDKListenerRules matches the rules by name first, which is stable when the priorities shift. The rules
+// left are matched by priority and direction, which updates the rules created with the former names in place.
 func matchResAndSDKListenerRules(resLRs []*albmodel.ListenerRule, sdkLRs []albsdk.Rule) ([]albmodel.ResAndSDKListenerRulePair, []*albmodel.ListenerRule, []albsdk.Rule) {
 	var matchedResAndSDKLRs []albmodel.ResAndSDKListenerRulePair
 	var unmatchedResLRs []*albmodel.ListenerRule
 	var unmatchedSDKLRs []albsdk.Rule
 
-	resLRByPriorityDirection := mapResListenerRuleByPriority(resLRs)
-	sdkLRByPriorityDirection := mapSDKListenerRuleByPriority(sdkLRs)
+	sdkLRByName := make(map[string]albsdk.Rule)
+	for _, sdkLR := range sdkLRs {
+		if _, ok := sdkLRByName[sdkLR.RuleName]; ok {
+			// the rules with the same name are matched by priority
+			continue
+		}
+		sdkLRByName[sdkLR.RuleName] = sdkLR
+	}
+	matchedSDKLRIDs := sets.NewString()
+	var resLRsLeft []*albmodel.ListenerRule
+	for _, resLR := range resLRs {
+		sdkLR, ok := sdkLRByName[resLR.Spec.RuleName]
+		if !ok || resLR.Spec.RuleName == "" || sdkLR.Direction != resLR.Spec.RuleDirection {
+			resLRsLeft = append(resLRsLeft, resLR)
+			continue
+		}
+		delete(sdkLRByName, resLR.Spec.RuleName)
+		matchedSDKLRIDs.Insert(sdkLR.RuleId)
+		matchedResAndSDKLRs = append(matchedResAndSDKLRs, albmodel.ResAndSDKListenerRulePair{
+			ResLR: resLR,
+			SdkLR: &sdkLR,
+		})
+	}
+	var sdkLRsLeft []albsdk.Rule
+	for _, sdkLR := range sdkLRs {
+		if !matchedSDKLRIDs.Has(sdkLR.RuleId) {
+			sdkLRsLeft = append(sdkLRsLeft, sdkLR)
+		}
+	}
+
+	resLRByPriorityDirection := mapResListenerRuleByPriority(resLRsLeft)
+	sdkLRByPriorityDirection := mapSDKListenerRuleByPriority(sdkLRsLeft)
 	resLRPriorityDirections := sets.StringKeySet(resLRByPriorityDirection)
 	sdkLRPriorityDirections := sets.StringKeySet(sdkLRByPriorityDirection)
 	for _, priorityDirection := range resLRPriorityDirections.Intersection(sdkLRPriorityDirections).List() {
@@ -0,0 +1,130 @@
+package applier
+
+import (
+	"fmt"
+	"testing"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/stretchr/testify/assert"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+)
+
+func newResListenerRules(names ...string) []*albmodel.ListenerRule {
+	stack := core.NewDefaultManager(core.StackID{})
+	var resLRs []*albmodel.ListenerRule
+	for i, name := range names {
+		resLRs = append(resLRs, albmodel.NewListenerRule(stack, fmt.Sprintf("80:%d", i+1), albmodel.ListenerRuleSpec{
+			ListenerID:          core.LiteralStringToken("lsn-1"),
+			ALBListenerRuleSpec: albmodel.ALBListenerRuleSpec{Priority: i + 1, RuleName: name},
+		}))
+	}
+	return resLRs
+}
+
+func newSDKListenerRules(names ...string) []albsdk.Rule {
+	var sdkLRs []albsdk.Rule
+	for i, name := range names {
+		sdkLRs = append(sdkLRs, albsdk.Rule{RuleId: "rule-id-" + name, RuleName: name, Priority: i + 1})
+	}
+	return sdkLRs
+}
+
+func TestMatchResAndSDKListenerRules(t *testing.T) {
+	// b is inserted before c, d is removed, the legacy rule is renamed in place
+	resLRs := newResListenerRules("a", "b", "c", "e")
+	sdkLRs := newSDKListenerRules("a", "c", "d", "rule-80-4")
+
+	matched, unmatchedRes, unmatchedSDK := matchResAndSDKListenerRules(resLRs, sdkLRs)
+	pairs := make(map[string]string)
+	for _, pair := range matched {
+		pairs[pair.ResLR.Spec.RuleName] = pair.SdkLR.RuleName
+	}
+	assert.Equal(t, map[string]string{"a": "a", "c": "c", "e": "rule-80-4"}, pairs)
+	if assert.Equal(t, 1, len(unmatchedRes)) {
+		assert.Equal(t, "b", unmatchedRes[0].Spec.RuleName)
+	}
+	if assert.Equal(t, 1, len(unmatchedSDK)) {
+		assert.Equal(t, "d", unmatchedSDK[0].RuleName)
+	}
+}
+
+func TestBuildListenerRulesToCreate(t *testing.T) {
+	resLRs := newResListenerRules("a", "b", "c", "d")
+	sdkLRs := newSDKListenerRules("a", "c")
+
+	_, unmatchedRes, _ := matchResAndSDKListenerRules(resLRs, sdkLRs)
+	createLRs, tempLRs, deferredLRs := buildListenerRulesToCreate(unmatchedRes, resLRs, sdkLRs)
+	if !assert.Equal(t, 2, len(createLRs)) {
+		return
+	}
+	// b takes the priority 2 of c, it is created after all the rules then moved to its priority
+	assert.Equal(t, "b", createLRs[0].Spec.RuleName)
+	assert.Equal(t, 5, createLRs[0].Spec.Priority)
+	assert.Equal(t, resLRs[1], tempLRs[createLRs[0]])
+	assert.Equal(t, 2, resLRs[1].Spec.Priority)
+	// the priority 4 of d is free
+	assert.Equal(t, resLRs[3], createLRs[1])
+	assert.Equal(t, 1, len(tempLRs))
+	assert.Empty(t, deferredLRs)
+}
+
+func TestBuildListenerRulesToCreateAtMaxPriority(t *testing.T) {
+	newRules := func(from, to int) ([]*albmodel.ListenerRule, []albsdk.Rule) {
+		var resLRs []*albmodel.ListenerRule
+		var sdkLRs []albsdk.Rule
+		for priority := from; priority <= to; priority++ {
+			name := fmt.Sprintf("rule-%d", priority)
+			resLRs = append(resLRs, &albmodel.ListenerRule{Spec: albmodel.ListenerRuleSpec{
+				ALBListenerRuleSpec: albmodel.ALBListenerRuleSpec{Priority: priority, RuleName: name}}})
+			sdkLRs = append(sdkLRs, albsdk.Rule{RuleId: "rule-id-" + name, RuleName: name, Priority: priority})
+		}
+		return resLRs, sdkLRs
+	}
+	newRule := &albmodel.ListenerRule{Spec: albmodel.ListenerRuleSpec{
+		ALBListenerRuleSpec: albmodel.ALBListenerRuleSpec{Priority: 9999, RuleName: "new"}}}
+
+	// the rules take the priorities up to the max, the copy takes the first free priority from the start
+	resLRs, sdkLRs := newRules(2, maxListenerRulePriority)
+	createLRs, tempLRs, deferredLRs := buildListenerRulesToCreate([]*albmodel.ListenerRule{newRule}, append(resLRs, newRule), sdkLRs)
+	if assert.Equal(t, 1, len(createLRs)) {
+		assert.Equal(t, 1, createLRs[0].Spec.Priority)
+		assert.Equal(t, newRule, tempLRs[createLRs[0]])
+	}
+	assert.Empty(t, deferredLRs)
+
+	// the priority left is the target of another rule, the rule is created after the update
+	resLRs, sdkLRs = newRules(2, maxListenerRulePriority)
+	resLRs = append(resLRs, &albmodel.ListenerRule{Spec: albmodel.ListenerRuleSpec{
+		ALBListenerRuleSpec: albmodel.ALBListenerRuleSpec{Priority: 1, RuleName: "moved"}}})
+	createLRs, tempLRs, deferredLRs = buildListenerRulesToCreate([]*albmodel.ListenerRule{newRule}, append(resLRs, newRule), sdkLRs)
+	assert.Empty(t, createLRs)
+	assert.Empty(t, tempLRs)
+	assert.Equal(t, []*albmodel.ListenerRule{newRule}, deferredLRs)
+}
+
+func TestSortListenerRulePairsForReorder(t *testing.T) {
+	pair := func(name string, from, to int) albmodel.ResAndSDKListenerRulePair {
+		return albmodel.ResAndSDKListenerRulePair{
+			ResLR: &albmodel.ListenerRule{Spec: albmodel.ListenerRuleSpec{ALBListenerRuleSpec: albmodel.ALBListenerRuleSpec{RuleName: name, Priority: to}}},
+			SdkLR: &albsdk.Rule{RuleName: name, Priority: from},
+		}
+	}
+	pairs := []albmodel.ResAndSDKListenerRulePair{
+		pair("new", 6, 2),
+		pair("a", 1, 1),
+		pair("b", 2, 3),
+		pair("c", 3, 4),
+		pair("e", 5, 5),
+		pair("f", 8, 7),
+		pair("g", 9, 8),
+	}
+	sortListenerRulePairsForReorder(pairs)
+
+	// the batches are sent from the end
+	var order []string
+	for i := len(pairs) - 1; i >= 0; i-- {
+		order = append(order, pairs[i].ResLR.Spec.RuleName)
+	}
+	assert.Equal(t, []string{"c", "b", "new", "f", "g", "e", "a"}, order)
+}
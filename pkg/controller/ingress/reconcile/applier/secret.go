@@ -2,6 +2,7 @@ package applier
 
 import (
 	"context"
+	"strings"
 	"sync"
 
 	"k8s.io/alibaba-load-balancer-controller/pkg/util"
@@ -40,10 +41,45 @@ func (s *secretStackApplier) Apply(ctx context.Context) error {
 		s.logger.V(util.SynLogLevel).Info("synthesize secretStack: SecretCertificate not found, skip", "traceID", traceID)
 		return nil
 	}
-	sdkCerts, err := s.albProvider.DescribeSSLCertificateList(ctx)
-	if err != nil {
-		return err
+	var resServerCerts, resCACerts []*albmodel.SecretCertificate
+	for _, cert := range resCerts {
+		if cert.Spec.IsCA {
+			resCACerts = append(resCACerts, cert)
+		} else {
+			resServerCerts = append(resServerCerts, cert)
+		}
+	}
+	if len(resServerCerts) != 0 {
+		sdkCerts, err := s.albProvider.DescribeSSLCertificateList(ctx)
+		if err != nil {
+			return err
+		}
+		if err := s.applyCertificates(ctx, resServerCerts, sdkCerts, func(cert *albmodel.SecretCertificate) (string, error) {
+			return s.albProvider.CreateSSLCertificateWithName(ctx, cert.Spec.CertName, cert.Spec.Certificate, cert.Spec.PrivateKey)
+		}); err != nil {
+			return err
+		}
+	}
+	if len(resCACerts) != 0 {
+		sdkCerts, err := s.albProvider.DescribeCACertificateList(ctx)
+		if err != nil {
+			return err
+		}
+		if err := s.applyCertificates(ctx, resCACerts, sdkCerts, func(cert *albmodel.SecretCertificate) (string, error) {
+			return s.albProvider.CreateCACertificateWithName(ctx, cert.Spec.CertName, cert.Spec.Certificate)
+		}); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// applyCertificates uploads the certificates not found in CAS by create, and records the identifiers of all the
+// certificates in their status.
+func (s *secretStackApplier) applyCertificates(ctx context.Context, resCerts []*albmodel.SecretCertificate, sdkCerts []model.CertificateInfo,
+	create func(cert *albmodel.SecretCertificate) (string, error)) error {
+	traceID := ctx.Value(util.TraceID)
+
 	matchedResAndSDKCerts, unmatchedResCerts, _ := matchResAndSDKCertificates(resCerts, sdkCerts)
 
 	if len(matchedResAndSDKCerts) != 0 {
@@ -70,13 +106,14 @@ func (s *secretStackApplier) Apply(ctx context.Context) error {
 			util.RandomSleepFunc(util.ConcurrentMaxSleepMillisecondTime)
 
 			defer wgCreate.Done()
-			certId, err := s.albProvider.CreateSSLCertificateWithName(ctx, cert.Spec.CertName, cert.Spec.Certificate, cert.Spec.PrivateKey)
+			certId, err := create(cert)
 			if errCreate == nil && err != nil {
 				errCreate = err
 			}
 			for _, resCert := range resCertMapByCertName[cert.Spec.CertName] {
 				resCert.SetStatus(albmodel.SecretCertificateStatus{
 					CertIdentifier: certId,
+					Owned:          true,
 				})
 			}
 		}(cert)
@@ -98,6 +135,7 @@ func (s *secretStackApplier) Apply(ctx context.Context) error {
 			defer wgUpdate.Done()
 			certPair.ResCert.SetStatus(albmodel.SecretCertificateStatus{
 				CertIdentifier: certPair.SdkCert.CertIdentifier,
+				Owned:          certPair.SdkCert.CertName == certPair.ResCert.Spec.CertName,
 			})
 		}(certPair)
 	}
@@ -118,38 +156,57 @@ type resAndSDKCertificatePair struct {
 	SdkCert model.CertificateInfo
 }
 
+// matchResAndSDKCertificates matches the certificates with the ones in CAS by their content. A certificate is
+// matched by its name if the certificate of the same name in CAS has the same fingerprint, otherwise by its
+// fingerprint, so that a renewed certificate is uploaded, and a certificate already in CAS is not uploaded again.
 func matchResAndSDKCertificates(resCerts []*albmodel.SecretCertificate, sdkCerts []model.CertificateInfo) ([]resAndSDKCertificatePair, []*albmodel.SecretCertificate, []model.CertificateInfo) {
 	var matchedResAndSDKCerts []resAndSDKCertificatePair
 	var unmatchedResCerts []*albmodel.SecretCertificate
 	var unmatchedSDKCerts []model.CertificateInfo
 	resCertsByName := mapResCertByName(resCerts)
 	sdkCertsByName := mapSDKCertByName(sdkCerts)
+	sdkCertsByFingerprint := mapSDKCertByFingerprint(sdkCerts)
 
-	resCertNames := sets.StringKeySet(resCertsByName)
-	sdkCertNames := sets.StringKeySet(sdkCertsByName)
-
+	matchedSDKCertIDs := sets.NewString()
 	for _, cert := range resCerts {
-		sdkCert := sdkCertsByName[cert.Spec.CertName]
+		sdkCert := matchSDKCertificate(cert, sdkCertsByName, sdkCertsByFingerprint)
 		if sdkCert == nil {
 			continue
 		}
+		matchedSDKCertIDs.Insert(sdkCert.CertIdentifier)
 		matchedResAndSDKCerts = append(matchedResAndSDKCerts, resAndSDKCertificatePair{
 			ResCert: cert,
 			SdkCert: *sdkCert,
 		})
 	}
 
-	for _, name := range resCertNames.Difference(sdkCertNames).List() {
-		unmatchedResCerts = append(unmatchedResCerts, resCertsByName[name])
+	for _, name := range sets.StringKeySet(resCertsByName).List() {
+		cert := resCertsByName[name]
+		if matchSDKCertificate(cert, sdkCertsByName, sdkCertsByFingerprint) == nil {
+			unmatchedResCerts = append(unmatchedResCerts, cert)
+		}
 	}
 
-	for _, name := range sdkCertNames.Difference(resCertNames).List() {
-		unmatchedSDKCerts = append(unmatchedSDKCerts, *sdkCertsByName[name])
+	for _, cert := range sdkCerts {
+		if !matchedSDKCertIDs.Has(cert.CertIdentifier) {
+			unmatchedSDKCerts = append(unmatchedSDKCerts, cert)
+		}
 	}
 
 	return matchedResAndSDKCerts, unmatchedResCerts, unmatchedSDKCerts
 }
 
+func matchSDKCertificate(cert *albmodel.SecretCertificate, sdkCertsByName, sdkCertsByFingerprint map[string]*model.CertificateInfo) *model.CertificateInfo {
+	if sdkCert, ok := sdkCertsByName[cert.Spec.CertName]; ok &&
+		(cert.Spec.Fingerprint == "" || sdkCert.Sha2 == "" || strings.EqualFold(cert.Spec.Fingerprint, sdkCert.Sha2)) {
+		return sdkCert
+	}
+	if cert.Spec.Fingerprint == "" {
+		return nil
+	}
+	return sdkCertsByFingerprint[strings.ToUpper(cert.Spec.Fingerprint)]
+}
+
 func mapResCertByName(resCerts []*albmodel.SecretCertificate) map[string]*albmodel.SecretCertificate {
 	resCertsByName := make(map[string]*albmodel.SecretCertificate)
 	for _, cert := range resCerts {
@@ -164,3 +221,14 @@ func mapSDKCertByName(sdkCerts []model.CertificateInfo) map[string]*model.Certif
 	}
 	return sdkCertsByName
 }
+
+func mapSDKCertByFingerprint(sdkCerts []model.CertificateInfo) map[string]*model.CertificateInfo {
+	sdkCertsByFingerprint := make(map[string]*model.CertificateInfo)
+	for i, cert := range sdkCerts {
+		if cert.Sha2 == "" {
+			continue
+		}
+		sdkCertsByFingerprint[strings.ToUpper(cert.Sha2)] = &sdkCerts[i]
+	}
+	return sdkCertsByFingerprint
+}
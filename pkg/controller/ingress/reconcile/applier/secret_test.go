@@ -0,0 +1,103 @@
+package applier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+)
+
+// certCloud records the certificates uploaded to CAS
+type certCloud struct {
+	prvd.Provider
+	certs      []model.CertificateInfo
+	caCerts    []model.CertificateInfo
+	uploaded   []string
+	caUploaded []string
+}
+
+func (c *certCloud) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	return c.certs, nil
+}
+
+func (c *certCloud) CreateSSLCertificateWithName(ctx context.Context, certName, certificate, privateKey string) (string, error) {
+	c.uploaded = append(c.uploaded, certName)
+	return "cert-" + certName, nil
+}
+
+func (c *certCloud) DescribeCACertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	return c.caCerts, nil
+}
+
+func (c *certCloud) CreateCACertificateWithName(ctx context.Context, certName, certificate string) (string, error) {
+	c.caUploaded = append(c.caUploaded, certName)
+	return "ca-" + certName, nil
+}
+
+func TestMatchResAndSDKCertificates(t *testing.T) {
+	stack := core.NewDefaultManager(core.StackID{Name: "alb"})
+	renewed := albmodel.NewSecretCertificate(stack, "renewed", albmodel.SecretCertificateSpec{CertName: "default-tls-abcdef", Fingerprint: "bb"})
+	reused := albmodel.NewSecretCertificate(stack, "reused", albmodel.SecretCertificateSpec{CertName: "default-other-123456", Fingerprint: "cc"})
+	unchanged := albmodel.NewSecretCertificate(stack, "unchanged", albmodel.SecretCertificateSpec{CertName: "default-web-654321", Fingerprint: "dd"})
+	sdkCerts := []model.CertificateInfo{
+		{CertName: "default-tls-abcdef", CertIdentifier: "cert-old", Sha2: "AA"},
+		{CertName: "uploaded-by-user", CertIdentifier: "cert-user", Sha2: "CC"},
+		{CertName: "default-web-654321", CertIdentifier: "cert-web", Sha2: "DD"},
+	}
+
+	matched, unmatchedRes, unmatchedSDK := matchResAndSDKCertificates(
+		[]*albmodel.SecretCertificate{renewed, reused, unchanged}, sdkCerts)
+	matchedIDs := make(map[*albmodel.SecretCertificate]string)
+	for _, pair := range matched {
+		matchedIDs[pair.ResCert] = pair.SdkCert.CertIdentifier
+	}
+	// the certificate of the same name with another fingerprint is not matched, so that the renewal is uploaded
+	assert.Equal(t, map[*albmodel.SecretCertificate]string{reused: "cert-user", unchanged: "cert-web"}, matchedIDs)
+	assert.Equal(t, []*albmodel.SecretCertificate{renewed}, unmatchedRes)
+	if assert.Equal(t, 1, len(unmatchedSDK)) {
+		assert.Equal(t, "cert-old", unmatchedSDK[0].CertIdentifier)
+	}
+}
+
+func TestSecretApplierOwnership(t *testing.T) {
+	stack := core.NewDefaultManager(core.StackID{Name: "alb"})
+	renewed := albmodel.NewSecretCertificate(stack, "renewed", albmodel.SecretCertificateSpec{CertName: "default-tls-fedcba", Fingerprint: "BB"})
+	reused := albmodel.NewSecretCertificate(stack, "reused", albmodel.SecretCertificateSpec{CertName: "default-other-123456", Fingerprint: "CC"})
+	unchanged := albmodel.NewSecretCertificate(stack, "unchanged", albmodel.SecretCertificateSpec{CertName: "default-web-654321", Fingerprint: "DD"})
+	cloud := &certCloud{certs: []model.CertificateInfo{
+		{CertName: "uploaded-by-user", CertIdentifier: "cert-user", Sha2: "CC"},
+		{CertName: "default-web-654321", CertIdentifier: "cert-web", Sha2: "DD"},
+	}}
+
+	assert.NoError(t, NewSecretApplier(cloud, stack, logr.Discard()).Apply(context.TODO()))
+	assert.Equal(t, []string{"default-tls-fedcba"}, cloud.uploaded)
+	assert.Equal(t, albmodel.SecretCertificateStatus{CertIdentifier: "cert-default-tls-fedcba", Owned: true}, *renewed.Status)
+	// the certificate uploaded by the user is used, but never owned by the controller
+	assert.Equal(t, albmodel.SecretCertificateStatus{CertIdentifier: "cert-user", Owned: false}, *reused.Status)
+	assert.Equal(t, albmodel.SecretCertificateStatus{CertIdentifier: "cert-web", Owned: true}, *unchanged.Status)
+}
+
+func TestSecretApplierCACertificates(t *testing.T) {
+	stack := core.NewDefaultManager(core.StackID{Name: "alb"})
+	rotated := albmodel.NewSecretCertificate(stack, "rotated", albmodel.SecretCertificateSpec{CertName: "default-clients-ca-fedcba", Fingerprint: "BB", IsCA: true})
+	unchanged := albmodel.NewSecretCertificate(stack, "unchanged", albmodel.SecretCertificateSpec{CertName: "default-partners-ca-654321", Fingerprint: "DD", IsCA: true})
+	cloud := &certCloud{
+		// the server certificate of the same content is never used as the CA certificate
+		certs: []model.CertificateInfo{{CertName: "default-clients-ca-fedcba", CertIdentifier: "cert-server", Sha2: "BB"}},
+		caCerts: []model.CertificateInfo{
+			{CertName: "default-clients-ca-abcdef", CertIdentifier: "ca-old", Sha2: "AA"},
+			{CertName: "default-partners-ca-654321", CertIdentifier: "ca-partners", Sha2: "DD"},
+		},
+	}
+
+	assert.NoError(t, NewSecretApplier(cloud, stack, logr.Discard()).Apply(context.TODO()))
+	assert.Empty(t, cloud.uploaded)
+	assert.Equal(t, []string{"default-clients-ca-fedcba"}, cloud.caUploaded)
+	assert.Equal(t, albmodel.SecretCertificateStatus{CertIdentifier: "ca-default-clients-ca-fedcba", Owned: true}, *rotated.Status)
+	assert.Equal(t, albmodel.SecretCertificateStatus{CertIdentifier: "ca-partners", Owned: true}, *unchanged.Status)
+}
@@ -2,9 +2,9 @@ package applier
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"k8s.io/alibaba-load-balancer-controller/pkg/util"
 
@@ -18,7 +18,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -26,14 +25,16 @@ import (
 	"github.com/go-logr/logr"
 )
 
-func NewServerApplier(kubeClient client.Client, albProvider prvd.Provider, serverGroupID string, endpoints []albmodel.BackendItem, trafficPolicy string, logger logr.Logger) *serverApplier {
+func NewServerApplier(kubeClient client.Client, albProvider prvd.Provider, serverGroupID string, endpoints []albmodel.BackendItem, trafficPolicy string,
+	connectionDrainTimeout int, logger logr.Logger) *serverApplier {
 	return &serverApplier{
-		kubeClient:    kubeClient,
-		albProvider:   albProvider,
-		serverGroupID: serverGroupID,
-		endpoints:     endpoints,
-		trafficPolicy: trafficPolicy,
-		logger:        logger,
+		kubeClient:             kubeClient,
+		albProvider:            albProvider,
+		serverGroupID:          serverGroupID,
+		endpoints:              endpoints,
+		trafficPolicy:          trafficPolicy,
+		connectionDrainTimeout: connectionDrainTimeout,
+		logger:                 logger,
 	}
 }
 
@@ -43,7 +44,16 @@ type serverApplier struct {
 	serverGroupID string
 	endpoints     []albmodel.BackendItem
 	trafficPolicy string
-	logger        logr.Logger
+	// connectionDrainTimeout is the time in seconds the draining endpoints are kept with weight 0,
+	// they are removed at once if it is 0
+	connectionDrainTimeout int
+	// draining is true if some draining endpoints are kept in the server group
+	draining bool
+	// serverDescription is set if the servers are owned by their description, the endpoints are registered with it
+	// and only the servers of it are updated or removed, the servers without description are adopted if they match
+	// an endpoint
+	serverDescription string
+	logger            logr.Logger
 }
 
 func (s *serverApplier) Apply(ctx context.Context) error {
@@ -53,11 +63,40 @@ func (s *serverApplier) Apply(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	endpoints := s.filterDrainedEndpoints(time.Now())
+	if s.serverDescription != "" {
+		servers = filterAdoptableServers(servers, s.serverDescription)
+		for i := range endpoints {
+			endpoints[i].Description = s.serverDescription
+		}
+	}
 	s.logger.V(util.SynLogLevel).Info("apply servers",
-		"endpoints", s.endpoints,
+		"endpoints", endpoints,
 		"traceID", traceID)
-	// todo matched endpoints need check and update weight
-	_, unmatchedResEndpoints, unmatchedSDKEndpoints := matchEndpointWithTargets(s.endpoints, servers, s.trafficPolicy)
+	matchedEndpoints, unmatchedResEndpoints, unmatchedSDKEndpoints := matchEndpointWithTargets(endpoints, servers, s.trafficPolicy)
+	// the draining endpoints are only kept if they are registered already
+	unmatchedResEndpoints = filterOutDrainingEndpoints(unmatchedResEndpoints)
+	if s.serverDescription != "" {
+		// the servers without description are only adopted if they match an endpoint
+		unmatchedSDKEndpoints = filterServersByDescription(unmatchedSDKEndpoints, s.serverDescription)
+	}
+
+	var weightChangedEndpoints []albmodel.BackendItem
+	for _, pair := range matchedEndpoints {
+		// the adopted servers are described by the update
+		if pair.endpoint.Weight != pair.target.Weight ||
+			(s.serverDescription != "" && pair.endpoint.Description != pair.target.Description) {
+			weightChangedEndpoints = append(weightChangedEndpoints, pair.endpoint)
+		}
+	}
+	if len(weightChangedEndpoints) != 0 {
+		s.logger.V(util.SynLogLevel).Info("apply servers",
+			"weightChangedEndpoints", weightChangedEndpoints,
+			"traceID", traceID)
+		if err := s.albProvider.UpdateALBServers(ctx, s.serverGroupID, weightChangedEndpoints); err != nil {
+			return err
+		}
+	}
 
 	if len(unmatchedResEndpoints) != 0 {
 		s.logger.V(util.SynLogLevel).Info("apply servers",
@@ -80,7 +119,7 @@ func (s *serverApplier) Apply(ctx context.Context) error {
 		if err := s.albProvider.ReplaceALBServers(ctx, s.serverGroupID, unmatchedResEndpoints, unmatchedSDKEndpoints); err != nil {
 			return err
 		}
-		_ = updateTargetHealthPodCondition(ctx, s.kubeClient, helper.BuildReadinessGatePodConditionType(), s.endpoints)
+		_ = updateTargetHealthPodCondition(ctx, s.kubeClient, helper.BuildReadinessGatePodConditionType(), endpoints)
 
 		return nil
 	}
@@ -93,7 +132,7 @@ func (s *serverApplier) Apply(ctx context.Context) error {
 		if err := s.albProvider.RegisterALBServers(ctx, s.serverGroupID, unmatchedResEndpoints); err != nil {
 			return err
 		}
-		_ = updateTargetHealthPodCondition(ctx, s.kubeClient, helper.BuildReadinessGatePodConditionType(), s.endpoints)
+		_ = updateTargetHealthPodCondition(ctx, s.kubeClient, helper.BuildReadinessGatePodConditionType(), endpoints)
 	}
 
 	return nil
@@ -103,6 +142,57 @@ func (s *serverApplier) PostApply(ctx context.Context) error {
 	return nil
 }
 
+// filterDrainedEndpoints removes the draining endpoints whose connection drain timeout elapsed, the others are kept
+// with weight 0 so that the server group stops sending new requests to them
+func (s *serverApplier) filterDrainedEndpoints(now time.Time) []albmodel.BackendItem {
+	endpoints := make([]albmodel.BackendItem, 0, len(s.endpoints))
+	for _, endpoint := range s.endpoints {
+		if endpoint.Draining {
+			if !helper.IsPodDraining(endpoint.Pod, s.connectionDrainTimeout, now) {
+				continue
+			}
+			endpoint.Weight = 0
+			s.draining = true
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// filterAdoptableServers returns the servers registered with the description or without description. The servers
+// registered before the server group is shared have no description, they are adopted if they match an endpoint,
+// the servers of the other clusters sharing the server group are left untouched.
+func filterAdoptableServers(servers []albsdk.BackendServer, description string) []albsdk.BackendServer {
+	var ret []albsdk.BackendServer
+	for _, server := range servers {
+		if server.Description == description || server.Description == "" {
+			ret = append(ret, server)
+		}
+	}
+	return ret
+}
+
+// filterServersByDescription returns the servers registered with the description
+func filterServersByDescription(servers []albsdk.BackendServer, description string) []albsdk.BackendServer {
+	var ret []albsdk.BackendServer
+	for _, server := range servers {
+		if server.Description == description {
+			ret = append(ret, server)
+		}
+	}
+	return ret
+}
+
+func filterOutDrainingEndpoints(endpoints []albmodel.BackendItem) []albmodel.BackendItem {
+	var ret []albmodel.BackendItem
+	for _, endpoint := range endpoints {
+		if !endpoint.Draining {
+			ret = append(ret, endpoint)
+		}
+	}
+	return ret
+}
+
 func matchEndpointWithTargets(endpoints []albmodel.BackendItem, targets []albsdk.BackendServer, trafficPolicy string) ([]endpointAndTargetPair, []albmodel.BackendItem, []albsdk.BackendServer) {
 	var matchedEndpointAndTargets []endpointAndTargetPair
 	var unmatchedEndpoints []albmodel.BackendItem
@@ -230,7 +320,7 @@ func updateTargetHealthPodConditionForPod(ctx context.Context, kubeClient client
 		newTargetHealthCond.LastTransitionTime = metav1.Now()
 	}
 
-	patch, err := buildPodConditionPatch(pod, newTargetHealthCond)
+	patch, err := helper.BuildPodConditionPatch(pod, newTargetHealthCond)
 	if err != nil {
 		return false, err
 	}
@@ -251,31 +341,6 @@ func updateTargetHealthPodConditionForPod(ctx context.Context, kubeClient client
 	return true, nil
 }
 
-func buildPodConditionPatch(pod *v1.Pod, condition v1.PodCondition) (client.Patch, error) {
-	oldData, err := json.Marshal(v1.Pod{
-		Status: v1.PodStatus{
-			Conditions: nil,
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-	newData, err := json.Marshal(v1.Pod{
-		ObjectMeta: metav1.ObjectMeta{UID: pod.UID}, // only put the uid in the new object to ensure it appears in the patch as a precondition
-		Status: v1.PodStatus{
-			Conditions: []v1.PodCondition{condition},
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, v1.Pod{})
-	if err != nil {
-		return nil, err
-	}
-	return client.RawPatch(types.StrategicMergePatchType, patchBytes), nil
-}
-
 // HasAnyOfReadinessGates returns whether podInfo has any of these readinessGates
 func HasAnyOfReadinessGates(pod *v1.Pod, conditionTypes []v1.PodConditionType) bool {
 	for _, rg := range pod.Spec.ReadinessGates {
@@ -273,12 +273,22 @@ func matchResAndSDKServerGroupsSGP(resSGPs []*albmodel.ServerGroup, sdkSGPs []al
 	for _, resID := range resSGPIDs.Intersection(sdkSGPIDs).List() {
 		resSGP := resSGPsByID[resID]
 		sdkSGPs := sdkSGPsByID[resID]
+		matched := false
 		for _, sdkSGP := range sdkSGPs {
+			// the type of a server group can't be updated, it is replaced by a new one
+			if isServerGroupTypeChanged(resSGP, sdkSGP) {
+				unmatchedSDKSGPs = append(unmatchedSDKSGPs, sdkSGP)
+				continue
+			}
+			matched = true
 			matchedResAndSDKSGPs = append(matchedResAndSDKSGPs, resAndSDKServerGroupPairSGP{
 				ResSGP: resSGP,
 				SdkSGP: sdkSGP,
 			})
 		}
+		if !matched {
+			unmatchedResSGPs = append(unmatchedResSGPs, resSGP)
+		}
 	}
 	for _, resID := range resSGPIDs.Difference(sdkSGPIDs).List() {
 		unmatchedResSGPs = append(unmatchedResSGPs, resSGPsByID[resID])
@@ -290,6 +300,11 @@ func matchResAndSDKServerGroupsSGP(resSGPs []*albmodel.ServerGroup, sdkSGPs []al
 	return matchedResAndSDKSGPs, unmatchedResSGPs, unmatchedSDKSGPs, nil
 }
 
+func isServerGroupTypeChanged(resSGP *albmodel.ServerGroup, sdkSGP albmodel.ServerGroupWithTags) bool {
+	return resSGP.Spec.ServerGroupType != "" && sdkSGP.ServerGroupType != "" &&
+		!strings.EqualFold(resSGP.Spec.ServerGroupType, sdkSGP.ServerGroupType)
+}
+
 func mapResServerGroupByResourceIDSGP(resSGPs []*albmodel.ServerGroup) map[string]*albmodel.ServerGroup {
 	resSGPsByID := make(map[string]*albmodel.ServerGroup, len(resSGPs))
 	for _, resSGP := range resSGPs {
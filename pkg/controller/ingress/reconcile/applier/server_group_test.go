@@ -0,0 +1,43 @@
+package applier
+
+import (
+	"testing"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/stretchr/testify/assert"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+)
+
+func TestMatchResAndSDKServerGroupsByType(t *testing.T) {
+	const resourceIDTagKey = "ack.aliyun.com/resource-id"
+	stack := core.NewDefaultManager(core.StackID{Name: "alb"})
+	sgpSpec := func(sgpType string) albmodel.ServerGroupSpec {
+		var spec albmodel.ServerGroupSpec
+		spec.ServerGroupType = sgpType
+		return spec
+	}
+	web := albmodel.NewServerGroup(stack, "web", sgpSpec(util.DefaultServerGroupType))
+	onPrem := albmodel.NewServerGroup(stack, "on-prem", sgpSpec(util.ServerGroupTypeIp))
+	sdkSGP := func(id, resID, sgpType string) albmodel.ServerGroupWithTags {
+		return albmodel.ServerGroupWithTags{
+			ServerGroup: albsdk.ServerGroup{ServerGroupId: id, ServerGroupType: sgpType},
+			Tags:        map[string]string{resourceIDTagKey: resID},
+		}
+	}
+
+	matched, unmatchedRes, unmatchedSDK, err := matchResAndSDKServerGroupsSGP(
+		[]*albmodel.ServerGroup{web, onPrem},
+		[]albmodel.ServerGroupWithTags{sdkSGP("sgp-web", "web", "Instance"), sdkSGP("sgp-on-prem", "on-prem", "Instance")},
+		resourceIDTagKey)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(matched)) {
+		assert.Equal(t, "sgp-web", matched[0].SdkSGP.ServerGroupId)
+	}
+	// the server group of the service without pods is replaced by an Ip server group
+	assert.Equal(t, []*albmodel.ServerGroup{onPrem}, unmatchedRes)
+	if assert.Equal(t, 1, len(unmatchedSDK)) {
+		assert.Equal(t, "sgp-on-prem", unmatchedSDK[0].ServerGroupId)
+	}
+}
@@ -0,0 +1,200 @@
+package applier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// serverCloud records the changes of the servers of a server group
+type serverCloud struct {
+	prvd.Provider
+	servers      []albsdk.BackendServer
+	registered   []albmodel.BackendItem
+	deregistered []albsdk.BackendServer
+	updated      []albmodel.BackendItem
+}
+
+func (c *serverCloud) ListALBServers(ctx context.Context, serverGroupID string) ([]albsdk.BackendServer, error) {
+	return c.servers, nil
+}
+
+func (c *serverCloud) RegisterALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	c.registered = append(c.registered, resServers...)
+	return nil
+}
+
+func (c *serverCloud) DeregisterALBServers(ctx context.Context, serverGroupID string, sdkServers []albsdk.BackendServer) error {
+	c.deregistered = append(c.deregistered, sdkServers...)
+	return nil
+}
+
+func (c *serverCloud) ReplaceALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem, sdkServers []albsdk.BackendServer) error {
+	c.registered = append(c.registered, resServers...)
+	c.deregistered = append(c.deregistered, sdkServers...)
+	return nil
+}
+
+func (c *serverCloud) UpdateALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	c.updated = append(c.updated, resServers...)
+	return nil
+}
+
+func terminatingPod(name string, since time.Duration) *v1.Pod {
+	// the pod is requested to terminate since the duration with a grace period of 60 seconds
+	deletion := metav1.NewTime(time.Now().Add(-since).Add(60 * time.Second))
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:                  "default",
+		Name:                       name,
+		DeletionTimestamp:          &deletion,
+		DeletionGracePeriodSeconds: pointer.Int64(60),
+	}}
+}
+
+func TestServerApplierDrainEndpoints(t *testing.T) {
+	eni := func(id, ip string) albmodel.BackendItem {
+		return albmodel.BackendItem{ServerId: id, ServerIp: ip, Port: 8080, Type: util.ServerTypeEni, Weight: util.DefaultServerWeight}
+	}
+	draining := func(item albmodel.BackendItem, pod *v1.Pod) albmodel.BackendItem {
+		item.Weight = 0
+		item.Draining = true
+		item.Pod = pod
+		return item
+	}
+	target := func(id, ip string) albsdk.BackendServer {
+		return albsdk.BackendServer{ServerId: id, ServerIp: ip, Port: 8080, ServerType: util.ServerTypeEni, Weight: util.DefaultServerWeight}
+	}
+
+	cloud := &serverCloud{servers: []albsdk.BackendServer{
+		target("eni-1", "10.0.0.1"),
+		target("eni-2", "10.0.0.2"),
+		target("eni-3", "10.0.0.3"),
+	}}
+	endpoints := []albmodel.BackendItem{
+		eni("eni-1", "10.0.0.1"),
+		// drained for 10 seconds
+		draining(eni("eni-2", "10.0.0.2"), terminatingPod("draining", 10*time.Second)),
+		// the drain timeout elapsed
+		draining(eni("eni-3", "10.0.0.3"), terminatingPod("drained", 60*time.Second)),
+		// the draining endpoint is not registered
+		draining(eni("eni-4", "10.0.0.4"), terminatingPod("unregistered", 10*time.Second)),
+	}
+	applier := NewServerApplier(fake.NewClientBuilder().Build(), cloud, "sgp-1", endpoints, util.TrafficPolicyEni, 30, logr.Discard())
+
+	assert.NoError(t, applier.Apply(context.TODO()))
+	assert.True(t, applier.draining)
+	if assert.Equal(t, 1, len(cloud.updated)) {
+		assert.Equal(t, "eni-2", cloud.updated[0].ServerId)
+		assert.Equal(t, 0, cloud.updated[0].Weight)
+	}
+	if assert.Equal(t, 1, len(cloud.deregistered)) {
+		assert.Equal(t, "eni-3", cloud.deregistered[0].ServerId)
+	}
+	assert.Empty(t, cloud.registered)
+
+	// the draining endpoints are removed at once without drain timeout
+	cloud = &serverCloud{servers: []albsdk.BackendServer{target("eni-1", "10.0.0.1"), target("eni-2", "10.0.0.2")}}
+	applier = NewServerApplier(fake.NewClientBuilder().Build(), cloud, "sgp-1", endpoints[:2], util.TrafficPolicyEni, 0, logr.Discard())
+	assert.NoError(t, applier.Apply(context.TODO()))
+	assert.False(t, applier.draining)
+	assert.Empty(t, cloud.updated)
+	if assert.Equal(t, 1, len(cloud.deregistered)) {
+		assert.Equal(t, "eni-2", cloud.deregistered[0].ServerId)
+	}
+}
+
+func (c *serverCloud) SelectALBServerGroupsByID(ctx context.Context, serverGroupID string) (albmodel.ServerGroupWithTags, error) {
+	sgp := albmodel.ServerGroupWithTags{Tags: map[string]string{util.ClusterNameTagKey: "other-cluster"}}
+	sgp.ServerGroupId = serverGroupID
+	return sgp, nil
+}
+
+func TestConsoleServiceApplierSharedServerGroup(t *testing.T) {
+	eni := func(id, ip string) albmodel.BackendItem {
+		return albmodel.BackendItem{ServerId: id, ServerIp: ip, Port: 8080, Type: util.ServerTypeEni, Weight: util.DefaultServerWeight}
+	}
+	target := func(id, ip, description string) albsdk.BackendServer {
+		return albsdk.BackendServer{ServerId: id, ServerIp: ip, Port: 8080, ServerType: util.ServerTypeEni, Weight: util.DefaultServerWeight,
+			Description: description}
+	}
+	stack := func(description string) *albmodel.ConsoleServiceStack {
+		return &albmodel.ConsoleServiceStack{
+			ClusterID:         "cluster-a",
+			ServerGroupID:     "sgp-1",
+			Namespace:         "default",
+			Name:              "tea-svc",
+			ServerDescription: description,
+			TrafficPolicy:     util.TrafficPolicyEni,
+			Backends:          []albmodel.BackendItem{eni("eni-1", "10.0.0.1"), eni("eni-2", "10.0.0.2")},
+		}
+	}
+	servers := func() []albsdk.BackendServer {
+		return []albsdk.BackendServer{
+			target("eni-1", "10.0.0.1", "k8s.tea-svc.default.cluster-a"),
+			target("eni-3", "10.0.0.3", "k8s.tea-svc.default.cluster-a"),
+			target("eni-9", "10.1.0.9", "k8s.tea-svc.default.cluster-b"),
+		}
+	}
+
+	// the server group tagged by another cluster is refused unless it is shared
+	cloud := &serverCloud{servers: servers()}
+	applier := NewConsoleServiceManagerApplier(fake.NewClientBuilder().Build(), cloud, logr.Discard())
+	assert.Error(t, applier.Apply(context.TODO(), stack("")))
+
+	assert.NoError(t, applier.Apply(context.TODO(), stack("k8s.tea-svc.default.cluster-a")))
+	if assert.Equal(t, 1, len(cloud.registered)) {
+		assert.Equal(t, "eni-2", cloud.registered[0].ServerId)
+		assert.Equal(t, "k8s.tea-svc.default.cluster-a", cloud.registered[0].Description)
+	}
+	// the servers of the other cluster are kept
+	if assert.Equal(t, 1, len(cloud.deregistered)) {
+		assert.Equal(t, "eni-3", cloud.deregistered[0].ServerId)
+	}
+}
+
+func TestServerApplierAdoptServersWithoutDescription(t *testing.T) {
+	eni := func(id, ip string) albmodel.BackendItem {
+		return albmodel.BackendItem{ServerId: id, ServerIp: ip, Port: 8080, Type: util.ServerTypeEni, Weight: util.DefaultServerWeight}
+	}
+	target := func(id, ip, description string) albsdk.BackendServer {
+		return albsdk.BackendServer{ServerId: id, ServerIp: ip, Port: 8080, ServerType: util.ServerTypeEni, Weight: util.DefaultServerWeight,
+			Description: description}
+	}
+	const description = "k8s.tea-svc.default.cluster-a"
+
+	// the servers were registered without description before the server group is shared
+	cloud := &serverCloud{servers: []albsdk.BackendServer{
+		target("eni-1", "10.0.0.1", ""),
+		target("eni-3", "10.0.0.3", description),
+		target("eni-8", "10.1.0.8", ""),
+		target("eni-9", "10.1.0.9", "k8s.tea-svc.default.cluster-b"),
+	}}
+	applier := NewServerApplier(fake.NewClientBuilder().Build(), cloud, "sgp-1",
+		[]albmodel.BackendItem{eni("eni-1", "10.0.0.1"), eni("eni-2", "10.0.0.2")}, util.TrafficPolicyEni, 0, logr.Discard())
+	applier.serverDescription = description
+
+	assert.NoError(t, applier.Apply(context.TODO()))
+	// the matching server is adopted instead of registered again
+	if assert.Equal(t, 1, len(cloud.updated)) {
+		assert.Equal(t, "eni-1", cloud.updated[0].ServerId)
+		assert.Equal(t, description, cloud.updated[0].Description)
+	}
+	if assert.Equal(t, 1, len(cloud.registered)) {
+		assert.Equal(t, "eni-2", cloud.registered[0].ServerId)
+	}
+	// the server without description which matches no endpoint is kept
+	if assert.Equal(t, 1, len(cloud.deregistered)) {
+		assert.Equal(t, "eni-3", cloud.deregistered[0].ServerId)
+	}
+}
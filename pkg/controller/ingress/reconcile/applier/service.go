@@ -48,15 +48,17 @@ func (m *defaultServiceManagerApplier) Apply(ctx context.Context, albProvider pr
 	matchedResAndSDKSGPs := serverGroupApplier.MatchedResAndSDKSGPs
 
 	var (
-		err     error
-		wg      sync.WaitGroup
-		chApply = make(chan struct{}, util.ServerGroupConcurrentNum)
+		err      error
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		draining bool
+		chApply  = make(chan struct{}, util.ServerGroupConcurrentNum)
 	)
 	for _, v := range matchedResAndSDKSGPs {
 		chApply <- struct{}{}
 		wg.Add(1)
 
-		go func(serverGroupID string, backends []albmodel.BackendItem) {
+		go func(serverGroupID string, backends []albmodel.BackendItem, drainTimeout int) {
 			util.RandomSleepFunc(util.ConcurrentMaxSleepMillisecondTime)
 
 			defer func() {
@@ -64,17 +66,26 @@ func (m *defaultServiceManagerApplier) Apply(ctx context.Context, albProvider pr
 				<-chApply
 			}()
 
-			serverApplier := NewServerApplier(m.kubeClient, albProvider, serverGroupID, backends, serviceStack.TrafficPolicy, m.logger)
+			serverApplier := NewServerApplier(m.kubeClient, albProvider, serverGroupID, backends, serviceStack.TrafficPolicy, drainTimeout, m.logger)
 			if errOnce := serverApplier.Apply(ctx); err == nil && errOnce != nil {
 				m.logger.Error(errOnce, "synthesize servers failed", "serverGroupID", serverGroupID)
 				err = errOnce
 			}
-		}(v.SdkSGP.ServerGroupId, v.ResSGP.Backends)
+			if serverApplier.draining {
+				mu.Lock()
+				draining = true
+				mu.Unlock()
+			}
+		}(v.SdkSGP.ServerGroupId, v.ResSGP.Backends, v.ResSGP.ConnectionDrainTimeout)
 	}
 	wg.Wait()
 	if err != nil {
 		return err
 	}
+	// retry until the draining backends are removed
+	if draining {
+		serviceStack.ContainsPotentialReadyEndpoints = true
+	}
 
 	return nil
 }
@@ -154,11 +165,15 @@ func transServiceStackToServerGroupsWithNameKey(serviceStack *albmodel.ServiceMa
 			}
 			albconfig := serviceStack.IngressAlbConfigMap[serviceStack.Namespace+"/"+ingressName]
 
-			serverGroups = append(serverGroups, albmodel.ServiceGroupWithNameKey{
-				NamedKey:     serverGroupNamedKey,
-				AlbConfigKey: albconfig,
-				Backends:     serverGroup.Backends,
-			})
+			// the ingress of a sharded albconfig may have server groups on any of the shards
+			for _, albconfigKey := range append([]string{albconfig}, serviceStack.AlbConfigShards[albconfig]...) {
+				serverGroups = append(serverGroups, albmodel.ServiceGroupWithNameKey{
+					NamedKey:               serverGroupNamedKey,
+					AlbConfigKey:           albconfigKey,
+					Backends:               serverGroup.Backends,
+					ConnectionDrainTimeout: serviceStack.IngressConnectionDrainTimeout[serviceStack.Namespace+"/"+ingressName],
+				})
+			}
 		}
 	}
 
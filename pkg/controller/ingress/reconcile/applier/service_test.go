@@ -0,0 +1,75 @@
+package applier
+
+import (
+	"context"
+	"testing"
+
+	albsdk "github.com/aliyun/alibaba-cloud-sdk-go/services/alb"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// serviceCloud returns the server groups of a Service and records the server groups the servers are registered to
+type serviceCloud struct {
+	serverCloud
+	serverGroups     []albmodel.ServerGroupWithTags
+	registeredGroups []string
+}
+
+func (c *serviceCloud) ListALBServerGroupsWithTags(ctx context.Context, tagFilters map[string]string) ([]albmodel.ServerGroupWithTags, error) {
+	return c.serverGroups, nil
+}
+
+func (c *serviceCloud) RegisterALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	c.registeredGroups = append(c.registeredGroups, serverGroupID)
+	return c.serverCloud.RegisterALBServers(ctx, serverGroupID, resServers)
+}
+
+func newServiceServerGroup(id, albconfig string) albmodel.ServerGroupWithTags {
+	sgp := albmodel.ServerGroupWithTags{Tags: map[string]string{
+		util.AlbConfigFullTagKey:    albconfig,
+		util.ClusterNameTagKey:      "cluster-1",
+		util.ServiceNamespaceTagKey: "default",
+		util.IngressNameTagKey:      "web",
+		util.ServiceNameTagKey:      "web",
+		util.ServicePortTagKey:      "80",
+	}}
+	sgp.ServerGroup = albsdk.ServerGroup{ServerGroupId: id}
+	return sgp
+}
+
+func TestServiceManagerApplierShardedAlbConfig(t *testing.T) {
+	cloud := &serviceCloud{serverGroups: []albmodel.ServerGroupWithTags{
+		newServiceServerGroup("sgp-shard-1", "kube-system/alb-shard-1"),
+		newServiceServerGroup("sgp-other", "kube-system/other"),
+	}}
+	serviceStack := &albmodel.ServiceManager{
+		ClusterID: "cluster-1",
+		Namespace: "default",
+		Name:      "web",
+		PortToServerGroup: map[int32]*albmodel.ServerGroupWithIngress{
+			80: {IngressNames: []string{"web"}, Backends: []albmodel.BackendItem{
+				{ServerId: "eni-1", ServerIp: "10.0.0.1", Port: 8080, Type: util.ServerTypeEni, Weight: util.DefaultServerWeight},
+			}},
+		},
+		IngressAlbConfigMap: map[string]string{"default/web": "kube-system/alb"},
+		AlbConfigShards:     map[string][]string{"kube-system/alb": {"kube-system/alb-shard-1"}},
+	}
+
+	// the ingress only has rules on the second shard of the albconfig
+	applier := NewServiceManagerApplier(fake.NewClientBuilder().Build(), cloud, logr.Discard())
+	assert.NoError(t, applier.Apply(context.TODO(), cloud, serviceStack))
+	assert.Equal(t, []string{"sgp-shard-1"}, cloud.registeredGroups)
+	if assert.Equal(t, 1, len(cloud.registered)) {
+		assert.Equal(t, "eni-1", cloud.registered[0].ServerId)
+	}
+
+	// the server groups of the shards are not synced once the albconfig is not sharded
+	*cloud = serviceCloud{serverGroups: cloud.serverGroups}
+	serviceStack.AlbConfigShards = nil
+	assert.NoError(t, applier.Apply(context.TODO(), cloud, serviceStack))
+	assert.Empty(t, cloud.registeredGroups)
+}
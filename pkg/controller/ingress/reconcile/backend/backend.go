@@ -40,16 +40,24 @@ func (mgr *Manager) BuildServicePortSDKBackends(ctx context.Context, svcKey type
 		return nil, false, err
 	}
 
+	policy, err := helper.GetALBServiceTrafficPolicy(svc)
+	if err != nil {
+		return nil, false, err
+	}
+	return mgr.BuildServicePortSDKBackendsWithPolicy(ctx, svc, port, policy)
+}
+
+// BuildServicePortSDKBackendsWithPolicy builds the backends of the service port by the given traffic policy
+// instead of the one of the service.
+func (mgr *Manager) BuildServicePortSDKBackendsWithPolicy(ctx context.Context, svc *v1.Service, port intstr.IntOrString, policy helper.TrafficPolicy) ([]alb.BackendItem, bool, error) {
 	var (
 		modelBackends                   []alb.BackendItem
 		endpoints                       []NodePortEndpoint
 		containsPotentialReadyEndpoints bool
+		err                             error
 	)
+	svcKey := util.NamespacedName(svc)
 
-	policy, err := helper.GetServiceTrafficPolicy(svc)
-	if err != nil {
-		return nil, false, err
-	}
 	switch policy {
 	case helper.ENITrafficPolicy:
 		endpoints, containsPotentialReadyEndpoints, err = mgr.ResolveENIEndpoints(ctx, util.NamespacedName(svc), port)
@@ -66,6 +74,11 @@ func (mgr *Manager) BuildServicePortSDKBackends(ctx context.Context, svcKey type
 		if err != nil {
 			return modelBackends, containsPotentialReadyEndpoints, err
 		}
+	case helper.IPTrafficPolicy:
+		endpoints, err = mgr.ResolveIPEndpoints(ctx, util.NamespacedName(svc), port)
+		if err != nil {
+			return modelBackends, containsPotentialReadyEndpoints, err
+		}
 	default:
 		return modelBackends, containsPotentialReadyEndpoints, fmt.Errorf("not supported traffic policy [%s]", policy)
 	}
@@ -73,6 +86,10 @@ func (mgr *Manager) BuildServicePortSDKBackends(ctx context.Context, svcKey type
 	for _, endpoint := range endpoints {
 		modelBackends = append(modelBackends, alb.BackendItem(endpoint))
 	}
+	if err := setBackendWeights(svc, modelBackends); err != nil {
+		return nil, containsPotentialReadyEndpoints, fmt.Errorf("set backend weights of service %s error: %s", svcKey.String(), err.Error())
+	}
+	setZoneBalancedWeights(svc, modelBackends)
 
 	return modelBackends, containsPotentialReadyEndpoints, nil
 }
@@ -3,9 +3,11 @@ package backend
 import (
 	"context"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
+	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
 
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/store"
@@ -18,10 +20,12 @@ import (
 	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
 	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
 	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/cache"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -37,6 +41,8 @@ type EndpointResolver interface {
 	ResolveLocalEndpoints(ctx context.Context, svcKey types.NamespacedName, port intstr.IntOrString) ([]NodePortEndpoint, bool, error)
 
 	ResolveClusterEndpoints(ctx context.Context, svcKey types.NamespacedName, port intstr.IntOrString) ([]NodePortEndpoint, bool, error)
+
+	ResolveIPEndpoints(ctx context.Context, svcKey types.NamespacedName, port intstr.IntOrString) ([]NodePortEndpoint, error)
 }
 
 type PodEndpoint struct {
@@ -44,6 +50,10 @@ type PodEndpoint struct {
 	Port     int
 	NodeName *string
 	Pod      *corev1.Pod
+	// Draining is true if the pod is terminating but still serving
+	Draining bool
+	// Zone is the zone of the node of the pod
+	Zone string
 }
 
 type NodePortEndpoint alb.BackendItem
@@ -57,6 +67,7 @@ func NewDefaultEndpointResolver(store store.Storer, k8sClient client.Client, clo
 		loadNodeMutex: &sync.Mutex{},
 		nodeCache:     cache.NewExpiring(),
 		nodeCacheTTL:  365 * 24 * time.Hour,
+		lookupIPAddr:  net.DefaultResolver.LookupIPAddr,
 	}
 }
 
@@ -70,6 +81,8 @@ type defaultEndpointResolver struct {
 	loadNodeMutex *sync.Mutex
 	nodeCache     *cache.Expiring
 	nodeCacheTTL  time.Duration
+	// lookupIPAddr resolves the external name of a Service
+	lookupIPAddr func(ctx context.Context, host string) ([]net.IPAddr, error)
 }
 
 func (r *defaultEndpointResolver) findServiceAndServicePort(ctx context.Context, svcKey types.NamespacedName, port intstr.IntOrString) (*corev1.Service, corev1.ServicePort, error) {
@@ -89,6 +102,9 @@ func (r *defaultEndpointResolver) findServiceAndServicePort(ctx context.Context,
 }
 
 func (r *defaultEndpointResolver) resolvePodEndpoints(ctx context.Context, svc *corev1.Service, svcPort corev1.ServicePort) ([]PodEndpoint, bool, error) {
+	if utilfeature.DefaultMutableFeatureGate.Enabled(ctrlCfg.EndpointSlice) {
+		return r.resolvePodEndpointsFromSlices(ctx, svc, svcPort)
+	}
 	epsKey := util.NamespacedName(svc)
 	eps := &corev1.Endpoints{}
 	if err := r.k8sClient.Get(ctx, epsKey, eps); err != nil {
@@ -146,6 +162,83 @@ func (r *defaultEndpointResolver) resolvePodEndpoints(ctx context.Context, svc *
 
 	return endpoints, containsPotentialReadyEndpoints, nil
 }
+
+// resolvePodEndpointsFromSlices resolves the pod endpoints from the EndpointSlices of the Service. Only the slices
+// of the primary ip family of the Service are used, so that a pod of a dual-stack Service is added once.
+func (r *defaultEndpointResolver) resolvePodEndpointsFromSlices(ctx context.Context, svc *corev1.Service, svcPort corev1.ServicePort) ([]PodEndpoint, bool, error) {
+	slices, err := r.store.ListServiceEndpointSlices(util.NamespacedName(svc).String())
+	if err != nil {
+		return nil, false, err
+	}
+	addressType := discovery.AddressTypeIPv4
+	if len(svc.Spec.IPFamilies) != 0 && svc.Spec.IPFamilies[0] == corev1.IPv6Protocol {
+		addressType = discovery.AddressTypeIPv6
+	}
+
+	var endpoints []PodEndpoint
+	containsPotentialReadyEndpoints := false
+	// the same endpoint may be in several slices during an update of the slices
+	resolved := make(map[string]bool)
+	for _, es := range slices {
+		if es.AddressType != addressType {
+			continue
+		}
+		var backendPort int
+		for _, p := range es.Ports {
+			name := ""
+			if p.Name != nil {
+				name = *p.Name
+			}
+			if name == svcPort.Name && p.Port != nil {
+				backendPort = int(*p.Port)
+				break
+			}
+		}
+
+		for _, ep := range es.Endpoints {
+			if ep.TargetRef == nil || ep.TargetRef.Kind != "Pod" || len(ep.Addresses) == 0 {
+				continue
+			}
+			if resolved[ep.Addresses[0]] {
+				continue
+			}
+			pod, err := r.findPodByReference(ctx, svc.Namespace, *ep.TargetRef)
+			if err != nil {
+				klog.Errorf("findPodByReference error: %s", err.Error())
+				return nil, false, err
+			}
+			// a nil ready condition means the endpoint is ready, a terminating endpoint is never ready
+			if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+				resolved[ep.Addresses[0]] = true
+				endpoints = append(endpoints, buildSlicePodEndpoint(ep, backendPort, pod))
+				continue
+			}
+			if ep.Conditions.Terminating != nil && *ep.Conditions.Terminating {
+				// a terminating pod still serving is drained before it is removed
+				if ep.Conditions.Serving != nil && *ep.Conditions.Serving {
+					resolved[ep.Addresses[0]] = true
+					endpoint := buildSlicePodEndpoint(ep, backendPort, pod)
+					endpoint.Draining = true
+					endpoints = append(endpoints, endpoint)
+				}
+				continue
+			}
+			// readiness gates
+			if !helper.IsPodHasReadinessGate(pod) {
+				continue
+			}
+			if !helper.IsPodContainersReady(pod) {
+				containsPotentialReadyEndpoints = true
+				continue
+			}
+			resolved[ep.Addresses[0]] = true
+			endpoints = append(endpoints, buildSlicePodEndpoint(ep, backendPort, pod))
+		}
+	}
+
+	return endpoints, containsPotentialReadyEndpoints, nil
+}
+
 func (r *defaultEndpointResolver) findPodByReference(ctx context.Context, namespace string, podRef corev1.ObjectReference) (*corev1.Pod, error) {
 	podKey := fmt.Sprintf("%s/%s", podRef.Namespace, podRef.Name)
 	return r.store.GetPod(podKey)
@@ -161,6 +254,7 @@ func (r *defaultEndpointResolver) ResolveENIEndpoints(ctx context.Context, svcKe
 		return nil, containsPotentialReadyEndpoints, err
 	}
 
+	r.setPodEndpointZones(ctx, podEndpoints)
 	eps, err := r.transPodEndpointsToEnis(podEndpoints)
 	if err != nil {
 		return nil, containsPotentialReadyEndpoints, err
@@ -168,6 +262,148 @@ func (r *defaultEndpointResolver) ResolveENIEndpoints(ctx context.Context, svcKe
 	return eps, containsPotentialReadyEndpoints, nil
 }
 
+// ResolveIPEndpoints resolves the addresses of a Service without selector by its Endpoints, or the ones of an
+// ExternalName Service by its external name. The addresses are registered to an Ip server group.
+func (r *defaultEndpointResolver) ResolveIPEndpoints(ctx context.Context, svcKey types.NamespacedName, port intstr.IntOrString) ([]NodePortEndpoint, error) {
+	svc, svcPort, err := r.findServiceAndServicePort(ctx, svcKey, port)
+	if err != nil {
+		return nil, err
+	}
+
+	var ipEndpoints []PodEndpoint
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		ipEndpoints, err = r.resolveExternalNameEndpoints(ctx, svc, svcPort)
+	} else {
+		ipEndpoints, err = r.resolveAddressEndpoints(ctx, svc, svcPort)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var eps []NodePortEndpoint
+	for _, ep := range ipEndpoints {
+		eps = append(eps, buildNodePortEndpoint(ep.IP, ep.IP, ep.Port, alb.IPBackendType, util.DefaultServerWeight, nil))
+	}
+	return eps, nil
+}
+
+// resolveAddressEndpoints resolves the ready addresses of the manually managed Endpoints of a Service, the
+// addresses are not required to reference pods.
+func (r *defaultEndpointResolver) resolveAddressEndpoints(ctx context.Context, svc *corev1.Service, svcPort corev1.ServicePort) ([]PodEndpoint, error) {
+	var endpoints []PodEndpoint
+	resolved := make(map[string]bool)
+	add := func(ip string, port int) {
+		key := fmt.Sprintf("%s:%d", ip, port)
+		if port == 0 || resolved[key] {
+			return
+		}
+		resolved[key] = true
+		endpoints = append(endpoints, PodEndpoint{IP: ip, Port: port})
+	}
+
+	if utilfeature.DefaultMutableFeatureGate.Enabled(ctrlCfg.EndpointSlice) {
+		slices, err := r.store.ListServiceEndpointSlices(util.NamespacedName(svc).String())
+		if err != nil {
+			return nil, err
+		}
+		for _, es := range slices {
+			if es.AddressType != discovery.AddressTypeIPv4 {
+				continue
+			}
+			var backendPort int
+			for _, p := range es.Ports {
+				name := ""
+				if p.Name != nil {
+					name = *p.Name
+				}
+				if name == svcPort.Name && p.Port != nil {
+					backendPort = int(*p.Port)
+					break
+				}
+			}
+			for _, ep := range es.Endpoints {
+				if len(ep.Addresses) == 0 || ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+					continue
+				}
+				add(ep.Addresses[0], backendPort)
+			}
+		}
+		return endpoints, nil
+	}
+
+	eps := &corev1.Endpoints{}
+	if err := r.k8sClient.Get(ctx, util.NamespacedName(svc), eps); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, subset := range eps.Subsets {
+		var backendPort int
+		for _, p := range subset.Ports {
+			if p.Name == svcPort.Name {
+				backendPort = int(p.Port)
+				break
+			}
+		}
+		for _, addr := range subset.Addresses {
+			add(addr.IP, backendPort)
+		}
+	}
+	return endpoints, nil
+}
+
+// resolveExternalNameEndpoints resolves the IPv4 addresses of the external name of a Service. The backend port is
+// the numeric target port of the service port, or the service port itself.
+func (r *defaultEndpointResolver) resolveExternalNameEndpoints(ctx context.Context, svc *corev1.Service, svcPort corev1.ServicePort) ([]PodEndpoint, error) {
+	port := svcPort.TargetPort.IntValue()
+	if port == 0 {
+		port = int(svcPort.Port)
+	}
+
+	var ips []net.IP
+	if ip := net.ParseIP(svc.Spec.ExternalName); ip != nil {
+		ips = append(ips, ip)
+	} else {
+		addrs, err := r.lookupIPAddr(ctx, svc.Spec.ExternalName)
+		if err != nil {
+			return nil, fmt.Errorf("resolve external name %s of service %s: %w", svc.Spec.ExternalName, util.Key(svc), err)
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	var endpoints []PodEndpoint
+	for _, ip := range ips {
+		if ip.To4() == nil {
+			continue
+		}
+		endpoints = append(endpoints, PodEndpoint{IP: ip.String(), Port: port})
+	}
+	return endpoints, nil
+}
+
+// setPodEndpointZones sets the zones of the pod endpoints by the nodes of the pods, the zone is left empty if the
+// node is not found
+func (r *defaultEndpointResolver) setPodEndpointZones(ctx context.Context, podEndpoints []PodEndpoint) {
+	zones := make(map[string]string)
+	for i := range podEndpoints {
+		if podEndpoints[i].NodeName == nil {
+			continue
+		}
+		name := *podEndpoints[i].NodeName
+		if _, ok := zones[name]; !ok {
+			node := &corev1.Node{}
+			if err := r.k8sClient.Get(ctx, types.NamespacedName{Name: name}, node); err != nil {
+				r.logger.Info("get node of pod endpoint failed", "node", name, "error", err.Error())
+			}
+			zones[name] = helper.GetNodeZone(node)
+		}
+		podEndpoints[i].Zone = zones[name]
+	}
+}
+
 func (r *defaultEndpointResolver) ResolveLocalEndpoints(ctx context.Context, svcKey types.NamespacedName, port intstr.IntOrString) ([]NodePortEndpoint, bool, error) {
 	svc, svcPort, err := r.findServiceAndServicePort(ctx, svcKey, port)
 	if err != nil {
@@ -197,6 +433,10 @@ func (r *defaultEndpointResolver) ResolveLocalEndpoints(ctx context.Context, svc
 	eciEndpoints := make([]PodEndpoint, 0)
 
 	for _, podEndPoint := range podEndPoints {
+		// only the eni backends are drained
+		if podEndPoint.Draining {
+			continue
+		}
 		if podEndPoint.NodeName == nil {
 			return nil, containsPotentialReadyEndpoints, errors.New("empty node name")
 		}
@@ -206,6 +446,7 @@ func (r *defaultEndpointResolver) ResolveLocalEndpoints(ctx context.Context, svc
 			continue
 		}
 
+		podEndPoint.Zone = helper.GetNodeZone(&node)
 		if node.Labels["type"] == util.LabelNodeTypeVK {
 			eciEndpoints = append(eciEndpoints, podEndPoint)
 			continue
@@ -220,7 +461,9 @@ func (r *defaultEndpointResolver) ResolveLocalEndpoints(ctx context.Context, svc
 			return nil, containsPotentialReadyEndpoints, err
 		}
 
-		ecsEndpoints = append(ecsEndpoints, buildNodePortEndpoint(id, "", int(svcNodePort), alb.ECSBackendType, util.DefaultServerWeight, podEndPoint.Pod))
+		endpoint := buildNodePortEndpoint(id, "", int(svcNodePort), alb.ECSBackendType, util.DefaultServerWeight, podEndPoint.Pod)
+		endpoint.Zone = podEndPoint.Zone
+		ecsEndpoints = append(ecsEndpoints, endpoint)
 	}
 
 	if len(eciEndpoints) != 0 {
@@ -268,11 +511,17 @@ func (r *defaultEndpointResolver) ResolveClusterEndpoints(ctx context.Context, s
 			return nil, containsPotentialReadyEndpoints, err
 		}
 
-		ecsEndpoints = append(ecsEndpoints, buildNodePortEndpoint(id, "", int(svcNodePort), alb.ECSBackendType, util.DefaultServerWeight, nil))
+		endpoint := buildNodePortEndpoint(id, "", int(svcNodePort), alb.ECSBackendType, util.DefaultServerWeight, nil)
+		endpoint.Zone = helper.GetNodeZone(&node)
+		ecsEndpoints = append(ecsEndpoints, endpoint)
 	}
 
 	eciEndpoints := make([]PodEndpoint, 0)
 	for _, podEndPoint := range podEndPoints {
+		// only the eni backends are drained
+		if podEndPoint.Draining {
+			continue
+		}
 		if podEndPoint.NodeName == nil {
 			return nil, containsPotentialReadyEndpoints, errors.New("empty node name")
 		}
@@ -283,6 +532,7 @@ func (r *defaultEndpointResolver) ResolveClusterEndpoints(ctx context.Context, s
 		}
 
 		if node.Labels["type"] == util.LabelNodeTypeVK {
+			podEndPoint.Zone = helper.GetNodeZone(&node)
 			eciEndpoints = append(eciEndpoints, podEndPoint)
 		}
 	}
@@ -351,11 +601,16 @@ func (r *defaultEndpointResolver) transPodEndpointsToEnis(backends []PodEndpoint
 	}
 
 	var ips []string
+	ipVersion := pkgModel.IPv4
 	for _, b := range backends {
 		ips = append(ips, b.IP)
+		// the endpoints of a service are of its primary ip family
+		if ip := net.ParseIP(b.IP); ip != nil && ip.To4() == nil {
+			ipVersion = pkgModel.IPv6
+		}
 	}
 
-	result, err := r.cloud.DescribeNetworkInterfaces(vpcId, ips, pkgModel.IPv4)
+	result, err := r.cloud.DescribeNetworkInterfaces(vpcId, ips, ipVersion)
 	if err != nil {
 		return nil, fmt.Errorf("call DescribeNetworkInterfaces: %s", err.Error())
 	}
@@ -366,7 +621,13 @@ func (r *defaultEndpointResolver) transPodEndpointsToEnis(backends []PodEndpoint
 			return nil, fmt.Errorf("can not find eniid for ip %s in vpc %s", backends[i].IP, vpcId)
 		}
 		// for ENI backend type, port should be set to targetPort (default value), no need to update
-		nodePortEndpoints = append(nodePortEndpoints, buildNodePortEndpoint(eniid, backends[i].IP, backends[i].Port, alb.ENIBackendType, util.DefaultServerWeight, backends[i].Pod))
+		endpoint := buildNodePortEndpoint(eniid, backends[i].IP, backends[i].Port, alb.ENIBackendType, util.DefaultServerWeight, backends[i].Pod)
+		endpoint.Zone = backends[i].Zone
+		if backends[i].Draining {
+			endpoint.Weight = 0
+			endpoint.Draining = true
+		}
+		nodePortEndpoints = append(nodePortEndpoints, endpoint)
 	}
 
 	return nodePortEndpoints, nil
@@ -382,6 +643,15 @@ func buildPodEndpoint(epAddr corev1.EndpointAddress, port int, pod *corev1.Pod)
 	}
 }
 
+func buildSlicePodEndpoint(ep discovery.Endpoint, port int, pod *corev1.Pod) PodEndpoint {
+	return PodEndpoint{
+		IP:       ep.Addresses[0],
+		Port:     port,
+		NodeName: ep.NodeName,
+		Pod:      pod,
+	}
+}
+
 func buildNodePortEndpoint(instanceID string, serverIP string, port int, tp string, weight int, pod *corev1.Pod) NodePortEndpoint {
 	nodePortEndpoint := NodePortEndpoint{
 		ServerId: instanceID,
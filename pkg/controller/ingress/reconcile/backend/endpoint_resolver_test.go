@@ -0,0 +1,171 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/store"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// sliceStore serves the EndpointSlices and the pods of the resolver
+type sliceStore struct {
+	store.Storer
+	slices []*discovery.EndpointSlice
+	pods   map[string]*corev1.Pod
+}
+
+func (s sliceStore) ListServiceEndpointSlices(key string) ([]*discovery.EndpointSlice, error) {
+	return s.slices, nil
+}
+
+func (s sliceStore) GetPod(key string) (*corev1.Pod, error) {
+	if pod, ok := s.pods[key]; ok {
+		return pod, nil
+	}
+	return nil, store.NotExistsError(key)
+}
+
+func TestResolvePodEndpointsFromSlices(t *testing.T) {
+	gate := []corev1.PodReadinessGate{{ConditionType: helper.BuildReadinessGatePodConditionType()}}
+	containersReady := []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionTrue}}
+	pods := map[string]*corev1.Pod{
+		"default/ready":         {},
+		"default/terminating":   {Spec: corev1.PodSpec{ReadinessGates: gate}, Status: corev1.PodStatus{Conditions: containersReady}},
+		"default/gated":         {Spec: corev1.PodSpec{ReadinessGates: gate}, Status: corev1.PodStatus{Conditions: containersReady}},
+		"default/gated-pending": {Spec: corev1.PodSpec{ReadinessGates: gate}},
+		"default/not-ready":     {},
+		"default/draining":      {},
+	}
+	endpoint := func(ip, pod string, ready, terminating bool) discovery.Endpoint {
+		return discovery.Endpoint{
+			Addresses:  []string{ip},
+			NodeName:   pointer.String("node"),
+			Conditions: discovery.EndpointConditions{Ready: &ready, Terminating: &terminating},
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod},
+		}
+	}
+	ports := []discovery.EndpointPort{
+		{Name: pointer.String("metrics"), Port: pointer.Int32(9090)},
+		{Name: pointer.String("http"), Port: pointer.Int32(8080)},
+	}
+	draining := endpoint("10.0.0.6", "draining", false, true)
+	draining.Conditions.Serving = pointer.Bool(true)
+	slices := []*discovery.EndpointSlice{
+		{
+			ObjectMeta:  metav1.ObjectMeta{Namespace: "default", Name: "web-ipv4-a"},
+			AddressType: discovery.AddressTypeIPv4,
+			Ports:       ports,
+			Endpoints: []discovery.Endpoint{
+				endpoint("10.0.0.1", "ready", true, false),
+				endpoint("10.0.0.2", "terminating", false, true),
+				endpoint("10.0.0.3", "gated", false, false),
+				endpoint("10.0.0.4", "gated-pending", false, false),
+				endpoint("10.0.0.5", "not-ready", false, false),
+				draining,
+			},
+		},
+		{
+			// the endpoint is duplicated in another slice while the slices are updated
+			ObjectMeta:  metav1.ObjectMeta{Namespace: "default", Name: "web-ipv4-b"},
+			AddressType: discovery.AddressTypeIPv4,
+			Ports:       ports,
+			Endpoints:   []discovery.Endpoint{endpoint("10.0.0.1", "ready", true, false)},
+		},
+		{
+			ObjectMeta:  metav1.ObjectMeta{Namespace: "default", Name: "web-ipv6"},
+			AddressType: discovery.AddressTypeIPv6,
+			Ports:       ports,
+			Endpoints:   []discovery.Endpoint{endpoint("fd00::1", "ready", true, false)},
+		},
+	}
+	r := &defaultEndpointResolver{store: sliceStore{slices: slices, pods: pods}, logger: logr.Discard()}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+	svcPort := corev1.ServicePort{Name: "http", Port: 80}
+
+	endpoints, potential, err := r.resolvePodEndpointsFromSlices(context.TODO(), svc, svcPort)
+	assert.NoError(t, err)
+	assert.True(t, potential)
+	var ips []string
+	for _, ep := range endpoints {
+		ips = append(ips, ep.IP)
+		assert.Equal(t, 8080, ep.Port)
+		// the terminating endpoint still serving is drained
+		assert.Equal(t, ep.IP == "10.0.0.6", ep.Draining)
+	}
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.3", "10.0.0.6"}, ips)
+
+	// the primary ip family of a dual-stack service
+	svc.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}
+	endpoints, _, err = r.resolvePodEndpointsFromSlices(context.TODO(), svc, svcPort)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(endpoints)) {
+		assert.Equal(t, "fd00::1", endpoints[0].IP)
+	}
+}
+
+func TestResolveIPEndpoints(t *testing.T) {
+	onPrem := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "on-prem"},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Name: "http", Port: 80},
+			{Name: "metrics", Port: 9090},
+		}},
+	}
+	onPremEndpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "on-prem"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses:         []corev1.EndpointAddress{{IP: "172.16.0.1"}, {IP: "172.16.0.2"}},
+			NotReadyAddresses: []corev1.EndpointAddress{{IP: "172.16.0.3"}},
+			Ports:             []corev1.EndpointPort{{Name: "metrics", Port: 19090}, {Name: "http", Port: 8080}},
+		}},
+	}
+	externalService := func(name, externalName string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: corev1.ServiceSpec{
+				Type:         corev1.ServiceTypeExternalName,
+				ExternalName: externalName,
+				Ports:        []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8443)}},
+			},
+		}
+	}
+	r := &defaultEndpointResolver{
+		k8sClient: fake.NewClientBuilder().WithObjects(onPrem, onPremEndpoints,
+			externalService("legacy", "192.168.10.1"), externalService("dc", "api.dc.example.com")).Build(),
+		logger: logr.Discard(),
+		lookupIPAddr: func(ctx context.Context, host string) ([]net.IPAddr, error) {
+			return []net.IPAddr{{IP: net.ParseIP("10.10.0.1")}, {IP: net.ParseIP("fd00::1")}}, nil
+		},
+	}
+	resolve := func(name string, port intstr.IntOrString) []string {
+		eps, err := r.ResolveIPEndpoints(context.TODO(), types.NamespacedName{Namespace: "default", Name: name}, port)
+		assert.NoError(t, err)
+		var servers []string
+		for _, ep := range eps {
+			assert.Equal(t, alb.IPBackendType, ep.Type)
+			assert.Equal(t, ep.ServerIp, ep.ServerId)
+			servers = append(servers, fmt.Sprintf("%s:%d", ep.ServerIp, ep.Port))
+		}
+		return servers
+	}
+
+	// the ready addresses of the manual endpoints, they don't reference pods
+	assert.Equal(t, []string{"172.16.0.1:8080", "172.16.0.2:8080"}, resolve("on-prem", intstr.FromInt(80)))
+	assert.Equal(t, []string{"172.16.0.1:19090", "172.16.0.2:19090"}, resolve("on-prem", intstr.FromString("metrics")))
+	// the external name is an address, or a name of IPv4 addresses
+	assert.Equal(t, []string{"192.168.10.1:8443"}, resolve("legacy", intstr.FromInt(80)))
+	assert.Equal(t, []string{"10.10.0.1:8443"}, resolve("dc", intstr.FromInt(80)))
+}
@@ -0,0 +1,130 @@
+package backend
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+// the weight policies of the pod backends set by the annotations.AlbBackendWeightPolicy annotation of the Service
+const (
+	// PodAnnotationWeightPolicy uses the annotations.AlbBackendWeight annotation of the pod
+	PodAnnotationWeightPolicy = "pod-annotation"
+	// CPURequestWeightPolicy weights the pods by their cpu requests, the pod requesting the most cpu gets the
+	// default weight
+	CPURequestWeightPolicy = "cpu-request"
+	// PodPercentWeightPolicy shares the annotations.AlbBackendWeight annotation of the Service equally among the pods
+	PodPercentWeightPolicy = "pod-percent"
+)
+
+// setBackendWeights sets the weight of the pod backends by the weight policy of the Service. The node backends and
+// the draining backends keep their weight.
+func setBackendWeights(svc *v1.Service, backends []alb.BackendItem) error {
+	policy, ok := svc.Annotations[annotations.AlbBackendWeightPolicy]
+	if !ok {
+		return nil
+	}
+	var pods []int
+	for i := range backends {
+		if backends[i].Type == alb.ENIBackendType && backends[i].Pod != nil && !backends[i].Draining {
+			pods = append(pods, i)
+		}
+	}
+	if len(pods) == 0 {
+		return nil
+	}
+
+	switch policy {
+	case PodAnnotationWeightPolicy:
+		for _, i := range pods {
+			weight, err := parseWeight(backends[i].Pod.Annotations, util.DefaultServerWeight)
+			if err != nil {
+				return fmt.Errorf("pod %s/%s: %s", backends[i].Pod.Namespace, backends[i].Pod.Name, err.Error())
+			}
+			backends[i].Weight = weight
+		}
+	case CPURequestWeightPolicy:
+		var maxCPU int64
+		for _, i := range pods {
+			if cpu := podCPURequest(backends[i].Pod); cpu > maxCPU {
+				maxCPU = cpu
+			}
+		}
+		if maxCPU == 0 {
+			return nil
+		}
+		for _, i := range pods {
+			weight := int(podCPURequest(backends[i].Pod) * int64(util.DefaultServerWeight) / maxCPU)
+			if weight < 1 {
+				weight = 1
+			}
+			backends[i].Weight = weight
+		}
+	case PodPercentWeightPolicy:
+		total, err := parseWeight(svc.Annotations, util.DefaultServerWeight)
+		if err != nil {
+			return err
+		}
+		per := 0
+		if total != 0 {
+			per = total / len(pods)
+			if per < 1 {
+				per = 1
+			}
+		}
+		for _, i := range pods {
+			backends[i].Weight = per
+		}
+	default:
+		return fmt.Errorf("unknown backend weight policy [%s]", policy)
+	}
+	return nil
+}
+
+// setZoneBalancedWeights balances the weights of the backends by their zones if the Service enables the
+// annotations.AlbTopologyAwareWeight annotation, so that every zone receives the same share of the traffic. The
+// draining backends keep weight 0.
+func setZoneBalancedWeights(svc *v1.Service, backends []alb.BackendItem) {
+	if svc.Annotations[annotations.AlbTopologyAwareWeight] != "true" {
+		return
+	}
+	var (
+		weights []int
+		zones   []string
+	)
+	for _, b := range backends {
+		weights = append(weights, b.Weight)
+		zones = append(zones, b.Zone)
+	}
+	for i, w := range helper.BalanceWeightsByZone(weights, zones, util.DefaultServerWeight) {
+		backends[i].Weight = w
+	}
+}
+
+func parseWeight(anno map[string]string, defaultWeight int) (int, error) {
+	v, ok := anno[annotations.AlbBackendWeight]
+	if !ok {
+		return defaultWeight, nil
+	}
+	weight, err := strconv.Atoi(v)
+	if err != nil || weight < 0 || weight > 100 {
+		return 0, fmt.Errorf("invalid backend weight [%s], it must be within [0, 100]", v)
+	}
+	return weight, nil
+}
+
+// podCPURequest returns the cpu requests of the containers of the pod in millicores
+func podCPURequest(pod *v1.Pod) int64 {
+	var cpu int64
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Requests[v1.ResourceCPU]; ok {
+			cpu += q.MilliValue()
+		}
+	}
+	return cpu
+}
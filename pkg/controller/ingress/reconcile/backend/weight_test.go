@@ -0,0 +1,104 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetBackendWeights(t *testing.T) {
+	pod := func(cpu string, anno map[string]string) *v1.Pod {
+		p := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: anno}}
+		if cpu != "" {
+			p.Spec.Containers = []v1.Container{{Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+			}}}
+		}
+		return p
+	}
+	backends := func() []alb.BackendItem {
+		return []alb.BackendItem{
+			{Type: alb.ENIBackendType, Weight: util.DefaultServerWeight, Pod: pod("2", map[string]string{annotations.AlbBackendWeight: "20"})},
+			{Type: alb.ENIBackendType, Weight: util.DefaultServerWeight, Pod: pod("500m", nil)},
+			{Type: alb.ENIBackendType, Weight: util.DefaultServerWeight, Pod: pod("", nil)},
+			// the node and the draining backends keep their weight
+			{Type: alb.ECSBackendType, Weight: util.DefaultServerWeight},
+			{Type: alb.ENIBackendType, Weight: 0, Pod: pod("1", nil), Draining: true},
+		}
+	}
+	weights := func(items []alb.BackendItem) []int {
+		var ret []int
+		for _, item := range items {
+			ret = append(ret, item.Weight)
+		}
+		return ret
+	}
+	svc := func(anno map[string]string) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: anno}}
+	}
+
+	cases := []struct {
+		name    string
+		anno    map[string]string
+		weights []int
+		err     bool
+	}{
+		{name: "no policy", weights: []int{100, 100, 100, 100, 0}},
+		{name: "pod annotation", anno: map[string]string{annotations.AlbBackendWeightPolicy: PodAnnotationWeightPolicy},
+			weights: []int{20, 100, 100, 100, 0}},
+		{name: "cpu request", anno: map[string]string{annotations.AlbBackendWeightPolicy: CPURequestWeightPolicy},
+			weights: []int{100, 25, 1, 100, 0}},
+		{name: "pod percent", anno: map[string]string{annotations.AlbBackendWeightPolicy: PodPercentWeightPolicy, annotations.AlbBackendWeight: "60"},
+			weights: []int{20, 20, 20, 100, 0}},
+		{name: "invalid weight", anno: map[string]string{annotations.AlbBackendWeightPolicy: PodPercentWeightPolicy, annotations.AlbBackendWeight: "101"},
+			err: true},
+		{name: "unknown policy", anno: map[string]string{annotations.AlbBackendWeightPolicy: "random"}, err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			items := backends()
+			err := setBackendWeights(svc(c.anno), items)
+			if c.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.weights, weights(items))
+		})
+	}
+}
+
+func TestSetZoneBalancedWeights(t *testing.T) {
+	backends := func() []alb.BackendItem {
+		return []alb.BackendItem{
+			{Type: alb.ENIBackendType, Weight: util.DefaultServerWeight, Zone: "cn-hangzhou-a"},
+			{Type: alb.ENIBackendType, Weight: util.DefaultServerWeight, Zone: "cn-hangzhou-b"},
+			{Type: alb.ENIBackendType, Weight: util.DefaultServerWeight, Zone: "cn-hangzhou-b"},
+			{Type: alb.ENIBackendType, Weight: util.DefaultServerWeight, Zone: "cn-hangzhou-b"},
+			{Type: alb.ENIBackendType, Weight: 0, Zone: "cn-hangzhou-a", Draining: true},
+		}
+	}
+	weights := func(items []alb.BackendItem) []int {
+		var ret []int
+		for _, item := range items {
+			ret = append(ret, item.Weight)
+		}
+		return ret
+	}
+
+	items := backends()
+	setZoneBalancedWeights(&v1.Service{}, items)
+	assert.Equal(t, []int{100, 100, 100, 100, 0}, weights(items))
+
+	items = backends()
+	setZoneBalancedWeights(&v1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		annotations.AlbTopologyAwareWeight: "true",
+	}}}, items)
+	assert.Equal(t, []int{100, 33, 33, 33, 0}, weights(items))
+}
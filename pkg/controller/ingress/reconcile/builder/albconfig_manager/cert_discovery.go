@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
 	"k8s.io/alibaba-load-balancer-controller/pkg/util"
 
 	"k8s.io/klog/v2"
@@ -46,7 +47,7 @@ func (d *casCertDiscovery) Discover(ctx context.Context, tlsHosts []string) ([]s
 		var certIDsForHost []string
 		for certID, domains := range domainsByCertID {
 			for domain := range domains {
-				if d.domainMatchesHost(domain, host) {
+				if domainMatchesHost(domain, host) {
 					certIDsForHost = append(certIDsForHost, certID)
 					break
 				}
@@ -78,7 +79,12 @@ func (d *casCertDiscovery) loadDomainsForAllCertificates(ctx context.Context) (m
 	return domainsByCertID, nil
 }
 
-func (d *casCertDiscovery) domainMatchesHost(domainName string, tlsHost string) bool {
+// CertificateMatchesHost returns true if the common name or a SAN of the certificate matches the host.
+func CertificateMatchesHost(cert model.CertificateInfo, host string) bool {
+	return domainMatchesHost(cert.CommonName, host) || domainMatchesHost(cert.Sans, host)
+}
+
+func domainMatchesHost(domainName string, tlsHost string) bool {
 	isMatch := false
 	domains := strings.Split(domainName, ",")
 	lower_host := strings.ToLower(tlsHost)
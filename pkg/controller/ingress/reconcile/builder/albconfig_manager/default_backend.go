@@ -0,0 +1,104 @@
+package albconfigmanager
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultBackendConflict is an Ingress whose spec.defaultBackend is ignored, because another Ingress before it in
+// the group order sets another default backend.
+type DefaultBackendConflict struct {
+	Ingress    *networking.Ingress
+	SelectedBy *networking.Ingress
+}
+
+func (c DefaultBackendConflict) String() string {
+	return fmt.Sprintf("defaultBackend %s of ingress %s is ignored, the listeners use defaultBackend %s of ingress %s",
+		defaultBackendString(c.Ingress), util.Key(c.Ingress), defaultBackendString(c.SelectedBy), util.Key(c.SelectedBy))
+}
+
+func defaultBackendString(ing *networking.Ingress) string {
+	svc := ing.Spec.DefaultBackend.Service
+	return fmt.Sprintf("%s:%d", svc.Name, svc.Port.Number)
+}
+
+// SelectDefaultBackend returns the member whose spec.defaultBackend is used by the listeners of the group, which
+// is the first member in the group order setting a Service backend, nil if there is none. The members setting
+// another backend are returned as conflicts. Canary ingresses are ignored.
+func SelectDefaultBackend(members []*networking.Ingress) (*networking.Ingress, []DefaultBackendConflict) {
+	var selected *networking.Ingress
+	var conflicts []DefaultBackendConflict
+	for _, member := range members {
+		if member.Spec.DefaultBackend == nil || member.Spec.DefaultBackend.Service == nil {
+			continue
+		}
+		if annotations.GetStringAnnotationMutil(annotations.NginxCanary, annotations.AlbCanary, member) == "true" {
+			continue
+		}
+		if selected == nil {
+			selected = member
+			continue
+		}
+		if member.Namespace != selected.Namespace || defaultBackendString(member) != defaultBackendString(selected) {
+			conflicts = append(conflicts, DefaultBackendConflict{Ingress: member, SelectedBy: selected})
+		}
+	}
+	return selected, conflicts
+}
+
+// AlbConfigDefaultBackendService returns the Service of spec.defaultBackend of the albconfig, nil if it is not set
+func AlbConfigDefaultBackendService(albconfig *v1.AlbConfig) *types.NamespacedName {
+	backend := albconfig.Spec.DefaultBackend
+	if backend == nil || backend.ServiceName == "" {
+		return nil
+	}
+	namespace := backend.Namespace
+	if namespace == "" {
+		namespace = albConfigNamespace(albconfig)
+	}
+	return &types.NamespacedName{Namespace: namespace, Name: backend.ServiceName}
+}
+
+// albConfigNamespace returns the namespace of the albconfig, kube-system if it is cluster scoped
+func albConfigNamespace(albconfig *v1.AlbConfig) string {
+	if albconfig.Namespace == "" {
+		return ALBConfigNamespace
+	}
+	return albconfig.Namespace
+}
+
+// AlbConfigDefaultBackendIngressName returns the name of the ingress owning the server group of spec.defaultBackend
+// of the albconfig. The ingress doesn't exist, it names the server group in the namespace of the Service, and it is
+// not a valid Ingress name so that it never refers to the server groups of an Ingress.
+func AlbConfigDefaultBackendIngressName(albconfig *v1.AlbConfig) string {
+	return albconfig.Name + util.DefaultBackendFlag
+}
+
+// resolveDefaultBackend returns the ingress owning the server group of the default backend of the listeners and
+// the backend, nil if neither the members nor the albconfig set a default backend. A default backend of the
+// albconfig in another namespace must be allowed by a ServiceReferenceGrant of that namespace.
+func (t *defaultModelBuildTask) resolveDefaultBackend(ctx context.Context) (*networking.Ingress, *networking.IngressServiceBackend, error) {
+	if ing, _ := SelectDefaultBackend(t.ingGroup.Members); ing != nil {
+		return ing, ing.Spec.DefaultBackend.Service, nil
+	}
+	svcKey := AlbConfigDefaultBackendService(t.albconfig)
+	if svcKey == nil {
+		return nil, nil, nil
+	}
+	ing := new(networking.Ingress)
+	ing.Namespace = svcKey.Namespace
+	ing.Name = AlbConfigDefaultBackendIngressName(t.albconfig)
+	if err := t.checkServiceReference(ctx, v1.ServiceReferenceGrantFromAlbConfig, albConfigNamespace(t.albconfig), *svcKey); err != nil {
+		return ing, nil, err
+	}
+	return ing, &networking.IngressServiceBackend{
+		Name: svcKey.Name,
+		Port: networking.ServiceBackendPort{Number: t.albconfig.Spec.DefaultBackend.ServicePort},
+	}, nil
+}
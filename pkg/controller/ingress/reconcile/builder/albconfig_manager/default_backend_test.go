@@ -0,0 +1,120 @@
+package albconfigmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func defaultBackendIngress(name, svcName string, port int32) *networking.Ingress {
+	ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	if svcName != "" {
+		ing.Spec.DefaultBackend = &networking.IngressBackend{
+			Service: &networking.IngressServiceBackend{Name: svcName, Port: networking.ServiceBackendPort{Number: port}},
+		}
+	}
+	return ing
+}
+
+func TestSelectDefaultBackend(t *testing.T) {
+	selected, conflicts := SelectDefaultBackend([]*networking.Ingress{defaultBackendIngress("a", "", 0)})
+	assert.Nil(t, selected)
+	assert.Empty(t, conflicts)
+
+	canary := defaultBackendIngress("canary", "canary-svc", 80)
+	canary.Annotations = map[string]string{annotations.AlbCanary: "true"}
+	first := defaultBackendIngress("b", "web", 80)
+	same := defaultBackendIngress("c", "web", 80)
+	otherPort := defaultBackendIngress("d", "web", 8080)
+	other := defaultBackendIngress("e", "api", 80)
+	selected, conflicts = SelectDefaultBackend([]*networking.Ingress{
+		defaultBackendIngress("a", "", 0), canary, first, same, otherPort, other,
+	})
+	assert.Equal(t, first, selected)
+	if assert.Equal(t, 2, len(conflicts)) {
+		assert.Equal(t, otherPort, conflicts[0].Ingress)
+		assert.Equal(t, first, conflicts[0].SelectedBy)
+		assert.Equal(t, other, conflicts[1].Ingress)
+		assert.Equal(t, "defaultBackend api:80 of ingress default/e is ignored, the listeners use defaultBackend web:80 of ingress default/b",
+			conflicts[1].String())
+	}
+}
+
+func TestAlbConfigDefaultBackendService(t *testing.T) {
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "alb"}}
+	assert.Nil(t, AlbConfigDefaultBackendService(albconfig))
+
+	albconfig.Spec.DefaultBackend = &v1.DefaultBackendSpec{ServiceName: "fallback", ServicePort: 80}
+	assert.Equal(t, &types.NamespacedName{Namespace: ALBConfigNamespace, Name: "fallback"}, AlbConfigDefaultBackendService(albconfig))
+
+	albconfig.Namespace = "infra"
+	assert.Equal(t, &types.NamespacedName{Namespace: "infra", Name: "fallback"}, AlbConfigDefaultBackendService(albconfig))
+
+	albconfig.Spec.DefaultBackend.Namespace = "default"
+	assert.Equal(t, &types.NamespacedName{Namespace: "default", Name: "fallback"}, AlbConfigDefaultBackendService(albconfig))
+	assert.Equal(t, "alb:default-backend", AlbConfigDefaultBackendIngressName(albconfig))
+	// the name is not a valid Ingress name, an Ingress named after the albconfig doesn't share the server group
+	assert.NotEmpty(t, validation.IsDNS1123Subdomain(AlbConfigDefaultBackendIngressName(albconfig)))
+}
+
+func TestBuildLsDefaultAction(t *testing.T) {
+	newTask := func(albconfig *v1.AlbConfig, members ...*networking.Ingress) *defaultModelBuildTask {
+		return &defaultModelBuildTask{
+			stack:                core.NewDefaultManager(core.StackID{Namespace: ALBConfigNamespace, Name: albconfig.Name}),
+			albconfig:            albconfig,
+			ingGroup:             &Group{Members: members},
+			errResultWithIngress: make(map[*networking.Ingress]error),
+			sgpByResID:           make(map[string]*alb.ServerGroup),
+			kubeClient:           fake.NewClientBuilder().Build(),
+		}
+	}
+	serverGroupKey := func(task *defaultModelBuildTask, action alb.Action) alb.ServerGroupNamedKey {
+		var sgps []*alb.ServerGroup
+		_ = task.stack.ListResources(&sgps)
+		if assert.Equal(t, 1, len(sgps)) && assert.Equal(t, util.RuleActionTypeForward, action.Type) {
+			assert.Equal(t, []core.Resource{sgps[0]}, action.ForwardConfig.ServerGroups[0].ServerGroupID.Dependencies())
+			return sgps[0].Spec.ServerGroupNamedKey
+		}
+		return alb.ServerGroupNamedKey{}
+	}
+
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "alb"}}
+	task := newTask(albconfig)
+	action, err := task.buildLsDefaultAction(context.TODO(), 80)
+	assert.NoError(t, err)
+	key := serverGroupKey(task, action)
+	assert.Equal(t, fakeDefaultServiceName, key.ServiceName)
+	assert.Equal(t, "alb"+util.DefaultListenerFlag+"80", key.IngressName)
+
+	albconfig.Spec.DefaultBackend = &v1.DefaultBackendSpec{ServiceName: "fallback", ServicePort: 8080}
+	task = newTask(albconfig, defaultBackendIngress("a", "", 0))
+	action, err = task.buildLsDefaultAction(context.TODO(), 80)
+	assert.NoError(t, err)
+	key = serverGroupKey(task, action)
+	assert.Equal(t, alb.ServerGroupNamedKey{
+		IngressName: "alb" + util.DefaultBackendFlag,
+		ServiceName: "fallback",
+		Namespace:   ALBConfigNamespace,
+		ServicePort: 8080,
+	}, key)
+
+	// the default backend of an ingress takes precedence over the albconfig, and is shared by the listeners
+	task = newTask(albconfig, defaultBackendIngress("a", "", 0), defaultBackendIngress("b", "web", 80))
+	for _, port := range []int{80, 443} {
+		action, err = task.buildLsDefaultAction(context.TODO(), port)
+		assert.NoError(t, err)
+		key = serverGroupKey(task, action)
+		assert.Equal(t, alb.ServerGroupNamedKey{IngressName: "b", ServiceName: "web", Namespace: "default", ServicePort: 80}, key)
+	}
+}
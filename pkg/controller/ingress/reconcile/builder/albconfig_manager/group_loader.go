@@ -46,6 +46,19 @@ type Group struct {
 	Members []*networking.Ingress
 
 	InactiveMembers []*networking.Ingress
+
+	// IsolatedMembers are the members failed to build, keyed by namespace/name.
+	// They are replaced by the version last synced in Members.
+	IsolatedMembers map[string]*IsolatedMember
+}
+
+type IsolatedMember struct {
+	// Ingress is the current version of the ingress
+	Ingress *networking.Ingress
+	// Err is the build error of the current version
+	Err error
+	// LastSynced is the version used in Members instead
+	LastSynced *networking.Ingress
 }
 
 type GroupLoader interface {
@@ -257,6 +270,19 @@ const (
 	maxGroupOder      int64 = 1000
 )
 
+// ingressGroupOrder returns the order of the ingress in its group, and whether the order is set explicitly
+func ingressGroupOrder(ing *networking.Ingress) (int64, bool, error) {
+	v := annotations.GetStringAnnotationMutil(util.IngressSuffixAlbConfigOrder, annotations.Order, ing)
+	if v == "" {
+		return defaultGroupOrder, false, nil
+	}
+	order, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return defaultGroupOrder, true, err
+	}
+	return order, true, nil
+}
+
 func (m *defaultGroupLoader) sortGroupMembers(members []*networking.Ingress) ([]*networking.Ingress, error, *networking.Ingress) {
 	if len(members) == 0 {
 		return nil, nil, nil
@@ -264,16 +290,9 @@ func (m *defaultGroupLoader) sortGroupMembers(members []*networking.Ingress) ([]
 	groupMemberWithOrderList := make([]groupMemberWithOrder, 0, len(members))
 	explicitOrders := make(map[int64]*networking.Ingress)
 	for _, member := range members {
-		var order = defaultGroupOrder
-		exists := false
-		v := annotations.GetStringAnnotationMutil(util.IngressSuffixAlbConfigOrder, annotations.Order, member)
-		if v != "" {
-			exists = true
-			i, err := strconv.ParseInt(v, 10, 64)
-			if err != nil {
-				return nil, errors.Wrapf(err, "failed to load Ingress group order for ingress: %v", util.NamespacedName(member)), member
-			}
-			order = i
+		order, exists, err := ingressGroupOrder(member)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load Ingress group order for ingress: %v", util.NamespacedName(member)), member
 		}
 		if exists {
 			if order < minGroupOrder || order > maxGroupOder {
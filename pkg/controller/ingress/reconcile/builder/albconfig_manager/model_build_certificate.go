@@ -5,13 +5,18 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
+	"strings"
 
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
 	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
 	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
+const caCertKey = "ca.crt"
+
 func (t *defaultModelBuildTask) buildSecretCertificate(ctx context.Context, ing networking.Ingress, secretName, clusterID string, pp PortProtocol) (*alb.SecretCertificate, error) {
 	scSpecDst, err := t.buildSecretCertificateSpec(ctx, ing, secretName, clusterID)
 	if err != nil {
@@ -44,12 +49,57 @@ func (t *defaultModelBuildTask) buildSecretCertificateSpec(ctx context.Context,
 	sc := alb.SecretCertificateSpec{
 		CertName:    certName,
 		IsDefault:   false,
+		Secret:      fmt.Sprintf("%s/%s", secret.Namespace, secret.Name),
+		Fingerprint: helper.CertificateFingerprint(crt),
 		Certificate: crt,
 		PrivateKey:  key,
 	}
 	return sc, nil
 }
 
+// buildCASecretCertificate builds the CA certificate of a listener from the CA bundle in ca.crt of the Secret.
+func (t *defaultModelBuildTask) buildCASecretCertificate(ctx context.Context, secretRef, clusterID string, pp PortProtocol) (*alb.SecretCertificate, error) {
+	key, err := CaCertificateSecretKey(t.albconfig, secretRef)
+	if err != nil {
+		return nil, err
+	}
+	var secret = &corev1.Secret{}
+	if err := t.kubeClient.Get(ctx, key, secret); err != nil {
+		return nil, err
+	}
+	crt := string(secret.Data[caCertKey])
+	if len(crt) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s", key.String(), caCertKey)
+	}
+	scSpecDst := alb.SecretCertificateSpec{
+		CertName:    fmt.Sprintf("%s-%s-ca-%s", secret.Namespace, secret.Name, computeDigest(clusterID, crt)),
+		Secret:      key.String(),
+		Fingerprint: helper.CertificateFingerprint(crt),
+		IsCA:        true,
+		Certificate: crt,
+	}
+	scResID := fmt.Sprintf("%v-%v-%v", pp.Port, pp.Protocol, scSpecDst.CertName)
+	if sc, exists := t.scByResID[scResID]; exists {
+		return sc, nil
+	}
+	sc := alb.NewSecretCertificate(t.stack, scResID, scSpecDst)
+	t.scByResID[scResID] = sc
+	return sc, nil
+}
+
+// CaCertificateSecretKey parses the namespace/name of the CA Secret of a listener, the Secret is in the namespace
+// of the albconfig if only its name is given.
+func CaCertificateSecretKey(albconfig *v1.AlbConfig, secretRef string) (types.NamespacedName, error) {
+	parts := strings.Split(secretRef, "/")
+	switch {
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+	case len(parts) == 1 && parts[0] != "":
+		return types.NamespacedName{Namespace: albConfigNamespace(albconfig), Name: parts[0]}, nil
+	}
+	return types.NamespacedName{}, fmt.Errorf("caCertificateSecret must be in the format of namespace/name or name: %s", secretRef)
+}
+
 func computeDigest(args ...string) string {
 	data := ""
 	for _, a := range args {
@@ -0,0 +1,74 @@
+package albconfigmanager
+
+import (
+	"context"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCaCertificateSecretKey(t *testing.T) {
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "alb"}}
+	key, err := CaCertificateSecretKey(albconfig, "default/clients")
+	assert.NoError(t, err)
+	assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "clients"}, key)
+
+	key, err = CaCertificateSecretKey(albconfig, "clients")
+	assert.NoError(t, err)
+	assert.Equal(t, types.NamespacedName{Namespace: ALBConfigNamespace, Name: "clients"}, key)
+
+	albconfig.Namespace = "infra"
+	key, err = CaCertificateSecretKey(albconfig, "clients")
+	assert.NoError(t, err)
+	assert.Equal(t, types.NamespacedName{Namespace: "infra", Name: "clients"}, key)
+
+	_, err = CaCertificateSecretKey(albconfig, "default/")
+	assert.Error(t, err)
+}
+
+func TestBuildCASecretCertificate(t *testing.T) {
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("ca")}))
+	task := &defaultModelBuildTask{
+		stack:     core.NewDefaultManager(core.StackID{Namespace: ALBConfigNamespace, Name: "alb"}),
+		albconfig: &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "alb"}},
+		kubeClient: fake.NewClientBuilder().WithObjects(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "clients"},
+				Data:       map[string][]byte{caCertKey: []byte(ca)},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "tls"},
+				Data:       map[string][]byte{corev1.TLSCertKey: []byte(ca)},
+			},
+		).Build(),
+		scByResID: make(map[string]*alb.SecretCertificate),
+	}
+	pp := PortProtocol{Port: 443, Protocol: ProtocolHTTPS}
+
+	cert, err := task.buildCASecretCertificate(context.TODO(), "default/clients", "cluster", pp)
+	assert.NoError(t, err)
+	assert.True(t, cert.Spec.IsCA)
+	assert.Equal(t, "default/clients", cert.Spec.Secret)
+	assert.Equal(t, "default-clients-ca-"+computeDigest("cluster", ca), cert.Spec.CertName)
+	assert.Equal(t, helper.CertificateFingerprint(ca), cert.Spec.Fingerprint)
+	assert.Empty(t, cert.Spec.PrivateKey)
+
+	// the certificate is built once for the listener
+	again, err := task.buildCASecretCertificate(context.TODO(), "default/clients", "cluster", pp)
+	assert.NoError(t, err)
+	assert.Same(t, cert, again)
+
+	_, err = task.buildCASecretCertificate(context.TODO(), "default/tls", "cluster", pp)
+	assert.Error(t, err)
+	_, err = task.buildCASecretCertificate(context.TODO(), "default/missing", "cluster", pp)
+	assert.Error(t, err)
+}
@@ -89,6 +89,19 @@ func (t *defaultModelBuildTask) buildListenerSpec(ctx context.Context, lbID core
 		if len(apiLs.CaCertificates) != 0 {
 			modelLs.CaCertificates = transCertificatesFromAPIToSDK(apiLs.CaCertificates)
 		}
+		modelLs.CaEnabled = apiLs.CaEnabled
+		if len(apiLs.CaCertificateSecret) != 0 {
+			if len(apiLs.CaCertificates) != 0 {
+				return alb.ListenerSpec{}, fmt.Errorf("caCertificates and caCertificateSecret cannot use together")
+			}
+			pp := PortProtocol{Port: int32(modelLs.ListenerPort), Protocol: ProtocolHTTPS}
+			caCert, err := t.buildCASecretCertificate(ctx, apiLs.CaCertificateSecret, t.clusterID, pp)
+			if err != nil {
+				return alb.ListenerSpec{}, err
+			}
+			modelLs.CaCertificates = []alb.Certificate{caCert}
+			modelLs.CaEnabled = true
+		}
 		if len(modelLs.SecurityPolicyId) == 0 {
 			modelLs.SecurityPolicyId = t.defaultListenerSecurityPolicyId
 		}
@@ -7,10 +7,12 @@ import (
 	"strconv"
 	"strings"
 
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/store"
 	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/configcache"
 
 	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util/hash"
 
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
 	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
@@ -29,6 +31,9 @@ const (
 	HTTPS443               = "443"
 )
 
+// ListenerRuleNameHashLength is the length of the identity hash in the rule name
+const ListenerRuleNameHashLength = 16
+
 var (
 	lowerRuleActionTypeFixedResponse = strings.ToLower(util.RuleActionTypeFixedResponse)
 	lowerRuleActionTypeRedirect      = strings.ToLower(util.RuleActionTypeRedirect)
@@ -84,7 +89,7 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 			}
 		}
 	}
-	var rules []alb.ListenerRule
+	var rules []orderedListenerRule
 	canaryServerGroupWithIngress := make(map[string][]canarySGPWithIngress, 0)
 	nonCanaryPath := make(map[string]bool, 0)
 	for _, ing := range ingList {
@@ -141,6 +146,7 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 				continue
 			}
 		}
+		order, explicitOrder, _ := ingressGroupOrder(&ing)
 		for _, rule := range ing.Spec.Rules {
 			if rule.HTTP == nil {
 				continue
@@ -173,22 +179,35 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 					t.errResultWithIngress[&ing] = err
 					return errors.Wrapf(err, "buildListenerRules-Direction(ingress: %v)", util.NamespacedName(&ing))
 				}
-				rules = append(rules, alb.ListenerRule{
-					Spec: alb.ListenerRuleSpec{
-						ListenerID: lsID,
-						ALBListenerRuleSpec: alb.ALBListenerRuleSpec{
-							RuleActions:    actions,
-							RuleConditions: conditions,
-							RuleDirection:  direction,
+				ingKeys := []string{util.Key(&ing)}
+				for _, canary := range canaryServerGroupWithIngress[rule.Host+"-"+path.Path] {
+					ingKeys = append(ingKeys, util.Key(&canary.canaryIngress))
+				}
+				rules = append(rules, orderedListenerRule{
+					rule: alb.ListenerRule{
+						Spec: alb.ListenerRuleSpec{
+							ListenerID: lsID,
+							ALBListenerRuleSpec: alb.ALBListenerRuleSpec{
+								RuleActions:    actions,
+								RuleConditions: conditions,
+								RuleDirection:  direction,
+							},
+							Ingresses: ingKeys,
 						},
 					},
+					order:         order,
+					explicitOrder: explicitOrder,
+					specificity:   buildRuleSpecificity(conditions),
 				})
 			}
 		}
 	}
 
+	sortListenerRulesByPrecedence(rules)
 	priority := 1
-	for _, rule := range rules {
+	ruleNames := make(map[string]int)
+	for _, ordered := range rules {
+		rule := ordered.rule
 		ruleResID := fmt.Sprintf("%v-%v:%v", port, protocol, priority)
 		klog.Infof("ruleResID: %s", ruleResID)
 		lrs := alb.ListenerRuleSpec{
@@ -197,10 +216,11 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 				Priority:       priority,
 				RuleConditions: rule.Spec.RuleConditions,
 				RuleActions:    rule.Spec.RuleActions,
-				RuleName:       fmt.Sprintf("%v-%v-%v", ListenerRuleNamePrefix, port, priority),
 				RuleDirection:  rule.Spec.RuleDirection,
 			},
+			Ingresses: rule.Spec.Ingresses,
 		}
+		lrs.RuleName = buildListenerRuleName(port, lrs, ruleNames)
 		_ = alb.NewListenerRule(t.stack, ruleResID, lrs)
 		priority += 1
 	}
@@ -208,6 +228,33 @@ func (t *defaultModelBuildTask) buildListenerRules(ctx context.Context, lsID cor
 	return nil
 }
 
+// listenerRuleIdentity is what a listener rule matches and which ingress owns it, the actions and the priority are
+// not part of it, so the rule keeps its name when they change.
+type listenerRuleIdentity struct {
+	Ingress    string          `json:"ingress,omitempty"`
+	Direction  string          `json:"direction,omitempty"`
+	Conditions []alb.Condition `json:"conditions,omitempty"`
+}
+
+// buildListenerRuleName returns the stable name of the rule, derived from the hash of its identity. The applier
+// matches the rules on the listener by name, so inserting a rule only shifts the priorities of the other rules.
+// The rules with the same identity are numbered in the order of their priorities.
+func buildListenerRuleName(port int32, lrs alb.ListenerRuleSpec, ruleNames map[string]int) string {
+	identity := listenerRuleIdentity{
+		Direction:  lrs.RuleDirection,
+		Conditions: lrs.RuleConditions,
+	}
+	if len(lrs.Ingresses) != 0 {
+		identity.Ingress = lrs.Ingresses[0]
+	}
+	name := fmt.Sprintf("%v-%v-%v", ListenerRuleNamePrefix, port, hash.HashObject(identity)[:ListenerRuleNameHashLength])
+	ruleNames[name]++
+	if n := ruleNames[name]; n > 1 {
+		return fmt.Sprintf("%v-%v", name, n)
+	}
+	return name
+}
+
 /*
  * true if rule need config on https listener
  */
@@ -246,8 +293,9 @@ func contains(s []string, e string) bool {
 }
 
 func (t *defaultModelBuildTask) buildListenerRulesCommon(ctx context.Context, lsID core.StringToken, port int32, ingList []networking.Ingress) error {
-	var rules []alb.ListenerRule
+	var rules []orderedListenerRule
 	for _, ing := range ingList {
+		order, explicitOrder, _ := ingressGroupOrder(&ing)
 		for _, rule := range ing.Spec.Rules {
 			if rule.HTTP == nil {
 				continue
@@ -280,27 +328,35 @@ func (t *defaultModelBuildTask) buildListenerRulesCommon(ctx context.Context, ls
 				}
 				lrs := alb.ListenerRuleSpec{
 					ListenerID: lsID,
+					Ingresses:  []string{util.Key(&ing)},
 				}
 				lrs.RuleActions = actions
 				lrs.RuleConditions = conditions
-				rules = append(rules, alb.ListenerRule{
-					Spec: lrs,
+				rules = append(rules, orderedListenerRule{
+					rule:          alb.ListenerRule{Spec: lrs},
+					order:         order,
+					explicitOrder: explicitOrder,
+					specificity:   buildRuleSpecificity(conditions),
 				})
 			}
 		}
 	}
 
+	sortListenerRulesByPrecedence(rules)
 	priority := 1
-	for _, rule := range rules {
+	ruleNames := make(map[string]int)
+	for _, ordered := range rules {
+		rule := ordered.rule
 		ruleResID := fmt.Sprintf("%v:%v", port, priority)
 		klog.Infof("ruleResID: %s", ruleResID)
 		lrs := alb.ListenerRuleSpec{
 			ListenerID: lsID,
+			Ingresses:  rule.Spec.Ingresses,
 		}
 		lrs.Priority = priority
 		lrs.RuleConditions = rule.Spec.RuleConditions
 		lrs.RuleActions = rule.Spec.RuleActions
-		lrs.RuleName = fmt.Sprintf("%v-%v-%v", ListenerRuleNamePrefix, port, priority)
+		lrs.RuleName = buildListenerRuleName(port, lrs, ruleNames)
 		_ = alb.NewListenerRule(t.stack, ruleResID, lrs)
 		priority += 1
 	}
@@ -1013,20 +1069,24 @@ func (t *defaultModelBuildTask) buildActionForwardSGP(ctx context.Context, ing *
 
 func (t *defaultModelBuildTask) toCustomAction(ctx context.Context, ing *networking.Ingress, action configcache.Action) (alb.Action, error) {
 	actType := strings.ToLower(action.Type)
-	var toAct *alb.Action
-	var err error
 	switch actType {
 	case lowerRuleActionTypeFixedResponse:
-		toAct = &alb.Action{
+		if action.FixedResponseConfig == nil {
+			return alb.Action{}, missingActionConfigError(action.Type, "FixedResponseConfig")
+		}
+		return alb.Action{
 			Type: util.RuleActionTypeFixedResponse,
 			FixedResponseConfig: &alb.FixedResponseConfig{
 				Content:     action.FixedResponseConfig.Content,
 				ContentType: action.FixedResponseConfig.ContentType,
 				HttpCode:    action.FixedResponseConfig.HttpCode,
 			},
-		}
+		}, nil
 	case lowerRuleActionTypeRedirect:
-		toAct = &alb.Action{
+		if action.RedirectConfig == nil {
+			return alb.Action{}, missingActionConfigError(action.Type, "RedirectConfig")
+		}
+		toAct := alb.Action{
 			Type: util.RuleActionTypeRedirect,
 			RedirectConfig: &alb.RedirectConfig{
 				Host:     "${host}",
@@ -1038,9 +1098,15 @@ func (t *defaultModelBuildTask) toCustomAction(ctx context.Context, ing *network
 			},
 		}
 		bActCfg, _ := json.Marshal(action.RedirectConfig)
-		err = json.Unmarshal(bActCfg, &toAct.RedirectConfig)
+		if err := json.Unmarshal(bActCfg, &toAct.RedirectConfig); err != nil {
+			return alb.Action{}, err
+		}
+		return toAct, nil
 	case lowerRuleActionTypeInsertHeader:
-		toAct = &alb.Action{
+		if action.InsertHeaderConfig == nil {
+			return alb.Action{}, missingActionConfigError(action.Type, "InsertHeaderConfig")
+		}
+		return alb.Action{
 			Type: util.RuleActionTypeInsertHeader,
 			InsertHeaderConfig: &alb.InsertHeaderConfig{
 				CoverEnabled: action.InsertHeaderConfig.CoverEnabled,
@@ -1048,43 +1114,59 @@ func (t *defaultModelBuildTask) toCustomAction(ctx context.Context, ing *network
 				Value:        action.InsertHeaderConfig.Value,
 				ValueType:    action.InsertHeaderConfig.ValueType,
 			},
-		}
+		}, nil
 	case lowerRuleActionTypeTrafficMirror:
-		trafficMirrorAction := buildTrafficMirrorAction(action)
-		toAct = &trafficMirrorAction
+		if action.TrafficMirrorConfig == nil {
+			return alb.Action{}, missingActionConfigError(action.Type, "TrafficMirrorConfig")
+		}
+		return buildTrafficMirrorAction(action), nil
 	case lowerRuleActionTypeRemoveHeader:
-		toAct = &alb.Action{
+		if action.RemoveHeaderConfig == nil {
+			return alb.Action{}, missingActionConfigError(action.Type, "RemoveHeaderConfig")
+		}
+		return alb.Action{
 			Type: util.RuleActionTypeRemoveHeader,
 			RemoveHeaderConfig: &alb.RemoveHeaderConfig{
 				Key: action.RemoveHeaderConfig.Key,
 			},
-		}
+		}, nil
 	case lowerRuleActionTypeForward:
-		forwardAction, aErr := t.buildAnnotationForwardAction(ctx, ing, action)
-		if aErr != nil {
-			err = fmt.Errorf("build ForwardAction Failed: %v", aErr)
+		if action.ForwardConfig == nil || len(action.ForwardConfig.ServerGroups) == 0 {
+			return alb.Action{}, missingActionConfigError(action.Type, "ForwardConfig.ServerGroups")
 		}
-		toAct = &forwardAction
+		forwardAction, err := t.buildAnnotationForwardAction(ctx, ing, action)
+		if err != nil {
+			return alb.Action{}, fmt.Errorf("build ForwardAction Failed: %v", err)
+		}
+		return forwardAction, nil
 	case lowerRuleActionTypeRewrite:
-		toAct = &alb.Action{
+		if action.RewriteConfig == nil {
+			return alb.Action{}, missingActionConfigError(action.Type, "RewriteConfig")
+		}
+		return alb.Action{
 			Type: util.RuleActionTypeRewrite,
 			RewriteConfig: &alb.RewriteConfig{
 				Host:  action.RewriteConfig.Host,
 				Path:  action.RewriteConfig.Path,
 				Query: action.RewriteConfig.Query,
 			},
-		}
+		}, nil
 	case lowerRuleActionTypeTrafficLimit:
-		QpsLimitAction, aErr := t.buildQpsLimitAction(ctx, action.TrafficLimitConfig.QPS, action.TrafficLimitConfig.QPSPerIp, ing)
-		if aErr != nil {
-			err = fmt.Errorf("build TrafficLimitAction Failed: %v", aErr)
-		} else {
-			toAct = QpsLimitAction
+		if action.TrafficLimitConfig == nil {
+			return alb.Action{}, missingActionConfigError(action.Type, "TrafficLimitConfig")
+		}
+		qpsLimitAction, err := t.buildQpsLimitAction(ctx, action.TrafficLimitConfig.QPS, action.TrafficLimitConfig.QPSPerIp, ing)
+		if err != nil {
+			return alb.Action{}, fmt.Errorf("build TrafficLimitAction Failed: %v", err)
 		}
+		return *qpsLimitAction, nil
 	default:
-		err = fmt.Errorf("readAction Failed(unknown action type): %s", actType)
+		return alb.Action{}, fmt.Errorf("readAction Failed(unknown action type): %s", action.Type)
 	}
-	return *toAct, err
+}
+
+func missingActionConfigError(actType, config string) error {
+	return fmt.Errorf("readAction Failed: %s is required by action %s", config, actType)
 }
 
 func buildTrafficMirrorAction(action configcache.Action) alb.Action {
@@ -1119,10 +1201,18 @@ func (t *defaultModelBuildTask) buildAnnotationForwardAction(ctx context.Context
 				Weight:        sgp.Weight,
 			})
 		} else {
+			svcKey := sgp.ServiceKey(ing.Namespace)
+			sgpIng := ing
+			if svcKey.Namespace != ing.Namespace {
+				if err := t.checkServiceReference(ctx, v1.ServiceReferenceGrantFromIngress, ing.Namespace, svcKey); err != nil {
+					return alb.Action{}, err
+				}
+				sgpIng = crossNamespaceIngress(ing, svcKey.Namespace)
+			}
 			svc := new(corev1.Service)
-			svc.Namespace = ing.Namespace
-			svc.Name = sgp.ServiceName
-			modelSgp, err := t.buildServerGroup(ctx, ing, svc, sgp.ServicePort)
+			svc.Namespace = svcKey.Namespace
+			svc.Name = svcKey.Name
+			modelSgp, err := t.buildServerGroup(ctx, sgpIng, svc, sgp.ServicePort)
 			if err != nil {
 				return alb.Action{}, err
 			}
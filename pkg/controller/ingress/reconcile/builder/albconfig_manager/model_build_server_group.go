@@ -9,11 +9,13 @@ import (
 	"strconv"
 	"strings"
 
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
 	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
 	"k8s.io/alibaba-load-balancer-controller/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 )
@@ -49,7 +51,7 @@ func (t *defaultModelBuildTask) buildServerGroupName(ing *networking.Ingress, sv
 	return fmt.Sprintf("%s-%s-%s", svc.Namespace, svc.Name, fmt.Sprintf("%v", port))
 }
 
-func (t *defaultModelBuildTask) buildServerGroupSpec(_ context.Context,
+func (t *defaultModelBuildTask) buildServerGroupSpec(ctx context.Context,
 	ing *networking.Ingress, svc *corev1.Service, port int) (alb.ServerGroupSpec, error) {
 
 	// preCheck tag value
@@ -64,6 +66,10 @@ func (t *defaultModelBuildTask) buildServerGroupSpec(_ context.Context,
 	if err := checkBackendSchedulerAnnotations(ing); err != nil {
 		return alb.ServerGroupSpec{}, err
 	}
+	sgpType, err := t.buildServerGroupType(ctx, util.NamespacedName(svc))
+	if err != nil {
+		return alb.ServerGroupSpec{}, err
+	}
 
 	tags := make([]alb.ALBTag, 0)
 	tags = append(tags, []alb.ALBTag{
@@ -96,18 +102,40 @@ func (t *defaultModelBuildTask) buildServerGroupSpec(_ context.Context,
 	var sgpSpec alb.ServerGroupSpec
 	sgpSpec.ServerGroupNamedKey = sgpNameKey
 	sgpSpec.Tags = tags
-	sgpSpec.HealthCheckConfig = buildServerGroupHealthCheckConfig(ing)
+	sgpSpec.HealthCheckConfig = BuildServerGroupHealthCheckConfig(ing)
 	sgpSpec.ServerGroupName = t.buildServerGroupName(ing, svc, port)
 	sgpSpec.UpstreamKeepaliveEnabled = buildServerGroupKeepalived(ing)
+	sgpSpec.ConnectionDrainConfig = BuildServerGroupConnectionDrainConfig(ing)
+	sgpSpec.SlowStartConfig = buildServerGroupSlowStartConfig(ing)
 	sgpSpec.Scheduler = t.buildServerGroupScheduler(ing)
 	sgpSpec.UchConfig = t.buildServerGroupUchSchedulerConfig(ing)
 	sgpSpec.Protocol = t.buildServerGroupProtocol(ing)
-	sgpSpec.StickySessionConfig = buildServerGroupStickySessionConfig(ing)
-	sgpSpec.ServerGroupType = t.defaultServerGroupType
+	sgpSpec.StickySessionConfig = BuildServerGroupStickySessionConfig(ing)
+	sgpSpec.ServerGroupType = sgpType
 	sgpSpec.VpcId = t.vpcID
 	return sgpSpec, nil
 }
 
+// buildServerGroupType returns Ip for the server group of a Service without pods, whose addresses are registered
+// directly, see helper.IsIPBackendService.
+func (t *defaultModelBuildTask) buildServerGroupType(ctx context.Context, svcKey types.NamespacedName) (string, error) {
+	// the ingress validation builds the server groups into a throwaway stack without client
+	if t.kubeClient == nil {
+		return t.defaultServerGroupType, nil
+	}
+	svc := &corev1.Service{}
+	if err := t.kubeClient.Get(ctx, svcKey, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return t.defaultServerGroupType, nil
+		}
+		return "", fmt.Errorf("get service %s: %w", svcKey, err)
+	}
+	if helper.IsIPBackendService(svc) {
+		return util.ServerGroupTypeIp, nil
+	}
+	return t.defaultServerGroupType, nil
+}
+
 func checkBackendSchedulerAnnotations(ing *networking.Ingress) error {
 	if v, ok := ing.Annotations[annotations.AlbBackendScheduler]; ok {
 		switch v {
@@ -209,7 +237,7 @@ func (t *defaultModelBuildTask) buildServerGroupProtocol(ing *networking.Ingress
 	return backendProtocol
 }
 
-func buildServerGroupHealthCheckConfig(ing *networking.Ingress) alb.HealthCheckConfig {
+func BuildServerGroupHealthCheckConfig(ing *networking.Ingress) alb.HealthCheckConfig {
 	healthCheckEnabled := util.DefaultServerGroupHealthCheckEnabled
 	if v, ok := ing.Annotations[annotations.HealthCheckEnabled]; ok && v == "true" {
 		healthCheckEnabled = true
@@ -294,7 +322,7 @@ func buildServerGroupHealthCheckConfig(ing *networking.Ingress) alb.HealthCheckC
 	}
 }
 
-func buildServerGroupStickySessionConfig(ing *networking.Ingress) alb.StickySessionConfig {
+func BuildServerGroupStickySessionConfig(ing *networking.Ingress) alb.StickySessionConfig {
 	sessionStickEnabled := util.DefaultServerGroupStickySessionEnabled
 	if v, ok := ing.Annotations[annotations.SessionStick]; ok && v == "true" {
 		sessionStickEnabled = true
@@ -326,3 +354,41 @@ func buildServerGroupKeepalived(ing *networking.Ingress) bool {
 	}
 	return serverGroupUpstreamKeepaliveEnabled
 }
+
+// BuildServerGroupConnectionDrainConfig builds the connection drain config of the server groups of the ingress, the
+// terminating backends are kept with weight 0 for the timeout before they are removed.
+func BuildServerGroupConnectionDrainConfig(ing *networking.Ingress) alb.ConnectionDrainConfig {
+	conf := alb.ConnectionDrainConfig{}
+	if v, ok := ing.Annotations[annotations.AlbConnectionDrainEnabled]; !ok || v != "true" {
+		return conf
+	}
+	conf.ConnectionDrainEnabled = true
+	conf.ConnectionDrainTimeout = util.DefaultServerGroupConnectionDrainTimeout
+	if v, ok := ing.Annotations[annotations.AlbConnectionDrainTimeout]; ok {
+		if val, err := strconv.Atoi(v); err != nil {
+			klog.Error(err.Error())
+		} else {
+			conf.ConnectionDrainTimeout = val
+		}
+	}
+	return conf
+}
+
+// buildServerGroupSlowStartConfig builds the slow start config of the server groups of the ingress, the weight of
+// a new backend ramps up during the duration.
+func buildServerGroupSlowStartConfig(ing *networking.Ingress) alb.SlowStartConfig {
+	conf := alb.SlowStartConfig{}
+	if v, ok := ing.Annotations[annotations.AlbSlowStartEnabled]; !ok || v != "true" {
+		return conf
+	}
+	conf.SlowStartEnabled = true
+	conf.SlowStartDuration = util.DefaultServerGroupSlowStartDuration
+	if v, ok := ing.Annotations[annotations.AlbSlowStartDuration]; ok {
+		if val, err := strconv.Atoi(v); err != nil {
+			klog.Error(err.Error())
+		} else {
+			conf.SlowStartDuration = val
+		}
+	}
+	return conf
+}
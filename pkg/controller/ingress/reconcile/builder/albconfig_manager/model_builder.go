@@ -131,9 +131,26 @@ var (
 	fakeDefaultServiceName = "fake-svc"
 )
 
+// buildLsDefaultAction forwards the requests matching no rule of the listener to the default backend of the group,
+// or to an empty server group if there is none.
 func (t *defaultModelBuildTask) buildLsDefaultAction(ctx context.Context, lsPort int) (alb.Action, error) {
+	ing, backend, err := t.resolveDefaultBackend(ctx)
+	if err != nil {
+		t.errResultWithIngress[ing] = err
+		return alb.Action{}, err
+	}
+	if ing != nil {
+		action := buildActionViaServiceAndServicePort(ctx, backend.Name, int(backend.Port.Number), 100)
+		actions, err := t.buildAction(ctx, *ing, action)
+		if err != nil {
+			t.errResultWithIngress[ing] = err
+			return alb.Action{}, err
+		}
+		return actions, nil
+	}
+
 	svcName := fakeDefaultServiceName
-	ing := new(networking.Ingress)
+	ing = new(networking.Ingress)
 	ing.Namespace = t.albconfig.Namespace
 	if ing.Namespace == "" {
 		ing.Namespace = ALBConfigNamespace
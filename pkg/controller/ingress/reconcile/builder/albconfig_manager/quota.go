@@ -0,0 +1,96 @@
+package albconfigmanager
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+)
+
+// Quota is the quotas of an ALB instance which the model is checked against before it is applied
+type Quota struct {
+	// ListenerRules is the max number of forwarding rules of a listener
+	ListenerRules int
+	// ServerGroups is the max number of server groups used by an ALB instance
+	ServerGroups int
+}
+
+// editionQuotas are the default quotas of the ALB editions, as listed by the forwarding rules per listener and the
+// server groups per instance in "Application Load Balancer > Product Overview > Limits" of the Alibaba Cloud
+// documentation, keep them in line with that page. The quotas raised in Quota Center are set by spec.quota.
+var editionQuotas = map[string]Quota{
+	util.LoadBalancerEditionBasic:    {ListenerRules: 100, ServerGroups: 100},
+	util.LoadBalancerEditionStandard: {ListenerRules: 300, ServerGroups: 200},
+	util.LoadBalancerEditionWaf:      {ListenerRules: 300, ServerGroups: 200},
+}
+
+// GetQuota returns the quotas of the ALB instance of the albconfig
+func GetQuota(albconfig *v1.AlbConfig) Quota {
+	edition := util.DefaultLoadBalancerEdition
+	if albconfig.Spec.LoadBalancer != nil && albconfig.Spec.LoadBalancer.Edition != "" {
+		edition = albconfig.Spec.LoadBalancer.Edition
+	}
+	quota, ok := editionQuotas[edition]
+	if !ok {
+		quota = editionQuotas[util.DefaultLoadBalancerEdition]
+	}
+	if override := albconfig.Spec.Quota; override != nil {
+		if override.ListenerRules > 0 {
+			quota.ListenerRules = override.ListenerRules
+		}
+		if override.ServerGroups > 0 {
+			quota.ServerGroups = override.ServerGroups
+		}
+	}
+	return quota
+}
+
+// QuotaViolation is a quota exceeded by the model
+type QuotaViolation struct {
+	// Resource is the listener of the rules, or empty for the server groups of the instance
+	Resource string
+	Count    int
+	Limit    int
+}
+
+func (v QuotaViolation) String() string {
+	if v.Resource == "" {
+		return fmt.Sprintf("%d server groups exceed the quota %d of the instance", v.Count, v.Limit)
+	}
+	return fmt.Sprintf("%d forwarding rules exceed the quota %d of listener %s", v.Count, v.Limit, v.Resource)
+}
+
+// CheckQuota returns the quotas exceeded by the stack
+func CheckQuota(stack core.Manager, quota Quota) []QuotaViolation {
+	if stack == nil {
+		return nil
+	}
+	var violations []QuotaViolation
+
+	var resLRs []*alb.ListenerRule
+	_ = stack.ListResources(&resLRs)
+	rulesByListener := make(map[string]int)
+	for _, lr := range resLRs {
+		rulesByListener[listenerRuleListenerKey(lr)]++
+	}
+	listeners := make([]string, 0, len(rulesByListener))
+	for listener := range rulesByListener {
+		listeners = append(listeners, listener)
+	}
+	sort.Strings(listeners)
+	for _, listener := range listeners {
+		if count := rulesByListener[listener]; count > quota.ListenerRules {
+			violations = append(violations, QuotaViolation{Resource: listener, Count: count, Limit: quota.ListenerRules})
+		}
+	}
+
+	var resSGPs []*alb.ServerGroup
+	_ = stack.ListResources(&resSGPs)
+	if len(resSGPs) > quota.ServerGroups {
+		violations = append(violations, QuotaViolation{Count: len(resSGPs), Limit: quota.ServerGroups})
+	}
+	return violations
+}
@@ -0,0 +1,79 @@
+package albconfigmanager
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ServiceReferenceGranted returns true if one of the grants allows the resources of the kind in the namespace to
+// reference the Service. The namespace is ignored for AlbConfig, which is cluster scoped.
+func ServiceReferenceGranted(grants []v1.ServiceReferenceGrant, kind v1.ServiceReferenceGrantFromKind,
+	namespace string, svcName string) bool {
+	for _, grant := range grants {
+		if !grantsServiceFrom(grant.Spec, kind, namespace) {
+			continue
+		}
+		if len(grant.Spec.To) == 0 {
+			return true
+		}
+		for _, to := range grant.Spec.To {
+			if to.Name == svcName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func grantsServiceFrom(spec v1.ServiceReferenceGrantSpec, kind v1.ServiceReferenceGrantFromKind, namespace string) bool {
+	for _, from := range spec.From {
+		if from.Kind != kind {
+			continue
+		}
+		if kind == v1.ServiceReferenceGrantFromAlbConfig || from.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// CrossNamespaceIngressName returns the name of the ingress owning the server groups of the Services the ingress
+// references in other namespaces. The ingress doesn't exist, it names the server groups in the namespace of the
+// Services, so that they are synced with the Services of that namespace.
+func CrossNamespaceIngressName(ing *networking.Ingress) string {
+	return ing.Name + util.CrossNamespaceFlag + ing.Namespace
+}
+
+// crossNamespaceIngress returns a copy of the ingress in the namespace of a Service it references, see
+// CrossNamespaceIngressName.
+func crossNamespaceIngress(ing *networking.Ingress, namespace string) *networking.Ingress {
+	crossIng := ing.DeepCopy()
+	crossIng.Namespace = namespace
+	crossIng.Name = CrossNamespaceIngressName(ing)
+	return crossIng
+}
+
+// checkServiceReference returns an error if the Service is not in the namespace of the referencing resource, and
+// no ServiceReferenceGrant in the namespace of the Service allows the reference.
+func (t *defaultModelBuildTask) checkServiceReference(ctx context.Context, kind v1.ServiceReferenceGrantFromKind,
+	namespace string, svcKey types.NamespacedName) error {
+	// the ingress validation has no client, the grants are checked when the model is built
+	if svcKey.Namespace == namespace || t.kubeClient == nil {
+		return nil
+	}
+	grants := &v1.ServiceReferenceGrantList{}
+	if err := t.kubeClient.List(ctx, grants, client.InNamespace(svcKey.Namespace)); err != nil {
+		return fmt.Errorf("list servicereferencegrants of namespace %s: %w", svcKey.Namespace, err)
+	}
+	if !ServiceReferenceGranted(grants.Items, kind, namespace, svcKey.Name) {
+		return fmt.Errorf("reference to service %s from %s of namespace %s is not allowed by any ServiceReferenceGrant of namespace %s",
+			svcKey, kind, namespace, svcKey.Namespace)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package albconfigmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/configcache"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestServiceReferenceGranted(t *testing.T) {
+	grant := func(from []v1.ServiceReferenceGrantFrom, to ...string) v1.ServiceReferenceGrant {
+		g := v1.ServiceReferenceGrant{Spec: v1.ServiceReferenceGrantSpec{From: from}}
+		for _, name := range to {
+			g.Spec.To = append(g.Spec.To, v1.ServiceReferenceGrantTo{Name: name})
+		}
+		return g
+	}
+	fromPlatform := []v1.ServiceReferenceGrantFrom{{Kind: v1.ServiceReferenceGrantFromIngress, Namespace: "platform"}}
+
+	assert.False(t, ServiceReferenceGranted(nil, v1.ServiceReferenceGrantFromIngress, "platform", "tea-svc"))
+	assert.True(t, ServiceReferenceGranted([]v1.ServiceReferenceGrant{grant(fromPlatform)},
+		v1.ServiceReferenceGrantFromIngress, "platform", "tea-svc"))
+	assert.False(t, ServiceReferenceGranted([]v1.ServiceReferenceGrant{grant(fromPlatform)},
+		v1.ServiceReferenceGrantFromIngress, "default", "tea-svc"))
+	assert.False(t, ServiceReferenceGranted([]v1.ServiceReferenceGrant{grant(fromPlatform)},
+		v1.ServiceReferenceGrantFromAlbConfig, "platform", "tea-svc"))
+
+	// only the listed services are granted
+	grants := []v1.ServiceReferenceGrant{grant(fromPlatform, "coffee-svc"), grant(fromPlatform, "tea-svc")}
+	assert.True(t, ServiceReferenceGranted(grants, v1.ServiceReferenceGrantFromIngress, "platform", "tea-svc"))
+	assert.False(t, ServiceReferenceGranted(grants, v1.ServiceReferenceGrantFromIngress, "platform", "juice-svc"))
+
+	// albconfigs are cluster scoped
+	fromAlbConfig := []v1.ServiceReferenceGrantFrom{{Kind: v1.ServiceReferenceGrantFromAlbConfig}}
+	assert.True(t, ServiceReferenceGranted([]v1.ServiceReferenceGrant{grant(fromAlbConfig)},
+		v1.ServiceReferenceGrantFromAlbConfig, ALBConfigNamespace, "tea-svc"))
+}
+
+func TestBuildAnnotationForwardActionCrossNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1.SchemeBuilder.AddToScheme(scheme)
+	newTask := func(objs ...runtime.Object) *defaultModelBuildTask {
+		return &defaultModelBuildTask{
+			stack:      core.NewDefaultManager(core.StackID{Namespace: ALBConfigNamespace, Name: "alb"}),
+			kubeClient: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+			sgpByResID: make(map[string]*alb.ServerGroup),
+		}
+	}
+	ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "platform", Name: "shared"}}
+	action := configcache.Action{
+		Type: "ForwardGroup",
+		ForwardConfig: &configcache.ForwardActionConfig{ServerGroups: []configcache.ServerGroupTuple{
+			{ServiceName: "web", ServicePort: 80, Weight: 50},
+			{ServiceName: "team-a/tea-svc", ServicePort: 80, Weight: 50},
+		}},
+	}
+
+	_, err := newTask().buildAnnotationForwardAction(context.TODO(), ing, action)
+	assert.Error(t, err)
+
+	task := newTask(&v1.ServiceReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "platform"},
+		Spec: v1.ServiceReferenceGrantSpec{
+			From: []v1.ServiceReferenceGrantFrom{{Kind: v1.ServiceReferenceGrantFromIngress, Namespace: "platform"}},
+		},
+	})
+	forward, err := task.buildAnnotationForwardAction(context.TODO(), ing, action)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(forward.ForwardConfig.ServerGroups))
+
+	var sgps []*alb.ServerGroup
+	_ = task.stack.ListResources(&sgps)
+	keys := make(map[types.NamespacedName]alb.ServerGroupNamedKey)
+	for _, sgp := range sgps {
+		keys[types.NamespacedName{Namespace: sgp.Spec.Namespace, Name: sgp.Spec.ServiceName}] = sgp.Spec.ServerGroupNamedKey
+	}
+	assert.Equal(t, "shared", keys[types.NamespacedName{Namespace: "platform", Name: "web"}].IngressName)
+	// the server group of the service in another namespace is owned by the ingress in that namespace
+	assert.Equal(t, "shared-from-platform", keys[types.NamespacedName{Namespace: "team-a", Name: "tea-svc"}].IngressName)
+}
+
+func TestResolveDefaultBackendCrossNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1.SchemeBuilder.AddToScheme(scheme)
+	albconfig := &v1.AlbConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "alb"},
+		Spec:       v1.AlbConfigSpec{DefaultBackend: &v1.DefaultBackendSpec{Namespace: "infra", ServiceName: "fallback", ServicePort: 80}},
+	}
+	newTask := func(objs ...runtime.Object) *defaultModelBuildTask {
+		return &defaultModelBuildTask{
+			albconfig:  albconfig,
+			ingGroup:   &Group{},
+			kubeClient: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(),
+		}
+	}
+
+	_, _, err := newTask().resolveDefaultBackend(context.TODO())
+	assert.Error(t, err)
+
+	ing, backend, err := newTask(&v1.ServiceReferenceGrant{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "infra", Name: "albconfigs"},
+		Spec: v1.ServiceReferenceGrantSpec{
+			From: []v1.ServiceReferenceGrantFrom{{Kind: v1.ServiceReferenceGrantFromAlbConfig}},
+			To:   []v1.ServiceReferenceGrantTo{{Name: "fallback"}},
+		},
+	}).resolveDefaultBackend(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, "infra", ing.Namespace)
+	assert.Equal(t, "fallback", backend.Name)
+}
@@ -0,0 +1,329 @@
+package albconfigmanager
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+)
+
+const (
+	pathClassRegex = iota
+	pathClassPrefix
+	pathClassExact
+)
+
+const (
+	hostClassAny = iota
+	hostClassWildcard
+	hostClassExact
+)
+
+// ruleSpecificity ranks how narrow the conditions of a listener rule are,
+// a more specific rule gets a higher priority when the Ingress group order is not set.
+type ruleSpecificity struct {
+	// pathClass and pathLength are taken from the widest path pattern of the rule
+	pathClass  int
+	pathLength int
+	hostClass  int
+	// conditions is the number of conditions other than host and path
+	conditions int
+}
+
+func buildRuleSpecificity(conditions []alb.Condition) ruleSpecificity {
+	s := ruleSpecificity{pathClass: pathClassPrefix, hostClass: hostClassAny}
+	for _, cond := range conditions {
+		switch cond.Type {
+		case util.RuleConditionFieldPath:
+			for i, value := range cond.PathConfig.Values {
+				class, length := classifyPathPattern(value)
+				if i == 0 || class < s.pathClass || (class == s.pathClass && length < s.pathLength) {
+					s.pathClass, s.pathLength = class, length
+				}
+			}
+		case util.RuleConditionFieldHost:
+			for i, value := range cond.HostConfig.Values {
+				class := hostClassExact
+				if strings.ContainsAny(value, "*?") {
+					class = hostClassWildcard
+				}
+				if i == 0 || class < s.hostClass {
+					s.hostClass = class
+				}
+			}
+		default:
+			s.conditions++
+		}
+	}
+	return s
+}
+
+// classifyPathPattern returns the class of the path pattern, and the length of its literal prefix
+func classifyPathPattern(pattern string) (int, int) {
+	if strings.HasPrefix(pattern, "~") {
+		return pathClassRegex, 0
+	}
+	if i := strings.IndexAny(pattern, "*?"); i >= 0 {
+		return pathClassPrefix, i
+	}
+	return pathClassExact, len(pattern)
+}
+
+// moreSpecificThan compares the path first: exact > longer prefix > regex, then the host: exact > wildcard > any,
+// then the number of the other conditions.
+func (s ruleSpecificity) moreSpecificThan(o ruleSpecificity) bool {
+	if s.pathClass != o.pathClass {
+		return s.pathClass > o.pathClass
+	}
+	if s.pathLength != o.pathLength {
+		return s.pathLength > o.pathLength
+	}
+	if s.hostClass != o.hostClass {
+		return s.hostClass > o.hostClass
+	}
+	return s.conditions > o.conditions
+}
+
+// orderedListenerRule is a listener rule before its priority is allocated
+type orderedListenerRule struct {
+	rule          alb.ListenerRule
+	order         int64
+	explicitOrder bool
+	specificity   ruleSpecificity
+}
+
+// sortListenerRulesByPrecedence sorts the rules by the Ingress group order. The rules of an ingress with an
+// explicit order keep the order of its paths, the other rules of the same order are sorted by specificity.
+// The sort is stable, so the rules of the same specificity keep the order of the sorted group members.
+func sortListenerRulesByPrecedence(rules []orderedListenerRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.order != b.order {
+			return a.order < b.order
+		}
+		if a.explicitOrder != b.explicitOrder {
+			return a.explicitOrder
+		}
+		if a.explicitOrder {
+			return false
+		}
+		return a.specificity.moreSpecificThan(b.specificity)
+	})
+}
+
+type RuleConflictType string
+
+const (
+	// RuleConflictDuplicate means the rule has the same conditions as a rule of higher priority
+	RuleConflictDuplicate RuleConflictType = "Duplicate"
+	// RuleConflictShadowed means every request matched by the rule is matched by a rule of higher priority
+	RuleConflictShadowed RuleConflictType = "Shadowed"
+)
+
+// RuleConflict is a listener rule which never matches any request
+type RuleConflict struct {
+	Type RuleConflictType
+	// Listener is the resource id of the listener in the stack
+	Listener   string
+	Rule       *alb.ListenerRule
+	ShadowedBy *alb.ListenerRule
+}
+
+func (c RuleConflict) String() string {
+	verb := "shadowed"
+	if c.Type == RuleConflictDuplicate {
+		verb = "duplicated"
+	}
+	return fmt.Sprintf("listener %s: rule %s (priority %d, ingresses %s) is %s by rule %s (priority %d, ingresses %s)",
+		c.Listener,
+		c.Rule.Spec.RuleName, c.Rule.Spec.Priority, strings.Join(c.Rule.Spec.Ingresses, ","),
+		verb,
+		c.ShadowedBy.Spec.RuleName, c.ShadowedBy.Spec.Priority, strings.Join(c.ShadowedBy.Spec.Ingresses, ","))
+}
+
+// AnalyzeListenerRules finds the listener rules in the stack which are duplicated or shadowed by a rule of higher
+// priority on the same listener. The analysis is conservative: a rule is only reported if its host and path are
+// covered by the other rule, and the other rule has no other condition than the ones of the rule.
+func AnalyzeListenerRules(stack core.Manager) []RuleConflict {
+	if stack == nil {
+		return nil
+	}
+	var resLRs []*alb.ListenerRule
+	_ = stack.ListResources(&resLRs)
+
+	rulesByListener := make(map[string][]*alb.ListenerRule)
+	for _, lr := range resLRs {
+		key := listenerRuleListenerKey(lr)
+		rulesByListener[key] = append(rulesByListener[key], lr)
+	}
+	listeners := make([]string, 0, len(rulesByListener))
+	for key := range rulesByListener {
+		listeners = append(listeners, key)
+	}
+	sort.Strings(listeners)
+
+	var conflicts []RuleConflict
+	for _, listener := range listeners {
+		rules := rulesByListener[listener]
+		sort.Slice(rules, func(i, j int) bool {
+			return rules[i].Spec.Priority < rules[j].Spec.Priority
+		})
+		for i, lr := range rules {
+			for _, higher := range rules[:i] {
+				if higher.Spec.RuleDirection != lr.Spec.RuleDirection ||
+					!conditionsCover(higher.Spec.RuleConditions, lr.Spec.RuleConditions) {
+					continue
+				}
+				conflict := RuleConflict{Type: RuleConflictShadowed, Listener: listener, Rule: lr, ShadowedBy: higher}
+				if conditionsCover(lr.Spec.RuleConditions, higher.Spec.RuleConditions) {
+					conflict.Type = RuleConflictDuplicate
+				}
+				conflicts = append(conflicts, conflict)
+				break
+			}
+		}
+	}
+	return conflicts
+}
+
+func listenerRuleListenerKey(lr *alb.ListenerRule) string {
+	if deps := lr.Spec.ListenerID.Dependencies(); len(deps) != 0 {
+		return deps[0].ID()
+	}
+	id, _ := lr.Spec.ListenerID.Resolve(context.Background())
+	return id
+}
+
+// conditionsCover returns true if every request matched by the conditions b is matched by the conditions a
+func conditionsCover(a, b []alb.Condition) bool {
+	if !patternsCover(conditionValues(a, util.RuleConditionFieldHost), conditionValues(b, util.RuleConditionFieldHost), true) {
+		return false
+	}
+	if !patternsCover(conditionValues(a, util.RuleConditionFieldPath), conditionValues(b, util.RuleConditionFieldPath), false) {
+		return false
+	}
+	for _, condA := range a {
+		if condA.Type == util.RuleConditionFieldHost || condA.Type == util.RuleConditionFieldPath {
+			continue
+		}
+		found := false
+		for _, condB := range b {
+			if reflect.DeepEqual(condA, condB) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// conditionValues returns the values of the host or path conditions, nil if there is no such condition
+func conditionValues(conditions []alb.Condition, condType string) []string {
+	var values []string
+	found := false
+	for _, cond := range conditions {
+		if cond.Type != condType {
+			continue
+		}
+		found = true
+		switch condType {
+		case util.RuleConditionFieldHost:
+			values = append(values, cond.HostConfig.Values...)
+		case util.RuleConditionFieldPath:
+			values = append(values, cond.PathConfig.Values...)
+		}
+	}
+	if !found {
+		return nil
+	}
+	return values
+}
+
+// patternsCover returns true if every value matched by one of the patterns b is matched by one of the patterns a.
+// No pattern matches any value.
+func patternsCover(a, b []string, ignoreCase bool) bool {
+	if a == nil {
+		return true
+	}
+	if b == nil {
+		b = []string{"*"}
+	}
+	if ignoreCase {
+		a, b = toLowerAll(a), toLowerAll(b)
+	}
+	for _, pb := range b {
+		covered := false
+		for _, pa := range a {
+			if patternCovers(pa, pb) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+func toLowerAll(values []string) []string {
+	lower := make([]string, 0, len(values))
+	for _, v := range values {
+		lower = append(lower, strings.ToLower(v))
+	}
+	return lower
+}
+
+// patternCovers returns true if every value matched by the pattern b is matched by the pattern a.
+// Patterns may contain the wildcards * and ?, regex patterns start with ~ and only cover themselves.
+func patternCovers(a, b string) bool {
+	if a == b {
+		return true
+	}
+	if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+		return false
+	}
+	if !strings.ContainsAny(b, "*?") {
+		return wildcardMatch(a, b)
+	}
+	// a pattern like prefix* covers the patterns whose literal prefix starts with the prefix
+	if i := strings.IndexAny(a, "*?"); i == len(a)-1 && a[i] == '*' {
+		prefix := a[:i]
+		literal := b[:strings.IndexAny(b, "*?")]
+		return strings.HasPrefix(literal, prefix)
+	}
+	return false
+}
+
+// wildcardMatch matches the value against the pattern, * matches any sequence of characters and ? matches one
+func wildcardMatch(pattern, value string) bool {
+	p, v := 0, 0
+	star, match := -1, 0
+	for v < len(value) {
+		if p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]) {
+			p++
+			v++
+		} else if p < len(pattern) && pattern[p] == '*' {
+			star, match = p, v
+			p++
+		} else if star >= 0 {
+			p = star + 1
+			match++
+			v = match
+		} else {
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,242 @@
+package albconfigmanager
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func hostCondition(hosts ...string) alb.Condition {
+	return alb.Condition{Type: util.RuleConditionFieldHost, HostConfig: alb.HostConfig{Values: hosts}}
+}
+
+func pathCondition(paths ...string) alb.Condition {
+	return alb.Condition{Type: util.RuleConditionFieldPath, PathConfig: alb.PathConfig{Values: paths}}
+}
+
+func headerCondition(key, value string) alb.Condition {
+	return alb.Condition{Type: util.RuleConditionFieldHeader, HeaderConfig: alb.HeaderConfig{Key: key, Values: []string{value}}}
+}
+
+func TestSortListenerRulesByPrecedence(t *testing.T) {
+	rule := func(name string, order int64, explicit bool, conditions ...alb.Condition) orderedListenerRule {
+		return orderedListenerRule{
+			rule:          alb.ListenerRule{Spec: alb.ListenerRuleSpec{ALBListenerRuleSpec: alb.ALBListenerRuleSpec{RuleName: name}}},
+			order:         order,
+			explicitOrder: explicit,
+			specificity:   buildRuleSpecificity(conditions),
+		}
+	}
+	rules := []orderedListenerRule{
+		rule("catch-all", defaultGroupOrder, false, pathCondition("/*")),
+		rule("regex", defaultGroupOrder, false, pathCondition("~*/api/v[0-9]+")),
+		rule("prefix-api", defaultGroupOrder, false, pathCondition("/api", "/api/*")),
+		rule("exact-api-health", defaultGroupOrder, false, pathCondition("/api/health")),
+		rule("prefix-api-v1", defaultGroupOrder, false, pathCondition("/api/v1", "/api/v1/*")),
+		rule("prefix-api-host", defaultGroupOrder, false, hostCondition("demo.example.com"), pathCondition("/api", "/api/*")),
+		rule("prefix-api-header", defaultGroupOrder, false, hostCondition("demo.example.com"), pathCondition("/api", "/api/*"),
+			headerCondition("x-canary", "true")),
+		rule("prefix-api-wildcard-host", defaultGroupOrder, false, hostCondition("*.example.com"), pathCondition("/api", "/api/*")),
+		rule("explicit-catch-all", 1, true, pathCondition("/*")),
+		rule("explicit-exact", 1, true, pathCondition("/exact")),
+		rule("late", 20, true, pathCondition("/late")),
+	}
+	sortListenerRulesByPrecedence(rules)
+
+	names := make([]string, 0, len(rules))
+	for _, r := range rules {
+		names = append(names, r.rule.Spec.RuleName)
+	}
+	assert.Equal(t, []string{
+		"explicit-catch-all",
+		"explicit-exact",
+		"exact-api-health",
+		"prefix-api-v1",
+		"prefix-api-header",
+		"prefix-api-host",
+		"prefix-api-wildcard-host",
+		"prefix-api",
+		"catch-all",
+		"regex",
+		"late",
+	}, names)
+}
+
+func TestBuildListenerRulesCommonPrecedence(t *testing.T) {
+	exact, prefix := networking.PathTypeExact, networking.PathTypePrefix
+	newIngress := func(name string, paths ...networking.HTTPIngressPath) networking.Ingress {
+		for i := range paths {
+			paths[i].Backend.Service = &networking.IngressServiceBackend{Name: name, Port: networking.ServiceBackendPort{Number: 80}}
+		}
+		return networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec: networking.IngressSpec{Rules: []networking.IngressRule{{
+				IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{Paths: paths}},
+			}}},
+		}
+	}
+	task := &defaultModelBuildTask{stack: core.NewDefaultManager(core.StackID{Name: "alb"})}
+	err := task.buildListenerRulesCommon(context.TODO(), core.LiteralStringToken("lsn-80"), 80, []networking.Ingress{
+		newIngress("catch-all", networking.HTTPIngressPath{Path: "/", PathType: &prefix}),
+		newIngress("api", networking.HTTPIngressPath{Path: "/api/health", PathType: &exact}),
+	})
+	assert.NoError(t, err)
+
+	var lrs []*alb.ListenerRule
+	_ = task.stack.ListResources(&lrs)
+	ingresses := make(map[int]string)
+	for _, lr := range lrs {
+		ingresses[lr.Spec.Priority] = lr.Spec.Ingresses[0]
+	}
+	// the rules of the knative ingresses are allocated by specificity as well
+	assert.Equal(t, map[int]string{1: "default/api", 2: "default/catch-all"}, ingresses)
+}
+
+func TestPatternCovers(t *testing.T) {
+	cases := []struct {
+		a, b    string
+		covered bool
+	}{
+		{a: "/api", b: "/api", covered: true},
+		{a: "/api", b: "/api/v1"},
+		{a: "/*", b: "/api/v1", covered: true},
+		{a: "/api/*", b: "/api/v1/*", covered: true},
+		{a: "/api/v1/*", b: "/api/*"},
+		{a: "/api/*", b: "/api/v?/users", covered: true},
+		{a: "/a?c", b: "/abc", covered: true},
+		{a: "/a?c", b: "/a*c"},
+		{a: "~*/api", b: "/api"},
+		{a: "/*", b: "~*/api"},
+		{a: "~*/api", b: "~*/api", covered: true},
+		{a: "*.example.com", b: "demo.example.com", covered: true},
+		{a: "demo.example.com", b: "*.example.com"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.covered, patternCovers(c.a, c.b), "%s covers %s", c.a, c.b)
+	}
+}
+
+func TestConditionsCover(t *testing.T) {
+	cases := []struct {
+		name    string
+		a, b    []alb.Condition
+		covered bool
+	}{
+		{
+			name:    "any host",
+			a:       []alb.Condition{pathCondition("/*")},
+			b:       []alb.Condition{hostCondition("demo.example.com"), pathCondition("/api")},
+			covered: true,
+		},
+		{
+			name: "other host",
+			a:    []alb.Condition{hostCondition("a.example.com"), pathCondition("/*")},
+			b:    []alb.Condition{hostCondition("b.example.com"), pathCondition("/api")},
+		},
+		{
+			name:    "host case insensitive",
+			a:       []alb.Condition{hostCondition("Demo.Example.com"), pathCondition("/api", "/api/*")},
+			b:       []alb.Condition{hostCondition("demo.example.com"), pathCondition("/api/v1", "/api/v1/*")},
+			covered: true,
+		},
+		{
+			name: "path prefix only partly covered",
+			a:    []alb.Condition{pathCondition("/api/*")},
+			b:    []alb.Condition{pathCondition("/api", "/api/*")},
+		},
+		{
+			name: "extra header",
+			a:    []alb.Condition{pathCondition("/*"), headerCondition("x-canary", "true")},
+			b:    []alb.Condition{pathCondition("/api")},
+		},
+		{
+			name:    "same header",
+			a:       []alb.Condition{pathCondition("/*"), headerCondition("x-canary", "true")},
+			b:       []alb.Condition{pathCondition("/api"), headerCondition("x-canary", "true")},
+			covered: true,
+		},
+		{
+			name: "no path",
+			a:    []alb.Condition{pathCondition("/api/*")},
+			b:    []alb.Condition{hostCondition("demo.example.com")},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.covered, conditionsCover(c.a, c.b))
+		})
+	}
+}
+
+func TestAnalyzeListenerRules(t *testing.T) {
+	assert.Nil(t, AnalyzeListenerRules(nil))
+
+	stack := core.NewDefaultManager(core.StackID{})
+	newListener := func(port int) *alb.Listener {
+		return alb.NewListener(stack, fmt.Sprintf("%d", port), alb.ListenerSpec{
+			LoadBalancerID:  core.LiteralStringToken("alb-1"),
+			ALBListenerSpec: alb.ALBListenerSpec{ListenerPort: port, ListenerProtocol: "HTTP"},
+		})
+	}
+	ls1, ls2 := newListener(80), newListener(443)
+	newRule := func(ls *alb.Listener, priority int, ingress string, conditions ...alb.Condition) *alb.ListenerRule {
+		return alb.NewListenerRule(stack, fmt.Sprintf("%s:%d", ls.ID(), priority), alb.ListenerRuleSpec{
+			ListenerID: ls.ListenerID(),
+			ALBListenerRuleSpec: alb.ALBListenerRuleSpec{
+				Priority:       priority,
+				RuleName:       fmt.Sprintf("rule-%s-%d", ls.ID(), priority),
+				RuleConditions: conditions,
+			},
+			Ingresses: []string{ingress},
+		})
+	}
+	apiRule := newRule(ls1, 1, "default/a", hostCondition("demo.example.com"), pathCondition("/api", "/api/*"))
+	duplicate := newRule(ls1, 2, "default/b", hostCondition("demo.example.com"), pathCondition("/api", "/api/*"))
+	shadowed := newRule(ls1, 3, "default/c", hostCondition("demo.example.com"), pathCondition("/api/v1", "/api/v1/*"))
+	newRule(ls1, 4, "default/d", hostCondition("other.example.com"), pathCondition("/api", "/api/*"))
+	newRule(ls1, 5, "default/e", hostCondition("demo.example.com"), pathCondition("/web"))
+	// the same conditions on another listener don't conflict
+	newRule(ls2, 1, "default/c", hostCondition("demo.example.com"), pathCondition("/api/v1", "/api/v1/*"))
+
+	conflicts := AnalyzeListenerRules(stack)
+	if assert.Equal(t, 2, len(conflicts)) {
+		assert.Equal(t, RuleConflictDuplicate, conflicts[0].Type)
+		assert.Equal(t, duplicate, conflicts[0].Rule)
+		assert.Equal(t, apiRule, conflicts[0].ShadowedBy)
+		assert.Equal(t, RuleConflictShadowed, conflicts[1].Type)
+		assert.Equal(t, shadowed, conflicts[1].Rule)
+		assert.Equal(t, apiRule, conflicts[1].ShadowedBy)
+		assert.Contains(t, conflicts[1].String(), "default/c")
+		assert.Contains(t, conflicts[1].String(), "shadowed")
+	}
+}
+
+func TestBuildListenerRuleName(t *testing.T) {
+	spec := func(ingress string, conditions ...alb.Condition) alb.ListenerRuleSpec {
+		return alb.ListenerRuleSpec{
+			ALBListenerRuleSpec: alb.ALBListenerRuleSpec{RuleConditions: conditions},
+			Ingresses:           []string{ingress},
+		}
+	}
+	names := make(map[string]int)
+	api := buildListenerRuleName(80, spec("default/a", pathCondition("/api")), names)
+	assert.Regexp(t, "^rule-80-[0-9a-f]{16}$", api)
+
+	// the priority and the actions don't change the name
+	moved := spec("default/a", pathCondition("/api"))
+	moved.Priority = 10
+	moved.RuleActions = []alb.Action{{Type: util.RuleActionTypeFixedResponse}}
+	assert.Equal(t, api, buildListenerRuleName(80, moved, make(map[string]int)))
+
+	assert.NotEqual(t, api, buildListenerRuleName(80, spec("default/b", pathCondition("/api")), names))
+	assert.NotEqual(t, api, buildListenerRuleName(80, spec("default/a", pathCondition("/web")), names))
+	assert.NotEqual(t, api, buildListenerRuleName(443, spec("default/a", pathCondition("/api")), names))
+	assert.Equal(t, api+"-2", buildListenerRuleName(80, spec("default/a", pathCondition("/api")), names))
+}
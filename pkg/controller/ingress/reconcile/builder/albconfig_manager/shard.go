@@ -0,0 +1,324 @@
+package albconfigmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/configcache"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// DefaultMaxShards is the max number of ALB instances of a sharded Ingress group if spec.sharding.maxShards is not set
+	DefaultMaxShards = 5
+	shardNameFormat  = "%s-shard-%d"
+)
+
+// Shard is a part of the hosts of an Ingress group served by its own ALB instance. The first shard is served by the
+// ALB instance of the AlbConfig, the others by the ALB instances created for the shards.
+type Shard struct {
+	Name  string
+	Hosts []string
+	// AlbConfig is the albconfig of the ALB instance of the shard, derived from the albconfig of the group
+	AlbConfig *v1.AlbConfig
+	// Group holds the members of the group with the rules of the hosts of the shard only
+	Group *Group
+}
+
+// IsShardingEnabled returns true if the Ingress group may be split across several ALB instances
+func IsShardingEnabled(albconfig *v1.AlbConfig) bool {
+	return albconfig.Spec.Sharding != nil && albconfig.Spec.Sharding.Enabled
+}
+
+// IsSharded returns true if the Ingress group is served by several ALB instances
+func IsSharded(albconfig *v1.AlbConfig) bool {
+	return len(albconfig.Status.Shards) > 1
+}
+
+// ShardIngressGroup splits the Ingress group by host, so that the rules of each shard are within the quota of a
+// listener and its server groups within the quota of an instance. The hosts keep the shard recorded in the albconfig
+// status as long as the shard is within the quota. The rules without host are served by the first shard.
+func ShardIngressGroup(albconfig *v1.AlbConfig, ingGroup *Group, quota Quota) ([]*Shard, error) {
+	maxShards := DefaultMaxShards
+	if albconfig.Spec.Sharding != nil && albconfig.Spec.Sharding.MaxShards > 0 {
+		maxShards = albconfig.Spec.Sharding.MaxShards
+	}
+	assignments, err := assignShardHosts(albconfig.Name, countHostLoads(ingGroup.Members), albconfig.Status.Shards,
+		quota, maxShards)
+	if err != nil {
+		return nil, err
+	}
+
+	shards := make([]*Shard, 0, len(assignments))
+	for i, assignment := range assignments {
+		shard := &Shard{
+			Name:      assignment.Name,
+			Hosts:     assignment.Hosts,
+			AlbConfig: albconfig,
+			Group:     filterGroupByHosts(ingGroup, ingGroup.ID, sets.NewString(assignment.Hosts...), i == 0),
+		}
+		if i != 0 {
+			shard.AlbConfig = buildShardAlbConfig(albconfig, assignment.Name)
+			shard.Group.ID = GroupID{Namespace: ingGroup.ID.Namespace, Name: assignment.Name}
+		}
+		shards = append(shards, shard)
+	}
+	return shards, nil
+}
+
+// ShardGroupIDs returns the group ids of the shards other than the first one recorded in the albconfig status, which
+// are the stack ids the resources of the shards are tagged with
+func ShardGroupIDs(albconfig *v1.AlbConfig, id GroupID) []GroupID {
+	var ids []GroupID
+	for i, status := range albconfig.Status.Shards {
+		if i != 0 {
+			ids = append(ids, GroupID{Namespace: id.Namespace, Name: status.Name})
+		}
+	}
+	return ids
+}
+
+// StaleShards returns the shards recorded in the albconfig status which are no longer used. Their albconfig is
+// deleting, so that building them results in an empty stack which deletes the ALB instance of the shard.
+func StaleShards(albconfig *v1.AlbConfig, ingGroup *Group, shards []*Shard) []*Shard {
+	used := sets.NewString()
+	for _, shard := range shards {
+		used.Insert(shard.Name)
+	}
+	var stale []*Shard
+	for i, status := range albconfig.Status.Shards {
+		if i == 0 || used.Has(status.Name) {
+			continue
+		}
+		shardCfg := buildShardAlbConfig(albconfig, status.Name)
+		now := metav1.Now()
+		shardCfg.DeletionTimestamp = &now
+		stale = append(stale, &Shard{
+			Name:      status.Name,
+			AlbConfig: shardCfg,
+			Group:     &Group{ID: GroupID{Namespace: ingGroup.ID.Namespace, Name: status.Name}},
+		})
+	}
+	return stale
+}
+
+// buildShardAlbConfig derives the albconfig of the ALB instance of a shard, the instance is always created by the
+// controller, even if the albconfig reuses an existing instance.
+func buildShardAlbConfig(albconfig *v1.AlbConfig, name string) *v1.AlbConfig {
+	shardCfg := albconfig.DeepCopy()
+	shardCfg.Name = name
+	shardCfg.Status = v1.IngressStatus{}
+	if shardCfg.Spec.LoadBalancer != nil {
+		shardCfg.Spec.LoadBalancer.Id = ""
+		if shardCfg.Spec.LoadBalancer.Name != "" {
+			shardCfg.Spec.LoadBalancer.Name = fmt.Sprintf("%s-%s", shardCfg.Spec.LoadBalancer.Name, name)
+		}
+	}
+	return shardCfg
+}
+
+// hostLoad is the resources used by the rules of a host, the forwarding rules on a listener and the server groups
+type hostLoad struct {
+	rules int
+	// serverGroups are the resource ids of the server groups forwarded to by the rules of the host
+	serverGroups sets.String
+}
+
+// countHostLoads returns the number of paths of each host, which is the max number of rules of the host on a listener,
+// and the server groups of the backends of the paths
+func countHostLoads(members []*networking.Ingress) map[string]*hostLoad {
+	hostLoads := make(map[string]*hostLoad)
+	for _, ing := range members {
+		// the paths of a canary ingress are merged into the rules of the ingress it belongs to, their backends still
+		// have their own server groups
+		canary := annotations.GetStringAnnotationMutil(annotations.NginxCanary, annotations.AlbCanary, ing) == "true"
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			load, ok := hostLoads[rule.Host]
+			if !ok {
+				load = &hostLoad{serverGroups: sets.NewString()}
+				hostLoads[rule.Host] = load
+			}
+			if !canary {
+				load.rules += len(rule.HTTP.Paths)
+			}
+			for _, path := range rule.HTTP.Paths {
+				load.serverGroups.Insert(pathServerGroups(ing, path)...)
+			}
+		}
+	}
+	return hostLoads
+}
+
+// pathServerGroups returns the resource ids of the server groups of the Services the path forwards to, by the
+// forward actions of the annotation of the backend if any
+func pathServerGroups(ing *networking.Ingress, path networking.HTTPIngressPath) []string {
+	if path.Backend.Service == nil {
+		return nil
+	}
+	serverGroupID := func(ingName string, svcKey types.NamespacedName, port interface{}) string {
+		return fmt.Sprintf("%s/%s-%s:%v", svcKey.Namespace, ingName, svcKey.Name, port)
+	}
+	actionStr, ok := ing.Annotations[fmt.Sprintf(annotations.INGRESS_ALB_ACTIONS_ANNOTATIONS, path.Backend.Service.Name)]
+	if !ok {
+		var port interface{} = path.Backend.Service.Port.Number
+		if path.Backend.Service.Port.Name != "" {
+			port = path.Backend.Service.Port.Name
+		}
+		return []string{serverGroupID(ing.Name, types.NamespacedName{Namespace: ing.Namespace, Name: path.Backend.Service.Name}, port)}
+	}
+	var actions []configcache.Action
+	if err := json.Unmarshal([]byte(actionStr), &actions); err != nil {
+		return nil
+	}
+	var ids []string
+	for _, action := range actions {
+		if !strings.EqualFold(action.Type, util.RuleActionTypeForward) || action.ForwardConfig == nil {
+			continue
+		}
+		for _, sgp := range action.ForwardConfig.ServerGroups {
+			if sgp.ServerGroupID != "" {
+				continue
+			}
+			svcKey := sgp.ServiceKey(ing.Namespace)
+			ingName := ing.Name
+			if svcKey.Namespace != ing.Namespace {
+				ingName = CrossNamespaceIngressName(ing)
+			}
+			ids = append(ids, serverGroupID(ingName, svcKey, sgp.ServicePort))
+		}
+	}
+	return ids
+}
+
+// assignShardHosts assigns the hosts to the shards, the first shard is named after the albconfig and serves the
+// rules without host. The hosts are kept on the shard recorded in the status unless the shard exceeds the quota,
+// the other hosts are assigned to the first shard with room, a new shard is added if there is none.
+func assignShardHosts(name string, hostLoads map[string]*hostLoad, recorded []v1.ShardStatus, quota Quota, maxShards int) ([]v1.ShardStatus, error) {
+	type shardLoad struct {
+		status       v1.ShardStatus
+		rules        int
+		serverGroups sets.String
+	}
+	newShardLoad := func(name string) *shardLoad {
+		return &shardLoad{status: v1.ShardStatus{Name: name}, serverGroups: sets.NewString()}
+	}
+	fits := func(shard *shardLoad, load *hostLoad) bool {
+		return shard.rules+load.rules <= quota.ListenerRules &&
+			shard.serverGroups.Union(load.serverGroups).Len() <= quota.ServerGroups
+	}
+	add := func(shard *shardLoad, load *hostLoad) {
+		shard.rules += load.rules
+		shard.serverGroups.Insert(load.serverGroups.UnsortedList()...)
+	}
+
+	assigned := sets.NewString()
+	shards := []*shardLoad{newShardLoad(name)}
+	if load, ok := hostLoads[""]; ok {
+		add(shards[0], load)
+		assigned.Insert("")
+	}
+	for i, status := range recorded {
+		shard := shards[0]
+		if i != 0 {
+			shard = newShardLoad(status.Name)
+			shards = append(shards, shard)
+		}
+		for _, host := range status.Hosts {
+			load, ok := hostLoads[host]
+			if !ok || assigned.Has(host) || !fits(shard, load) {
+				continue
+			}
+			shard.status.Hosts = append(shard.status.Hosts, host)
+			add(shard, load)
+			assigned.Insert(host)
+		}
+	}
+
+	hosts := make([]string, 0, len(hostLoads))
+	for host := range hostLoads {
+		if !assigned.Has(host) {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		load := hostLoads[host]
+		if load.rules > quota.ListenerRules {
+			return nil, fmt.Errorf("host %s has %d forwarding rules, which exceed the quota %d of a listener", host, load.rules, quota.ListenerRules)
+		}
+		if load.serverGroups.Len() > quota.ServerGroups {
+			return nil, fmt.Errorf("host %s has %d server groups, which exceed the quota %d of an instance", host, load.serverGroups.Len(), quota.ServerGroups)
+		}
+		var target *shardLoad
+		for _, shard := range shards {
+			if fits(shard, load) {
+				target = shard
+				break
+			}
+		}
+		if target == nil {
+			names := sets.NewString()
+			for _, shard := range shards {
+				names.Insert(shard.status.Name)
+			}
+			target = newShardLoad(nextShardName(name, names))
+			shards = append(shards, target)
+		}
+		target.status.Hosts = append(target.status.Hosts, host)
+		add(target, load)
+	}
+
+	result := make([]v1.ShardStatus, 0, len(shards))
+	for i, shard := range shards {
+		if i != 0 && len(shard.status.Hosts) == 0 {
+			continue
+		}
+		sort.Strings(shard.status.Hosts)
+		result = append(result, shard.status)
+	}
+	if len(result) > maxShards {
+		return nil, fmt.Errorf("the hosts need %d ALB instances, which exceed the max shards %d", len(result), maxShards)
+	}
+	return result, nil
+}
+
+// nextShardName returns the shard name with the smallest index not used
+func nextShardName(name string, used sets.String) string {
+	for i := 1; ; i++ {
+		if shardName := fmt.Sprintf(shardNameFormat, name, i); !used.Has(shardName) {
+			return shardName
+		}
+	}
+}
+
+// filterGroupByHosts returns a group whose members only have the rules of the hosts, the members without any rule
+// left are removed. The rules without host are kept if anyHost is true.
+func filterGroupByHosts(ingGroup *Group, id GroupID, hosts sets.String, anyHost bool) *Group {
+	shardGroup := &Group{ID: id}
+	for _, member := range ingGroup.Members {
+		var rules []networking.IngressRule
+		for _, rule := range member.Spec.Rules {
+			if hosts.Has(rule.Host) || (anyHost && rule.Host == "") {
+				rules = append(rules, rule)
+			}
+		}
+		if len(rules) == 0 && !(anyHost && len(member.Spec.Rules) == 0) {
+			continue
+		}
+		shardMember := member.DeepCopy()
+		shardMember.Spec.Rules = rules
+		shardGroup.Members = append(shardGroup.Members, shardMember)
+	}
+	return shardGroup
+}
@@ -0,0 +1,239 @@
+package albconfigmanager
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestGetQuota(t *testing.T) {
+	albconfig := &v1.AlbConfig{Spec: v1.AlbConfigSpec{LoadBalancer: &v1.LoadBalancerSpec{}}}
+	assert.Equal(t, editionQuotas[util.LoadBalancerEditionBasic], GetQuota(albconfig))
+
+	albconfig.Spec.LoadBalancer.Edition = util.LoadBalancerEditionStandard
+	assert.Equal(t, editionQuotas[util.LoadBalancerEditionStandard], GetQuota(albconfig))
+
+	albconfig.Spec.Quota = &v1.QuotaSpec{ListenerRules: 1000}
+	quota := GetQuota(albconfig)
+	assert.Equal(t, 1000, quota.ListenerRules)
+	assert.Equal(t, editionQuotas[util.LoadBalancerEditionStandard].ServerGroups, quota.ServerGroups)
+}
+
+func TestCheckQuota(t *testing.T) {
+	assert.Nil(t, CheckQuota(nil, Quota{}))
+
+	stack := core.NewDefaultManager(core.StackID{})
+	ls := alb.NewListener(stack, "80", alb.ListenerSpec{
+		LoadBalancerID:  core.LiteralStringToken("alb-1"),
+		ALBListenerSpec: alb.ALBListenerSpec{ListenerPort: 80, ListenerProtocol: "HTTP"},
+	})
+	for i := 1; i <= 3; i++ {
+		alb.NewListenerRule(stack, fmt.Sprintf("80:%d", i), alb.ListenerRuleSpec{
+			ListenerID:          ls.ListenerID(),
+			ALBListenerRuleSpec: alb.ALBListenerRuleSpec{Priority: i},
+		})
+	}
+	alb.NewServerGroup(stack, "sgp-1", alb.ServerGroupSpec{})
+
+	assert.Empty(t, CheckQuota(stack, Quota{ListenerRules: 3, ServerGroups: 1}))
+	violations := CheckQuota(stack, Quota{ListenerRules: 2, ServerGroups: 0})
+	if assert.Equal(t, 2, len(violations)) {
+		assert.Equal(t, QuotaViolation{Resource: "80", Count: 3, Limit: 2}, violations[0])
+		assert.Contains(t, violations[0].String(), "listener 80")
+		assert.Equal(t, QuotaViolation{Count: 1, Limit: 0}, violations[1])
+	}
+}
+
+func newHostIngress(name string, paths map[string]int) *networking.Ingress {
+	ing := &networking.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	for host, n := range paths {
+		rule := networking.IngressRule{Host: host, IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{}}}
+		for i := 0; i < n; i++ {
+			rule.HTTP.Paths = append(rule.HTTP.Paths, networking.HTTPIngressPath{Path: fmt.Sprintf("/%d", i)})
+		}
+		ing.Spec.Rules = append(ing.Spec.Rules, rule)
+	}
+	return ing
+}
+
+// newHostLoads returns the loads of the hosts with the rules, the server groups of each rule are not shared
+func newHostLoads(hostRules map[string]int) map[string]*hostLoad {
+	hostLoads := make(map[string]*hostLoad)
+	for host, rules := range hostRules {
+		load := &hostLoad{rules: rules, serverGroups: sets.NewString()}
+		for i := 0; i < rules; i++ {
+			load.serverGroups.Insert(fmt.Sprintf("%s:%d", host, i))
+		}
+		hostLoads[host] = load
+	}
+	return hostLoads
+}
+
+func TestAssignShardHosts(t *testing.T) {
+	hostRules := map[string]int{"": 1, "a.com": 2, "b.com": 2, "c.com": 3, "d.com": 1}
+	quota := Quota{ListenerRules: 4, ServerGroups: 100}
+
+	shards, err := assignShardHosts("alb", newHostLoads(hostRules), nil, quota, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []v1.ShardStatus{
+		{Name: "alb", Hosts: []string{"a.com", "d.com"}},
+		{Name: "alb-shard-1", Hosts: []string{"b.com"}},
+		{Name: "alb-shard-2", Hosts: []string{"c.com"}},
+	}, shards)
+
+	// the hosts stay on their shard, a new host fills the first shard with room
+	hostRules["e.com"] = 2
+	delete(hostRules, "d.com")
+	shards, err = assignShardHosts("alb", newHostLoads(hostRules), shards, quota, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []v1.ShardStatus{
+		{Name: "alb", Hosts: []string{"a.com"}},
+		{Name: "alb-shard-1", Hosts: []string{"b.com", "e.com"}},
+		{Name: "alb-shard-2", Hosts: []string{"c.com"}},
+	}, shards)
+
+	// a host which outgrows its shard moves, an empty shard is dropped and its name is reused
+	hostRules = map[string]int{"a.com": 2, "c.com": 1, "f.com": 4}
+	shards, err = assignShardHosts("alb", newHostLoads(hostRules), []v1.ShardStatus{
+		{Name: "alb", Hosts: []string{"a.com"}},
+		{Name: "alb-shard-2", Hosts: []string{"c.com"}},
+	}, quota, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []v1.ShardStatus{
+		{Name: "alb", Hosts: []string{"a.com"}},
+		{Name: "alb-shard-2", Hosts: []string{"c.com"}},
+		{Name: "alb-shard-1", Hosts: []string{"f.com"}},
+	}, shards)
+
+	_, err = assignShardHosts("alb", newHostLoads(map[string]int{"a.com": 5}), nil, quota, 5)
+	assert.Error(t, err)
+	_, err = assignShardHosts("alb", newHostLoads(map[string]int{"a.com": 4, "b.com": 4}), nil, quota, 1)
+	assert.Error(t, err)
+}
+
+func TestAssignShardHostsByServerGroups(t *testing.T) {
+	quota := Quota{ListenerRules: 100, ServerGroups: 3}
+
+	// the rules fit into a listener, the server groups do not fit into an instance
+	shards, err := assignShardHosts("alb", newHostLoads(map[string]int{"a.com": 2, "b.com": 2}), nil, quota, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []v1.ShardStatus{
+		{Name: "alb", Hosts: []string{"a.com"}},
+		{Name: "alb-shard-1", Hosts: []string{"b.com"}},
+	}, shards)
+
+	// the server groups shared by the hosts are counted once
+	hostLoads := newHostLoads(map[string]int{"a.com": 2, "b.com": 2})
+	hostLoads["b.com"].serverGroups = hostLoads["a.com"].serverGroups
+	shards, err = assignShardHosts("alb", hostLoads, nil, quota, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []v1.ShardStatus{{Name: "alb", Hosts: []string{"a.com", "b.com"}}}, shards)
+
+	_, err = assignShardHosts("alb", newHostLoads(map[string]int{"a.com": 4}), nil, quota, 5)
+	assert.Error(t, err)
+}
+
+func TestCountHostLoads(t *testing.T) {
+	backend := func(svc string, port int32) networking.IngressBackend {
+		return networking.IngressBackend{Service: &networking.IngressServiceBackend{
+			Name: svc, Port: networking.ServiceBackendPort{Number: port}}}
+	}
+	ing := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", Annotations: map[string]string{
+			"alb.ingress.kubernetes.io/actions.forward": `[{"type":"ForwardGroup","ForwardConfig":{"ServerGroups":[` +
+				`{"ServiceName":"v1","ServicePort":80,"Weight":50},{"ServiceName":"other/v2","ServicePort":80,"Weight":50},` +
+				`{"ServerGroupID":"sgp-fixed","Weight":0}]}}]`,
+		}},
+		Spec: networking.IngressSpec{Rules: []networking.IngressRule{
+			{Host: "a.com", IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{Paths: []networking.HTTPIngressPath{
+				{Path: "/", Backend: backend("web", 80)},
+				{Path: "/api", Backend: backend("web", 80)},
+			}}}},
+			{Host: "b.com", IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{Paths: []networking.HTTPIngressPath{
+				{Path: "/", Backend: backend("forward", 0)},
+			}}}},
+		}},
+	}
+	canary := newHostIngress("canary", nil)
+	canary.Annotations = map[string]string{"alb.ingress.kubernetes.io/canary": "true"}
+	canary.Spec.Rules = []networking.IngressRule{{Host: "a.com", IngressRuleValue: networking.IngressRuleValue{
+		HTTP: &networking.HTTPIngressRuleValue{Paths: []networking.HTTPIngressPath{{Path: "/", Backend: backend("web-canary", 80)}}}}}}
+
+	hostLoads := countHostLoads([]*networking.Ingress{ing, canary})
+	if assert.Equal(t, 2, len(hostLoads)) {
+		// the canary paths add their server groups, not their rules
+		assert.Equal(t, 2, hostLoads["a.com"].rules)
+		assert.Equal(t, []string{"default/canary-web-canary:80", "default/web-web:80"}, hostLoads["a.com"].serverGroups.List())
+		assert.Equal(t, 1, hostLoads["b.com"].rules)
+		assert.Equal(t, []string{"default/web-v1:80", "other/" + CrossNamespaceIngressName(ing) + "-v2:80"},
+			hostLoads["b.com"].serverGroups.List())
+	}
+}
+
+func TestShardIngressGroup(t *testing.T) {
+	albconfig := &v1.AlbConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "alb"},
+		Spec: v1.AlbConfigSpec{
+			LoadBalancer: &v1.LoadBalancerSpec{Id: "alb-reused", Name: "lb"},
+			Sharding:     &v1.ShardingSpec{Enabled: true},
+		},
+		Status: v1.IngressStatus{Shards: []v1.ShardStatus{{Name: "alb"}, {Name: "alb-shard-3", Hosts: []string{"old.com"}}}},
+	}
+	canary := newHostIngress("canary", map[string]int{"b.com": 2})
+	canary.Annotations = map[string]string{"alb.ingress.kubernetes.io/canary": "true"}
+	ingGroup := &Group{
+		ID: GroupID{Namespace: ALBConfigNamespace, Name: "alb"},
+		Members: []*networking.Ingress{
+			newHostIngress("a", map[string]int{"": 1, "a.com": 2}),
+			newHostIngress("b", map[string]int{"b.com": 2}),
+			canary,
+		},
+	}
+	assert.True(t, IsShardingEnabled(albconfig))
+	assert.True(t, IsSharded(albconfig))
+
+	shards, err := ShardIngressGroup(albconfig, ingGroup, Quota{ListenerRules: 3, ServerGroups: 10})
+	assert.NoError(t, err)
+	if !assert.Equal(t, 2, len(shards)) {
+		return
+	}
+	primary, second := shards[0], shards[1]
+	assert.Equal(t, albconfig, primary.AlbConfig)
+	assert.Equal(t, ingGroup.ID, primary.Group.ID)
+	if assert.Equal(t, 1, len(primary.Group.Members)) {
+		assert.Equal(t, 2, len(primary.Group.Members[0].Spec.Rules))
+	}
+
+	// the shard left empty is reused
+	assert.Equal(t, "alb-shard-3", second.Name)
+	assert.Equal(t, []string{"b.com"}, second.Hosts)
+	assert.Equal(t, "alb-shard-3", second.AlbConfig.Name)
+	assert.Equal(t, "", second.AlbConfig.Spec.LoadBalancer.Id)
+	assert.Equal(t, "lb-alb-shard-3", second.AlbConfig.Spec.LoadBalancer.Name)
+	assert.Equal(t, "alb-shard-3", second.Group.ID.Name)
+	assert.Equal(t, 2, len(second.Group.Members))
+	// the members of the group are not changed
+	assert.Equal(t, 2, len(ingGroup.Members[0].Spec.Rules))
+	assert.Equal(t, "alb-reused", albconfig.Spec.LoadBalancer.Id)
+
+	assert.Empty(t, StaleShards(albconfig, ingGroup, shards))
+
+	// all the hosts fit into the first shard
+	shards, err = ShardIngressGroup(albconfig, ingGroup, Quota{ListenerRules: 10, ServerGroups: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(shards))
+	stale := StaleShards(albconfig, ingGroup, shards)
+	if assert.Equal(t, 1, len(stale)) {
+		assert.Equal(t, "alb-shard-3", stale[0].Name)
+		assert.False(t, stale[0].AlbConfig.DeletionTimestamp.IsZero())
+		assert.Empty(t, stale[0].Group.Members)
+	}
+}
@@ -0,0 +1,207 @@
+package albconfigmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/configcache"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// ValidateIngress runs the annotation parsers of the model builder against the ingress,
+// so that an invalid ingress can be rejected before it is reconciled. It never calls the cloud.
+func ValidateIngress(ing *networking.Ingress) error {
+	var errs []error
+	if err := checkIngressProtocolAnnotations(ing); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkBackendSchedulerAnnotations(ing); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkIngressListenPorts(ing); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkIngressGroupOrder(ing); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkIngressIntAnnotations(ing); err != nil {
+		errs = append(errs, err)
+	}
+
+	t := &defaultModelBuildTask{}
+	qps, _ := annotations.GetStringAnnotation(annotations.AlbTrafficLimitQps, ing)
+	qpsPerIp, _ := annotations.GetStringAnnotation(annotations.AlbTrafficLimitIpQps, ing)
+	if qps != "" || qpsPerIp != "" {
+		if _, err := t.buildQpsLimitAction(context.TODO(), qps, qpsPerIp, ing); err != nil {
+			errs = append(errs, fmt.Errorf("invalid traffic limit annotations: %s", err.Error()))
+		}
+	}
+
+	// knative ingresses may still use the legacy format of actions, which is parsed by buildListenerRulesCommon
+	if _, ok := ing.Labels[util.KnativeIngress]; ok {
+		return utilerrors.NewAggregate(errs)
+	}
+	canary := annotations.GetStringAnnotationMutil(annotations.NginxCanary, annotations.AlbCanary, ing) == "true"
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+			svcName := path.Backend.Service.Name
+			conditionKey := fmt.Sprintf(annotations.INGRESS_ALB_CONDITIONS_ANNOTATIONS, svcName)
+			if _, exist := ing.Annotations[conditionKey]; exist && canary {
+				errs = append(errs, fmt.Errorf("%s: canary and customize condition can not exist at the same time", conditionKey))
+			}
+			if _, err := t.buildRuleConditions(context.TODO(), rule, path, *ing); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s", conditionKey, err.Error()))
+			}
+			if err := checkIngressActions(ing, svcName, qps, qpsPerIp); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// ValidateAlbConfig checks the listeners of the AlbConfig the same way the model builder does.
+func ValidateAlbConfig(albconfig *v1.AlbConfig) error {
+	var errs []error
+	if albconfig.Spec.LoadBalancer == nil {
+		errs = append(errs, fmt.Errorf("spec.config is required"))
+	}
+
+	ports := make(map[int]string)
+	for i, ls := range albconfig.Spec.Listeners {
+		if ls == nil {
+			continue
+		}
+		field := fmt.Sprintf("spec.listeners[%d]", i)
+		port := ls.Port.IntValue()
+		if port < 0 || port > 65535 {
+			errs = append(errs, fmt.Errorf("%s.port must be within [1, 65535]: %s", field, ls.Port.String()))
+		}
+		if ls.Protocol != "" &&
+			ls.Protocol != string(ProtocolHTTP) &&
+			ls.Protocol != string(ProtocolHTTPS) &&
+			ls.Protocol != string(ProtocolQUIC) {
+			errs = append(errs, fmt.Errorf("%s.protocol must be within [%v, %v, %v]: %s",
+				field, ProtocolHTTP, ProtocolHTTPS, ProtocolQUIC, ls.Protocol))
+		}
+		if protocol, ok := ports[port]; ok && port != 0 {
+			errs = append(errs, fmt.Errorf("%s.port %d conflicts with the %s listener on the same port", field, port, protocol))
+		}
+		ports[port] = ls.Protocol
+
+		switch ls.AclConfig.AclType {
+		case "", util.AclTypeWhite, util.AclTypeBlack:
+		default:
+			errs = append(errs, fmt.Errorf("%s.aclConfig.aclType must be within [%s, %s]: %s",
+				field, util.AclTypeWhite, util.AclTypeBlack, ls.AclConfig.AclType))
+		}
+		if len(ls.AclConfig.AclEntries) > 0 && len(ls.AclConfig.AclIds) > 0 {
+			errs = append(errs, fmt.Errorf("%s.aclConfig: aclEntry and aclIds cannot use together", field))
+		}
+		if len(ls.CaCertificateSecret) != 0 {
+			if ls.Protocol != string(ProtocolHTTPS) {
+				errs = append(errs, fmt.Errorf("%s.caCertificateSecret is only supported by %v listeners", field, ProtocolHTTPS))
+			}
+			if len(ls.CaCertificates) > 0 {
+				errs = append(errs, fmt.Errorf("%s: caCertificates and caCertificateSecret cannot use together", field))
+			}
+			if _, err := CaCertificateSecretKey(albconfig, ls.CaCertificateSecret); err != nil {
+				errs = append(errs, fmt.Errorf("%s.%s", field, err.Error()))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func checkIngressListenPorts(ing *networking.Ingress) error {
+	pps, err := ComputeIngressListenPorts(ing)
+	if err != nil {
+		return err
+	}
+	protocols := make(map[int32]Protocol)
+	for _, pp := range pps {
+		if protocol, ok := protocols[pp.Port]; ok && protocol != pp.Protocol {
+			return fmt.Errorf("conflict listen-ports configuration: port %d is used by both %s and %s", pp.Port, protocol, pp.Protocol)
+		}
+		protocols[pp.Port] = pp.Protocol
+	}
+	return nil
+}
+
+func checkIngressGroupOrder(ing *networking.Ingress) error {
+	v := annotations.GetStringAnnotationMutil(util.IngressSuffixAlbConfigOrder, annotations.Order, ing)
+	if v == "" {
+		return nil
+	}
+	order, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse Ingress group order: %s", v)
+	}
+	if order < minGroupOrder || order > maxGroupOder {
+		return fmt.Errorf("explicit Ingress group order must be within [%v:%v], order: %v", minGroupOrder, maxGroupOder, order)
+	}
+	return nil
+}
+
+// checkIngressIntAnnotations rejects the integer annotations which the builder would otherwise ignore with a log
+func checkIngressIntAnnotations(ing *networking.Ingress) error {
+	for _, key := range []string{
+		annotations.HealthCheckTimeout,
+		annotations.HealthCheckInterval,
+		annotations.HealthThreshold,
+		annotations.UnHealthThreshold,
+		annotations.HealthCheckConnectPort,
+		annotations.CookieTimeout,
+	} {
+		if v, ok := ing.Annotations[key]; ok {
+			if _, err := strconv.Atoi(v); err != nil {
+				return fmt.Errorf("%s must be an integer, got %s", key, v)
+			}
+		}
+	}
+	return nil
+}
+
+// checkIngressActions parses the actions with the same parser as the model builder. The server groups
+// of forward actions are only built into a throwaway stack.
+func checkIngressActions(ing *networking.Ingress, svcName, qps, qpsPerIp string) error {
+	actionKey := fmt.Sprintf(annotations.INGRESS_ALB_ACTIONS_ANNOTATIONS, svcName)
+	actionStr, exist := ing.Annotations[actionKey]
+	if !exist {
+		return nil
+	}
+	actions := make([]configcache.Action, 0)
+	if err := json.Unmarshal([]byte(actionStr), &actions); err != nil {
+		return fmt.Errorf("%s: failed to parse actions: %s", actionKey, err.Error())
+	}
+
+	t := &defaultModelBuildTask{
+		stack:      core.NewDefaultManager(core.StackID(types.NamespacedName{Name: "validation"})),
+		sgpByResID: make(map[string]*alb.ServerGroup),
+	}
+	for _, action := range actions {
+		if (qps != "" || qpsPerIp != "") && strings.EqualFold(action.Type, util.RuleActionTypeTrafficLimit) {
+			return fmt.Errorf("%s: can't exist action trafficlimit and annotation traffic-limit-qps at the same time", actionKey)
+		}
+		if _, err := t.toCustomAction(context.TODO(), ing, action); err != nil {
+			return fmt.Errorf("%s: %s", actionKey, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package albconfigmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/annotations"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestValidateIngress(t *testing.T) {
+	ing := getDefaultIngress()
+	assert.Nil(t, ValidateIngress(ing))
+
+	ing.Annotations[annotations.ListenPorts] = `[{"HTTP": 80}, {"HTTPS": 80}]`
+	assert.NotNil(t, ValidateIngress(ing))
+
+	ing = getDefaultIngress()
+	ing.Annotations[annotations.HealthCheckInterval] = "2s"
+	assert.NotNil(t, ValidateIngress(ing))
+
+	ing = getDefaultIngress()
+	ing.Annotations["alb.ingress.kubernetes.io/actions.tea-svc"] = `[{"type": "Unknown"}]`
+	assert.NotNil(t, ValidateIngress(ing))
+}
+
+func TestValidateAlbConfig(t *testing.T) {
+	albconfig := &v1.AlbConfig{
+		Spec: v1.AlbConfigSpec{
+			LoadBalancer: &v1.LoadBalancerSpec{},
+			Listeners: []*v1.ListenerSpec{
+				{Port: intstr.FromInt(80), Protocol: "HTTP"},
+				{Port: intstr.FromInt(443), Protocol: "HTTPS"},
+			},
+		},
+	}
+	assert.Nil(t, ValidateAlbConfig(albconfig))
+
+	albconfig.Spec.Listeners = append(albconfig.Spec.Listeners, &v1.ListenerSpec{Port: intstr.FromInt(80), Protocol: "HTTPS"})
+	assert.NotNil(t, ValidateAlbConfig(albconfig))
+
+	albconfig.Spec.Listeners = []*v1.ListenerSpec{{Port: intstr.FromInt(80), Protocol: "TCP"}}
+	assert.NotNil(t, ValidateAlbConfig(albconfig))
+
+	albconfig.Spec.Listeners = []*v1.ListenerSpec{{Port: intstr.FromInt(443), Protocol: "HTTPS", CaCertificateSecret: "default/clients"}}
+	assert.Nil(t, ValidateAlbConfig(albconfig))
+	albconfig.Spec.Listeners[0].CaCertificates = []v1.Certificate{{CertificateId: "123-cn-hangzhou"}}
+	assert.NotNil(t, ValidateAlbConfig(albconfig))
+	albconfig.Spec.Listeners = []*v1.ListenerSpec{{Port: intstr.FromInt(80), Protocol: "HTTP", CaCertificateSecret: "clients"}}
+	assert.NotNil(t, ValidateAlbConfig(albconfig))
+	albconfig.Spec.Listeners = []*v1.ListenerSpec{{Port: intstr.FromInt(443), Protocol: "HTTPS", CaCertificateSecret: "default/clients/ca"}}
+	assert.NotNil(t, ValidateAlbConfig(albconfig))
+
+	albconfig.Spec.LoadBalancer = nil
+	albconfig.Spec.Listeners = nil
+	assert.NotNil(t, ValidateAlbConfig(albconfig))
+}
+
+func getDefaultIngress() *networking.Ingress {
+	prefix := networking.PathTypePrefix
+	return &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "demo",
+			Namespace:   "default",
+			Annotations: map[string]string{},
+		},
+		Spec: networking.IngressSpec{
+			Rules: []networking.IngressRule{
+				{
+					Host: "demo.domain.ingress.top",
+					IngressRuleValue: networking.IngressRuleValue{
+						HTTP: &networking.HTTPIngressRuleValue{
+							Paths: []networking.HTTPIngressPath{
+								{
+									Path:     "/tea",
+									PathType: &prefix,
+									Backend: networking.IngressBackend{
+										Service: &networking.IngressServiceBackend{
+											Name: "tea-svc",
+											Port: networking.ServiceBackendPort{Number: 80},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestValidateIngressActions(t *testing.T) {
+	actionKey := "alb.ingress.kubernetes.io/actions.tea-svc"
+	cases := []struct {
+		name    string
+		actions string
+		qps     string
+		valid   bool
+	}{
+		{name: "fixed response", actions: `[{"type": "FixedResponse", "FixedResponseConfig": {"content": "ok", "contentType": "text/plain", "httpCode": "200"}}]`, valid: true},
+		{name: "redirect", actions: `[{"type": "Redirect", "RedirectConfig": {"httpCode": "302"}}]`, valid: true},
+		{name: "forward by service", actions: `[{"type": "ForwardGroup", "ForwardConfig": {"ServerGroups": [{"ServiceName": "tea-svc", "ServicePort": 80, "Weight": 100}]}}]`, valid: true},
+		{name: "traffic limit", actions: `[{"type": "TrafficLimit", "TrafficLimitConfig": {"QPS": "100"}}]`, valid: true},
+		{name: "redirect without config", actions: `[{"type": "Redirect"}]`},
+		{name: "fixed response without config", actions: `[{"type": "FixedResponse"}]`},
+		{name: "insert header without config", actions: `[{"type": "InsertHeader"}]`},
+		{name: "remove header without config", actions: `[{"type": "RemoveHeader"}]`},
+		{name: "rewrite without config", actions: `[{"type": "Rewrite"}]`},
+		{name: "traffic limit without config", actions: `[{"type": "TrafficLimit"}]`},
+		{name: "traffic mirror without config", actions: `[{"type": "TrafficMirror"}]`},
+		{name: "forward without server groups", actions: `[{"type": "ForwardGroup", "ForwardConfig": {}}]`},
+		{name: "invalid traffic limit", actions: `[{"type": "TrafficLimit", "TrafficLimitConfig": {"QPS": "abc"}}]`},
+		{name: "traffic limit with qps annotation", actions: `[{"type": "TrafficLimit", "TrafficLimitConfig": {"QPS": "100"}}]`, qps: "100"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ing := getDefaultIngress()
+			ing.Annotations[actionKey] = c.actions
+			if c.qps != "" {
+				ing.Annotations[annotations.AlbTrafficLimitQps] = c.qps
+			}
+			err := ValidateIngress(ing)
+			assert.Equal(t, c.valid, err == nil, "%v", err)
+		})
+	}
+}
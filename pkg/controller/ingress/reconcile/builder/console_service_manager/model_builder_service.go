@@ -3,6 +3,7 @@ package servicemanager
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
 	corev1 "k8s.io/api/core/v1"
@@ -39,6 +40,9 @@ func (s defaultConsoleServiceManagerBuilder) Build(ctx context.Context, svc *cor
 	serviceStack.ServerGroupID = svc.Annotations[annotations.AlbServerGroupId]
 	serviceStack.Namespace = svc.Namespace
 	serviceStack.Name = svc.Name
+	if strings.EqualFold(svc.Annotations[annotations.AlbServerGroupMultiCluster], "true") {
+		serviceStack.ServerDescription = alb.SharedServerDescription(clusterId, svc.Namespace, svc.Name)
+	}
 	serviceStack.ContainsPotentialReadyEndpoints = false
 	serviceStack.Backends = []alb.BackendItem{}
 	service := &corev1.Service{}
@@ -48,7 +52,7 @@ func (s defaultConsoleServiceManagerBuilder) Build(ctx context.Context, svc *cor
 	}, service); err != nil && !errors.IsNotFound(err) {
 		return nil, err
 	} else {
-		policy, err := helper.GetServiceTrafficPolicy(svc)
+		policy, err := helper.GetALBServiceTrafficPolicy(svc)
 		if err != nil {
 			return nil, err
 		}
@@ -32,11 +32,13 @@ func (b defaultServiceManagerBuilder) Build(ctx context.Context, svcStackCtx *al
 	serverStack.Namespace = svcStackCtx.ServiceNamespace
 	serverStack.Name = svcStackCtx.ServiceName
 	serverStack.IngressAlbConfigMap = svcStackCtx.IngressAlbConfigMap
+	serverStack.AlbConfigShards = svcStackCtx.AlbConfigShards
+	serverStack.IngressConnectionDrainTimeout = svcStackCtx.IngressConnectionDrainTimeout
 	port2ServerGroup := make(map[int32]*alb.ServerGroupWithIngress)
 	port2Backends := make(map[int32][]alb.BackendItem)
 	containsPotentialReadyEndpoints := false
 	if !svcStackCtx.IsServiceNotFound {
-		policy, err := helper.GetServiceTrafficPolicy(svcStackCtx.Service)
+		policy, err := helper.GetALBServiceTrafficPolicy(svcStackCtx.Service)
 		if err != nil {
 			return nil, err
 		}
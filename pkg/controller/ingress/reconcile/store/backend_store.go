@@ -0,0 +1,119 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// backendStore is a Storer which only watches endpoints and pods, for the controllers
+// which need the backends of services but read the other objects from the manager client.
+type backendStore struct {
+	endpointInformer      cache.SharedIndexInformer
+	endpointSliceInformer cache.SharedIndexInformer
+	podInformer           cache.SharedIndexInformer
+
+	endpoints      EndpointLister
+	endpointSlices EndpointSliceLister
+	pods           PodLister
+}
+
+// NewBackendStore creates a Storer which only serves GetServiceEndpoints, ListServiceEndpointSlices and GetPod.
+func NewBackendStore(namespace string, resyncPeriod time.Duration, client clientset.Interface) Storer {
+	infFactory := informers.NewSharedInformerFactoryWithOptions(client, resyncPeriod,
+		informers.WithNamespace(namespace),
+	)
+	s := &backendStore{
+		endpointInformer: infFactory.Core().V1().Endpoints().Informer(),
+		podInformer:      infFactory.Core().V1().Pods().Informer(),
+	}
+	s.endpoints.Store = s.endpointInformer.GetStore()
+	s.pods.Store = s.podInformer.GetStore()
+	if utilfeature.DefaultMutableFeatureGate.Enabled(ctrlCfg.EndpointSlice) {
+		s.endpointSliceInformer = infFactory.Discovery().V1().EndpointSlices().Informer()
+		if err := s.endpointSliceInformer.AddIndexers(cache.Indexers{
+			EndpointSliceServiceIndex: EndpointSliceServiceIndexFunc,
+		}); err != nil {
+			klog.Errorf("add endpointslice indexer error: %s", err.Error())
+		}
+		s.endpointSlices.Indexer = s.endpointSliceInformer.GetIndexer()
+	}
+	return s
+}
+
+func (s *backendStore) GetService(key string) (*corev1.Service, error) {
+	return nil, fmt.Errorf("backend store does not watch services, service %s", key)
+}
+
+func (s *backendStore) GetServiceEndpoints(key string) (*corev1.Endpoints, error) {
+	return s.endpoints.ByKey(key)
+}
+
+func (s *backendStore) ListServiceEndpointSlices(key string) ([]*discovery.EndpointSlice, error) {
+	if s.endpointSliceInformer == nil {
+		return nil, fmt.Errorf("EndpointSlice feature gate is disabled, service %s", key)
+	}
+	return s.endpointSlices.ByService(key)
+}
+
+func (s *backendStore) GetPod(key string) (*corev1.Pod, error) {
+	return s.pods.ByKey(key)
+}
+
+func (s *backendStore) GetIngress(key string) (*networking.Ingress, error) {
+	return nil, fmt.Errorf("backend store does not watch ingresses, ingress %s", key)
+}
+
+func (s *backendStore) ListIngresses() []*Ingress {
+	return nil
+}
+
+func (s *backendStore) DeleteIngress(_ *Ingress) error {
+	return nil
+}
+
+func (s *backendStore) SetAlbConfigDefaultBackend(_ string, _ string) {
+}
+
+func (s *backendStore) ListDefaultBackendAlbConfigs(_ string) []string {
+	return nil
+}
+
+func (s *backendStore) Run(stopCh chan struct{}) {
+	go s.endpointInformer.Run(stopCh)
+	go s.podInformer.Run(stopCh)
+	if s.endpointSliceInformer != nil {
+		go s.endpointSliceInformer.Run(stopCh)
+	}
+	if !cache.WaitForCacheSync(stopCh, s.hasSynced()...) {
+		runtime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
+	}
+}
+
+func (s *backendStore) hasSynced() []cache.InformerSynced {
+	synced := []cache.InformerSynced{
+		s.endpointInformer.HasSynced,
+		s.podInformer.HasSynced,
+	}
+	if s.endpointSliceInformer != nil {
+		synced = append(synced, s.endpointSliceInformer.HasSynced)
+	}
+	return synced
+}
+
+func (s *backendStore) WaitCache(stopCh chan struct{}) (bool, error) {
+	if !cache.WaitForCacheSync(stopCh, s.hasSynced()...) {
+		return false, fmt.Errorf("timed out waiting for caches to sync")
+	}
+	return true, nil
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	apiv1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -137,6 +138,41 @@ func (s *EndpointLister) ByKey(key string) (*apiv1.Endpoints, error) {
 	return eps.(*apiv1.Endpoints), nil
 }
 
+// EndpointSliceServiceIndex is the index of the EndpointSlices by the key of their Service
+const EndpointSliceServiceIndex = "service"
+
+// EndpointSliceServiceIndexFunc indexes the EndpointSlices by the key of the Service in the
+// kubernetes.io/service-name label.
+func EndpointSliceServiceIndexFunc(obj interface{}) ([]string, error) {
+	es, ok := obj.(*discovery.EndpointSlice)
+	if !ok {
+		return nil, fmt.Errorf("object %T is not an EndpointSlice", obj)
+	}
+	svcName := es.Labels[discovery.LabelServiceName]
+	if svcName == "" {
+		return nil, nil
+	}
+	return []string{fmt.Sprintf("%s/%s", es.Namespace, svcName)}, nil
+}
+
+// EndpointSliceLister makes an Indexer that lists EndpointSlices.
+type EndpointSliceLister struct {
+	cache.Indexer
+}
+
+// ByService returns the EndpointSlices of the Service matching key in the local EndpointSlice Indexer.
+func (s *EndpointSliceLister) ByService(key string) ([]*discovery.EndpointSlice, error) {
+	objs, err := s.ByIndex(EndpointSliceServiceIndex, key)
+	if err != nil {
+		return nil, err
+	}
+	slices := make([]*discovery.EndpointSlice, 0, len(objs))
+	for _, obj := range objs {
+		slices = append(slices, obj.(*discovery.EndpointSlice))
+	}
+	return slices, nil
+}
+
 // IngressLister makes a Store that lists Ingress.
 type IngressLister struct {
 	cache.Store
@@ -37,11 +37,14 @@ import (
 
 	"github.com/eapache/channels"
 
+	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
 	corev1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1"
 	networking "k8s.io/api/networking/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -63,6 +66,10 @@ type Storer interface {
 	// GetServiceEndpoints returns the Endpoints of a Service matching key.
 	GetServiceEndpoints(key string) (*corev1.Endpoints, error)
 
+	// ListServiceEndpointSlices returns the EndpointSlices of a Service matching key, the EndpointSlices
+	// are only watched if the EndpointSlice feature gate is enabled.
+	ListServiceEndpointSlices(key string) ([]*discovery.EndpointSlice, error)
+
 	GetPod(key string) (*corev1.Pod, error)
 	GetIngress(key string) (*networking.Ingress, error)
 
@@ -71,6 +78,12 @@ type Storer interface {
 
 	// Delete Ingress
 	DeleteIngress(ing *Ingress) error
+
+	// SetAlbConfigDefaultBackend records the Service referenced by spec.defaultBackend of the AlbConfig,
+	// an empty svcKey removes the reference.
+	SetAlbConfigDefaultBackend(albconfig string, svcKey string)
+	// ListDefaultBackendAlbConfigs returns the AlbConfigs whose spec.defaultBackend references the Service.
+	ListDefaultBackendAlbConfigs(svcKey string) []string
 	// Run initiates the synchronization of the controllers
 	Run(stopCh chan struct{})
 
@@ -79,14 +92,16 @@ type Storer interface {
 
 // Informer defines the required SharedIndexInformers that interact with the API server.
 type Informer struct {
-	Ingress      cache.SharedIndexInformer
-	Endpoint     cache.SharedIndexInformer
-	Service      cache.SharedIndexInformer
-	Node         cache.SharedIndexInformer
-	IngressClass cache.SharedIndexInformer
-	Pod          cache.SharedIndexInformer
-	Secret       cache.SharedIndexInformer
-	k8s118       bool
+	Ingress       cache.SharedIndexInformer
+	Endpoint      cache.SharedIndexInformer
+	EndpointSlice cache.SharedIndexInformer
+	Service       cache.SharedIndexInformer
+	Node          cache.SharedIndexInformer
+	IngressClass  cache.SharedIndexInformer
+	Pod           cache.SharedIndexInformer
+	Secret        cache.SharedIndexInformer
+	k8s118        bool
+	endpointSlice bool
 }
 
 // Lister contains object listers (stores).
@@ -94,6 +109,7 @@ type Lister struct {
 	Ingress               IngressLister
 	Service               ServiceLister
 	Endpoint              EndpointLister
+	EndpointSlice         EndpointSliceLister
 	Pod                   PodLister
 	Node                  NodeLister
 	Secret                SecretLister
@@ -126,6 +142,14 @@ func (i *Informer) Run(stopCh chan struct{}) {
 	) {
 		runtime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
 	}
+	if i.endpointSlice {
+		go i.EndpointSlice.Run(stopCh)
+		if !cache.WaitForCacheSync(stopCh,
+			i.EndpointSlice.HasSynced,
+		) {
+			runtime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
+		}
+	}
 	if i.k8s118 {
 		go i.IngressClass.Run(stopCh)
 		if !cache.WaitForCacheSync(stopCh,
@@ -163,6 +187,10 @@ type k8sStore struct {
 	// secret in the annotations.
 	secretIngressMap ObjectRefMap
 
+	// defaultBackendAlbConfigMap contains information about which albconfig references a
+	// service in spec.defaultBackend.
+	defaultBackendAlbConfigMap ObjectRefMap
+
 	// updateCh for ingress
 	updateCh *channels.RingChannel
 
@@ -195,6 +223,8 @@ func New(
 		syncSecretMu:     &sync.Mutex{},
 		backendConfigMu:  &sync.RWMutex{},
 		secretIngressMap: NewObjectRefMap(),
+
+		defaultBackendAlbConfigMap: NewObjectRefMap(),
 	}
 
 	eventBroadcaster := record.NewBroadcaster()
@@ -215,6 +245,17 @@ func New(
 	store.informers.Endpoint = infFactory.Core().V1().Endpoints().Informer()
 	store.listers.Endpoint.Store = store.informers.Endpoint.GetStore()
 
+	if utilfeature.DefaultMutableFeatureGate.Enabled(ctrlCfg.EndpointSlice) {
+		store.informers.endpointSlice = true
+		store.informers.EndpointSlice = infFactory.Discovery().V1().EndpointSlices().Informer()
+		if err := store.informers.EndpointSlice.AddIndexers(cache.Indexers{
+			EndpointSliceServiceIndex: EndpointSliceServiceIndexFunc,
+		}); err != nil {
+			klog.Errorf("add endpointslice indexer error: %s", err.Error())
+		}
+		store.listers.EndpointSlice.Indexer = store.informers.EndpointSlice.GetIndexer()
+	}
+
 	store.informers.Service = infFactory.Core().V1().Services().Informer()
 	store.listers.Service.Store = store.informers.Service.GetStore()
 
@@ -301,6 +342,11 @@ func New(
 			if reflect.DeepEqual(oldIng, curIng) {
 				return
 			}
+			if validOld && validCur && isIngressSyncStatusUpdate(oldIng, curIng) {
+				// the sync status written by the controller is cached but doesn't trigger a reconcile
+				store.syncIngress(curIng)
+				return
+			}
 			if !validOld && validCur {
 				if isCatchAllIngress(curIng.Spec) && disableCatchAll {
 					klog.InfoS("ignoring update for catch-all ingress because of --disable-catch-all", "ingress", klog.KObj(curIng))
@@ -399,6 +445,36 @@ func New(
 			}
 		},
 	}
+	esEventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			es := obj.(*discovery.EndpointSlice)
+			klog.Info("controller: endpointslice add event", util.NamespacedName(es).String())
+			store.enqueueEndpointSliceService(updateServerCh, es)
+		},
+		DeleteFunc: func(obj interface{}) {
+			es, ok := obj.(*discovery.EndpointSlice)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				if es, ok = tombstone.Obj.(*discovery.EndpointSlice); !ok {
+					return
+				}
+			}
+			klog.Info("controller: endpointslice delete event", util.NamespacedName(es).String())
+			store.enqueueEndpointSliceService(updateServerCh, es)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			es1 := old.(*discovery.EndpointSlice)
+			es2 := cur.(*discovery.EndpointSlice)
+			if reflect.DeepEqual(es1.Endpoints, es2.Endpoints) && reflect.DeepEqual(es1.Ports, es2.Ports) {
+				return
+			}
+			klog.Info("controller: endpointslice update event", util.NamespacedName(es2).String())
+			store.enqueueEndpointSliceService(updateServerCh, es2)
+		},
+	}
 	podEventHandler := cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			err := store.listers.Pod.Add(obj)
@@ -544,7 +620,12 @@ func New(
 	}
 
 	store.informers.Ingress.AddEventHandler(ingEventHandler)
-	store.informers.Endpoint.AddEventHandler(epEventHandler)
+	// the EndpointSlices replace the Endpoints, which are truncated at 1000 addresses
+	if store.informers.endpointSlice {
+		store.informers.EndpointSlice.AddEventHandler(esEventHandler)
+	} else {
+		store.informers.Endpoint.AddEventHandler(epEventHandler)
+	}
 	store.informers.Node.AddEventHandler(podEventHandler)
 	store.informers.Service.AddEventHandler(serviceHandler)
 	store.informers.Node.AddEventHandler(nodeEventHandler)
@@ -582,6 +663,24 @@ func (s *k8sStore) enqueueImpactedIngresses(updateCh *channels.RingChannel, svc
 
 }
 
+// enqueueEndpointSliceService enqueues the ingresses of the Service owning the EndpointSlice
+func (s *k8sStore) enqueueEndpointSliceService(updateCh *channels.RingChannel, es *discovery.EndpointSlice) {
+	keys, _ := EndpointSliceServiceIndexFunc(es)
+	if len(keys) == 0 {
+		return
+	}
+	svc, exist, err := s.listers.Service.GetByKey(keys[0])
+	if err != nil {
+		klog.Error(err, "get service GetByKey by endpointslice failed", "endpointslice", util.NamespacedName(es).String())
+		return
+	}
+	if !exist {
+		klog.Warningf("esEventHandler %s", keys[0])
+		return
+	}
+	s.enqueueImpactedSvcIngresses(updateCh, helper.EndPointEvent, svc.(*corev1.Service))
+}
+
 func (s *k8sStore) enqueueImpactedSvcIngresses(updateCh *channels.RingChannel, eventType helper.EventType, svc *corev1.Service) {
 	ingList := s.listers.Ingress.List()
 
@@ -591,6 +690,10 @@ func (s *k8sStore) enqueueImpactedSvcIngresses(updateCh *channels.RingChannel, e
 			continue
 		}
 		isAlbSvc := false
+		if backend := ing.Spec.DefaultBackend; backend != nil && backend.Service != nil &&
+			svc.Namespace == ing.Namespace && svc.Name == backend.Service.Name {
+			isAlbSvc = true
+		}
 		for _, rule := range ing.Spec.Rules {
 			if rule.HTTP == nil {
 				continue
@@ -641,7 +744,7 @@ func (s *k8sStore) enqueueImpactedSvcIngresses(updateCh *channels.RingChannel, e
 		exist, sgps := CheckAnnotationForwardAction(*ing)
 		if exist {
 			for _, sg := range sgps {
-				if svc.Namespace == ing.Namespace && svc.Name == sg.ServiceName {
+				if sg.ServiceKey(ing.Namespace) == util.NamespacedName(svc) {
 					isAlbSvc = true
 					break
 				}
@@ -661,6 +764,14 @@ func (s *k8sStore) enqueueImpactedSvcIngresses(updateCh *channels.RingChannel, e
 			Type: eventType,
 			Obj:  svc,
 		}
+		return
+	}
+
+	if s.defaultBackendAlbConfigMap.Has(util.Key(svc)) {
+		updateCh.In() <- helper.Event{
+			Type: eventType,
+			Obj:  svc,
+		}
 	}
 }
 
@@ -712,6 +823,22 @@ func isCatchAllIngress(spec networking.IngressSpec) bool {
 
 // syncIngress parses ingress annotations converting the value of the
 // annotation to a go struct
+// isIngressSyncStatusUpdate returns true if only the annotations written by the controller to report
+// the sync status are changed.
+func isIngressSyncStatusUpdate(old, cur *networking.Ingress) bool {
+	if reflect.DeepEqual(old.Annotations, cur.Annotations) {
+		return false
+	}
+	oldCopy, curCopy := old.DeepCopy(), cur.DeepCopy()
+	oldCopy.Annotations, curCopy.Annotations = helper.GetIngressUserAnnotations(old), helper.GetIngressUserAnnotations(cur)
+	if len(oldCopy.Annotations) == 0 && len(curCopy.Annotations) == 0 {
+		oldCopy.Annotations, curCopy.Annotations = nil, nil
+	}
+	oldCopy.ResourceVersion, curCopy.ResourceVersion = "", ""
+	oldCopy.ManagedFields, curCopy.ManagedFields = nil, nil
+	return reflect.DeepEqual(oldCopy, curCopy)
+}
+
 func (s *k8sStore) syncIngress(ing *networking.Ingress) {
 	key := MetaNamespaceKey(ing)
 	klog.V(3).Infof("updating annotations information for ingress %v", key)
@@ -784,6 +911,13 @@ func (s *k8sStore) waitCache(stopCh chan struct{}) (bool, error) {
 	) {
 		runtime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
 	}
+	if s.informers.endpointSlice {
+		if !cache.WaitForCacheSync(stopCh,
+			s.informers.EndpointSlice.HasSynced,
+		) {
+			runtime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
+		}
+	}
 	if s.informers.k8s118 {
 		if !cache.WaitForCacheSync(stopCh,
 			s.informers.IngressClass.HasSynced,
@@ -824,11 +958,32 @@ func (s *k8sStore) DeleteIngress(ing *Ingress) error {
 	return s.listers.IngressWithAnnotation.Delete(ing)
 }
 
+// SetAlbConfigDefaultBackend records the Service referenced by spec.defaultBackend of the AlbConfig.
+func (s *k8sStore) SetAlbConfigDefaultBackend(albconfig string, svcKey string) {
+	s.defaultBackendAlbConfigMap.Delete(albconfig)
+	if svcKey != "" {
+		s.defaultBackendAlbConfigMap.Insert(albconfig, svcKey)
+	}
+}
+
+// ListDefaultBackendAlbConfigs returns the AlbConfigs whose spec.defaultBackend references the Service.
+func (s *k8sStore) ListDefaultBackendAlbConfigs(svcKey string) []string {
+	return s.defaultBackendAlbConfigMap.Reference(svcKey)
+}
+
 // GetServiceEndpoints returns the Endpoints of a Service matching key.
 func (s *k8sStore) GetServiceEndpoints(key string) (*corev1.Endpoints, error) {
 	return s.listers.Endpoint.ByKey(key)
 }
 
+// ListServiceEndpointSlices returns the EndpointSlices of a Service matching key.
+func (s *k8sStore) ListServiceEndpointSlices(key string) ([]*discovery.EndpointSlice, error) {
+	if !s.informers.endpointSlice {
+		return nil, fmt.Errorf("EndpointSlice feature gate is disabled, service %s", key)
+	}
+	return s.listers.EndpointSlice.ByService(key)
+}
+
 func (s *k8sStore) GetPod(key string) (*corev1.Pod, error) {
 	return s.listers.Pod.ByKey(key)
 }
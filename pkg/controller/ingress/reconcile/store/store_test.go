@@ -0,0 +1,70 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	discovery "k8s.io/api/discovery/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestIsIngressSyncStatusUpdate(t *testing.T) {
+	old := &networking.Ingress{}
+	old.ResourceVersion = "1"
+
+	status := old.DeepCopy()
+	status.ResourceVersion = "2"
+	status.Annotations = map[string]string{helper.AnnotationIngressSyncStatus: `{"synced":true}`}
+	assert.True(t, isIngressSyncStatusUpdate(old, status))
+
+	lastSynced := status.DeepCopy()
+	lastSynced.Annotations[helper.AnnotationIngressLastSynced] = `{"hash":"x"}`
+	assert.True(t, isIngressSyncStatusUpdate(status, lastSynced))
+
+	anno := status.DeepCopy()
+	anno.Annotations["alb.ingress.kubernetes.io/rewrite-target"] = "/"
+	assert.False(t, isIngressSyncStatusUpdate(status, anno))
+
+	labels := status.DeepCopy()
+	labels.Labels = map[string]string{helper.LabelAlbHash: "x"}
+	labels.Annotations[helper.AnnotationIngressSyncStatus] = `{"synced":false}`
+	assert.False(t, isIngressSyncStatusUpdate(status, labels))
+
+	assert.False(t, isIngressSyncStatusUpdate(old, old.DeepCopy()))
+}
+
+func TestEndpointSliceListerByService(t *testing.T) {
+	lister := EndpointSliceLister{cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		EndpointSliceServiceIndex: EndpointSliceServiceIndexFunc,
+	})}
+	slice := func(namespace, name, svc string) *discovery.EndpointSlice {
+		es := &discovery.EndpointSlice{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		if svc != "" {
+			es.Labels = map[string]string{discovery.LabelServiceName: svc}
+		}
+		return es
+	}
+	for _, es := range []*discovery.EndpointSlice{
+		slice("default", "web-ipv4", "web"),
+		slice("default", "web-ipv6", "web"),
+		slice("other", "web-ipv4", "web"),
+		slice("default", "orphan", ""),
+	} {
+		assert.NoError(t, lister.Add(es))
+	}
+
+	slices, err := lister.ByService("default/web")
+	assert.NoError(t, err)
+	var names []string
+	for _, es := range slices {
+		names = append(names, es.Name)
+	}
+	assert.ElementsMatch(t, []string{"web-ipv4", "web-ipv6"}, names)
+
+	slices, err = lister.ByService("default/api")
+	assert.NoError(t, err)
+	assert.Empty(t, slices)
+}
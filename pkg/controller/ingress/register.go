@@ -44,6 +44,8 @@ func RegisterCRD(cfg *rest.Config) error {
 	client := crd.NewClient(extc)
 	for _, crd := range []CRD{
 		NewAlbConfigCRD(client),
+		NewServerGroupBindingCRD(client),
+		NewServiceReferenceGrantCRD(client),
 	} {
 		err := crd.Initialize()
 		if err != nil {
@@ -85,6 +87,38 @@ func (p *AlbConfigCRD) Initialize() error {
 		Version:                 "v1",
 		Scope:                   apiextv1.ClusterScoped,
 		EnableStatusSubresource: true,
+		AdditionalPrinterColumns: []apiextv1.CustomResourceColumnDefinition{
+			{
+				Name:     "ALBID",
+				Type:     "string",
+				JSONPath: ".status.loadBalancer.id",
+			},
+			{
+				Name:     "DNSNAME",
+				Type:     "string",
+				JSONPath: ".status.loadBalancer.dnsname",
+			},
+			{
+				Name:     "PORT&PROTOCOL",
+				Type:     "string",
+				JSONPath: ".status.loadBalancer.listeners[*].portAndProtocol",
+			},
+			{
+				Name:     "CERTID",
+				Type:     "string",
+				JSONPath: ".status.loadBalancer.listeners[*].certificates[*].certificateId",
+			},
+			{
+				Name:     "READY",
+				Type:     "string",
+				JSONPath: ".status.conditions[?(@.type==\"Ready\")].status",
+			},
+			{
+				Name:     "AGE",
+				Type:     "date",
+				JSONPath: ".metadata.creationTimestamp",
+			},
+		},
 	}
 
 	return p.crdc.EnsurePresent(crd)
@@ -97,3 +131,98 @@ func (p *AlbConfigCRD) GetListerWatcher() cache.ListerWatcher {
 
 // GetObject satisfies resource.crd interface (and retrieve.Retriever).
 func (p *AlbConfigCRD) GetObject() runtime.Object { return &v1.AlbConfig{} }
+
+// ServerGroupBindingCRD is the namespaced crd binding a Service to an existing server group.
+type ServerGroupBindingCRD struct {
+	crdc crd.Interface
+}
+
+func NewServerGroupBindingCRD(crdClient crd.Interface) *ServerGroupBindingCRD {
+	return &ServerGroupBindingCRD{
+		crdc: crdClient,
+	}
+}
+
+// Initialize satisfies resource.crd interface.
+func (p *ServerGroupBindingCRD) Initialize() error {
+	crd := crd.Conf{
+		Kind:                    "ServerGroupBinding",
+		NamePlural:              "servergroupbindings",
+		ShortNames:              []string{"sgb"},
+		Group:                   "alibabacloud.com",
+		Version:                 "v1",
+		Scope:                   apiextv1.NamespaceScoped,
+		EnableStatusSubresource: true,
+		AdditionalPrinterColumns: []apiextv1.CustomResourceColumnDefinition{
+			{
+				Name:     "SERVERGROUPID",
+				Type:     "string",
+				JSONPath: ".spec.serverGroupId",
+			},
+			{
+				Name:     "TYPE",
+				Type:     "string",
+				JSONPath: ".spec.serverGroupType",
+			},
+			{
+				Name:     "SERVICE",
+				Type:     "string",
+				JSONPath: ".spec.serviceName",
+			},
+			{
+				Name:     "READY",
+				Type:     "string",
+				JSONPath: ".status.conditions[?(@.type==\"Ready\")].status",
+			},
+			{
+				Name:     "AGE",
+				Type:     "date",
+				JSONPath: ".metadata.creationTimestamp",
+			},
+		},
+	}
+
+	return p.crdc.EnsurePresent(crd)
+}
+
+// GetListerWatcher satisfies resource.crd interface (and retrieve.Retriever).
+func (p *ServerGroupBindingCRD) GetListerWatcher() cache.ListerWatcher {
+	return nil
+}
+
+// GetObject satisfies resource.crd interface (and retrieve.Retriever).
+func (p *ServerGroupBindingCRD) GetObject() runtime.Object { return &v1.ServerGroupBinding{} }
+
+// ServiceReferenceGrantCRD is the namespaced crd allowing the Services of its namespace to be referenced from other
+// namespaces.
+type ServiceReferenceGrantCRD struct {
+	crdc crd.Interface
+}
+
+func NewServiceReferenceGrantCRD(crdClient crd.Interface) *ServiceReferenceGrantCRD {
+	return &ServiceReferenceGrantCRD{
+		crdc: crdClient,
+	}
+}
+
+// Initialize satisfies resource.crd interface.
+func (p *ServiceReferenceGrantCRD) Initialize() error {
+	crd := crd.Conf{
+		Kind:       "ServiceReferenceGrant",
+		NamePlural: "servicereferencegrants",
+		ShortNames: []string{"srg"},
+		Group:      "alibabacloud.com",
+		Version:    "v1",
+		Scope:      apiextv1.NamespaceScoped,
+	}
+
+	return p.crdc.EnsurePresent(crd)
+}
+
+// GetListerWatcher satisfies resource.crd interface (and retrieve.Retriever).
+func (p *ServiceReferenceGrantCRD) GetListerWatcher() cache.ListerWatcher {
+	return nil
+}
+
+// GetObject satisfies resource.crd interface (and retrieve.Retriever).
+func (p *ServiceReferenceGrantCRD) GetObject() runtime.Object { return &v1.ServiceReferenceGrant{} }
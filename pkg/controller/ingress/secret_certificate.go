@@ -0,0 +1,149 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// buildSecretCertificateStatuses returns the certificates uploaded from the Secrets by the controller which are in
+// use by the stacks of an albconfig.
+func buildSecretCertificateStatuses(stacks []core.Manager) []v1.SecretCertificateStatus {
+	statusByCertID := make(map[string]v1.SecretCertificateStatus)
+	for _, stack := range stacks {
+		var certs []*albmodel.SecretCertificate
+		_ = stack.ListResources(&certs)
+		for _, cert := range certs {
+			if cert.Status == nil || !cert.Status.Owned || cert.Status.CertIdentifier == "" {
+				continue
+			}
+			statusByCertID[cert.Status.CertIdentifier] = v1.SecretCertificateStatus{
+				CertificateId: cert.Status.CertIdentifier,
+				Secret:        cert.Spec.Secret,
+				CA:            cert.Spec.IsCA,
+			}
+		}
+	}
+	var statuses []v1.SecretCertificateStatus
+	for _, certID := range sets.StringKeySet(statusByCertID).List() {
+		statuses = append(statuses, statusByCertID[certID])
+	}
+	return statuses
+}
+
+// staleSecretCertificates returns the certificates recorded in the status of the albconfig which are neither in use
+// by the albconfig, nor by another albconfig.
+func staleSecretCertificates(albconfig *v1.AlbConfig, inUse []v1.SecretCertificateStatus, albconfigs []v1.AlbConfig) []v1.SecretCertificateStatus {
+	used := sets.NewString()
+	for _, cert := range inUse {
+		used.Insert(cert.CertificateId)
+	}
+	for _, other := range albconfigs {
+		if other.Name == albconfig.Name {
+			continue
+		}
+		for _, cert := range other.Status.SecretCertificates {
+			used.Insert(cert.CertificateId)
+		}
+		for _, ls := range other.Status.LoadBalancer.Listeners {
+			for _, cert := range ls.Certificates {
+				used.Insert(cert.CertificateId)
+			}
+		}
+	}
+	var stale []v1.SecretCertificateStatus
+	for _, cert := range albconfig.Status.SecretCertificates {
+		if !used.Has(cert.CertificateId) {
+			stale = append(stale, cert)
+		}
+	}
+	return stale
+}
+
+// collectSecretCertificates deletes the stale certificates of the albconfig from CAS, and records the certificates
+// in use in the status of the albconfig without updating it. The certificates failed to be deleted are kept in
+// the status, so that they are deleted by the next reconcile.
+func (g *albconfigReconciler) collectSecretCertificates(ctx context.Context, albconfig *v1.AlbConfig, stacks []core.Manager) {
+	inUse := buildSecretCertificateStatuses(stacks)
+	stale := albconfig.Status.SecretCertificates
+	if len(stale) != 0 {
+		stale = g.deleteStaleSecretCertificates(ctx, albconfig, inUse)
+	}
+	statuses := append(inUse, stale...)
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].CertificateId < statuses[j].CertificateId
+	})
+	albconfig.Status.SecretCertificates = statuses
+}
+
+// deleteStaleSecretCertificates deletes the stale certificates of the albconfig and returns the ones failed to be
+// deleted.
+func (g *albconfigReconciler) deleteStaleSecretCertificates(ctx context.Context, albconfig *v1.AlbConfig,
+	inUse []v1.SecretCertificateStatus) []v1.SecretCertificateStatus {
+	albconfigs := &v1.AlbConfigList{}
+	if err := g.k8sClient.List(ctx, albconfigs); err != nil {
+		g.logger.Error(err, "list albconfigs to collect certificates", "albconfig", albconfig.Name)
+		return staleSecretCertificates(albconfig, inUse, nil)
+	}
+	stale := staleSecretCertificates(albconfig, inUse, albconfigs.Items)
+	if len(stale) == 0 {
+		return nil
+	}
+	var staleServerCerts, staleCACerts []v1.SecretCertificateStatus
+	for _, cert := range stale {
+		if cert.CA {
+			staleCACerts = append(staleCACerts, cert)
+		} else {
+			staleServerCerts = append(staleServerCerts, cert)
+		}
+	}
+	var failed []v1.SecretCertificateStatus
+	if len(staleServerCerts) != 0 {
+		failed = append(failed, g.deleteSecretCertificates(ctx, albconfig, staleServerCerts,
+			g.cloud.DescribeSSLCertificateList, g.cloud.DeleteSSLCertificate)...)
+	}
+	if len(staleCACerts) != 0 {
+		failed = append(failed, g.deleteSecretCertificates(ctx, albconfig, staleCACerts,
+			g.cloud.DescribeCACertificateList, g.cloud.DeleteCACertificate)...)
+	}
+	return failed
+}
+
+// deleteSecretCertificates deletes the certificates still in CAS and returns the ones failed to be deleted.
+func (g *albconfigReconciler) deleteSecretCertificates(ctx context.Context, albconfig *v1.AlbConfig, stale []v1.SecretCertificateStatus,
+	list func(ctx context.Context) ([]model.CertificateInfo, error), del func(ctx context.Context, certId string) error) []v1.SecretCertificateStatus {
+	sdkCerts, err := list(ctx)
+	if err != nil {
+		g.logger.Error(err, "list certificates to collect", "albconfig", albconfig.Name)
+		return stale
+	}
+	existing := sets.NewString()
+	for _, cert := range sdkCerts {
+		existing.Insert(cert.CertIdentifier)
+	}
+
+	var failed []v1.SecretCertificateStatus
+	for _, cert := range stale {
+		if !existing.Has(cert.CertificateId) {
+			continue
+		}
+		if err := del(ctx, cert.CertificateId); err != nil {
+			g.eventRecorder.Event(albconfig, corev1.EventTypeWarning, helper.IngressEventReasonFailedDeleteCert,
+				fmt.Sprintf("Failed delete certificate %s of secret %s due to %s", cert.CertificateId, cert.Secret, helper.GetLogMessage(err)))
+			failed = append(failed, cert)
+			continue
+		}
+		g.logger.Info("deleted stale certificate", "albconfig", albconfig.Name, "certificateId", cert.CertificateId,
+			"secret", cert.Secret, "traceID", ctx.Value(util.TraceID))
+	}
+	return failed
+}
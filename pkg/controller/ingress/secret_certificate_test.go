@@ -0,0 +1,129 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/alb/core"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// certCloud records the certificates deleted from CAS
+type certCloud struct {
+	prvd.Provider
+	certIDs   []string
+	caCertIDs []string
+	deleted   []string
+	caDeleted []string
+	failedIDs map[string]bool
+}
+
+func (c *certCloud) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	var certs []model.CertificateInfo
+	for _, id := range c.certIDs {
+		certs = append(certs, model.CertificateInfo{CertIdentifier: id})
+	}
+	return certs, nil
+}
+
+func (c *certCloud) DeleteSSLCertificate(ctx context.Context, certId string) error {
+	if c.failedIDs[certId] {
+		return fmt.Errorf("certificate %s is in use", certId)
+	}
+	c.deleted = append(c.deleted, certId)
+	return nil
+}
+
+func (c *certCloud) DescribeCACertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	var certs []model.CertificateInfo
+	for _, id := range c.caCertIDs {
+		certs = append(certs, model.CertificateInfo{CertIdentifier: id})
+	}
+	return certs, nil
+}
+
+func (c *certCloud) DeleteCACertificate(ctx context.Context, certId string) error {
+	c.caDeleted = append(c.caDeleted, certId)
+	return nil
+}
+
+func TestBuildSecretCertificateStatuses(t *testing.T) {
+	stack := core.NewDefaultManager(core.StackID{Name: "alb"})
+	shard := core.NewDefaultManager(core.StackID{Name: "alb-shard-1"})
+	newCert := func(stack core.Manager, id, secret string, status *albmodel.SecretCertificateStatus) {
+		cert := albmodel.NewSecretCertificate(stack, id, albmodel.SecretCertificateSpec{Secret: secret})
+		cert.Status = status
+	}
+	ca := albmodel.NewSecretCertificate(stack, "ca", albmodel.SecretCertificateSpec{Secret: "default/ca", IsCA: true})
+	ca.SetStatus(albmodel.SecretCertificateStatus{CertIdentifier: "ca-1", Owned: true})
+	newCert(stack, "b", "default/b", &albmodel.SecretCertificateStatus{CertIdentifier: "cert-b", Owned: true})
+	newCert(stack, "user", "default/user", &albmodel.SecretCertificateStatus{CertIdentifier: "cert-user"})
+	newCert(stack, "pending", "default/pending", nil)
+	newCert(shard, "a", "default/a", &albmodel.SecretCertificateStatus{CertIdentifier: "cert-a", Owned: true})
+
+	assert.Nil(t, buildSecretCertificateStatuses(nil))
+	assert.Equal(t, []v1.SecretCertificateStatus{
+		{CertificateId: "ca-1", Secret: "default/ca", CA: true},
+		{CertificateId: "cert-a", Secret: "default/a"},
+		{CertificateId: "cert-b", Secret: "default/b"},
+	}, buildSecretCertificateStatuses([]core.Manager{stack, shard}))
+}
+
+func TestStaleSecretCertificates(t *testing.T) {
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "alb"}}
+	albconfig.Status.SecretCertificates = []v1.SecretCertificateStatus{
+		{CertificateId: "cert-used"}, {CertificateId: "cert-shared"}, {CertificateId: "cert-listener"}, {CertificateId: "cert-stale"},
+	}
+	other := v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+	other.Status.SecretCertificates = []v1.SecretCertificateStatus{{CertificateId: "cert-shared"}}
+	other.Status.LoadBalancer.Listeners = []v1.ListenerStatus{
+		{PortAndProtocol: "443/HTTPS", Certificates: []v1.AppliedCertificate{{CertificateId: "cert-listener"}}},
+	}
+
+	assert.Equal(t, []v1.SecretCertificateStatus{{CertificateId: "cert-stale"}},
+		staleSecretCertificates(albconfig, []v1.SecretCertificateStatus{{CertificateId: "cert-used"}}, []v1.AlbConfig{*albconfig, other}))
+}
+
+func TestCollectSecretCertificates(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1.SchemeBuilder.AddToScheme(scheme)
+	albconfig := &v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "alb"}}
+	albconfig.Status.SecretCertificates = []v1.SecretCertificateStatus{
+		{CertificateId: "ca-old", CA: true}, {CertificateId: "cert-gone"}, {CertificateId: "cert-old"}, {CertificateId: "cert-locked"}, {CertificateId: "cert-used"},
+	}
+	cloud := &certCloud{
+		certIDs:   []string{"cert-old", "cert-locked", "cert-used"},
+		caCertIDs: []string{"ca-old"},
+		failedIDs: map[string]bool{"cert-locked": true},
+	}
+	recorder := record.NewFakeRecorder(10)
+	g := &albconfigReconciler{
+		cloud:         cloud,
+		k8sClient:     fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(albconfig.DeepCopy()).Build(),
+		eventRecorder: recorder,
+		logger:        logr.Discard(),
+	}
+	stack := core.NewDefaultManager(core.StackID{Name: "alb"})
+	cert := albmodel.NewSecretCertificate(stack, "used", albmodel.SecretCertificateSpec{Secret: "default/used"})
+	cert.SetStatus(albmodel.SecretCertificateStatus{CertIdentifier: "cert-used", Owned: true})
+
+	g.collectSecretCertificates(context.TODO(), albconfig, []core.Manager{stack})
+	assert.Equal(t, []string{"cert-old"}, cloud.deleted)
+	assert.Equal(t, []string{"ca-old"}, cloud.caDeleted)
+	// the certificate failed to be deleted is kept to be deleted by the next reconcile
+	assert.Equal(t, []v1.SecretCertificateStatus{
+		{CertificateId: "cert-locked"},
+		{CertificateId: "cert-used", Secret: "default/used"},
+	}, albconfig.Status.SecretCertificates)
+	assert.Equal(t, 1, len(recorder.Events))
+}
@@ -0,0 +1,71 @@
+package servergroupbinding
+
+import (
+	"context"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// bindingsForService maps a Service or its Endpoints to the ServerGroupBindings
+// of the Service.
+func (r *serverGroupBindingReconciler) bindingsForService(obj client.Object) []reconcile.Request {
+	sgbList := &v1.ServerGroupBindingList{}
+	if err := r.k8sClient.List(context.Background(), sgbList, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.logger.Error(err, "list servergroupbindings", "service", util.Key(obj))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	for _, sgb := range sgbList.Items {
+		if sgb.Spec.ServiceName == obj.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: sgb.Namespace, Name: sgb.Name},
+			})
+		}
+	}
+	return requests
+}
+
+// bindingsForNode maps a Node to all the ServerGroupBindings, the node
+// backends of every Service may change with it.
+func (r *serverGroupBindingReconciler) bindingsForNode(obj client.Object) []reconcile.Request {
+	sgbList := &v1.ServerGroupBindingList{}
+	if err := r.k8sClient.List(context.Background(), sgbList); err != nil {
+		r.logger.Error(err, "list servergroupbindings", "node", obj.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(sgbList.Items))
+	for _, sgb := range sgbList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: sgb.Namespace, Name: sgb.Name},
+		})
+	}
+	return requests
+}
+
+// bindingsForServerGroup maps a ServerGroupBinding to the other bindings of
+// its server group, a binding rejected as a duplicate is synced once the
+// binding before it is deleted or binds another server group.
+func (r *serverGroupBindingReconciler) bindingsForServerGroup(obj client.Object) []reconcile.Request {
+	sgb, ok := obj.(*v1.ServerGroupBinding)
+	if !ok {
+		return nil
+	}
+	sgbList := &v1.ServerGroupBindingList{}
+	if err := r.k8sClient.List(context.Background(), sgbList); err != nil {
+		r.logger.Error(err, "list servergroupbindings", "servergroupbinding", util.Key(obj))
+		return nil
+	}
+	requests := make([]reconcile.Request, 0)
+	for _, other := range sgbList.Items {
+		if other.UID != sgb.UID && other.Spec.ServerGroupId == sgb.Spec.ServerGroupId {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: other.Namespace, Name: other.Name},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,141 @@
+package servergroupbinding
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/context/shared"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	serverGroupBindingControllerName = "servergroupbinding-controller"
+
+	defaultMaxConcurrentReconciles = 3
+)
+
+type serverGroupBindingController struct {
+	c     controller.Controller
+	recon *serverGroupBindingReconciler
+}
+
+func (sgbC serverGroupBindingController) Start(ctx context.Context) error {
+	klog.Infof("serverGroupBindingController start")
+	go sgbC.recon.Start(ctx)
+	if _, err := sgbC.recon.store.WaitCache(sgbC.recon.stopCh); err != nil {
+		return err
+	}
+	return sgbC.c.Start(ctx)
+}
+
+// Add creates the ServerGroupBinding controller and adds it to the Manager.
+// The ServerGroupBinding CRD is registered together with the AlbConfig CRD.
+func Add(mgr manager.Manager, ctx *shared.SharedContext) error {
+	rateLimit := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(5*time.Second, 300*time.Second),
+		// 10 qps, 100 bucket size.  This is only for retry speed and its only the overall factor (not per item)
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+	r, err := NewServerGroupBindingReconciler(mgr, ctx)
+	if err != nil {
+		return err
+	}
+	c, err := controller.NewUnmanaged(
+		serverGroupBindingControllerName, mgr,
+		controller.Options{
+			Reconciler:              r,
+			MaxConcurrentReconciles: r.maxConcurrentReconciles,
+			RateLimiter:             rateLimit,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Watch(&source.Kind{Type: &v1.ServerGroupBinding{}},
+		&handler.EnqueueRequestForObject{}, bindingChangedPredicate()); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &v1.ServerGroupBinding{}},
+		handler.EnqueueRequestsFromMapFunc(r.bindingsForServerGroup), serverGroupChangedPredicate()); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &corev1.Service{}},
+		handler.EnqueueRequestsFromMapFunc(r.bindingsForService)); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &corev1.Endpoints{}},
+		handler.EnqueueRequestsFromMapFunc(r.bindingsForService)); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &corev1.Node{}},
+		handler.EnqueueRequestsFromMapFunc(r.bindingsForNode), nodeChangedPredicate()); err != nil {
+		return err
+	}
+
+	return mgr.Add(&serverGroupBindingController{c: c, recon: r})
+}
+
+// bindingChangedPredicate skips the status updates made by the controller itself.
+func bindingChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSgb, ok1 := e.ObjectOld.(*v1.ServerGroupBinding)
+			newSgb, ok2 := e.ObjectNew.(*v1.ServerGroupBinding)
+			if !ok1 || !ok2 {
+				return false
+			}
+			return !equality.Semantic.DeepEqual(oldSgb.Spec, newSgb.Spec) ||
+				oldSgb.DeletionTimestamp.IsZero() != newSgb.DeletionTimestamp.IsZero()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+	}
+}
+
+// serverGroupChangedPredicate passes the bindings releasing their server group, which are the ones deleted or
+// binding another server group.
+func serverGroupChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldSgb, ok1 := e.ObjectOld.(*v1.ServerGroupBinding)
+			newSgb, ok2 := e.ObjectNew.(*v1.ServerGroupBinding)
+			if !ok1 || !ok2 {
+				return false
+			}
+			return oldSgb.Spec.ServerGroupId != newSgb.Spec.ServerGroupId ||
+				oldSgb.DeletionTimestamp.IsZero() != newSgb.DeletionTimestamp.IsZero()
+		},
+	}
+}
+
+// nodeChangedPredicate skips the node status updates, only the nodes joining or leaving the cluster and the label
+// changes affect the backends.
+func nodeChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, ok1 := e.ObjectOld.(*corev1.Node)
+			newNode, ok2 := e.ObjectNew.(*corev1.Node)
+			if !ok1 || !ok2 {
+				return false
+			}
+			return !equality.Semantic.DeepEqual(oldNode.Labels, newNode.Labels) ||
+				oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable
+		},
+	}
+}
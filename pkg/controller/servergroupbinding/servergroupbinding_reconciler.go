@@ -0,0 +1,447 @@
+package servergroupbinding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	sdkutils "github.com/aliyun/alibaba-cloud-sdk-go/sdk/utils"
+	"github.com/go-logr/logr"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/context/shared"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/applier"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/backend"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/store"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	reconbackend "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/backend"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	nlbmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/nlb"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func NewServerGroupBindingReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*serverGroupBindingReconciler, error) {
+	logger := ctrl.Log.WithName("controllers").WithName(serverGroupBindingControllerName)
+	kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	nlbServerGroupMgr, err := service.NewServerGroupManager(mgr.GetClient(), ctx.Provider())
+	if err != nil {
+		return nil, fmt.Errorf("NewServerGroupManager error: %s", err.Error())
+	}
+	r := &serverGroupBindingReconciler{
+		cloud:                 ctx.Provider(),
+		k8sClient:             mgr.GetClient(),
+		eventRecorder:         mgr.GetEventRecorderFor("servergroupbinding"),
+		logger:                logger,
+		consoleServiceApplier: applier.NewConsoleServiceManagerApplier(mgr.GetClient(), ctx.Provider(), logger),
+		nlbServerGroupMgr:     nlbServerGroupMgr,
+		k8sFinalizerManager:   helper.NewDefaultFinalizerManager(mgr.GetClient()),
+		stopCh:                make(chan struct{}),
+
+		maxConcurrentReconciles: defaultMaxConcurrentReconciles,
+	}
+	// the backend manager only reads endpoints and pods from the store, the
+	// other objects come from the manager client
+	r.store = store.NewBackendStore("", 0, kubeClient)
+	r.albBackendMgr = backend.NewBackendManager(r.store, mgr.GetClient(), ctx.Provider(), logger)
+	return r, nil
+}
+
+type serverGroupBindingReconciler struct {
+	cloud                 prvd.Provider
+	k8sClient             client.Client
+	eventRecorder         record.EventRecorder
+	logger                logr.Logger
+	store                 store.Storer
+	albBackendMgr         *backend.Manager
+	consoleServiceApplier applier.ConsoleServiceManagerApplier
+	nlbServerGroupMgr     *service.ServerGroupManager
+	k8sFinalizerManager   helper.FinalizerManager
+	stopCh                chan struct{}
+
+	maxConcurrentReconciles int
+}
+
+// Start runs the informers of the backend store until ctx is done.
+func (r *serverGroupBindingReconciler) Start(ctx context.Context) {
+	r.logger.Info("Starting ServerGroupBinding controller")
+	r.store.Run(r.stopCh)
+	<-ctx.Done()
+	close(r.stopCh)
+}
+
+func (r *serverGroupBindingReconciler) Reconcile(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+	// new context for each request
+	ctx := context.Background()
+	traceID := sdkutils.GetUUID()
+	ctx = context.WithValue(ctx, util.TraceID, traceID)
+
+	var err error
+	startTime := time.Now()
+	r.logger.Info("start reconcile",
+		"request", req.String(),
+		"traceID", traceID,
+		"startTime", startTime)
+	defer func() {
+		if rec := recover(); rec != nil {
+			perr := fmt.Errorf("panic recover: %v", rec)
+			r.logger.Error(perr, "finish reconcile",
+				"request", req.String(),
+				"traceID", traceID,
+				"elapsedTime", time.Since(startTime).Milliseconds(),
+				"panicStack", string(debug.Stack()))
+			return
+		}
+		if err != nil {
+			r.logger.Error(err, "finish reconcile",
+				"request", req.String(),
+				"traceID", traceID,
+				"elapsedTime", time.Since(startTime).Milliseconds())
+			return
+		}
+		r.logger.Info("finish reconcile",
+			"request", req.String(),
+			"traceID", traceID,
+			"elapsedTime", time.Since(startTime).Milliseconds())
+	}()
+
+	err = r.reconcile(ctx, req)
+	return reconcile.Result{}, err
+}
+
+func (r *serverGroupBindingReconciler) reconcile(ctx context.Context, request reconcile.Request) error {
+	sgb := &v1.ServerGroupBinding{}
+	if err := r.k8sClient.Get(ctx, request.NamespacedName, sgb); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if !sgb.DeletionTimestamp.IsZero() {
+		return r.cleanupServers(ctx, sgb)
+	}
+	return r.reconcileServers(ctx, sgb)
+}
+
+func (r *serverGroupBindingReconciler) reconcileServers(ctx context.Context, sgb *v1.ServerGroupBinding) error {
+	if err := r.k8sFinalizerManager.AddFinalizers(ctx, sgb, util.ServerGroupBindingFinalizer); err != nil {
+		r.eventRecorder.Event(sgb, corev1.EventTypeWarning, helper.FailedAddFinalizer, helper.GetLogMessage(err))
+		return err
+	}
+
+	backends, retry, syncErr := r.syncServers(ctx, sgb)
+	if err := r.updateStatus(ctx, sgb, backends, syncErr); err != nil {
+		r.eventRecorder.Event(sgb, corev1.EventTypeWarning, helper.FailedUpdateStatus, helper.GetLogMessage(err))
+		if syncErr == nil {
+			return err
+		}
+	}
+	if syncErr != nil {
+		r.eventRecorder.Event(sgb, corev1.EventTypeWarning, helper.ServiceEventReasonFailedUpdateEndpoints, helper.GetLogMessage(syncErr))
+		return syncErr
+	}
+	if retry {
+		return fmt.Errorf("retry potential ready endpoints")
+	}
+	r.eventRecorder.Event(sgb, corev1.EventTypeNormal, helper.ServiceEventReasonSuccessfullyReconciled, "Successfully reconciled")
+	return nil
+}
+
+// cleanupServers deregisters the servers of a deleted binding from the server group.
+func (r *serverGroupBindingReconciler) cleanupServers(ctx context.Context, sgb *v1.ServerGroupBinding) error {
+	if !helper.HasFinalizer(sgb, util.ServerGroupBindingFinalizer) {
+		return nil
+	}
+	// nothing was registered by a binding with an invalid spec
+	if validateSpec(&sgb.Spec) != nil {
+		return r.k8sFinalizerManager.RemoveFinalizers(ctx, sgb, util.ServerGroupBindingFinalizer)
+	}
+	var err error
+	switch sgb.Spec.ServerGroupType {
+	case v1.ServerGroupTypeALB:
+		err = r.consoleServiceApplier.Apply(ctx, &albmodel.ConsoleServiceStack{
+			ClusterID:         r.cloud.ClusterID(),
+			ServerGroupID:     sgb.Spec.ServerGroupId,
+			Namespace:         sgb.Namespace,
+			Name:              sgb.Spec.ServiceName,
+			ServerDescription: serverDescription(sgb, r.cloud.ClusterID()),
+			Backends:          []albmodel.BackendItem{},
+		})
+	case v1.ServerGroupTypeNLB:
+		err = r.nlbServerGroupMgr.SyncServers(r.nlbRequestContext(ctx, sgb, nil), &nlbmodel.ServerGroup{
+			ServerGroupId:   sgb.Spec.ServerGroupId,
+			ServerGroupName: serverDescription(sgb, r.cloud.ClusterID()),
+		})
+	}
+	if err != nil {
+		r.eventRecorder.Event(sgb, corev1.EventTypeWarning, helper.ServiceEventReasonFailedUpdateEndpoints, helper.GetLogMessage(err))
+		return err
+	}
+	if err := r.k8sFinalizerManager.RemoveFinalizers(ctx, sgb, util.ServerGroupBindingFinalizer); err != nil {
+		r.eventRecorder.Event(sgb, corev1.EventTypeWarning, helper.FailedRemoveFinalizer, fmt.Sprintf("Failed remove finalizer due to %v", err))
+		return err
+	}
+	return nil
+}
+
+// bindingError is a sync error with the reason reported in the Ready condition.
+type bindingError struct {
+	reason v1.ServerGroupBindingConditionReason
+	err    error
+}
+
+func (e *bindingError) Error() string { return e.err.Error() }
+
+func (e *bindingError) Unwrap() error { return e.err }
+
+func errorReason(err error) v1.ServerGroupBindingConditionReason {
+	var bErr *bindingError
+	if errors.As(err, &bErr) {
+		return bErr.reason
+	}
+	return v1.ServerGroupBindingReasonSyncFailed
+}
+
+// syncServers registers the endpoints of the Service into the server group. It returns the registered servers and
+// whether the sync has to be retried for the endpoints which are not ready or still draining.
+func (r *serverGroupBindingReconciler) syncServers(ctx context.Context, sgb *v1.ServerGroupBinding) ([]v1.ServerGroupBindingBackend, bool, error) {
+	if err := validateSpec(&sgb.Spec); err != nil {
+		return nil, false, &bindingError{reason: v1.ServerGroupBindingReasonInvalidSpec, err: err}
+	}
+	conflict, err := r.conflictingBinding(ctx, sgb)
+	if err != nil {
+		return nil, false, err
+	}
+	if conflict != nil {
+		return nil, false, &bindingError{reason: v1.ServerGroupBindingReasonConflict,
+			err: fmt.Errorf("server group %s is already bound by servergroupbinding %s", sgb.Spec.ServerGroupId, util.Key(conflict))}
+	}
+
+	svc := &corev1.Service{}
+	if err := r.k8sClient.Get(ctx, types.NamespacedName{Namespace: sgb.Namespace, Name: sgb.Spec.ServiceName}, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, false, &bindingError{reason: v1.ServerGroupBindingReasonServiceNotFound, err: err}
+		}
+		return nil, false, err
+	}
+	svcPort, err := backend.LookupServicePort(svc, sgb.Spec.ServicePort)
+	if err != nil {
+		return nil, false, &bindingError{reason: v1.ServerGroupBindingReasonInvalidSpec, err: err}
+	}
+	policy, err := bindingTrafficPolicy(sgb, svc, svcPort)
+	if err != nil {
+		return nil, false, &bindingError{reason: v1.ServerGroupBindingReasonInvalidSpec, err: err}
+	}
+
+	switch sgb.Spec.ServerGroupType {
+	case v1.ServerGroupTypeALB:
+		return r.syncALBServers(ctx, sgb, svc, policy)
+	default:
+		return r.syncNLBServers(ctx, sgb, svc, svcPort, policy)
+	}
+}
+
+func (r *serverGroupBindingReconciler) syncALBServers(ctx context.Context, sgb *v1.ServerGroupBinding, svc *corev1.Service,
+	policy helper.TrafficPolicy) ([]v1.ServerGroupBindingBackend, bool, error) {
+	items, containsPotentialReadyEndpoints, err := r.albBackendMgr.BuildServicePortSDKBackendsWithPolicy(ctx, svc, sgb.Spec.ServicePort, policy)
+	if err != nil {
+		return nil, false, err
+	}
+	retry := containsPotentialReadyEndpoints
+	for i := range items {
+		if items[i].Draining {
+			retry = true
+			continue
+		}
+		if sgb.Spec.Weight != nil {
+			items[i].Weight = int(*sgb.Spec.Weight)
+		}
+	}
+
+	if err := r.consoleServiceApplier.Apply(ctx, &albmodel.ConsoleServiceStack{
+		ClusterID:                       r.cloud.ClusterID(),
+		ServerGroupID:                   sgb.Spec.ServerGroupId,
+		Namespace:                       svc.Namespace,
+		Name:                            svc.Name,
+		ServerDescription:               serverDescription(sgb, r.cloud.ClusterID()),
+		TrafficPolicy:                   string(policy),
+		ContainsPotentialReadyEndpoints: containsPotentialReadyEndpoints,
+		Backends:                        items,
+	}); err != nil {
+		return nil, false, err
+	}
+
+	backends := make([]v1.ServerGroupBindingBackend, 0, len(items))
+	for _, item := range items {
+		backends = append(backends, v1.ServerGroupBindingBackend{
+			ServerId:   item.ServerId,
+			ServerIp:   item.ServerIp,
+			ServerType: item.Type,
+			Port:       int32(item.Port),
+			Weight:     int32(item.Weight),
+		})
+	}
+	return backends, retry, nil
+}
+
+func (r *serverGroupBindingReconciler) syncNLBServers(ctx context.Context, sgb *v1.ServerGroupBinding, svc *corev1.Service,
+	svcPort corev1.ServicePort, policy helper.TrafficPolicy) ([]v1.ServerGroupBindingBackend, bool, error) {
+	// the backends are built by the traffic policy of the binding instead of the one of the service
+	bound := svc.DeepCopy()
+	if bound.Annotations == nil {
+		bound.Annotations = make(map[string]string)
+	}
+	switch policy {
+	case helper.ENITrafficPolicy:
+		bound.Annotations[helper.BackendType] = model.ENIBackendType
+	case helper.LocalTrafficPolicy:
+		bound.Annotations[helper.BackendType] = model.ECSBackendType
+		bound.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+	case helper.ClusterTrafficPolicy:
+		bound.Annotations[helper.BackendType] = model.ECSBackendType
+		bound.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+	}
+
+	reqCtx := r.nlbRequestContext(ctx, sgb, bound)
+	candidates, err := reconbackend.NewEndpointWithENI(reqCtx, r.k8sClient)
+	if err != nil {
+		return nil, false, err
+	}
+	sg := &nlbmodel.ServerGroup{
+		ServerGroupId:   sgb.Spec.ServerGroupId,
+		ServerGroupName: serverDescription(sgb, r.cloud.ClusterID()),
+		ServicePort:     &svcPort,
+	}
+	if err := r.nlbServerGroupMgr.BuildServers(reqCtx, sg, candidates); err != nil {
+		return nil, false, err
+	}
+	retry := candidates.ContainsPotentialReadyEndpoints
+	for i := range sg.Servers {
+		if sg.Servers[i].Draining {
+			retry = true
+			continue
+		}
+		if sgb.Spec.Weight != nil {
+			sg.Servers[i].Weight = *sgb.Spec.Weight
+		}
+	}
+
+	if err := r.nlbServerGroupMgr.SyncServers(reqCtx, sg); err != nil {
+		return nil, false, err
+	}
+
+	backends := make([]v1.ServerGroupBindingBackend, 0, len(sg.Servers))
+	for _, s := range sg.Servers {
+		backends = append(backends, v1.ServerGroupBindingBackend{
+			ServerId:   s.ServerId,
+			ServerIp:   s.ServerIp,
+			ServerType: string(s.ServerType),
+			Port:       s.Port,
+			Weight:     s.Weight,
+		})
+	}
+	return backends, retry, nil
+}
+
+func (r *serverGroupBindingReconciler) nlbRequestContext(ctx context.Context, sgb *v1.ServerGroupBinding, svc *corev1.Service) *svcCtx.RequestContext {
+	return &svcCtx.RequestContext{
+		Ctx:      ctx,
+		Service:  svc,
+		Anno:     &annotation.AnnotationRequest{Service: svc},
+		Log:      r.logger.WithValues("servergroupbinding", util.Key(sgb)),
+		Recorder: r.eventRecorder,
+	}
+}
+
+// serverDescription is the description of the servers registered by the binding, the binding only updates or removes
+// the servers with its description.
+func serverDescription(sgb *v1.ServerGroupBinding, clusterID string) string {
+	return fmt.Sprintf("k8s.sgb.%s.%s.%s", sgb.Name, sgb.Namespace, clusterID)
+}
+
+// conflictingBinding returns the binding which binds the server group of sgb before it, nil if there is none. The
+// oldest binding of a server group registers the servers, the others are rejected so that the endpoints are not
+// registered twice.
+func (r *serverGroupBindingReconciler) conflictingBinding(ctx context.Context, sgb *v1.ServerGroupBinding) (*v1.ServerGroupBinding, error) {
+	sgbList := &v1.ServerGroupBindingList{}
+	if err := r.k8sClient.List(ctx, sgbList); err != nil {
+		return nil, err
+	}
+	for i := range sgbList.Items {
+		other := &sgbList.Items[i]
+		if other.UID == sgb.UID || other.Spec.ServerGroupId != sgb.Spec.ServerGroupId || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if bindsBefore(other, sgb) {
+			return other, nil
+		}
+	}
+	return nil, nil
+}
+
+// bindsBefore returns true if a is created before b, or at the same time with a smaller key
+func bindsBefore(a, b *v1.ServerGroupBinding) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return util.Key(a) < util.Key(b)
+}
+
+func validateSpec(spec *v1.ServerGroupBindingSpec) error {
+	if spec.ServerGroupId == "" {
+		return fmt.Errorf("serverGroupId is required")
+	}
+	if spec.ServerGroupType != v1.ServerGroupTypeALB && spec.ServerGroupType != v1.ServerGroupTypeNLB {
+		return fmt.Errorf("serverGroupType %q is not supported, ALB or NLB is expected", spec.ServerGroupType)
+	}
+	if spec.ServiceName == "" {
+		return fmt.Errorf("serviceName is required")
+	}
+	if (spec.ServicePort.Type == intstr.Int && spec.ServicePort.IntVal == 0) ||
+		(spec.ServicePort.Type == intstr.String && spec.ServicePort.StrVal == "") {
+		return fmt.Errorf("servicePort is required")
+	}
+	if spec.Weight != nil && (*spec.Weight < 0 || *spec.Weight > 100) {
+		return fmt.Errorf("weight %d is out of range [0, 100]", *spec.Weight)
+	}
+	switch helper.TrafficPolicy(spec.TrafficPolicy) {
+	case "", helper.ENITrafficPolicy, helper.LocalTrafficPolicy, helper.ClusterTrafficPolicy:
+	default:
+		return fmt.Errorf("trafficPolicy %q is not supported, ENI, Local or Cluster is expected", spec.TrafficPolicy)
+	}
+	return nil
+}
+
+// bindingTrafficPolicy returns the traffic policy of the binding, or the one of the service if it is not set. The
+// node backends are registered by the node port, so a service port without node port only supports ENI.
+func bindingTrafficPolicy(sgb *v1.ServerGroupBinding, svc *corev1.Service, svcPort corev1.ServicePort) (helper.TrafficPolicy, error) {
+	policy := helper.TrafficPolicy(sgb.Spec.TrafficPolicy)
+	if policy == "" {
+		var err error
+		policy, err = helper.GetServiceTrafficPolicy(svc)
+		if err != nil {
+			return "", err
+		}
+	}
+	if policy != helper.ENITrafficPolicy && svcPort.NodePort == 0 {
+		return "", fmt.Errorf("service port %s of %s has no node port, only ENI traffic policy is supported",
+			sgb.Spec.ServicePort.String(), util.Key(svc))
+	}
+	return policy, nil
+}
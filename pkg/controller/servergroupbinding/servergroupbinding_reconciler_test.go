@@ -0,0 +1,230 @@
+package servergroupbinding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/alb"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateSpec(t *testing.T) {
+	valid := func() v1.ServerGroupBindingSpec {
+		return v1.ServerGroupBindingSpec{
+			ServerGroupId:   "sgp-1",
+			ServerGroupType: v1.ServerGroupTypeALB,
+			ServiceName:     "tea-svc",
+			ServicePort:     intstr.FromInt(80),
+		}
+	}
+	weight := func(w int32) *int32 { return &w }
+
+	cases := []struct {
+		name   string
+		modify func(spec *v1.ServerGroupBindingSpec)
+		err    bool
+	}{
+		{name: "valid", modify: func(spec *v1.ServerGroupBindingSpec) {}},
+		{name: "port name and weight", modify: func(spec *v1.ServerGroupBindingSpec) {
+			spec.ServicePort = intstr.FromString("http")
+			spec.Weight = weight(0)
+			spec.TrafficPolicy = "Local"
+		}},
+		{name: "no server group", modify: func(spec *v1.ServerGroupBindingSpec) { spec.ServerGroupId = "" }, err: true},
+		{name: "unknown type", modify: func(spec *v1.ServerGroupBindingSpec) { spec.ServerGroupType = "CLB" }, err: true},
+		{name: "no service", modify: func(spec *v1.ServerGroupBindingSpec) { spec.ServiceName = "" }, err: true},
+		{name: "no port", modify: func(spec *v1.ServerGroupBindingSpec) { spec.ServicePort = intstr.IntOrString{} }, err: true},
+		{name: "weight out of range", modify: func(spec *v1.ServerGroupBindingSpec) { spec.Weight = weight(101) }, err: true},
+		{name: "unknown traffic policy", modify: func(spec *v1.ServerGroupBindingSpec) { spec.TrafficPolicy = "eni" }, err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spec := valid()
+			c.modify(&spec)
+			err := validateSpec(&spec)
+			if c.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBindingTrafficPolicy(t *testing.T) {
+	nodePortSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "tea-svc"},
+		Spec: corev1.ServiceSpec{
+			Type:                  corev1.ServiceTypeNodePort,
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+		},
+	}
+	clusterIPSvc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "tea-svc"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP},
+	}
+	binding := func(policy string) *v1.ServerGroupBinding {
+		return &v1.ServerGroupBinding{Spec: v1.ServerGroupBindingSpec{ServicePort: intstr.FromInt(80), TrafficPolicy: policy}}
+	}
+
+	policy, err := bindingTrafficPolicy(binding(""), nodePortSvc, corev1.ServicePort{Port: 80, NodePort: 30080})
+	assert.NoError(t, err)
+	assert.Equal(t, helper.LocalTrafficPolicy, policy)
+
+	policy, err = bindingTrafficPolicy(binding("Cluster"), nodePortSvc, corev1.ServicePort{Port: 80, NodePort: 30080})
+	assert.NoError(t, err)
+	assert.Equal(t, helper.ClusterTrafficPolicy, policy)
+
+	policy, err = bindingTrafficPolicy(binding("ENI"), clusterIPSvc, corev1.ServicePort{Port: 80})
+	assert.NoError(t, err)
+	assert.Equal(t, helper.ENITrafficPolicy, policy)
+
+	_, err = bindingTrafficPolicy(binding("Local"), clusterIPSvc, corev1.ServicePort{Port: 80})
+	assert.Error(t, err)
+}
+
+func TestUpdateStatus(t *testing.T) {
+	backends := []v1.ServerGroupBindingBackend{{ServerId: "eni-1", ServerIp: "192.168.0.1", ServerType: "Eni", Port: 80, Weight: 100}}
+	cases := []struct {
+		name     string
+		syncErr  error
+		status   metav1.ConditionStatus
+		reason   v1.ServerGroupBindingConditionReason
+		backends int
+	}{
+		{name: "synced", status: metav1.ConditionTrue, reason: v1.ServerGroupBindingReasonSynced, backends: 1},
+		{name: "invalid spec", syncErr: &bindingError{reason: v1.ServerGroupBindingReasonInvalidSpec, err: fmt.Errorf("serviceName is required")},
+			status: metav1.ConditionFalse, reason: v1.ServerGroupBindingReasonInvalidSpec},
+		{name: "sync failed", syncErr: fmt.Errorf("quota exceeded"),
+			status: metav1.ConditionFalse, reason: v1.ServerGroupBindingReasonSyncFailed},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sgb := &v1.ServerGroupBinding{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "tea", Generation: 2}}
+			scheme := runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(scheme)
+			_ = v1.SchemeBuilder.AddToScheme(scheme)
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sgb).Build()
+			r := &serverGroupBindingReconciler{
+				k8sClient:     k8sClient,
+				eventRecorder: record.NewFakeRecorder(10),
+				logger:        logr.Discard(),
+			}
+
+			assert.Nil(t, r.updateStatus(context.TODO(), sgb, backends, c.syncErr))
+
+			updated := &v1.ServerGroupBinding{}
+			assert.Nil(t, k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(sgb), updated))
+			ready := meta.FindStatusCondition(updated.Status.Conditions, string(v1.ServerGroupBindingConditionReady))
+			assert.Equal(t, c.status, ready.Status)
+			assert.Equal(t, string(c.reason), ready.Reason)
+			assert.Equal(t, int64(2), updated.Status.ObservedGeneration)
+			assert.Equal(t, c.backends, len(updated.Status.Backends))
+			assert.Equal(t, c.syncErr != nil, updated.Status.LastError != "")
+		})
+	}
+}
+
+// bindingCloud is the cloud of the cluster of the bindings
+type bindingCloud struct {
+	prvd.Provider
+}
+
+func (c *bindingCloud) ClusterID() string {
+	return "cluster-a"
+}
+
+// consoleServiceApplier records the stacks applied to the ALB server groups
+type consoleServiceApplier struct {
+	stacks []*albmodel.ConsoleServiceStack
+}
+
+func (a *consoleServiceApplier) Apply(ctx context.Context, stack *albmodel.ConsoleServiceStack) error {
+	a.stacks = append(a.stacks, stack)
+	return nil
+}
+
+func newBinding(name, serverGroupID string, created time.Time) *v1.ServerGroupBinding {
+	return &v1.ServerGroupBinding{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name),
+			CreationTimestamp: metav1.NewTime(created)},
+		Spec: v1.ServerGroupBindingSpec{
+			ServerGroupId:   serverGroupID,
+			ServerGroupType: v1.ServerGroupTypeALB,
+			ServiceName:     "tea-svc",
+			ServicePort:     intstr.FromInt(80),
+		},
+	}
+}
+
+func newBindingTestClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1.SchemeBuilder.AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestConflictingBinding(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	first := newBinding("first", "sgp-1", now.Add(-time.Hour))
+	second := newBinding("second", "sgp-1", now)
+	same := newBinding("same", "sgp-1", now)
+	other := newBinding("other", "sgp-2", now.Add(-2*time.Hour))
+	r := &serverGroupBindingReconciler{k8sClient: newBindingTestClient(first, second, same, other)}
+
+	conflict, err := r.conflictingBinding(context.TODO(), first)
+	assert.NoError(t, err)
+	assert.Nil(t, conflict)
+
+	// the oldest binding, then the smaller key binds the server group
+	conflict, err = r.conflictingBinding(context.TODO(), second)
+	assert.NoError(t, err)
+	if assert.NotNil(t, conflict) {
+		assert.Equal(t, "first", conflict.Name)
+	}
+	assert.True(t, bindsBefore(same, second))
+
+	// the duplicate binding is rejected before any server is registered
+	_, _, err = r.syncServers(context.TODO(), second)
+	assert.Error(t, err)
+	assert.Equal(t, v1.ServerGroupBindingReasonConflict, errorReason(err))
+}
+
+func TestCleanupServersByDescription(t *testing.T) {
+	sgb := newBinding("tea", "sgp-1", time.Now())
+	sgb.Finalizers = []string{util.ServerGroupBindingFinalizer}
+	k8sClient := newBindingTestClient(sgb)
+	applier := &consoleServiceApplier{}
+	r := &serverGroupBindingReconciler{
+		cloud:                 &bindingCloud{},
+		k8sClient:             k8sClient,
+		eventRecorder:         record.NewFakeRecorder(10),
+		logger:                logr.Discard(),
+		consoleServiceApplier: applier,
+		k8sFinalizerManager:   helper.NewDefaultFinalizerManager(k8sClient),
+	}
+
+	// only the servers registered by the binding are removed
+	assert.NoError(t, r.cleanupServers(context.TODO(), sgb))
+	if assert.Equal(t, 1, len(applier.stacks)) {
+		assert.Equal(t, "k8s.sgb.tea.default.cluster-a", applier.stacks[0].ServerDescription)
+		assert.Empty(t, applier.stacks[0].Backends)
+	}
+}
@@ -0,0 +1,48 @@
+package servergroupbinding
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateStatus reports the result of a sync on the ServerGroupBinding. The
+// backends of the last successful sync are kept when the sync failed.
+func (r *serverGroupBindingReconciler) updateStatus(ctx context.Context, sgb *v1.ServerGroupBinding,
+	backends []v1.ServerGroupBindingBackend, syncErr error) error {
+	updated := sgb.DeepCopy()
+	updated.Status.ObservedGeneration = sgb.Generation
+
+	readyCond := metav1.Condition{
+		Type:               string(v1.ServerGroupBindingConditionReady),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(v1.ServerGroupBindingReasonSynced),
+		ObservedGeneration: sgb.Generation,
+	}
+	if syncErr != nil {
+		readyCond.Status = metav1.ConditionFalse
+		readyCond.Reason = string(errorReason(syncErr))
+		readyCond.Message = helper.GetLogMessage(syncErr)
+		updated.Status.LastError = helper.GetLogMessage(syncErr)
+	} else {
+		readyCond.Message = fmt.Sprintf("%d backends are registered", len(backends))
+		updated.Status.LastError = ""
+		updated.Status.Backends = backends
+	}
+	meta.SetStatusCondition(&updated.Status.Conditions, readyCond)
+
+	if equality.Semantic.DeepEqual(sgb.Status, updated.Status) {
+		return nil
+	}
+	if err := r.k8sClient.Status().Patch(ctx, updated, client.MergeFrom(sgb)); err != nil {
+		return fmt.Errorf("update servergroupbinding %s status: %w", util.Key(sgb), err)
+	}
+	return nil
+}
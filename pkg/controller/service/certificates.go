@@ -0,0 +1,308 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	nlbmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/nlb"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	"k8s.io/alibaba-load-balancer-controller/pkg/provider/alibaba/base"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CACertKey is the key of the CA bundle in the Secret referenced by the cacert-secret annotation
+	CACertKey = "ca.crt"
+
+	serviceKind = "Service"
+)
+
+// serviceCertificateName matches the names of the certificates uploaded for the services, see
+// certificateOwnerPrefix
+var serviceCertificateName = regexp.MustCompile(`-[0-9a-f]{6}-[0-9a-f]{6}$`)
+
+func NewCertificateManager(kubeClient client.Client, cloud prvd.Provider) *CertificateManager {
+	return &CertificateManager{
+		kubeClient: kubeClient,
+		cloud:      cloud,
+	}
+}
+
+// CertificateManager uploads the certificates in the Secrets referenced by a service to CAS for its TCPSSL
+// listeners, and deletes the ones uploaded for the service once its listeners no longer use them.
+type CertificateManager struct {
+	kubeClient client.Client
+	cloud      prvd.Provider
+}
+
+// UsesSecretCertificates returns true if the service references Secrets for the certificates of its listeners.
+// The listeners of such a service are reconciled every time, so that the renewed Secrets are uploaded.
+func UsesSecretCertificates(anno *annotation.AnnotationRequest) bool {
+	return anno.Get(annotation.CertSecret) != "" || anno.Get(annotation.CaCertSecret) != ""
+}
+
+// BuildLocalModel reads the Secrets referenced by the service into its TCPSSL listeners.
+func (mgr *CertificateManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) error {
+	certSecret, caCertSecret := reqCtx.Anno.Get(annotation.CertSecret), reqCtx.Anno.Get(annotation.CaCertSecret)
+	if certSecret != "" && reqCtx.Anno.Get(annotation.CertID) != "" {
+		return fmt.Errorf("annotation %s and %s can not be used together", annotation.CertID, annotation.CertSecret)
+	}
+	if caCertSecret != "" && reqCtx.Anno.Get(annotation.CaCertID) != "" {
+		return fmt.Errorf("annotation %s and %s can not be used together", annotation.CaCertID, annotation.CaCertSecret)
+	}
+
+	var cert, caCert *nlbmodel.SecretCertificate
+	var err error
+	if certSecret != "" {
+		if cert, err = mgr.buildSecretCertificate(reqCtx, certSecret, false); err != nil {
+			return err
+		}
+	}
+	if caCertSecret != "" {
+		if caCert, err = mgr.buildSecretCertificate(reqCtx, caCertSecret, true); err != nil {
+			return err
+		}
+	}
+	for _, lis := range mdl.Listeners {
+		if !isTCPSSL(lis.ListenerProtocol) {
+			continue
+		}
+		lis.CertificateSecret = cert
+		lis.CaCertificateSecret = caCert
+		// the ca bundle enables mutual authentication unless it is turned off explicitly
+		if caCert != nil && lis.CaEnabled == nil {
+			lis.CaEnabled = tea.Bool(true)
+		}
+	}
+	return nil
+}
+
+func (mgr *CertificateManager) buildSecretCertificate(reqCtx *svcCtx.RequestContext, name string, isCA bool,
+) (*nlbmodel.SecretCertificate, error) {
+	secret := &v1.Secret{}
+	key := types.NamespacedName{Namespace: reqCtx.Service.Namespace, Name: name}
+	if err := mgr.kubeClient.Get(reqCtx.Ctx, key, secret); err != nil {
+		return nil, fmt.Errorf("get secret %s error: %s", key, err.Error())
+	}
+
+	cert := &nlbmodel.SecretCertificate{Secret: key.String(), IsCA: isCA}
+	if isCA {
+		cert.Certificate = string(secret.Data[CACertKey])
+		if cert.Certificate == "" {
+			return nil, fmt.Errorf("secret %s has no %s", key, CACertKey)
+		}
+	} else {
+		cert.Certificate = string(secret.Data[v1.TLSCertKey])
+		cert.PrivateKey = string(secret.Data[v1.TLSPrivateKeyKey])
+		if cert.Certificate == "" || cert.PrivateKey == "" {
+			return nil, fmt.Errorf("secret %s has no %s or %s", key, v1.TLSCertKey, v1.TLSPrivateKeyKey)
+		}
+	}
+	cert.Fingerprint = helper.CertificateFingerprint(cert.Certificate)
+	if cert.Fingerprint == "" {
+		return nil, fmt.Errorf("secret %s has no PEM encoded certificate", key)
+	}
+	cert.CertName = certificateOwnerPrefix(reqCtx.Service) + shortDigest(cert.Certificate, cert.PrivateKey)
+	return cert, nil
+}
+
+// certificateOwnerPrefix returns the prefix of the names of the certificates uploaded for the service. The digest
+// tells the services of the same name in other clusters apart.
+func certificateOwnerPrefix(svc *v1.Service) string {
+	return fmt.Sprintf("%s-%s-%s-", svc.Namespace, svc.Name, shortDigest(base.CLUSTER_ID, svc.Namespace, "/", svc.Name))
+}
+
+func shortDigest(args ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(args, "")))
+	return hex.EncodeToString(sum[:])[0:6]
+}
+
+// EnsureCertificates uploads the certificates of the listeners which are not in CAS yet, and sets the certificate
+// ids of the listeners. A certificate already in CAS with the same fingerprint is used instead of uploading it
+// again, so a renewed Secret is uploaded first, and the listeners are switched to it afterwards.
+func (mgr *CertificateManager) EnsureCertificates(reqCtx *svcCtx.RequestContext, local *nlbmodel.NetworkLoadBalancer) error {
+	certs, caCerts := listSecretCertificates(local)
+	if len(certs) != 0 {
+		sdkCerts, err := mgr.cloud.DescribeSSLCertificateList(reqCtx.Ctx)
+		if err != nil {
+			return fmt.Errorf("DescribeSSLCertificateList error: %s", err.Error())
+		}
+		for _, cert := range certs {
+			if err := mgr.ensureCertificate(reqCtx, cert, sdkCerts); err != nil {
+				return err
+			}
+		}
+	}
+	if len(caCerts) != 0 {
+		sdkCerts, err := mgr.cloud.DescribeCACertificateList(reqCtx.Ctx)
+		if err != nil {
+			return fmt.Errorf("DescribeCACertificateList error: %s", err.Error())
+		}
+		for _, cert := range caCerts {
+			if err := mgr.ensureCertificate(reqCtx, cert, sdkCerts); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, lis := range local.Listeners {
+		if lis.CertificateSecret != nil {
+			lis.CertificateIds = []string{lis.CertificateSecret.CertificateId}
+		}
+		if lis.CaCertificateSecret != nil {
+			lis.CaCertificateIds = []string{lis.CaCertificateSecret.CertificateId}
+		}
+	}
+	return nil
+}
+
+func (mgr *CertificateManager) ensureCertificate(reqCtx *svcCtx.RequestContext, cert *nlbmodel.SecretCertificate,
+	sdkCerts []model.CertificateInfo) error {
+	for _, sdkCert := range sdkCerts {
+		if sdkCert.CertName == cert.CertName {
+			cert.CertificateId = sdkCert.CertIdentifier
+			return nil
+		}
+	}
+	// the certificates uploaded for other services are deleted with them, they are never reused
+	for _, sdkCert := range sdkCerts {
+		if strings.EqualFold(sdkCert.Sha2, cert.Fingerprint) && !serviceCertificateName.MatchString(sdkCert.CertName) {
+			cert.CertificateId = sdkCert.CertIdentifier
+			return nil
+		}
+	}
+
+	var err error
+	if cert.IsCA {
+		cert.CertificateId, err = mgr.cloud.CreateCACertificateWithName(reqCtx.Ctx, cert.CertName, cert.Certificate)
+	} else {
+		cert.CertificateId, err = mgr.cloud.CreateSSLCertificateWithName(reqCtx.Ctx, cert.CertName, cert.Certificate, cert.PrivateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("upload certificate of secret %s error: %s", cert.Secret, err.Error())
+	}
+	reqCtx.Log.Info(fmt.Sprintf("uploaded certificate %s of secret %s", cert.CertificateId, cert.Secret))
+	return nil
+}
+
+// CleanupCertificates deletes the certificates uploaded for the service which the listeners no longer use. It is
+// called after the listeners are applied, the certificates of the remote listeners which are not changed are
+// still in use.
+func (mgr *CertificateManager) CleanupCertificates(reqCtx *svcCtx.RequestContext, local, remote *nlbmodel.NetworkLoadBalancer) error {
+	inUse := sets.NewString()
+	for _, lis := range local.Listeners {
+		inUse.Insert(lis.CertificateIds...)
+		inUse.Insert(lis.CaCertificateIds...)
+	}
+	usesCert, usesCACert := reqCtx.Anno.Get(annotation.CertSecret) != "", reqCtx.Anno.Get(annotation.CaCertSecret) != ""
+	for _, r := range remote.Listeners {
+		usesCert = usesCert || len(r.CertificateIds) != 0
+		usesCACert = usesCACert || len(r.CaCertificateIds) != 0
+		for _, l := range local.Listeners {
+			if r.ListenerId == "" || r.ListenerId != l.ListenerId {
+				continue
+			}
+			if len(l.CertificateIds) == 0 {
+				inUse.Insert(r.CertificateIds...)
+			}
+			if len(l.CaCertificateIds) == 0 {
+				inUse.Insert(r.CaCertificateIds...)
+			}
+		}
+	}
+
+	prefix := certificateOwnerPrefix(reqCtx.Service)
+	if usesCert {
+		sdkCerts, err := mgr.cloud.DescribeSSLCertificateList(reqCtx.Ctx)
+		if err != nil {
+			return fmt.Errorf("DescribeSSLCertificateList error: %s", err.Error())
+		}
+		for _, cert := range staleCertificates(sdkCerts, prefix, inUse) {
+			reqCtx.Log.Info(fmt.Sprintf("delete certificate %s [%s]", cert.CertName, cert.CertIdentifier))
+			if err := mgr.cloud.DeleteSSLCertificate(reqCtx.Ctx, cert.CertIdentifier); err != nil {
+				return fmt.Errorf("delete certificate %s error: %s", cert.CertIdentifier, err.Error())
+			}
+		}
+	}
+	if usesCACert {
+		sdkCerts, err := mgr.cloud.DescribeCACertificateList(reqCtx.Ctx)
+		if err != nil {
+			return fmt.Errorf("DescribeCACertificateList error: %s", err.Error())
+		}
+		for _, cert := range staleCertificates(sdkCerts, prefix, inUse) {
+			reqCtx.Log.Info(fmt.Sprintf("delete ca certificate %s [%s]", cert.CertName, cert.CertIdentifier))
+			if err := mgr.cloud.DeleteCACertificate(reqCtx.Ctx, cert.CertIdentifier); err != nil {
+				return fmt.Errorf("delete ca certificate %s error: %s", cert.CertIdentifier, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// staleCertificates returns the certificates named with the owner prefix which are not in use.
+func staleCertificates(sdkCerts []model.CertificateInfo, prefix string, inUse sets.String) []model.CertificateInfo {
+	var stale []model.CertificateInfo
+	for _, cert := range sdkCerts {
+		if strings.HasPrefix(cert.CertName, prefix) && !inUse.Has(cert.CertIdentifier) {
+			stale = append(stale, cert)
+		}
+	}
+	return stale
+}
+
+// listSecretCertificates returns the distinct certificates and CA certificates of the listeners.
+func listSecretCertificates(mdl *nlbmodel.NetworkLoadBalancer) ([]*nlbmodel.SecretCertificate, []*nlbmodel.SecretCertificate) {
+	var certs, caCerts []*nlbmodel.SecretCertificate
+	seen := make(map[*nlbmodel.SecretCertificate]bool)
+	for _, lis := range mdl.Listeners {
+		if lis.CertificateSecret != nil && !seen[lis.CertificateSecret] {
+			seen[lis.CertificateSecret] = true
+			certs = append(certs, lis.CertificateSecret)
+		}
+		if lis.CaCertificateSecret != nil && !seen[lis.CaCertificateSecret] {
+			seen[lis.CaCertificateSecret] = true
+			caCerts = append(caCerts, lis.CaCertificateSecret)
+		}
+	}
+	return certs, caCerts
+}
+
+// CheckCertificateExpiry exports the expiry time of the certificates of the TCPSSL listeners, and records Warning
+// events on the service for the certificates expiring within the window.
+func (mgr *CertificateManager) CheckCertificateExpiry(reqCtx *svcCtx.RequestContext, local *nlbmodel.NetworkLoadBalancer) {
+	svc := reqCtx.Service
+	certIDs := make(map[string][]string)
+	for _, lis := range local.Listeners {
+		if lis.ListenerProtocol != nlbmodel.TCPSSL || len(lis.CertificateIds) == 0 {
+			continue
+		}
+		certIDs[fmt.Sprintf("%s/%s", lis.PortString(), lis.ListenerProtocol)] = lis.CertificateIds
+	}
+	if len(certIDs) == 0 || helper.NeedDeleteLoadBalancer(svc) {
+		helper.ForgetCertificateExpiry(serviceKind, svc.Namespace, svc.Name)
+		return
+	}
+	sdkCerts, err := mgr.cloud.DescribeSSLCertificateList(reqCtx.Ctx)
+	if err != nil {
+		reqCtx.Log.Error(err, "list certificates to check expiry")
+		return
+	}
+	expiring := helper.RecordCertificateExpiry(serviceKind, svc.Namespace, svc.Name,
+		helper.BuildListenerCertificates(certIDs, sdkCerts), ctrlCfg.ControllerCFG.CertificateExpiryWarningWindow, time.Now())
+	for _, cert := range expiring {
+		reqCtx.Recorder.Event(svc, v1.EventTypeWarning, helper.ServiceEventReasonCertificateExpiring, cert.ExpiryMessage())
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	nlbmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/nlb"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// certCloud records the certificates uploaded to and deleted from CAS
+type certCloud struct {
+	prvd.Provider
+	certs     []model.CertificateInfo
+	caCerts   []model.CertificateInfo
+	uploaded  []string
+	deleted   []string
+	caDeleted []string
+}
+
+func (c *certCloud) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	return c.certs, nil
+}
+
+func (c *certCloud) CreateSSLCertificateWithName(ctx context.Context, certName, certificate, privateKey string) (string, error) {
+	c.uploaded = append(c.uploaded, certName)
+	return "cert-" + certName, nil
+}
+
+func (c *certCloud) DeleteSSLCertificate(ctx context.Context, certId string) error {
+	c.deleted = append(c.deleted, certId)
+	return nil
+}
+
+func (c *certCloud) DescribeCACertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	return c.caCerts, nil
+}
+
+func (c *certCloud) CreateCACertificateWithName(ctx context.Context, certName, certificate string) (string, error) {
+	c.uploaded = append(c.uploaded, certName)
+	return "ca-" + certName, nil
+}
+
+func (c *certCloud) DeleteCACertificate(ctx context.Context, certId string) error {
+	c.caDeleted = append(c.caDeleted, certId)
+	return nil
+}
+
+func testPEMCertificate(content string) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte(content)}))
+}
+
+func newCertificateRequestContext(annotations map[string]string) *svcCtx.RequestContext {
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "game", Annotations: map[string]string{}}}
+	for k, v := range annotations {
+		svc.Annotations[annotation.Annotation(k)] = v
+	}
+	return &svcCtx.RequestContext{
+		Ctx:     context.TODO(),
+		Service: svc,
+		Anno:    annotation.NewAnnotationRequest(svc),
+		Log:     logr.Discard(),
+	}
+}
+
+func TestBuildSecretCertificates(t *testing.T) {
+	kubeClient := fake.NewClientBuilder().WithObjects(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "game-tls"},
+			Data:       map[string][]byte{v1.TLSCertKey: []byte(testPEMCertificate("game")), v1.TLSPrivateKeyKey: []byte("key")},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "clients"},
+			Data:       map[string][]byte{CACertKey: []byte(testPEMCertificate("ca"))},
+		},
+	).Build()
+	mgr := NewCertificateManager(kubeClient, nil)
+	newModel := func() *nlbmodel.NetworkLoadBalancer {
+		return &nlbmodel.NetworkLoadBalancer{Listeners: []*nlbmodel.ListenerAttribute{
+			{ListenerProtocol: nlbmodel.TCP, ListenerPort: 80},
+			{ListenerProtocol: nlbmodel.TCPSSL, ListenerPort: 443},
+		}}
+	}
+
+	reqCtx := newCertificateRequestContext(map[string]string{annotation.CertSecret: "game-tls", annotation.CaCertSecret: "clients"})
+	mdl := newModel()
+	assert.NoError(t, mgr.BuildLocalModel(reqCtx, mdl))
+	assert.Nil(t, mdl.Listeners[0].CertificateSecret)
+	tls := mdl.Listeners[1]
+	if assert.NotNil(t, tls.CertificateSecret) && assert.NotNil(t, tls.CaCertificateSecret) {
+		assert.Equal(t, "default/game-tls", tls.CertificateSecret.Secret)
+		assert.Equal(t, helper.CertificateFingerprint(testPEMCertificate("game")), tls.CertificateSecret.Fingerprint)
+		assert.Contains(t, tls.CertificateSecret.CertName, certificateOwnerPrefix(reqCtx.Service))
+		assert.True(t, tls.CaCertificateSecret.IsCA)
+	}
+	assert.True(t, tea.BoolValue(tls.CaEnabled))
+
+	reqCtx = newCertificateRequestContext(map[string]string{annotation.CertSecret: "game-tls", annotation.CertID: "123-cn-hangzhou"})
+	assert.Error(t, mgr.BuildLocalModel(reqCtx, newModel()))
+	reqCtx = newCertificateRequestContext(map[string]string{annotation.CaCertSecret: "game-tls"})
+	assert.Error(t, mgr.BuildLocalModel(reqCtx, newModel()))
+}
+
+func TestEnsureAndCleanupCertificates(t *testing.T) {
+	reqCtx := newCertificateRequestContext(map[string]string{annotation.CertSecret: "game-tls", annotation.CaCertSecret: "clients"})
+	prefix := certificateOwnerPrefix(reqCtx.Service)
+	cert := &nlbmodel.SecretCertificate{Secret: "default/game-tls", CertName: prefix + "aaaaaa", Fingerprint: "AA"}
+	caCert := &nlbmodel.SecretCertificate{Secret: "default/clients", IsCA: true, CertName: prefix + "cccccc", Fingerprint: "CC"}
+	local := &nlbmodel.NetworkLoadBalancer{Listeners: []*nlbmodel.ListenerAttribute{
+		{ListenerId: "lsn-443", ListenerProtocol: nlbmodel.TCPSSL, CertificateSecret: cert, CaCertificateSecret: caCert},
+		{ListenerId: "lsn-8443", ListenerProtocol: nlbmodel.TCPSSL, CertificateSecret: cert, CaCertificateSecret: caCert},
+	}}
+	cloud := &certCloud{
+		certs: []model.CertificateInfo{
+			{CertName: prefix + "999999", CertIdentifier: "cert-old"},
+			{CertName: "default-other-111111-222222", CertIdentifier: "cert-other", Sha2: "aa"},
+			{CertName: "user-upload", CertIdentifier: "cert-user"},
+		},
+		caCerts: []model.CertificateInfo{{CertName: "user-ca", CertIdentifier: "ca-user", Sha2: "cc"}},
+	}
+	mgr := NewCertificateManager(nil, cloud)
+
+	assert.NoError(t, mgr.EnsureCertificates(reqCtx, local))
+	// the certificate of the same content uploaded for another service is not reused
+	assert.Equal(t, []string{prefix + "aaaaaa"}, cloud.uploaded)
+	for _, lis := range local.Listeners {
+		assert.Equal(t, []string{"cert-" + prefix + "aaaaaa"}, lis.CertificateIds)
+		assert.Equal(t, []string{"ca-user"}, lis.CaCertificateIds)
+	}
+
+	cloud.certs = append(cloud.certs, model.CertificateInfo{CertName: prefix + "aaaaaa", CertIdentifier: "cert-" + prefix + "aaaaaa"})
+	remote := &nlbmodel.NetworkLoadBalancer{Listeners: []*nlbmodel.ListenerAttribute{
+		{ListenerId: "lsn-443", CertificateIds: []string{"cert-old"}},
+	}}
+	assert.NoError(t, mgr.CleanupCertificates(reqCtx, local, remote))
+	// only the certificates uploaded for the service are deleted
+	assert.Equal(t, []string{"cert-old"}, cloud.deleted)
+	assert.Empty(t, cloud.caDeleted)
+
+	// the listeners are deleted with the service
+	cloud.deleted = nil
+	assert.NoError(t, mgr.CleanupCertificates(reqCtx, &nlbmodel.NetworkLoadBalancer{}, &nlbmodel.NetworkLoadBalancer{}))
+	assert.Equal(t, []string{"cert-old", "cert-" + prefix + "aaaaaa"}, cloud.deleted)
+}
+
+func TestCheckCertificateExpiry(t *testing.T) {
+	defer func(window time.Duration) { ctrlCfg.ControllerCFG.CertificateExpiryWarningWindow = window }(ctrlCfg.ControllerCFG.CertificateExpiryWarningWindow)
+	ctrlCfg.ControllerCFG.CertificateExpiryWarningWindow = 7 * 24 * time.Hour
+
+	reqCtx := newCertificateRequestContext(nil)
+	recorder := record.NewFakeRecorder(10)
+	reqCtx.Recorder = recorder
+	reqCtx.Service.Spec.Type = v1.ServiceTypeLoadBalancer
+	cloud := &certCloud{certs: []model.CertificateInfo{
+		{CertIdentifier: "cert-soon", CertName: "soon", AfterDate: time.Now().Add(24 * time.Hour).UnixMilli()},
+		{CertIdentifier: "cert-later", CertName: "later", AfterDate: time.Now().Add(90 * 24 * time.Hour).UnixMilli()},
+	}}
+	mgr := NewCertificateManager(nil, cloud)
+	defer helper.ForgetCertificateExpiry(serviceKind, "default", "game")
+
+	mgr.CheckCertificateExpiry(reqCtx, &nlbmodel.NetworkLoadBalancer{Listeners: []*nlbmodel.ListenerAttribute{
+		{ListenerProtocol: nlbmodel.TCP, ListenerPort: 80},
+		{ListenerProtocol: nlbmodel.TCPSSL, ListenerPort: 443, CertificateIds: []string{"cert-soon"}},
+		{ListenerProtocol: nlbmodel.TCPSSL, ListenerPort: 8443, CertificateIds: []string{"cert-later"}},
+	}})
+	close(recorder.Events)
+	var events []string
+	for event := range recorder.Events {
+		events = append(events, event)
+	}
+	if assert.Len(t, events, 1) {
+		assert.Contains(t, events[0], "Warning CertificateExpiring certificate cert-soon (soon) of listener 443/TCPSSL")
+	}
+}
@@ -0,0 +1,440 @@
+package clb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/time/rate"
+	"k8s.io/alibaba-load-balancer-controller/pkg/context/shared"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	prvdutil "k8s.io/alibaba-load-balancer-controller/pkg/provider/alibaba/util"
+	"k8s.io/alibaba-load-balancer-controller/pkg/provider/dryrun"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util/metric"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// clbNotFoundErrorCode is returned by the SLB api when the load balancer is already deleted
+const clbNotFoundErrorCode = "InvalidLoadBalancerId.NotFound"
+
+func Add(mgr manager.Manager, ctx *shared.SharedContext) error {
+	reconciler, err := newReconciler(mgr, ctx)
+	if err != nil {
+		return fmt.Errorf("new clb reconciler error: %s", err.Error())
+	}
+	return add(mgr, reconciler)
+}
+
+func newReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*ReconcileCLB, error) {
+	recon := &ReconcileCLB{
+		cloud:            ctx.Provider(),
+		kubeClient:       mgr.GetClient(),
+		scheme:           mgr.GetScheme(),
+		logger:           ctrl.Log.WithName("controller").WithName("clb-controller"),
+		record:           mgr.GetEventRecorderFor("clb-controller"),
+		finalizerManager: helper.NewDefaultFinalizerManager(mgr.GetClient()),
+	}
+
+	slbManager := NewLoadBalancerManager(recon.cloud)
+	listenerManager := NewListenerManager(recon.cloud)
+	vGroupManager, err := NewVGroupManager(recon.kubeClient, recon.cloud)
+	if err != nil {
+		return nil, fmt.Errorf("NewVGroupManager error:%s", err.Error())
+	}
+	recon.builder = NewModelBuilder(slbManager, listenerManager, vGroupManager)
+	recon.applier = NewModelApplier(slbManager, listenerManager, vGroupManager)
+	return recon, nil
+}
+
+type clbController struct {
+	c     controller.Controller
+	recon *ReconcileCLB
+}
+
+func (n clbController) Start(ctx context.Context) error {
+	return n.c.Start(ctx)
+}
+
+func add(mgr manager.Manager, r *ReconcileCLB) error {
+	rateLimit := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(5*time.Second, 300*time.Second),
+		// 10 qps, 100 bucket size.  This is only for retry speed and its only the overall factor (not per item)
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+
+	recoverPanic := true
+	// Create a new controller
+	c, err := controller.NewUnmanaged(
+		"clb-controller", mgr,
+		controller.Options{
+			Reconciler:              r,
+			MaxConcurrentReconciles: 2,
+			RateLimiter:             rateLimit,
+			RecoverPanic:            &recoverPanic,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Watch(&source.Kind{Type: &v1.Service{}},
+		NewEnqueueRequestForServiceEvent(mgr.GetEventRecorderFor("clb-controller"))); err != nil {
+		return fmt.Errorf("watch resource svc error: %s", err.Error())
+	}
+
+	if err := c.Watch(&source.Kind{Type: &v1.Endpoints{}},
+		NewEnqueueRequestForEndpointEvent(mgr.GetEventRecorderFor("clb-controller"))); err != nil {
+		return fmt.Errorf("watch resource endpoint error: %s", err.Error())
+	}
+
+	if err := c.Watch(&source.Kind{Type: &v1.Node{}},
+		NewEnqueueRequestForNodeEvent(mgr.GetEventRecorderFor("clb-controller"))); err != nil {
+		return fmt.Errorf("watch resource node error: %s", err.Error())
+	}
+	return mgr.Add(&clbController{c: c, recon: r})
+}
+
+var _ reconcile.Reconciler = &ReconcileCLB{}
+
+type ReconcileCLB struct {
+	scheme  *runtime.Scheme
+	builder *ModelBuilder
+	applier *ModelApplier
+
+	// client
+	cloud      prvd.Provider
+	kubeClient client.Client
+
+	logger logr.Logger
+
+	//record event recorder
+	record           record.EventRecorder
+	finalizerManager helper.FinalizerManager
+}
+
+func (m *ReconcileCLB) Reconcile(_ context.Context, request reconcile.Request) (reconcile.Result, error) {
+	return reconcile.Result{}, m.reconcile(request)
+}
+
+func (m *ReconcileCLB) reconcile(request reconcile.Request) error {
+	startTime := time.Now()
+	svc := &v1.Service{}
+	err := m.kubeClient.Get(context.Background(), request.NamespacedName, svc)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			m.logger.Info("service not found, skip", "service", request.NamespacedName)
+			return nil
+		}
+		m.logger.Error(err, "reconcile: get service failed", "service", request.NamespacedName)
+	}
+
+	anno := &annotation.AnnotationRequest{Service: svc}
+	// new context for each request
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, dryrun.ContextService, svc)
+	reqCtx := &svcCtx.RequestContext{
+		Ctx:      ctx,
+		Service:  svc,
+		Anno:     anno,
+		Log:      m.logger.WithValues("service", util.Key(svc)),
+		Recorder: m.record,
+	}
+
+	klog.Infof("%s: ensure loadbalancer with service details, \n%+v", util.Key(svc), util.PrettyJson(svc))
+
+	if holder := helper.GetLoadBalancerClassHolder(svc); holder != "" {
+		reqCtx.Log.Info("load balancer class changed on a live service, skip")
+		// both controllers skip the service, only the one holding the load balancer reports it
+		if holder == helper.CLBFinalizer {
+			m.record.Event(svc, v1.EventTypeWarning, helper.ClassChangeForbidden,
+				fmt.Sprintf("The load balancer class of the service can not be changed to %q while its load balancer exists, "+
+					"change it back or recreate the service", helper.GetLoadBalancerClass(svc)))
+		}
+		return nil
+	}
+
+	if helper.NeedDeleteLoadBalancer(svc) {
+		err = m.cleanupLoadBalancerResources(reqCtx)
+	} else {
+		err = m.reconcileLoadBalancerResources(reqCtx)
+	}
+	if err != nil {
+		return err
+	}
+
+	reqCtx.Log.Info("successfully reconcile")
+	metric.SLBLatency.WithLabelValues("reconcile").Observe(metric.MsSince(startTime))
+
+	return nil
+}
+
+func (m *ReconcileCLB) cleanupLoadBalancerResources(reqCtx *svcCtx.RequestContext) error {
+	reqCtx.Log.Info("service do not need lb any more, try to delete it")
+	if helper.HasFinalizer(reqCtx.Service, helper.CLBFinalizer) {
+		lb, err := m.buildAndApplyModel(reqCtx)
+		if err != nil && !prvdutil.IsErrorCode(err, clbNotFoundErrorCode) {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedCleanLB,
+				fmt.Sprintf("Error deleting load balancer [%s]: %s",
+					lb.GetLoadBalancerId(), helper.GetLogMessage(err)))
+			return err
+		}
+
+		if err := m.removeServiceLabels(reqCtx.Service); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedRemoveHash,
+				fmt.Sprintf("Error removing service hash: %s", err.Error()))
+			return err
+		}
+
+		// When service type changes from LoadBalancer to NodePort,
+		// we need to clean Ingress attribute in service status
+		if err := m.removeServiceStatus(reqCtx, reqCtx.Service); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedUpdateStatus,
+				fmt.Sprintf("Error removing load balancer status: %s", err.Error()))
+			return err
+		}
+
+		if err := m.finalizerManager.RemoveFinalizers(reqCtx.Ctx, reqCtx.Service, helper.CLBFinalizer); err != nil {
+			m.record.Event(reqCtx.Service, v1.EventTypeWarning, helper.FailedRemoveFinalizer,
+				fmt.Sprintf("Error removing load balancer finalizer: %v", err.Error()))
+			return err
+		}
+	}
+	m.record.Event(reqCtx.Service, v1.EventTypeNormal, helper.SucceedCleanLB, "Clean load balancer")
+	return nil
+}
+
+func (m *ReconcileCLB) reconcileLoadBalancerResources(req *svcCtx.RequestContext) error {
+
+	if err := m.finalizerManager.AddFinalizers(req.Ctx, req.Service, helper.CLBFinalizer); err != nil {
+		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedAddFinalizer,
+			fmt.Sprintf("Error adding finalizer: %s", err.Error()))
+		return err
+	}
+
+	lb, err := m.buildAndApplyModel(req)
+	if err != nil {
+		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedSyncLB,
+			fmt.Sprintf("Error syncing load balancer [%s]: %s",
+				lb.GetLoadBalancerId(), helper.GetLogMessage(err)))
+		return err
+	}
+
+	if err := m.addServiceLabels(req.Service, lb.GetLoadBalancerId()); err != nil {
+		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedAddHash,
+			fmt.Sprintf("Error adding service hash: %s", err.Error()))
+		return err
+	}
+
+	if err := m.updateServiceStatus(req, req.Service, lb); err != nil {
+		m.record.Event(req.Service, v1.EventTypeWarning, helper.FailedUpdateStatus,
+			fmt.Sprintf("Error updating load balancer status: %s", err.Error()))
+		return err
+	}
+
+	m.record.Event(req.Service, v1.EventTypeNormal, helper.SucceedSyncLB,
+		fmt.Sprintf("Ensured load balancer [%s]", lb.LoadBalancerAttribute.LoadBalancerId))
+	return nil
+}
+
+func (m *ReconcileCLB) buildAndApplyModel(reqCtx *svcCtx.RequestContext) (*model.LoadBalancer, error) {
+
+	// build local model
+	localModel, err := m.builder.BuildModel(reqCtx, LocalModel)
+	if err != nil {
+		return nil, fmt.Errorf("build lb local model error: %s", err.Error())
+	}
+	mdlJson, err := json.Marshal(localModel)
+	if err != nil {
+		return nil, fmt.Errorf("marshal lbmdl error: %s", err.Error())
+	}
+	m.logger.V(5).Info(fmt.Sprintf("local build: %s", mdlJson))
+
+	// apply model
+	remoteModel, err := m.applier.Apply(reqCtx, localModel)
+	if err != nil {
+		return remoteModel, fmt.Errorf("apply model error: %w", err)
+	}
+	return remoteModel, nil
+}
+
+func (m *ReconcileCLB) updateServiceStatus(reqCtx *svcCtx.RequestContext, svc *v1.Service, lb *model.LoadBalancer) error {
+	preStatus := svc.Status.LoadBalancer.DeepCopy()
+	newStatus := &v1.LoadBalancerStatus{}
+	if lb == nil {
+		return fmt.Errorf("lb not found, cannot not patch service status")
+	}
+
+	// If the hostname annotation is set, display the hostname instead of the slb ip
+	if hostName := reqCtx.Anno.Get(annotation.HostName); hostName != "" {
+		newStatus.Ingress = append(newStatus.Ingress,
+			v1.LoadBalancerIngress{
+				Hostname: hostName,
+			})
+	} else {
+		newStatus.Ingress = append(newStatus.Ingress,
+			v1.LoadBalancerIngress{
+				IP: lb.LoadBalancerAttribute.Address,
+			})
+	}
+
+	// Write the state if changed
+	// TODO: Be careful here ... what if there were other changes to the service?
+	if !v1helper.LoadBalancerStatusEqual(preStatus, newStatus) {
+		util.ServiceLog.Info(fmt.Sprintf("status: [%v] [%v]", preStatus, newStatus))
+		var retErr error
+		_ = helper.Retry(
+			&wait.Backoff{
+				Duration: 1 * time.Second,
+				Steps:    3,
+				Factor:   2,
+				Jitter:   4,
+			},
+			func(svc *v1.Service) error {
+				// get latest svc from the shared informer cache
+				svcOld := &v1.Service{}
+				retErr = m.kubeClient.Get(reqCtx.Ctx, util.NamespacedName(svc), svcOld)
+				if retErr != nil {
+					return fmt.Errorf("error to get svc %s", util.Key(svc))
+				}
+				updated := svcOld.DeepCopy()
+				updated.Status.LoadBalancer = *newStatus
+				reqCtx.Log.Info(fmt.Sprintf("LoadBalancer: %v", updated.Status.LoadBalancer))
+				retErr = m.kubeClient.Status().Patch(reqCtx.Ctx, updated, client.MergeFrom(svcOld))
+				if retErr == nil {
+					return nil
+				}
+
+				// If the object no longer exists, we don't want to recreate it. Just bail
+				// out so that we can process the delete, which we should soon be receiving
+				// if we haven't already.
+				if apierrors.IsNotFound(retErr) {
+					util.ServiceLog.Error(retErr, "not persisting update to service that no longer exists")
+					retErr = nil
+					return nil
+				}
+				// TODO: Try to resolve the conflict if the change was unrelated to load
+				// balancer status. For now, just pass it up the stack.
+				if apierrors.IsConflict(retErr) {
+					return fmt.Errorf("not persisting update to service %s that "+
+						"has been changed since we received it: %v", util.Key(svc), retErr)
+				}
+				reqCtx.Log.Error(retErr, "failed to persist updated LoadBalancerStatus"+
+					" after creating its load balancer")
+				return fmt.Errorf("retry with %s, %s", retErr.Error(), helper.TRY_AGAIN)
+			},
+			svc,
+		)
+		return retErr
+	}
+	return nil
+
+}
+
+func (m *ReconcileCLB) removeServiceStatus(reqCtx *svcCtx.RequestContext, svc *v1.Service) error {
+	preStatus := svc.Status.LoadBalancer.DeepCopy()
+	newStatus := &v1.LoadBalancerStatus{}
+
+	// Write the state if changed
+	// TODO: Be careful here ... what if there were other changes to the service?
+	if !v1helper.LoadBalancerStatusEqual(preStatus, newStatus) {
+		util.ServiceLog.Info(fmt.Sprintf("status: [%v] [%v]", preStatus, newStatus))
+		return helper.Retry(
+			&wait.Backoff{
+				Duration: 1 * time.Second,
+				Steps:    3,
+				Factor:   2,
+				Jitter:   4,
+			},
+			func(svc *v1.Service) error {
+				// get latest svc from the shared informer cache
+				svcOld := &v1.Service{}
+				err := m.kubeClient.Get(reqCtx.Ctx, util.NamespacedName(svc), svcOld)
+				if err != nil {
+					return fmt.Errorf("error to get svc %s", util.Key(svc))
+				}
+				updated := svcOld.DeepCopy()
+				updated.Status.LoadBalancer = *newStatus
+				reqCtx.Log.Info(fmt.Sprintf("LoadBalancer: %v", updated.Status.LoadBalancer))
+				err = m.kubeClient.Status().Patch(reqCtx.Ctx, updated, client.MergeFrom(svcOld))
+				if err == nil {
+					return nil
+				}
+
+				// If the object no longer exists, we don't want to recreate it. Just bail
+				// out so that we can process the delete, which we should soon be receiving
+				// if we haven't already.
+				if apierrors.IsNotFound(err) {
+					util.ServiceLog.Error(err, "not persisting update to service that no longer exists")
+					return nil
+				}
+				// TODO: Try to resolve the conflict if the change was unrelated to load
+				// balancer status. For now, just pass it up the stack.
+				if apierrors.IsConflict(err) {
+					return fmt.Errorf("not persisting update to service %s that "+
+						"has been changed since we received it: %v", util.Key(svc), err)
+				}
+				reqCtx.Log.Error(err, "failed to persist updated LoadBalancerStatus"+
+					" after creating its load balancer")
+				return fmt.Errorf("retry with %s, %s", err.Error(), helper.TRY_AGAIN)
+			},
+			svc,
+		)
+	}
+	return nil
+
+}
+
+func (m *ReconcileCLB) addServiceLabels(svc *v1.Service, lbId string) error {
+	updated := svc.DeepCopy()
+	if updated.Labels == nil {
+		updated.Labels = make(map[string]string)
+	}
+	serviceHash := helper.GetServiceHash(svc)
+	updated.Labels[helper.LabelServiceHash] = serviceHash
+	if lbId != "" {
+		updated.Labels[helper.LabelLoadBalancerId] = lbId
+	}
+	if err := m.kubeClient.Status().Patch(context.Background(), updated, client.MergeFrom(svc)); err != nil {
+		return fmt.Errorf("%s failed to add service hash:, error: %s", util.Key(svc), err.Error())
+	}
+	return nil
+}
+
+func (m *ReconcileCLB) removeServiceLabels(svc *v1.Service) error {
+	updated := svc.DeepCopy()
+	needUpdate := false
+	if _, ok := updated.Labels[helper.LabelServiceHash]; ok {
+		delete(updated.Labels, helper.LabelServiceHash)
+		needUpdate = true
+	}
+	if _, ok := updated.Labels[helper.LabelLoadBalancerId]; ok {
+		delete(updated.Labels, helper.LabelLoadBalancerId)
+		needUpdate = true
+	}
+	if needUpdate {
+		if err := m.kubeClient.Status().Patch(context.Background(), updated, client.MergeFrom(svc)); err != nil {
+			return fmt.Errorf("%s failed to remove service hash:, error: %s", util.Key(svc), err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,495 @@
+package clb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	"k8s.io/alibaba-load-balancer-controller/pkg/provider/alibaba/base"
+	"k8s.io/alibaba-load-balancer-controller/pkg/provider/dryrun"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+// DefaultListenerBandwidth -1 means the listener bandwidth is not limited
+const DefaultListenerBandwidth = -1
+
+func NewListenerManager(cloud prvd.Provider) *ListenerManager {
+	return &ListenerManager{
+		cloud: cloud,
+	}
+}
+
+type ListenerManager struct {
+	cloud prvd.Provider
+}
+
+func (mgr *ListenerManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	for _, port := range reqCtx.Service.Spec.Ports {
+		listener, err := mgr.buildListenerFromServicePort(reqCtx, port)
+		if err != nil {
+			return fmt.Errorf("build listener from servicePort %d error: %s", port.Port, err.Error())
+		}
+		mdl.Listeners = append(mdl.Listeners, listener)
+	}
+	return nil
+}
+
+func (mgr *ListenerManager) BuildRemoteModel(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	listeners, err := mgr.cloud.DescribeLoadBalancerListeners(reqCtx.Ctx, mdl.LoadBalancerAttribute.LoadBalancerId)
+	if err != nil {
+		return fmt.Errorf("DescribeLoadBalancerListeners error:%s", err.Error())
+	}
+	mdl.Listeners = listeners
+	return nil
+}
+
+func (mgr *ListenerManager) buildListenerFromServicePort(reqCtx *svcCtx.RequestContext, port v1.ServicePort,
+) (model.ListenerAttribute, error) {
+	listener := model.ListenerAttribute{
+		NamedKey: &model.ListenerNamedKey{
+			Prefix:      model.DEFAULT_PREFIX,
+			CID:         base.CLUSTER_ID,
+			Namespace:   reqCtx.Service.Namespace,
+			ServiceName: reqCtx.Service.Name,
+			Port:        port.Port,
+		},
+		ListenerPort: int(port.Port),
+	}
+	listener.Description = listener.NamedKey.Key()
+	listener.VGroupName = getVGroupNamedKey(reqCtx.Service, port).Key()
+
+	proto, err := clbListenerProtocol(reqCtx.Anno.Get(annotation.ProtocolPort), port)
+	if err != nil {
+		return listener, err
+	}
+	listener.Protocol = proto
+
+	if err := setListenerFromAnnotation(reqCtx, &listener); err != nil {
+		return listener, err
+	}
+	return listener, nil
+}
+
+func (mgr *ListenerManager) CreateListener(reqCtx *svcCtx.RequestContext, lbId string, local model.ListenerAttribute) error {
+	var err error
+	switch local.Protocol {
+	case model.TCP:
+		err = mgr.cloud.CreateLoadBalancerTCPListener(reqCtx.Ctx, lbId, local)
+	case model.UDP:
+		err = mgr.cloud.CreateLoadBalancerUDPListener(reqCtx.Ctx, lbId, local)
+	case model.HTTP:
+		err = mgr.cloud.CreateLoadBalancerHTTPListener(reqCtx.Ctx, lbId, local)
+	case model.HTTPS:
+		err = mgr.cloud.CreateLoadBalancerHTTPSListener(reqCtx.Ctx, lbId, local)
+	default:
+		return fmt.Errorf("not support protocol %s", local.Protocol)
+	}
+	if err != nil {
+		return err
+	}
+	// listeners are stopped after creation
+	return mgr.cloud.StartLoadBalancerListener(reqCtx.Ctx, lbId, local.ListenerPort)
+}
+
+func (mgr *ListenerManager) DeleteListener(reqCtx *svcCtx.RequestContext, lbId string, port int) error {
+	return mgr.cloud.DeleteLoadBalancerListener(reqCtx.Ctx, lbId, port)
+}
+
+func (mgr *ListenerManager) UpdateListener(reqCtx *svcCtx.RequestContext, lbId string, local, remote model.ListenerAttribute,
+) error {
+	if remote.Status == model.Stopped {
+		if err := mgr.cloud.StartLoadBalancerListener(reqCtx.Ctx, lbId, remote.ListenerPort); err != nil {
+			return fmt.Errorf("start listener %d error: %s", remote.ListenerPort, err.Error())
+		}
+	}
+
+	update, needUpdate, updateDetail := diffListener(local, remote)
+	if !needUpdate {
+		reqCtx.Log.Info(fmt.Sprintf("update listener: %s [%d] not changed, skip", local.Protocol, local.ListenerPort))
+		return nil
+	}
+
+	reqCtx.Ctx = context.WithValue(reqCtx.Ctx, dryrun.ContextMessage, updateDetail)
+	reqCtx.Log.Info(fmt.Sprintf("update listener: %s [%d] changed, detail %s", local.Protocol, local.ListenerPort, updateDetail))
+	switch local.Protocol {
+	case model.TCP:
+		return mgr.cloud.SetLoadBalancerTCPListenerAttribute(reqCtx.Ctx, lbId, update)
+	case model.UDP:
+		return mgr.cloud.SetLoadBalancerUDPListenerAttribute(reqCtx.Ctx, lbId, update)
+	case model.HTTP:
+		return mgr.cloud.SetLoadBalancerHTTPListenerAttribute(reqCtx.Ctx, lbId, update)
+	case model.HTTPS:
+		return mgr.cloud.SetLoadBalancerHTTPSListenerAttribute(reqCtx.Ctx, lbId, update)
+	}
+	return fmt.Errorf("not support protocol %s", local.Protocol)
+}
+
+// diffListener returns the listener attributes need to be set, only the attributes defined by the service are compared
+func diffListener(local, remote model.ListenerAttribute) (model.ListenerAttribute, bool, string) {
+	update := model.ListenerAttribute{
+		ListenerPort: remote.ListenerPort,
+		Protocol:     remote.Protocol,
+		Description:  remote.Description,
+		VGroupId:     remote.VGroupId,
+	}
+	needUpdate := false
+	updateDetail := ""
+
+	if local.Description != remote.Description {
+		needUpdate = true
+		update.Description = local.Description
+		updateDetail += fmt.Sprintf("Description %v should be changed to %v;", remote.Description, local.Description)
+	}
+	if local.VGroupId != "" && local.VGroupId != remote.VGroupId {
+		needUpdate = true
+		update.VGroupId = local.VGroupId
+		updateDetail += fmt.Sprintf("VGroupId %v should be changed to %v;", remote.VGroupId, local.VGroupId)
+	}
+	if local.Scheduler != "" && local.Scheduler != remote.Scheduler {
+		needUpdate = true
+		update.Scheduler = local.Scheduler
+		updateDetail += fmt.Sprintf("Scheduler %v should be changed to %v;", remote.Scheduler, local.Scheduler)
+	}
+	if local.Bandwidth != 0 && local.Bandwidth != remote.Bandwidth {
+		needUpdate = true
+		update.Bandwidth = local.Bandwidth
+		updateDetail += fmt.Sprintf("Bandwidth %v should be changed to %v;", remote.Bandwidth, local.Bandwidth)
+	}
+	if local.AclStatus != "" && local.AclStatus != remote.AclStatus {
+		needUpdate = true
+		update.AclStatus = local.AclStatus
+		updateDetail += fmt.Sprintf("AclStatus %v should be changed to %v;", remote.AclStatus, local.AclStatus)
+	}
+	if local.AclStatus == model.OnFlag {
+		if local.AclId != "" && local.AclId != remote.AclId {
+			needUpdate = true
+			update.AclId = local.AclId
+			updateDetail += fmt.Sprintf("AclId %v should be changed to %v;", remote.AclId, local.AclId)
+		}
+		if local.AclType != "" && local.AclType != remote.AclType {
+			needUpdate = true
+			update.AclType = local.AclType
+			updateDetail += fmt.Sprintf("AclType %v should be changed to %v;", remote.AclType, local.AclType)
+		}
+	}
+	if local.HealthCheckConnectPort != 0 && local.HealthCheckConnectPort != remote.HealthCheckConnectPort {
+		needUpdate = true
+		update.HealthCheckConnectPort = local.HealthCheckConnectPort
+		updateDetail += fmt.Sprintf("HealthCheckConnectPort %v should be changed to %v;",
+			remote.HealthCheckConnectPort, local.HealthCheckConnectPort)
+	}
+	if local.HealthCheckInterval != 0 && local.HealthCheckInterval != remote.HealthCheckInterval {
+		needUpdate = true
+		update.HealthCheckInterval = local.HealthCheckInterval
+		updateDetail += fmt.Sprintf("HealthCheckInterval %v should be changed to %v;",
+			remote.HealthCheckInterval, local.HealthCheckInterval)
+	}
+	if local.HealthyThreshold != 0 && local.HealthyThreshold != remote.HealthyThreshold {
+		needUpdate = true
+		update.HealthyThreshold = local.HealthyThreshold
+		updateDetail += fmt.Sprintf("HealthyThreshold %v should be changed to %v;",
+			remote.HealthyThreshold, local.HealthyThreshold)
+	}
+	if local.UnhealthyThreshold != 0 && local.UnhealthyThreshold != remote.UnhealthyThreshold {
+		needUpdate = true
+		update.UnhealthyThreshold = local.UnhealthyThreshold
+		updateDetail += fmt.Sprintf("UnhealthyThreshold %v should be changed to %v;",
+			remote.UnhealthyThreshold, local.UnhealthyThreshold)
+	}
+	if local.HealthCheckDomain != "" && local.HealthCheckDomain != remote.HealthCheckDomain {
+		needUpdate = true
+		update.HealthCheckDomain = local.HealthCheckDomain
+		updateDetail += fmt.Sprintf("HealthCheckDomain %v should be changed to %v;",
+			remote.HealthCheckDomain, local.HealthCheckDomain)
+	}
+	if local.HealthCheckURI != "" && local.HealthCheckURI != remote.HealthCheckURI {
+		needUpdate = true
+		update.HealthCheckURI = local.HealthCheckURI
+		updateDetail += fmt.Sprintf("HealthCheckURI %v should be changed to %v;",
+			remote.HealthCheckURI, local.HealthCheckURI)
+	}
+	if local.HealthCheckHttpCode != "" && local.HealthCheckHttpCode != remote.HealthCheckHttpCode {
+		needUpdate = true
+		update.HealthCheckHttpCode = local.HealthCheckHttpCode
+		updateDetail += fmt.Sprintf("HealthCheckHttpCode %v should be changed to %v;",
+			remote.HealthCheckHttpCode, local.HealthCheckHttpCode)
+	}
+	if local.ConnectionDrain != "" && local.ConnectionDrain != remote.ConnectionDrain {
+		needUpdate = true
+		update.ConnectionDrain = local.ConnectionDrain
+		updateDetail += fmt.Sprintf("ConnectionDrain %v should be changed to %v;",
+			remote.ConnectionDrain, local.ConnectionDrain)
+	}
+	if local.ConnectionDrainTimeout != 0 && local.ConnectionDrainTimeout != remote.ConnectionDrainTimeout {
+		needUpdate = true
+		update.ConnectionDrainTimeout = local.ConnectionDrainTimeout
+		updateDetail += fmt.Sprintf("ConnectionDrainTimeout %v should be changed to %v;",
+			remote.ConnectionDrainTimeout, local.ConnectionDrainTimeout)
+	}
+
+	switch local.Protocol {
+	case model.TCP, model.UDP:
+		if local.HealthCheckConnectTimeout != 0 && local.HealthCheckConnectTimeout != remote.HealthCheckConnectTimeout {
+			needUpdate = true
+			update.HealthCheckConnectTimeout = local.HealthCheckConnectTimeout
+			updateDetail += fmt.Sprintf("HealthCheckConnectTimeout %v should be changed to %v;",
+				remote.HealthCheckConnectTimeout, local.HealthCheckConnectTimeout)
+		}
+		if local.HealthCheckType != "" && local.HealthCheckType != remote.HealthCheckType {
+			needUpdate = true
+			update.HealthCheckType = local.HealthCheckType
+			updateDetail += fmt.Sprintf("HealthCheckType %v should be changed to %v;",
+				remote.HealthCheckType, local.HealthCheckType)
+		}
+		if local.PersistenceTimeout != nil &&
+			(remote.PersistenceTimeout == nil || *local.PersistenceTimeout != *remote.PersistenceTimeout) {
+			needUpdate = true
+			update.PersistenceTimeout = local.PersistenceTimeout
+			updateDetail += fmt.Sprintf("PersistenceTimeout should be changed to %v;", *local.PersistenceTimeout)
+		}
+		if local.EstablishedTimeout != 0 && local.EstablishedTimeout != remote.EstablishedTimeout {
+			needUpdate = true
+			update.EstablishedTimeout = local.EstablishedTimeout
+			updateDetail += fmt.Sprintf("EstablishedTimeout %v should be changed to %v;",
+				remote.EstablishedTimeout, local.EstablishedTimeout)
+		}
+	case model.HTTP, model.HTTPS:
+		if local.HealthCheck != "" && local.HealthCheck != remote.HealthCheck {
+			needUpdate = true
+			update.HealthCheck = local.HealthCheck
+			updateDetail += fmt.Sprintf("HealthCheck %v should be changed to %v;", remote.HealthCheck, local.HealthCheck)
+		}
+		if local.HealthCheckTimeout != 0 && local.HealthCheckTimeout != remote.HealthCheckTimeout {
+			needUpdate = true
+			update.HealthCheckTimeout = local.HealthCheckTimeout
+			updateDetail += fmt.Sprintf("HealthCheckTimeout %v should be changed to %v;",
+				remote.HealthCheckTimeout, local.HealthCheckTimeout)
+		}
+		if local.HealthCheckMethod != "" && !strings.EqualFold(local.HealthCheckMethod, remote.HealthCheckMethod) {
+			needUpdate = true
+			update.HealthCheckMethod = local.HealthCheckMethod
+			updateDetail += fmt.Sprintf("HealthCheckMethod %v should be changed to %v;",
+				remote.HealthCheckMethod, local.HealthCheckMethod)
+		}
+		if local.StickySession != "" && local.StickySession != remote.StickySession {
+			needUpdate = true
+			update.StickySession = local.StickySession
+			updateDetail += fmt.Sprintf("StickySession %v should be changed to %v;",
+				remote.StickySession, local.StickySession)
+		}
+		if local.StickySessionType != "" && local.StickySessionType != remote.StickySessionType {
+			needUpdate = true
+			update.StickySessionType = local.StickySessionType
+			updateDetail += fmt.Sprintf("StickySessionType %v should be changed to %v;",
+				remote.StickySessionType, local.StickySessionType)
+		}
+		if local.Cookie != "" && local.Cookie != remote.Cookie {
+			needUpdate = true
+			update.Cookie = local.Cookie
+			updateDetail += fmt.Sprintf("Cookie %v should be changed to %v;", remote.Cookie, local.Cookie)
+		}
+		if local.CookieTimeout != 0 && local.CookieTimeout != remote.CookieTimeout {
+			needUpdate = true
+			update.CookieTimeout = local.CookieTimeout
+			updateDetail += fmt.Sprintf("CookieTimeout %v should be changed to %v;",
+				remote.CookieTimeout, local.CookieTimeout)
+		}
+		if local.XForwardedForProto != "" && local.XForwardedForProto != remote.XForwardedForProto {
+			needUpdate = true
+			update.XForwardedForProto = local.XForwardedForProto
+			updateDetail += fmt.Sprintf("XForwardedForProto %v should be changed to %v;",
+				remote.XForwardedForProto, local.XForwardedForProto)
+		}
+		if local.IdleTimeout != 0 && local.IdleTimeout != remote.IdleTimeout {
+			needUpdate = true
+			update.IdleTimeout = local.IdleTimeout
+			updateDetail += fmt.Sprintf("IdleTimeout %v should be changed to %v;", remote.IdleTimeout, local.IdleTimeout)
+		}
+		if local.RequestTimeout != 0 && local.RequestTimeout != remote.RequestTimeout {
+			needUpdate = true
+			update.RequestTimeout = local.RequestTimeout
+			updateDetail += fmt.Sprintf("RequestTimeout %v should be changed to %v;",
+				remote.RequestTimeout, local.RequestTimeout)
+		}
+	}
+
+	if local.Protocol == model.HTTPS {
+		// the certificate id is always sent by SetLoadBalancerHTTPSListenerAttribute
+		update.CertId = remote.CertId
+		if local.CertId != "" && local.CertId != remote.CertId {
+			needUpdate = true
+			update.CertId = local.CertId
+			updateDetail += fmt.Sprintf("CertId %v should be changed to %v;", remote.CertId, local.CertId)
+		}
+		if local.EnableHttp2 != "" && local.EnableHttp2 != remote.EnableHttp2 {
+			needUpdate = true
+			update.EnableHttp2 = local.EnableHttp2
+			updateDetail += fmt.Sprintf("EnableHttp2 %v should be changed to %v;", remote.EnableHttp2, local.EnableHttp2)
+		}
+		if local.TLSCipherPolicy != "" && local.TLSCipherPolicy != remote.TLSCipherPolicy {
+			needUpdate = true
+			update.TLSCipherPolicy = local.TLSCipherPolicy
+			updateDetail += fmt.Sprintf("TLSCipherPolicy %v should be changed to %v;",
+				remote.TLSCipherPolicy, local.TLSCipherPolicy)
+		}
+	}
+
+	return update, needUpdate, updateDetail
+}
+
+func setListenerFromAnnotation(reqCtx *svcCtx.RequestContext, listener *model.ListenerAttribute) error {
+	anno := reqCtx.Anno
+	var err error
+
+	listener.Scheduler = anno.Get(annotation.Scheduler)
+	// the bandwidth of the loadbalancer is shared by all listeners
+	listener.Bandwidth = DefaultListenerBandwidth
+
+	listener.AclStatus = model.FlagType(anno.Get(annotation.AclStatus))
+	listener.AclId = anno.Get(annotation.AclID)
+	listener.AclType = anno.Get(annotation.AclType)
+
+	listener.ConnectionDrain = model.FlagType(anno.Get(annotation.ConnectionDrain))
+	if listener.ConnectionDrainTimeout, err = atoiAnnotation(anno, annotation.ConnectionDrainTimeout); err != nil {
+		return err
+	}
+
+	// health check
+	listener.HealthCheck = model.FlagType(anno.Get(annotation.HealthCheckFlag))
+	listener.HealthCheckType = anno.Get(annotation.HealthCheckType)
+	listener.HealthCheckURI = anno.Get(annotation.HealthCheckURI)
+	listener.HealthCheckDomain = anno.Get(annotation.HealthCheckDomain)
+	listener.HealthCheckHttpCode = anno.Get(annotation.HealthCheckHTTPCode)
+	listener.HealthCheckMethod = anno.Get(annotation.HealthCheckMethod)
+	if listener.HealthCheckConnectPort, err = atoiAnnotation(anno, annotation.HealthCheckConnectPort); err != nil {
+		return err
+	}
+	if listener.HealthyThreshold, err = atoiAnnotation(anno, annotation.HealthyThreshold); err != nil {
+		return err
+	}
+	if listener.UnhealthyThreshold, err = atoiAnnotation(anno, annotation.UnhealthyThreshold); err != nil {
+		return err
+	}
+	if listener.HealthCheckInterval, err = atoiAnnotation(anno, annotation.HealthCheckInterval); err != nil {
+		return err
+	}
+	if listener.HealthCheckConnectTimeout, err = atoiAnnotation(anno, annotation.HealthCheckConnectTimeout); err != nil {
+		return err
+	}
+	if listener.HealthCheckTimeout, err = atoiAnnotation(anno, annotation.HealthCheckTimeout); err != nil {
+		return err
+	}
+
+	// layer 4
+	if anno.Get(annotation.PersistenceTimeout) != "" {
+		timeout, err := strconv.Atoi(anno.Get(annotation.PersistenceTimeout))
+		if err != nil {
+			return fmt.Errorf("PersistenceTimeout parse error: %s", err.Error())
+		}
+		listener.PersistenceTimeout = &timeout
+	}
+	if listener.EstablishedTimeout, err = atoiAnnotation(anno, annotation.EstablishedTimeout); err != nil {
+		return err
+	}
+
+	// layer 7
+	listener.StickySession = model.FlagType(anno.Get(annotation.SessionStick))
+	listener.StickySessionType = anno.Get(annotation.SessionStickType)
+	listener.Cookie = anno.Get(annotation.Cookie)
+	if listener.CookieTimeout, err = atoiAnnotation(anno, annotation.CookieTimeout); err != nil {
+		return err
+	}
+	listener.XForwardedForProto = model.FlagType(anno.Get(annotation.XForwardedForProto))
+	if listener.IdleTimeout, err = atoiAnnotation(anno, annotation.IdleTimeout); err != nil {
+		return err
+	}
+	if listener.RequestTimeout, err = atoiAnnotation(anno, annotation.RequestTimeout); err != nil {
+		return err
+	}
+
+	if listener.Protocol == model.HTTPS {
+		listener.CertId = anno.Get(annotation.CertID)
+		if listener.CertId == "" {
+			return fmt.Errorf("cert id is required for https listener %d", listener.ListenerPort)
+		}
+		listener.EnableHttp2 = model.FlagType(anno.Get(annotation.EnableHttp2))
+		listener.TLSCipherPolicy = anno.Get(annotation.TLSCipherPolicy)
+	}
+
+	// http listener redirects requests to the https forward port
+	if listener.Protocol == model.HTTP && anno.Get(annotation.ForwardPort) != "" {
+		forwardPort, err := forwardPort(anno.Get(annotation.ForwardPort), listener.ListenerPort)
+		if err != nil {
+			return err
+		}
+		if forwardPort != 0 {
+			listener.ListenerForward = model.OnFlag
+			listener.ForwardPort = forwardPort
+		}
+	}
+	return nil
+}
+
+func atoiAnnotation(anno *annotation.AnnotationRequest, key string) (int, error) {
+	if anno.Get(key) == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(anno.Get(key))
+	if err != nil {
+		return 0, fmt.Errorf("%s parse error: %s", key, err.Error())
+	}
+	return v, nil
+}
+
+// forwardPort parses the forward port annotation like "80:443,8080:8443"
+func forwardPort(port string, target int) (int, error) {
+	for _, v := range strings.Split(port, ",") {
+		pp := strings.Split(v, ":")
+		if len(pp) != 2 {
+			return 0, fmt.Errorf("forward-port format error: %s, expect 80:443,88:6443", port)
+		}
+		src, err := strconv.Atoi(pp[0])
+		if err != nil {
+			return 0, fmt.Errorf("forward-port format error: %s", err.Error())
+		}
+		dst, err := strconv.Atoi(pp[1])
+		if err != nil {
+			return 0, fmt.Errorf("forward-port format error: %s", err.Error())
+		}
+		if src == target {
+			return dst, nil
+		}
+	}
+	return 0, nil
+}
+
+func clbListenerProtocol(annotation string, port v1.ServicePort) (string, error) {
+	if annotation == "" {
+		return strings.ToLower(string(port.Protocol)), nil
+	}
+	for _, v := range strings.Split(annotation, ",") {
+		pp := strings.Split(v, ":")
+		if len(pp) < 2 {
+			return "", fmt.Errorf("port and "+
+				"protocol format must be like 'https:443' with colon separated. got=[%+v]", pp)
+		}
+
+		proto := strings.ToLower(pp[0])
+		if proto != model.HTTP && proto != model.HTTPS && proto != model.TCP && proto != model.UDP {
+			return "", fmt.Errorf("port protocol"+
+				" format must be either [http|https|tcp|udp], protocol not supported wit [%s]\n", pp[0])
+		}
+
+		if pp[1] == fmt.Sprintf("%d", port.Port) {
+			util.ServiceLog.Info(fmt.Sprintf("port [%d] transform protocol from %s to %s", port.Port, port.Protocol, proto))
+			return proto, nil
+		}
+	}
+	return strings.ToLower(string(port.Protocol)), nil
+}
@@ -0,0 +1,275 @@
+package clb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/tag"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+)
+
+func NewLoadBalancerManager(cloud prvd.Provider) *LoadBalancerManager {
+	return &LoadBalancerManager{
+		cloud: cloud,
+	}
+}
+
+type LoadBalancerManager struct {
+	cloud prvd.Provider
+}
+
+func (mgr *LoadBalancerManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	if reqCtx.Anno.Get(annotation.LoadBalancerId) != "" {
+		mdl.LoadBalancerAttribute.LoadBalancerId = reqCtx.Anno.Get(annotation.LoadBalancerId)
+		mdl.LoadBalancerAttribute.IsUserManaged = true
+	}
+
+	mdl.LoadBalancerAttribute.AddressType = model.AddressType(reqCtx.Anno.Get(annotation.AddressType))
+	mdl.LoadBalancerAttribute.InternetChargeType = model.InternetChargeType(reqCtx.Anno.Get(annotation.ChargeType))
+	mdl.LoadBalancerAttribute.InstanceChargeType = model.InstanceChargeType(reqCtx.Anno.Get(annotation.InstanceChargeType))
+	if reqCtx.Anno.Get(annotation.Bandwidth) != "" {
+		bandwidth, err := strconv.Atoi(reqCtx.Anno.Get(annotation.Bandwidth))
+		if err != nil {
+			return fmt.Errorf("Bandwidth parse error: %s", err.Error())
+		}
+		mdl.LoadBalancerAttribute.Bandwidth = bandwidth
+	}
+	mdl.LoadBalancerAttribute.LoadBalancerSpec = model.LoadBalancerSpecType(reqCtx.Anno.Get(annotation.Spec))
+	mdl.LoadBalancerAttribute.VSwitchId = reqCtx.Anno.Get(annotation.VswitchId)
+	mdl.LoadBalancerAttribute.MasterZoneId = reqCtx.Anno.Get(annotation.MasterZoneID)
+	mdl.LoadBalancerAttribute.SlaveZoneId = reqCtx.Anno.Get(annotation.SlaveZoneID)
+	mdl.LoadBalancerAttribute.LoadBalancerName = reqCtx.Anno.Get(annotation.LoadBalancerName)
+	mdl.LoadBalancerAttribute.AddressIPVersion = model.AddressIPVersionType(reqCtx.Anno.Get(annotation.IPVersion))
+	mdl.LoadBalancerAttribute.ResourceGroupId = reqCtx.Anno.Get(annotation.ResourceGroupId)
+	mdl.LoadBalancerAttribute.DeleteProtection = model.FlagType(reqCtx.Anno.Get(annotation.DeleteProtection))
+	mdl.LoadBalancerAttribute.ModificationProtectionStatus =
+		model.ModificationProtectionType(reqCtx.Anno.Get(annotation.ModificationProtection))
+	mdl.LoadBalancerAttribute.Tags = reqCtx.Anno.GetLoadBalancerAdditionalTags()
+	return nil
+}
+
+func (mgr *LoadBalancerManager) BuildRemoteModel(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	return mgr.Find(reqCtx, mdl)
+}
+
+func (mgr *LoadBalancerManager) Find(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	// 1. set loadbalancer id
+	if reqCtx.Anno.Get(annotation.LoadBalancerId) != "" {
+		mdl.LoadBalancerAttribute.LoadBalancerId = reqCtx.Anno.Get(annotation.LoadBalancerId)
+	}
+
+	// 2. set default loadbalancer name
+	// it's safe to set loadbalancer name which will be overwritten in FindLoadBalancer func
+	mdl.LoadBalancerAttribute.LoadBalancerName = reqCtx.Anno.GetDefaultLoadBalancerName()
+
+	// 3. set default loadbalancer tag
+	// filter tags using logic operator OR, so only TAGKEY tag can be added
+	mdl.LoadBalancerAttribute.Tags = []tag.Tag{
+		{
+			Key:   helper.TAGKEY,
+			Value: reqCtx.Anno.GetDefaultLoadBalancerName(),
+		},
+	}
+
+	return mgr.cloud.FindLoadBalancer(reqCtx.Ctx, mdl)
+}
+
+func (mgr *LoadBalancerManager) Create(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	if err := setDefaultValueForLoadBalancer(mgr, mdl, reqCtx.Anno); err != nil {
+		return fmt.Errorf("set model default value error: %s", err.Error())
+	}
+
+	if err := mgr.cloud.CreateLoadBalancer(reqCtx.Ctx, mdl); err != nil {
+		return err
+	}
+
+	return mgr.cloud.TagCLBResource(reqCtx.Ctx, mdl.LoadBalancerAttribute.LoadBalancerId,
+		mdl.LoadBalancerAttribute.Tags)
+}
+
+func (mgr *LoadBalancerManager) Delete(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	if mdl.LoadBalancerAttribute.LoadBalancerId == "" {
+		return nil
+	}
+
+	// the loadbalancer is created by the controller, so it is safe to turn off the protection before deleting it
+	if mdl.LoadBalancerAttribute.DeleteProtection == model.OnFlag {
+		if err := mgr.cloud.SetLoadBalancerDeleteProtection(reqCtx.Ctx,
+			mdl.LoadBalancerAttribute.LoadBalancerId, string(model.OffFlag)); err != nil {
+			return fmt.Errorf("turn off delete protection error: %w", err)
+		}
+	}
+
+	return mgr.cloud.DeleteLoadBalancer(reqCtx.Ctx, mdl)
+}
+
+func (mgr *LoadBalancerManager) Update(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) error {
+	lbId := remote.LoadBalancerAttribute.LoadBalancerId
+	local.LoadBalancerAttribute.LoadBalancerId = lbId
+
+	// immutable attributes
+	if local.LoadBalancerAttribute.AddressType != "" &&
+		!strings.EqualFold(string(local.LoadBalancerAttribute.AddressType),
+			string(remote.LoadBalancerAttribute.AddressType)) {
+		return fmt.Errorf("AddressType cannot be changed, service: %s, slb: %s",
+			local.LoadBalancerAttribute.AddressType, remote.LoadBalancerAttribute.AddressType)
+	}
+	if local.LoadBalancerAttribute.VSwitchId != "" &&
+		local.LoadBalancerAttribute.VSwitchId != remote.LoadBalancerAttribute.VSwitchId {
+		return fmt.Errorf("VSwitchId cannot be changed, service: %s, slb: %s",
+			local.LoadBalancerAttribute.VSwitchId, remote.LoadBalancerAttribute.VSwitchId)
+	}
+	if local.LoadBalancerAttribute.AddressIPVersion != "" &&
+		!strings.EqualFold(string(local.LoadBalancerAttribute.AddressIPVersion),
+			string(remote.LoadBalancerAttribute.AddressIPVersion)) {
+		return fmt.Errorf("AddressIPVersion cannot be changed, service: %s, slb: %s",
+			local.LoadBalancerAttribute.AddressIPVersion, remote.LoadBalancerAttribute.AddressIPVersion)
+	}
+	if local.LoadBalancerAttribute.ResourceGroupId != "" &&
+		local.LoadBalancerAttribute.ResourceGroupId != remote.LoadBalancerAttribute.ResourceGroupId {
+		return fmt.Errorf("ResourceGroupId cannot be changed, service: %s, slb: %s",
+			local.LoadBalancerAttribute.ResourceGroupId, remote.LoadBalancerAttribute.ResourceGroupId)
+	}
+
+	// mutable attributes
+	if local.LoadBalancerAttribute.DeleteProtection != "" &&
+		local.LoadBalancerAttribute.DeleteProtection != remote.LoadBalancerAttribute.DeleteProtection {
+		reqCtx.Log.Info(fmt.Sprintf("DeleteProtection changed from [%s] to [%s]",
+			remote.LoadBalancerAttribute.DeleteProtection, local.LoadBalancerAttribute.DeleteProtection))
+		if err := mgr.cloud.SetLoadBalancerDeleteProtection(reqCtx.Ctx, lbId,
+			string(local.LoadBalancerAttribute.DeleteProtection)); err != nil {
+			return fmt.Errorf("SetLoadBalancerDeleteProtection error: %s", err.Error())
+		}
+	}
+
+	if local.LoadBalancerAttribute.ModificationProtectionStatus != "" &&
+		local.LoadBalancerAttribute.ModificationProtectionStatus != remote.LoadBalancerAttribute.ModificationProtectionStatus {
+		reqCtx.Log.Info(fmt.Sprintf("ModificationProtectionStatus changed from [%s] to [%s]",
+			remote.LoadBalancerAttribute.ModificationProtectionStatus,
+			local.LoadBalancerAttribute.ModificationProtectionStatus))
+		flag := model.OffFlag
+		if local.LoadBalancerAttribute.ModificationProtectionStatus == model.ConsoleProtection {
+			flag = model.OnFlag
+		}
+		if err := mgr.cloud.SetLoadBalancerModificationProtection(reqCtx.Ctx, lbId, string(flag)); err != nil {
+			return fmt.Errorf("SetLoadBalancerModificationProtection error: %s", err.Error())
+		}
+	}
+
+	if local.LoadBalancerAttribute.InstanceChargeType != "" &&
+		!strings.EqualFold(string(local.LoadBalancerAttribute.InstanceChargeType),
+			string(remote.LoadBalancerAttribute.InstanceChargeType)) {
+		reqCtx.Log.Info(fmt.Sprintf("InstanceChargeType changed from [%s] to [%s]",
+			remote.LoadBalancerAttribute.InstanceChargeType, local.LoadBalancerAttribute.InstanceChargeType))
+		spec := local.LoadBalancerAttribute.LoadBalancerSpec
+		if local.LoadBalancerAttribute.InstanceChargeType.IsPayByCLCU() {
+			spec = ""
+		} else if spec == "" {
+			spec = model.LoadBalancerSpecType(reqCtx.Anno.GetDefaultValue(annotation.Spec))
+		}
+		if err := mgr.cloud.ModifyLoadBalancerInstanceChargeType(reqCtx.Ctx, lbId,
+			string(local.LoadBalancerAttribute.InstanceChargeType), string(spec)); err != nil {
+			return fmt.Errorf("ModifyLoadBalancerInstanceChargeType error: %s", err.Error())
+		}
+		remote.LoadBalancerAttribute.InstanceChargeType = local.LoadBalancerAttribute.InstanceChargeType
+		remote.LoadBalancerAttribute.LoadBalancerSpec = spec
+	}
+
+	// spec is only available for PayBySpec loadbalancers
+	if remote.LoadBalancerAttribute.InstanceChargeType.IsPayBySpec() &&
+		local.LoadBalancerAttribute.LoadBalancerSpec != "" &&
+		local.LoadBalancerAttribute.LoadBalancerSpec != remote.LoadBalancerAttribute.LoadBalancerSpec {
+		reqCtx.Log.Info(fmt.Sprintf("LoadBalancerSpec changed from [%s] to [%s]",
+			remote.LoadBalancerAttribute.LoadBalancerSpec, local.LoadBalancerAttribute.LoadBalancerSpec))
+		if err := mgr.cloud.ModifyLoadBalancerInstanceSpec(reqCtx.Ctx, lbId,
+			string(local.LoadBalancerAttribute.LoadBalancerSpec)); err != nil {
+			return fmt.Errorf("ModifyLoadBalancerInstanceSpec error: %s", err.Error())
+		}
+	}
+
+	// internet spec can only be changed for internet loadbalancers
+	if remote.LoadBalancerAttribute.AddressType == model.InternetAddressType {
+		chargeType := remote.LoadBalancerAttribute.InternetChargeType
+		bandwidth := remote.LoadBalancerAttribute.Bandwidth
+		needUpdate := false
+		if local.LoadBalancerAttribute.InternetChargeType != "" &&
+			!strings.EqualFold(string(local.LoadBalancerAttribute.InternetChargeType), string(chargeType)) {
+			needUpdate = true
+			chargeType = local.LoadBalancerAttribute.InternetChargeType
+		}
+		if local.LoadBalancerAttribute.Bandwidth != 0 &&
+			local.LoadBalancerAttribute.Bandwidth != bandwidth &&
+			strings.EqualFold(string(chargeType), string(model.PayByBandwidth)) {
+			needUpdate = true
+			bandwidth = local.LoadBalancerAttribute.Bandwidth
+		}
+		if needUpdate {
+			reqCtx.Log.Info(fmt.Sprintf("internet spec changed from [%s, %d] to [%s, %d]",
+				remote.LoadBalancerAttribute.InternetChargeType, remote.LoadBalancerAttribute.Bandwidth,
+				chargeType, bandwidth))
+			if err := mgr.cloud.ModifyLoadBalancerInternetSpec(reqCtx.Ctx, lbId, string(chargeType), bandwidth); err != nil {
+				return fmt.Errorf("ModifyLoadBalancerInternetSpec error: %s", err.Error())
+			}
+		}
+	}
+
+	if local.LoadBalancerAttribute.LoadBalancerName != "" &&
+		local.LoadBalancerAttribute.LoadBalancerName != remote.LoadBalancerAttribute.LoadBalancerName {
+		reqCtx.Log.Info(fmt.Sprintf("LoadBalancerName changed from [%s] to [%s]",
+			remote.LoadBalancerAttribute.LoadBalancerName, local.LoadBalancerAttribute.LoadBalancerName))
+		if err := mgr.cloud.SetLoadBalancerName(reqCtx.Ctx, lbId, local.LoadBalancerAttribute.LoadBalancerName); err != nil {
+			return fmt.Errorf("SetLoadBalancerName error: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func setDefaultValueForLoadBalancer(mgr *LoadBalancerManager, mdl *model.LoadBalancer, anno *annotation.AnnotationRequest,
+) error {
+	if mdl.LoadBalancerAttribute.AddressType == "" {
+		mdl.LoadBalancerAttribute.AddressType = model.AddressType(anno.GetDefaultValue(annotation.AddressType))
+	}
+
+	if mdl.LoadBalancerAttribute.LoadBalancerName == "" {
+		mdl.LoadBalancerAttribute.LoadBalancerName = anno.GetDefaultLoadBalancerName()
+	}
+
+	if mdl.LoadBalancerAttribute.InstanceChargeType.IsPayBySpec() &&
+		mdl.LoadBalancerAttribute.LoadBalancerSpec == "" {
+		mdl.LoadBalancerAttribute.LoadBalancerSpec = model.LoadBalancerSpecType(anno.GetDefaultValue(annotation.Spec))
+	}
+
+	if mdl.LoadBalancerAttribute.AddressIPVersion == "" {
+		mdl.LoadBalancerAttribute.AddressIPVersion = model.AddressIPVersionType(anno.GetDefaultValue(annotation.IPVersion))
+	}
+
+	if mdl.LoadBalancerAttribute.DeleteProtection == "" {
+		mdl.LoadBalancerAttribute.DeleteProtection = model.FlagType(anno.GetDefaultValue(annotation.DeleteProtection))
+	}
+
+	if mdl.LoadBalancerAttribute.ModificationProtectionStatus == "" {
+		mdl.LoadBalancerAttribute.ModificationProtectionStatus =
+			model.ModificationProtectionType(anno.GetDefaultValue(annotation.ModificationProtection))
+	}
+	if mdl.LoadBalancerAttribute.ModificationProtectionStatus == model.ConsoleProtection {
+		mdl.LoadBalancerAttribute.ModificationProtectionReason = model.ModificationProtectionReason
+	}
+
+	// intranet loadbalancers are created in the vpc of the cluster
+	if mdl.LoadBalancerAttribute.AddressType == model.IntranetAddressType && mdl.LoadBalancerAttribute.VpcId == "" {
+		vpcId, err := mgr.cloud.VpcID()
+		if err != nil {
+			return fmt.Errorf("get vpc id error: %s", err.Error())
+		}
+		mdl.LoadBalancerAttribute.VpcId = vpcId
+	}
+
+	mdl.LoadBalancerAttribute.Tags = append(anno.GetDefaultTags(), mdl.LoadBalancerAttribute.Tags...)
+	return nil
+}
@@ -0,0 +1,354 @@
+package clb
+
+import (
+	"context"
+	"fmt"
+
+	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/tag"
+	"k8s.io/alibaba-load-balancer-controller/pkg/provider/alibaba/base"
+	"k8s.io/alibaba-load-balancer-controller/pkg/provider/dryrun"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+func NewModelApplier(slbMgr *LoadBalancerManager, lisMgr *ListenerManager, vGroupMgr *VGroupManager) *ModelApplier {
+	return &ModelApplier{
+		slbMgr:    slbMgr,
+		lisMgr:    lisMgr,
+		vGroupMgr: vGroupMgr,
+	}
+}
+
+type ModelApplier struct {
+	slbMgr    *LoadBalancerManager
+	lisMgr    *ListenerManager
+	vGroupMgr *VGroupManager
+}
+
+func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *model.LoadBalancer) (*model.LoadBalancer, error) {
+	remote := &model.LoadBalancer{
+		NamespacedName: util.NamespacedName(reqCtx.Service),
+	}
+
+	err := m.slbMgr.BuildRemoteModel(reqCtx, remote)
+	if err != nil {
+		return remote, fmt.Errorf("get slb attribute from cloud error: %w", err)
+	}
+	reqCtx.Ctx = context.WithValue(reqCtx.Ctx, dryrun.ContextSLB, remote.LoadBalancerAttribute.LoadBalancerId)
+
+	serviceHashChanged := helper.IsServiceHashChanged(reqCtx.Service)
+	if serviceHashChanged || ctrlCfg.ControllerCFG.DryRun {
+		if err := m.applyLoadBalancerAttribute(reqCtx, local, remote); err != nil {
+			return remote, fmt.Errorf("reconcile slb attribute error: %w", err)
+		}
+	}
+
+	if remote.LoadBalancerAttribute.LoadBalancerId == "" {
+		if !helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+			return remote, fmt.Errorf("alicloud: can not find loadbalancer by tag [%s:%s]",
+				helper.TAGKEY, reqCtx.Anno.GetDefaultLoadBalancerName())
+		}
+		return remote, nil
+	}
+
+	if err := m.vGroupMgr.BuildRemoteModel(reqCtx, remote); err != nil {
+		return remote, fmt.Errorf("get vgroups from cloud error: %s", err.Error())
+	}
+	if err := m.applyVGroups(reqCtx, local, remote); err != nil {
+		return remote, fmt.Errorf("reconcile backends error: %s", err.Error())
+	}
+
+	if serviceHashChanged || ctrlCfg.ControllerCFG.DryRun {
+		if err := m.lisMgr.BuildRemoteModel(reqCtx, remote); err != nil {
+			return remote, fmt.Errorf("get lb listeners from cloud, error: %s", err.Error())
+		}
+		if err := m.applyListeners(reqCtx, local, remote); err != nil {
+			return remote, fmt.Errorf("reconcile listeners error: %s", err.Error())
+		}
+	}
+
+	if err := m.cleanup(reqCtx, local, remote); err != nil {
+		return remote, fmt.Errorf("cleanup vgroups error: %s", err.Error())
+	}
+
+	return remote, nil
+}
+
+func (m *ModelApplier) applyLoadBalancerAttribute(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) error {
+	if local == nil || remote == nil {
+		return fmt.Errorf("local or remote mdl is nil")
+	}
+
+	if local.NamespacedName.String() != remote.NamespacedName.String() {
+		return fmt.Errorf("models for different svc, local [%s], remote [%s]",
+			local.NamespacedName, remote.NamespacedName)
+	}
+
+	// delete slb
+	if helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+		if remote.LoadBalancerAttribute.LoadBalancerId == "" {
+			return nil
+		}
+		if !local.LoadBalancerAttribute.IsUserManaged {
+			err := m.slbMgr.Delete(reqCtx, remote)
+			if err != nil {
+				return fmt.Errorf("delete slb [%s] error: %w",
+					remote.LoadBalancerAttribute.LoadBalancerId, err)
+			}
+			reqCtx.Log.Info(fmt.Sprintf("successfully delete slb %s", remote.LoadBalancerAttribute.LoadBalancerId))
+			remote.LoadBalancerAttribute.LoadBalancerId = ""
+			remote.LoadBalancerAttribute.Address = ""
+			return nil
+		}
+		reqCtx.Log.Info(fmt.Sprintf("slb %s is reused, skip delete it", remote.LoadBalancerAttribute.LoadBalancerId))
+		return nil
+	}
+
+	// create slb
+	if remote.LoadBalancerAttribute.LoadBalancerId == "" {
+		if local.LoadBalancerAttribute.IsUserManaged {
+			return fmt.Errorf("alicloud: can not find loadbalancer by id [%s]",
+				local.LoadBalancerAttribute.LoadBalancerId)
+		}
+		if helper.IsServiceOwnIngress(reqCtx.Service) {
+			return fmt.Errorf("alicloud: can not find loadbalancer, but it's defined in service [%v] "+
+				"this may happen when you delete the loadbalancer", reqCtx.Service.Status.LoadBalancer.Ingress[0].IP)
+		}
+
+		if err := m.slbMgr.Create(reqCtx, local); err != nil {
+			return fmt.Errorf("create slb error: %s", err.Error())
+		}
+		reqCtx.Log.Info(fmt.Sprintf("successfully create lb %s", local.LoadBalancerAttribute.LoadBalancerId))
+		// update remote model
+		remote.LoadBalancerAttribute.LoadBalancerId = local.LoadBalancerAttribute.LoadBalancerId
+		if err := m.slbMgr.Find(reqCtx, remote); err != nil {
+			return fmt.Errorf("update remote model for lbId %s, error: %s",
+				remote.LoadBalancerAttribute.LoadBalancerId, err.Error())
+		}
+		return nil
+	}
+
+	tags, err := m.slbMgr.cloud.ListCLBTagResources(reqCtx.Ctx, remote.LoadBalancerAttribute.LoadBalancerId)
+	if err != nil {
+		return fmt.Errorf("ListCLBTagResources: %s", err.Error())
+	}
+	remote.LoadBalancerAttribute.Tags = tags
+
+	// check whether slb can be reused
+	if local.LoadBalancerAttribute.IsUserManaged {
+		if ok, reason := isSLBReusable(reqCtx.Service, tags, remote.LoadBalancerAttribute.Address); !ok {
+			return fmt.Errorf("the loadbalancer %s can not be reused, %s",
+				remote.LoadBalancerAttribute.LoadBalancerId, reason)
+		}
+	}
+
+	return m.slbMgr.Update(reqCtx, local, remote)
+}
+
+func (m *ModelApplier) applyVGroups(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) error {
+	for i := range local.VServerGroups {
+		found := false
+		var old model.VServerGroup
+		for _, rv := range remote.VServerGroups {
+			// for reuse vgroup case, find by vgroup id first
+			if local.VServerGroups[i].VGroupId != "" &&
+				local.VServerGroups[i].VGroupId == rv.VGroupId {
+				found = true
+				old = rv
+				break
+			}
+			// find by vgroup name
+			if local.VServerGroups[i].VGroupId == "" &&
+				local.VServerGroups[i].VGroupName == rv.VGroupName {
+				found = true
+				local.VServerGroups[i].VGroupId = rv.VGroupId
+				old = rv
+				break
+			}
+		}
+
+		// update
+		if found {
+			if err := m.vGroupMgr.UpdateVServerGroup(reqCtx, local.VServerGroups[i], old); err != nil {
+				return fmt.Errorf("EnsureVGroupUpdated error: %s", err.Error())
+			}
+			continue
+		}
+
+		if local.VServerGroups[i].IsUserManaged {
+			return fmt.Errorf("can not find vgroup %s in slb %s", local.VServerGroups[i].VGroupId,
+				remote.LoadBalancerAttribute.LoadBalancerId)
+		}
+
+		// create
+		reqCtx.Log.Info(fmt.Sprintf("create vgroup %s", local.VServerGroups[i].VGroupName))
+		// to avoid add too many backends in one action, create vgroup with empty backends,
+		// then use AddVServerGroupBackendServers to add backends
+		if err := m.vGroupMgr.CreateVServerGroup(reqCtx, &local.VServerGroups[i],
+			remote.LoadBalancerAttribute.LoadBalancerId); err != nil {
+			return fmt.Errorf("EnsureVGroupCreated error: %s", err.Error())
+		}
+		if len(local.VServerGroups[i].Backends) > 0 {
+			if err := m.vGroupMgr.BatchAddVServerGroupBackendServers(reqCtx, local.VServerGroups[i],
+				local.VServerGroups[i].Backends); err != nil {
+				return err
+			}
+		}
+		remote.VServerGroups = append(remote.VServerGroups, local.VServerGroups[i])
+	}
+
+	return nil
+}
+
+func (m *ModelApplier) applyListeners(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) error {
+	// listeners of the service are always removed from a reused loadbalancer when the service is deleted
+	if local.LoadBalancerAttribute.IsUserManaged && !helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+		if !reqCtx.Anno.IsForceOverride() {
+			reqCtx.Log.Info("listener override is false, skip reconcile listeners")
+			return nil
+		}
+	}
+	lbId := remote.LoadBalancerAttribute.LoadBalancerId
+
+	// associate listener and vGroup
+	for i := range local.Listeners {
+		if err := findVServerGroup(local.VServerGroups, &local.Listeners[i]); err != nil {
+			return fmt.Errorf("find vservergroup error: %s", err.Error())
+		}
+	}
+
+	// delete
+	var kept []model.ListenerAttribute
+	for _, r := range remote.Listeners {
+		found := false
+		for _, l := range local.Listeners {
+			if r.ListenerPort == l.ListenerPort && r.Protocol == l.Protocol {
+				found = true
+				break
+			}
+		}
+
+		if found {
+			kept = append(kept, r)
+			continue
+		}
+
+		if !isListenerManagedByService(r, reqCtx.Service) {
+			// the port of a user managed listener can not be reused by the service
+			for _, l := range local.Listeners {
+				if r.ListenerPort == l.ListenerPort {
+					return fmt.Errorf("port %d is used by listener [%s] which is not managed by the service",
+						r.ListenerPort, r.Description)
+				}
+			}
+			reqCtx.Log.V(5).Info(fmt.Sprintf("listener %s [%d] is managed by user, skip delete",
+				r.Protocol, r.ListenerPort))
+			continue
+		}
+
+		reqCtx.Log.Info(fmt.Sprintf("delete listener: %s [%d]", r.Protocol, r.ListenerPort))
+		if err := m.lisMgr.DeleteListener(reqCtx, lbId, r.ListenerPort); err != nil {
+			return fmt.Errorf("EnsureListenerDeleted error: %s", err.Error())
+		}
+	}
+
+	for i := range local.Listeners {
+		found := false
+		for j := range kept {
+			if local.Listeners[i].ListenerPort == kept[j].ListenerPort {
+				found = true
+				if err := m.lisMgr.UpdateListener(reqCtx, lbId, local.Listeners[i], kept[j]); err != nil {
+					return fmt.Errorf("EnsureListenerUpdated error: %s", err.Error())
+				}
+			}
+		}
+
+		// create
+		if !found {
+			reqCtx.Log.Info(fmt.Sprintf("create listener: %s [%d]", local.Listeners[i].Protocol, local.Listeners[i].ListenerPort))
+			if err := m.lisMgr.CreateListener(reqCtx, lbId, local.Listeners[i]); err != nil {
+				return fmt.Errorf("EnsureListenerCreated error: %s", err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *ModelApplier) cleanup(reqCtx *svcCtx.RequestContext, local, remote *model.LoadBalancer) error {
+	// delete vgroups which are created by the service but not used any more
+	// vgroups in use by listeners can not be deleted, so this must be done after listeners are reconciled
+	for _, r := range remote.VServerGroups {
+		if r.IsUserManaged || !isVGroupManagedByService(r, reqCtx.Service) {
+			continue
+		}
+		found := false
+		for _, l := range local.VServerGroups {
+			if l.VGroupId == r.VGroupId {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			reqCtx.Log.Info(fmt.Sprintf("delete vgroup [%s], %s", r.VGroupName, r.VGroupId))
+			if err := m.vGroupMgr.DeleteVServerGroup(reqCtx, r.VGroupId); err != nil {
+				return fmt.Errorf("delete vgroup %s failed, error: %s", r.VGroupId, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func isSLBReusable(service *v1.Service, tags []tag.Tag, address string) (bool, string) {
+	for _, t := range tags {
+		// the tag of the apiserver slb is "ack.aliyun.com": "${clusterid}",
+		// so can not reuse slbs which have ack.aliyun.com tag key.
+		if t.Key == helper.TAGKEY || t.Key == util.ClusterTagKey {
+			return false, "can not reuse loadbalancer created by kubernetes."
+		}
+	}
+
+	if len(service.Status.LoadBalancer.Ingress) > 0 {
+		found := false
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP == address || ingress.Hostname != "" {
+				found = true
+			}
+		}
+		if !found {
+			return false, fmt.Sprintf("service has been associated with ip [%v], cannot be bound to ip [%s]",
+				service.Status.LoadBalancer.Ingress[0].IP, address)
+		}
+	}
+
+	return true, ""
+}
+
+func isListenerManagedByService(lis model.ListenerAttribute, svc *v1.Service) bool {
+	return lis.NamedKey != nil && !lis.IsUserManaged &&
+		lis.NamedKey.ServiceName == svc.Name &&
+		lis.NamedKey.Namespace == svc.Namespace &&
+		lis.NamedKey.CID == base.CLUSTER_ID
+}
+
+func isVGroupManagedByService(vg model.VServerGroup, svc *v1.Service) bool {
+	return vg.NamedKey != nil &&
+		vg.NamedKey.ServiceName == svc.Name &&
+		vg.NamedKey.Namespace == svc.Namespace &&
+		vg.NamedKey.CID == base.CLUSTER_ID
+}
+
+func findVServerGroup(vgs []model.VServerGroup, lis *model.ListenerAttribute) error {
+	for _, vg := range vgs {
+		if vg.VGroupName == lis.VGroupName {
+			lis.VGroupId = vg.VGroupId
+			return nil
+		}
+	}
+	return fmt.Errorf("can not find vgroup by name %s", lis.VGroupName)
+}
@@ -0,0 +1,177 @@
+package clb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/errors"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model/tag"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	"k8s.io/alibaba-load-balancer-controller/pkg/provider/alibaba/base"
+	prvdutil "k8s.io/alibaba-load-balancer-controller/pkg/provider/alibaba/util"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestIsSLBReusable(t *testing.T) {
+	svcWithIP := func(ip, hostname string) *v1.Service {
+		svc := &v1.Service{}
+		svc.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: ip, Hostname: hostname}}
+		return svc
+	}
+	cases := []struct {
+		name     string
+		svc      *v1.Service
+		tags     []tag.Tag
+		address  string
+		reusable bool
+	}{
+		{name: "new service", svc: &v1.Service{}, address: "1.1.1.1", reusable: true},
+		{name: "created by kubernetes", svc: &v1.Service{}, tags: []tag.Tag{{Key: helper.TAGKEY, Value: "x"}}, address: "1.1.1.1"},
+		{name: "apiserver slb", svc: &v1.Service{}, tags: []tag.Tag{{Key: util.ClusterTagKey, Value: "c1"}}, address: "1.1.1.1"},
+		{name: "same address", svc: svcWithIP("1.1.1.1", ""), address: "1.1.1.1", reusable: true},
+		{name: "other address", svc: svcWithIP("2.2.2.2", ""), address: "1.1.1.1"},
+		{name: "hostname status", svc: svcWithIP("", "lb.example.com"), address: "1.1.1.1", reusable: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reusable, reason := isSLBReusable(c.svc, c.tags, c.address)
+			assert.Equal(t, c.reusable, reusable)
+			assert.Equal(t, c.reusable, reason == "")
+		})
+	}
+}
+
+// notFoundCloud fails to find the load balancer with the given api error code
+type notFoundCloud struct {
+	prvd.Provider
+	code string
+}
+
+func (c *notFoundCloud) FindLoadBalancer(ctx context.Context, mdl *model.LoadBalancer) error {
+	return prvdutil.SDKError("DescribeLoadBalancerAttribute",
+		errors.NewServerError(404, `{"Code":"`+c.code+`","Message":"The specified resource is not found"}`, ""))
+}
+
+func TestApplyKeepsErrorCode(t *testing.T) {
+	svc := &v1.Service{}
+	svc.Namespace, svc.Name = "default", "svc"
+	reqCtx := &svcCtx.RequestContext{Ctx: context.TODO(), Service: svc, Anno: &annotation.AnnotationRequest{Service: svc}}
+
+	for code, notFound := range map[string]bool{
+		clbNotFoundErrorCode:        true,
+		"ResourceNotFound.listener": false,
+	} {
+		applier := NewModelApplier(NewLoadBalancerManager(&notFoundCloud{code: code}), nil, nil)
+		_, err := applier.Apply(reqCtx, &model.LoadBalancer{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "ErrorCode: "+code)
+		assert.Equal(t, notFound, prvdutil.IsErrorCode(err, clbNotFoundErrorCode), code)
+	}
+}
+
+func TestIsListenerManagedByService(t *testing.T) {
+	svc := &v1.Service{}
+	svc.Namespace = "default"
+	svc.Name = "nginx"
+	key := func(ns, name, cid string) *model.ListenerNamedKey {
+		return &model.ListenerNamedKey{Prefix: model.DEFAULT_PREFIX, CID: cid, Namespace: ns, ServiceName: name, Port: 80}
+	}
+	cases := []struct {
+		name    string
+		lis     model.ListenerAttribute
+		managed bool
+	}{
+		{name: "managed", lis: model.ListenerAttribute{NamedKey: key("default", "nginx", base.CLUSTER_ID)}, managed: true},
+		{name: "no named key", lis: model.ListenerAttribute{}},
+		{name: "user managed", lis: model.ListenerAttribute{NamedKey: key("default", "nginx", base.CLUSTER_ID), IsUserManaged: true}},
+		{name: "other service", lis: model.ListenerAttribute{NamedKey: key("default", "other", base.CLUSTER_ID)}},
+		{name: "other namespace", lis: model.ListenerAttribute{NamedKey: key("kube-system", "nginx", base.CLUSTER_ID)}},
+		{name: "other cluster", lis: model.ListenerAttribute{NamedKey: key("default", "nginx", base.CLUSTER_ID+"-other")}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.managed, isListenerManagedByService(c.lis, svc))
+		})
+	}
+}
+
+func TestFindVServerGroup(t *testing.T) {
+	vgs := []model.VServerGroup{
+		{VGroupName: "k8s/80/nginx/default/c1", VGroupId: "rsp-1"},
+		{VGroupName: "k8s/443/nginx/default/c1", VGroupId: "rsp-2"},
+	}
+	lis := &model.ListenerAttribute{VGroupName: "k8s/443/nginx/default/c1"}
+	assert.Nil(t, findVServerGroup(vgs, lis))
+	assert.Equal(t, "rsp-2", lis.VGroupId)
+
+	lis = &model.ListenerAttribute{VGroupName: "k8s/8080/nginx/default/c1"}
+	assert.NotNil(t, findVServerGroup(vgs, lis))
+}
+
+func TestDiffListener(t *testing.T) {
+	remote := model.ListenerAttribute{
+		ListenerPort: 80,
+		Protocol:     model.HTTP,
+		Description:  "k8s/80/nginx/default/c1",
+		VGroupId:     "rsp-1",
+		Scheduler:    "wrr",
+		Bandwidth:    -1,
+	}
+	cases := []struct {
+		name       string
+		local      model.ListenerAttribute
+		needUpdate bool
+		check      func(t *testing.T, update model.ListenerAttribute)
+	}{
+		{
+			name: "unset attributes are ignored",
+			local: model.ListenerAttribute{
+				ListenerPort: 80,
+				Protocol:     model.HTTP,
+				Description:  "k8s/80/nginx/default/c1",
+			},
+		},
+		{
+			name: "scheduler changed",
+			local: model.ListenerAttribute{
+				ListenerPort: 80,
+				Protocol:     model.HTTP,
+				Description:  "k8s/80/nginx/default/c1",
+				Scheduler:    "rr",
+			},
+			needUpdate: true,
+			check: func(t *testing.T, update model.ListenerAttribute) {
+				assert.Equal(t, "rr", update.Scheduler)
+				assert.Equal(t, "rsp-1", update.VGroupId)
+			},
+		},
+		{
+			name: "vgroup changed",
+			local: model.ListenerAttribute{
+				ListenerPort: 80,
+				Protocol:     model.HTTP,
+				Description:  "k8s/80/nginx/default/c1",
+				VGroupId:     "rsp-2",
+			},
+			needUpdate: true,
+			check: func(t *testing.T, update model.ListenerAttribute) {
+				assert.Equal(t, "rsp-2", update.VGroupId)
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			update, needUpdate, _ := diffListener(c.local, remote)
+			assert.Equal(t, c.needUpdate, needUpdate)
+			if c.check != nil {
+				c.check(t, update)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package clb
+
+import (
+	"fmt"
+
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+)
+
+type ModelType string
+
+const (
+	// LOCAL_MODEL, model built based on cluster information
+	LocalModel = ModelType("local")
+
+	// REMOTE_MODEL, Model built based on cloud information
+	RemoteModel = ModelType("remote")
+)
+
+type IModelBuilder interface {
+	Build(reqCtx *svcCtx.RequestContext) (*model.LoadBalancer, error)
+}
+
+type ModelBuilder struct {
+	LoadBalancerMgr *LoadBalancerManager
+	ListenerMgr     *ListenerManager
+	VGroupMgr       *VGroupManager
+}
+
+// NewModelBuilder construct a new ModelBuilder
+func NewModelBuilder(slbMgr *LoadBalancerManager, lisMgr *ListenerManager, vGroupMgr *VGroupManager) *ModelBuilder {
+	return &ModelBuilder{
+		LoadBalancerMgr: slbMgr,
+		ListenerMgr:     lisMgr,
+		VGroupMgr:       vGroupMgr,
+	}
+}
+
+func (builder *ModelBuilder) Instance(modelType ModelType) IModelBuilder {
+	switch modelType {
+	case LocalModel:
+		return &localModel{builder}
+	case RemoteModel:
+		return &remoteModel{builder}
+	}
+	return &localModel{builder}
+}
+
+func (builder *ModelBuilder) BuildModel(reqCtx *svcCtx.RequestContext, modelType ModelType) (*model.LoadBalancer, error) {
+	return builder.Instance(modelType).Build(reqCtx)
+}
+
+// localModel build model according to the Kubernetes cluster info
+type localModel struct{ *ModelBuilder }
+
+func (c localModel) Build(reqCtx *svcCtx.RequestContext) (*model.LoadBalancer, error) {
+	lbMdl := &model.LoadBalancer{
+		NamespacedName: util.NamespacedName(reqCtx.Service),
+	}
+	// if the service do not need loadbalancer any more, return directly.
+	if helper.NeedDeleteLoadBalancer(reqCtx.Service) {
+		if reqCtx.Anno.Get(annotation.LoadBalancerId) != "" {
+			lbMdl.LoadBalancerAttribute.IsUserManaged = true
+		}
+		return lbMdl, nil
+	}
+	if err := c.LoadBalancerMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
+		return nil, fmt.Errorf("build slb attribute error: %s", err.Error())
+	}
+	if err := c.VGroupMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
+		return nil, fmt.Errorf("build slb backend error: %s", err.Error())
+	}
+	if err := c.ListenerMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
+		return nil, fmt.Errorf("build slb listener error: %s", err.Error())
+	}
+
+	return lbMdl, nil
+}
+
+// remoteModel build model according to the cloud loadbalancer info
+type remoteModel struct{ *ModelBuilder }
+
+func (c remoteModel) Build(reqCtx *svcCtx.RequestContext) (*model.LoadBalancer, error) {
+	lbMdl := &model.LoadBalancer{
+		NamespacedName: util.NamespacedName(reqCtx.Service),
+	}
+
+	err := c.LoadBalancerMgr.BuildRemoteModel(reqCtx, lbMdl)
+	if err != nil {
+		return nil, fmt.Errorf("can not get slb attribute from cloud, error: %s", err.Error())
+	}
+	if lbMdl.LoadBalancerAttribute.LoadBalancerId == "" {
+		return lbMdl, nil
+	}
+
+	if err := c.VGroupMgr.BuildRemoteModel(reqCtx, lbMdl); err != nil {
+		return nil, fmt.Errorf("can not get slb backend from cloud, error: %s", err.Error())
+	}
+
+	if err := c.ListenerMgr.BuildRemoteModel(reqCtx, lbMdl); err != nil {
+		return nil, fmt.Errorf("can not get slb listeners from cloud, error: %s", err.Error())
+	}
+
+	return lbMdl, nil
+}
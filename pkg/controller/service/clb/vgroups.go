@@ -0,0 +1,726 @@
+package clb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ctrlCfg "k8s.io/alibaba-load-balancer-controller/pkg/config"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	reconbackend "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/backend"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	"k8s.io/alibaba-load-balancer-controller/pkg/provider/alibaba/base"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const DefaultServerWeight = 100
+
+func NewVGroupManager(kubeClient client.Client, cloud prvd.Provider) (*VGroupManager, error) {
+	manager := &VGroupManager{
+		kubeClient:    kubeClient,
+		cloud:         cloud,
+		loadNodeMutex: &sync.Mutex{},
+		nodeCache:     cache.NewExpiring(),
+		nodeCacheTTL:  365 * 24 * time.Hour,
+	}
+
+	vpcId, err := manager.cloud.VpcID()
+	if err != nil {
+		return nil, err
+	}
+
+	manager.vpcId = vpcId
+	return manager, nil
+}
+
+type VGroupManager struct {
+	kubeClient    client.Client
+	cloud         prvd.Provider
+	vpcId         string
+	loadNodeMutex *sync.Mutex
+	nodeCache     *cache.Expiring
+	nodeCacheTTL  time.Duration
+}
+
+func (mgr *VGroupManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	var vgs []model.VServerGroup
+
+	candidates, err := reconbackend.NewEndpointWithENI(reqCtx, mgr.kubeClient)
+	if err != nil {
+		return err
+	}
+
+	for _, port := range reqCtx.Service.Spec.Ports {
+		vg := model.VServerGroup{
+			NamedKey:    getVGroupNamedKey(reqCtx.Service, port),
+			ServicePort: port,
+		}
+		vg.VGroupName = vg.NamedKey.Key()
+		if err := setVGroupAttributeFromAnno(reqCtx, &vg); err != nil {
+			return err
+		}
+		if err := mgr.setVGroupBackends(reqCtx, &vg, candidates); err != nil {
+			return fmt.Errorf("set VGroup for port %d error: %s", port.Port, err.Error())
+		}
+		vgs = append(vgs, vg)
+	}
+	mdl.VServerGroups = vgs
+	return nil
+}
+
+func (mgr *VGroupManager) BuildRemoteModel(reqCtx *svcCtx.RequestContext, mdl *model.LoadBalancer) error {
+	vgs, err := mgr.cloud.DescribeVServerGroups(reqCtx.Ctx, mdl.LoadBalancerAttribute.LoadBalancerId)
+	if err != nil {
+		return fmt.Errorf("DescribeVServerGroups error: %s", err.Error())
+	}
+	for i := range vgs {
+		attr, err := mgr.cloud.DescribeVServerGroupAttribute(reqCtx.Ctx, vgs[i].VGroupId)
+		if err != nil {
+			return fmt.Errorf("DescribeVServerGroupAttribute error: %s", err.Error())
+		}
+		vgs[i].Backends = attr.Backends
+	}
+	mdl.VServerGroups = vgs
+	return nil
+}
+
+func (mgr *VGroupManager) CreateVServerGroup(reqCtx *svcCtx.RequestContext, vg *model.VServerGroup, lbId string) error {
+	return mgr.cloud.CreateVServerGroup(reqCtx.Ctx, vg, lbId)
+}
+
+func (mgr *VGroupManager) DeleteVServerGroup(reqCtx *svcCtx.RequestContext, vGroupId string) error {
+	return mgr.cloud.DeleteVServerGroup(reqCtx.Ctx, vGroupId)
+}
+
+func (mgr *VGroupManager) UpdateVServerGroup(reqCtx *svcCtx.RequestContext, local, remote model.VServerGroup) error {
+	add, del, update := diff(remote, local)
+	if len(add) == 0 && len(del) == 0 && len(update) == 0 {
+		reqCtx.Log.Info(fmt.Sprintf("reconcile vgroup: [%s] not change, skip reconcile", remote.VGroupId),
+			"vgroupName", remote.VGroupName)
+		return nil
+	}
+
+	if len(add) > 0 {
+		if err := mgr.BatchAddVServerGroupBackendServers(reqCtx, local, add); err != nil {
+			return err
+		}
+	}
+	if len(del) > 0 {
+		if err := mgr.BatchRemoveVServerGroupBackendServers(reqCtx, remote, del); err != nil {
+			return err
+		}
+	}
+	if len(update) > 0 {
+		if err := mgr.BatchUpdateVServerGroupBackendServers(reqCtx, remote, update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mgr *VGroupManager) BatchAddVServerGroupBackendServers(reqCtx *svcCtx.RequestContext, vg model.VServerGroup,
+	add []model.BackendAttribute) error {
+	reqCtx.Log.Info(fmt.Sprintf("reconcile vgroup: [%s] backend add [%+v]", vg.VGroupId, add))
+	return reconbackend.Batch(add, reconbackend.MaxBackendNum,
+		func(list []interface{}) error {
+			backends, err := json.Marshal(list)
+			if err != nil {
+				return fmt.Errorf("marshal backends error: %s", err.Error())
+			}
+			return mgr.cloud.AddVServerGroupBackendServers(reqCtx.Ctx, vg.VGroupId, string(backends))
+		})
+}
+
+func (mgr *VGroupManager) BatchRemoveVServerGroupBackendServers(reqCtx *svcCtx.RequestContext, vg model.VServerGroup,
+	del []model.BackendAttribute) error {
+	reqCtx.Log.Info(fmt.Sprintf("reconcile vgroup: [%s] backend del [%+v]", vg.VGroupId, del))
+	return reconbackend.Batch(del, reconbackend.MaxBackendNum,
+		func(list []interface{}) error {
+			backends, err := json.Marshal(list)
+			if err != nil {
+				return fmt.Errorf("marshal backends error: %s", err.Error())
+			}
+			return mgr.cloud.RemoveVServerGroupBackendServers(reqCtx.Ctx, vg.VGroupId, string(backends))
+		})
+}
+
+func (mgr *VGroupManager) BatchUpdateVServerGroupBackendServers(reqCtx *svcCtx.RequestContext, vg model.VServerGroup,
+	update []model.BackendAttribute) error {
+	reqCtx.Log.Info(fmt.Sprintf("reconcile vgroup: [%s] backend update [%+v]", vg.VGroupId, update))
+	return reconbackend.Batch(update, reconbackend.MaxBackendNum,
+		func(list []interface{}) error {
+			backends, err := json.Marshal(list)
+			if err != nil {
+				return fmt.Errorf("marshal backends error: %s", err.Error())
+			}
+			return mgr.cloud.SetVServerGroupAttribute(reqCtx.Ctx, vg.VGroupId, string(backends))
+		})
+}
+
+func (mgr *VGroupManager) setVGroupBackends(reqCtx *svcCtx.RequestContext, vg *model.VServerGroup,
+	candidates *reconbackend.EndpointWithENI) error {
+
+	var (
+		backends []model.BackendAttribute
+		err      error
+	)
+
+	switch candidates.TrafficPolicy {
+	case helper.ENITrafficPolicy:
+		reqCtx.Log.Info(fmt.Sprintf("eni mode, build backends for %s", vg.NamedKey))
+		backends, err = mgr.buildENIBackends(candidates, *vg)
+		if err != nil {
+			return fmt.Errorf("build eni backends error: %s", err.Error())
+		}
+	case helper.LocalTrafficPolicy:
+		reqCtx.Log.Info(fmt.Sprintf("local mode, build backends for %s", vg.NamedKey))
+		backends, err = mgr.buildLocalBackends(reqCtx, candidates, *vg)
+		if err != nil {
+			return fmt.Errorf("build local backends error: %s", err.Error())
+		}
+	case helper.ClusterTrafficPolicy:
+		reqCtx.Log.Info(fmt.Sprintf("cluster mode, build backends for %s", vg.NamedKey))
+		backends, err = mgr.buildClusterBackends(reqCtx, candidates, *vg)
+		if err != nil {
+			return fmt.Errorf("build cluster backends error: %s", err.Error())
+		}
+	default:
+		return fmt.Errorf("not supported traffic policy [%s]", candidates.TrafficPolicy)
+	}
+
+	if len(backends) == 0 {
+		reqCtx.Recorder.Event(
+			reqCtx.Service,
+			v1.EventTypeNormal,
+			helper.UnAvailableBackends,
+			"There are no available nodes for LoadBalancer",
+		)
+	}
+
+	vg.Backends = backends
+	return nil
+}
+
+func setGenericBackendAttribute(candidates *reconbackend.EndpointWithENI, vg model.VServerGroup,
+) []model.BackendAttribute {
+	if utilfeature.DefaultMutableFeatureGate.Enabled(ctrlCfg.EndpointSlice) {
+		return setBackendsFromEndpointSlices(candidates, vg)
+	}
+	return setBackendsFromEndpoints(candidates, vg)
+}
+
+func setBackendsFromEndpoints(candidates *reconbackend.EndpointWithENI, vg model.VServerGroup) []model.BackendAttribute {
+	var backends []model.BackendAttribute
+
+	if candidates.Endpoints == nil || len(candidates.Endpoints.Subsets) == 0 {
+		return nil
+	}
+	for _, ep := range candidates.Endpoints.Subsets {
+		var backendPort int
+		if vg.ServicePort.TargetPort.Type == intstr.Int {
+			backendPort = vg.ServicePort.TargetPort.IntValue()
+		} else {
+			for _, p := range ep.Ports {
+				if p.Name == vg.ServicePort.Name {
+					backendPort = int(p.Port)
+					break
+				}
+			}
+			if backendPort == 0 {
+				klog.Warningf("%s cannot find port according port name: %s", vg.VGroupName, vg.ServicePort.Name)
+			}
+		}
+
+		for _, addr := range ep.Addresses {
+			backends = append(backends, model.BackendAttribute{
+				NodeName: addr.NodeName,
+				ServerIp: addr.IP,
+				// set backend port to targetPort by default
+				// if backend type is ecs, update backend port to nodePort
+				Port:        backendPort,
+				Description: vg.VGroupName,
+			})
+		}
+	}
+	return backends
+}
+
+func setBackendsFromEndpointSlices(candidates *reconbackend.EndpointWithENI, vg model.VServerGroup) []model.BackendAttribute {
+	// used for deduplicate when endpointslice is enabled
+	// https://kubernetes.io/docs/concepts/services-networking/endpoint-slices/#duplicate-endpoints
+	endpointMap := make(map[string]bool)
+	var backends []model.BackendAttribute
+
+	for _, es := range candidates.EndpointSlices {
+		var backendPort int
+		if vg.ServicePort.TargetPort.Type == intstr.Int {
+			backendPort = vg.ServicePort.TargetPort.IntValue()
+		} else {
+			for _, p := range es.Ports {
+				// be compatible with IntOrString type target port
+				if p.Name != nil && *p.Name == vg.ServicePort.Name {
+					if p.Port != nil {
+						backendPort = int(*p.Port)
+					}
+					break
+				}
+			}
+			if backendPort == 0 {
+				klog.Warningf("%s cannot find port according port name: %s", vg.VGroupName, vg.ServicePort.Name)
+			}
+		}
+
+		for _, ep := range es.Endpoints {
+			if ep.Conditions.Ready == nil || !*ep.Conditions.Ready {
+				continue
+			}
+
+			for _, addr := range ep.Addresses {
+				if _, ok := endpointMap[addr]; ok {
+					continue
+				}
+				endpointMap[addr] = true
+				// NodeName of endpoint is nil, use topology.hostname instead of NodeName
+				hostName := ep.Topology[v1.LabelHostname]
+				backends = append(backends, model.BackendAttribute{
+					NodeName:    &hostName,
+					ServerIp:    addr,
+					Port:        backendPort,
+					Description: vg.VGroupName,
+				})
+			}
+		}
+	}
+
+	return backends
+}
+
+func (mgr *VGroupManager) buildENIBackends(candidates *reconbackend.EndpointWithENI, vg model.VServerGroup,
+) ([]model.BackendAttribute, error) {
+	backends := setGenericBackendAttribute(candidates, vg)
+	if len(backends) == 0 {
+		return nil, nil
+	}
+
+	backends, err := updateENIBackends(mgr, backends, candidates.AddressIPVersion)
+	if err != nil {
+		return backends, err
+	}
+
+	return setWeightBackends(helper.ENITrafficPolicy, backends, vg.VGroupWeight), nil
+}
+
+func (mgr *VGroupManager) buildLocalBackends(reqCtx *svcCtx.RequestContext, candidates *reconbackend.EndpointWithENI,
+	vg model.VServerGroup) ([]model.BackendAttribute, error) {
+	initBackends := setGenericBackendAttribute(candidates, vg)
+	if len(initBackends) == 0 {
+		return nil, nil
+	}
+
+	var (
+		ecsBackends, eciBackends []model.BackendAttribute
+		err                      error
+	)
+
+	// 1. add ecs backends. add pod located nodes.
+	// Attention: will add duplicated ecs backends.
+	for _, backend := range initBackends {
+		if backend.NodeName == nil {
+			return nil, fmt.Errorf("add ecs backends for service[%s] error, NodeName is nil for ip %s ",
+				util.Key(reqCtx.Service), backend.ServerIp)
+		}
+		node := helper.FindNodeByNodeName(candidates.Nodes, *backend.NodeName)
+		if node == nil {
+			reqCtx.Log.Info(fmt.Sprintf("warning: can not find correspond node %s for endpoint %s", *backend.NodeName, backend.ServerIp))
+			continue
+		}
+
+		// check if the node is virtual node, virtual node add as eci backend
+		if node.Labels["type"] == helper.LabelNodeTypeVK {
+			eciBackends = append(eciBackends, backend)
+			continue
+		}
+
+		if helper.IsNodeExcludeFromLoadBalancer(node) {
+			reqCtx.Log.Info("node has exclude label which cannot be added to lb backend", "node", node.Name)
+			continue
+		}
+		id, err := mgr.getInstanceId(*node)
+		if err != nil {
+			return nil, err
+		}
+		backend.ServerId = id
+		backend.Type = model.ECSBackendType
+		// for ECS backend type, port should be set to NodePort
+		backend.Port = int(vg.ServicePort.NodePort)
+		ecsBackends = append(ecsBackends, backend)
+	}
+
+	// 2. add eci backends
+	if len(eciBackends) != 0 {
+		reqCtx.Log.Info("add eciBackends")
+		eciBackends, err = updateENIBackends(mgr, eciBackends, candidates.AddressIPVersion)
+		if err != nil {
+			return nil, fmt.Errorf("update eci backends error: %s", err.Error())
+		}
+	}
+
+	backends := append(ecsBackends, eciBackends...)
+
+	// 3. set weight
+	backends = setWeightBackends(helper.LocalTrafficPolicy, backends, vg.VGroupWeight)
+
+	// 4. remove duplicated ecs
+	return removeDuplicatedECS(backends), nil
+}
+
+func (mgr *VGroupManager) buildClusterBackends(reqCtx *svcCtx.RequestContext, candidates *reconbackend.EndpointWithENI,
+	vg model.VServerGroup) ([]model.BackendAttribute, error) {
+	initBackends := setGenericBackendAttribute(candidates, vg)
+
+	var (
+		ecsBackends, eciBackends []model.BackendAttribute
+		err                      error
+	)
+
+	// 1. add ecs backends. add all cluster nodes.
+	for _, node := range candidates.Nodes {
+		if helper.IsNodeExcludeFromLoadBalancer(&node) {
+			reqCtx.Log.Info("node has exclude label which cannot be added to lb backend", "node", node.Name)
+			continue
+		}
+		id, err := mgr.getInstanceId(node)
+		if err != nil {
+			return nil, err
+		}
+
+		ecsBackends = append(
+			ecsBackends,
+			model.BackendAttribute{
+				ServerId:    id,
+				Weight:      DefaultServerWeight,
+				Port:        int(vg.ServicePort.NodePort),
+				Type:        model.ECSBackendType,
+				Description: vg.VGroupName,
+			},
+		)
+	}
+
+	// 2. add eci backends
+	for _, b := range initBackends {
+		if b.NodeName == nil {
+			return nil, fmt.Errorf("add ecs backends for service[%s] error, NodeName is nil for ip %s ",
+				util.Key(reqCtx.Service), b.ServerIp)
+		}
+		node := helper.FindNodeByNodeName(candidates.Nodes, *b.NodeName)
+		if node == nil {
+			reqCtx.Log.Info(fmt.Sprintf("warning: can not find correspond node %s for endpoint %s",
+				*b.NodeName, b.ServerIp))
+			continue
+		}
+
+		// check if the node is VK
+		if node.Labels["type"] == helper.LabelNodeTypeVK {
+			eciBackends = append(eciBackends, b)
+			continue
+		}
+	}
+
+	if len(eciBackends) != 0 {
+		eciBackends, err = updateENIBackends(mgr, eciBackends, candidates.AddressIPVersion)
+		if err != nil {
+			return nil, fmt.Errorf("update eci backends error: %s", err.Error())
+		}
+	}
+
+	backends := append(ecsBackends, eciBackends...)
+
+	return setWeightBackends(helper.ClusterTrafficPolicy, backends, vg.VGroupWeight), nil
+}
+
+func (mgr *VGroupManager) getInstanceId(node v1.Node) (string, error) {
+	_, id, err := helper.NodeFromProviderID(node.Spec.ProviderID)
+	if err == nil {
+		return id, nil
+	}
+	id, err = mgr.getInstanceIdByNode(node)
+	if err != nil {
+		return "", fmt.Errorf("parse providerid: %s. "+
+			"expected: ${regionid}.${nodeid}, %s", node.Spec.ProviderID, err.Error())
+	}
+	return id, nil
+}
+
+func (mgr *VGroupManager) getInstanceIdByNode(node v1.Node) (string, error) {
+	ip := ""
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == v1.NodeInternalIP {
+			ip = addr.Address
+			break
+		}
+	}
+	if ip == "" {
+		return "", fmt.Errorf("get privateIp from node error: node.statu.addresses.type = internalIP not exist")
+	}
+	regionId, err := mgr.cloud.Region()
+	if err != nil {
+		return "", fmt.Errorf("get region id from metadata error:%s", err.Error())
+	}
+
+	mgr.loadNodeMutex.Lock()
+	defer mgr.loadNodeMutex.Unlock()
+
+	cacheKey := fmt.Sprintf("%s.%s.%s", regionId, mgr.vpcId, ip)
+	if rawCacheItem, ok := mgr.nodeCache.Get(cacheKey); ok {
+		return rawCacheItem.(string), nil
+	}
+
+	instancesList, err := mgr.cloud.GetInstanceByIp(ip, regionId, mgr.vpcId)
+	if err != nil || len(instancesList) < 1 {
+		return "", fmt.Errorf("the corresponding instance cannot be found;region=%s,vpc=%s,ip=%s", regionId, mgr.vpcId, ip)
+	}
+	instanceId := instancesList[0].InstanceId
+	mgr.nodeCache.Set(cacheKey, instanceId, mgr.nodeCacheTTL)
+
+	return instanceId, nil
+}
+
+func removeDuplicatedECS(backends []model.BackendAttribute) []model.BackendAttribute {
+	nodeMap := make(map[string]bool)
+	var uniqBackends []model.BackendAttribute
+	for _, backend := range backends {
+		if _, ok := nodeMap[backend.ServerId]; ok {
+			continue
+		}
+		nodeMap[backend.ServerId] = true
+		uniqBackends = append(uniqBackends, backend)
+	}
+	return uniqBackends
+}
+
+func updateENIBackends(mgr *VGroupManager, backends []model.BackendAttribute, ipVersion model.AddressIPVersionType) (
+	[]model.BackendAttribute, error) {
+	var ips []string
+	for _, b := range backends {
+		ips = append(ips, b.ServerIp)
+	}
+
+	result, err := mgr.cloud.DescribeNetworkInterfaces(mgr.vpcId, ips, ipVersion)
+	if err != nil {
+		return nil, fmt.Errorf("call DescribeNetworkInterfaces: %s", err.Error())
+	}
+
+	for i := range backends {
+		eniid, ok := result[backends[i].ServerIp]
+		if !ok {
+			return nil, fmt.Errorf("can not find eniid for ip %s in vpc %s", backends[i].ServerIp, mgr.vpcId)
+		}
+		// for ENI backend type, port should be set to targetPort (default value), no need to update
+		backends[i].ServerId = eniid
+		backends[i].Type = model.ENIBackendType
+	}
+	return backends, nil
+}
+
+func setWeightBackends(mode helper.TrafficPolicy, backends []model.BackendAttribute, weight *int) []model.BackendAttribute {
+	// use default
+	if weight == nil {
+		return podNumberAlgorithm(mode, backends)
+	}
+
+	return podPercentAlgorithm(mode, backends, *weight)
+}
+
+// podNumberAlgorithm (default algorithm)
+/*
+	Calculate node weight by pod.
+	ClusterMode:  nodeWeight = 1
+	ENIMode:      podWeight = 1
+	LocalMode:    node_weight = nodePodNum
+*/
+func podNumberAlgorithm(mode helper.TrafficPolicy, backends []model.BackendAttribute) []model.BackendAttribute {
+	if mode == helper.ENITrafficPolicy || mode == helper.ClusterTrafficPolicy {
+		for i := range backends {
+			backends[i].Weight = DefaultServerWeight
+		}
+		return backends
+	}
+
+	// LocalTrafficPolicy
+	ecsPods := make(map[string]int)
+	for _, b := range backends {
+		ecsPods[b.ServerId] += 1
+	}
+	for i := range backends {
+		backends[i].Weight = ecsPods[backends[i].ServerId]
+	}
+	return backends
+}
+
+// podPercentAlgorithm
+/*
+	Calculate node weight by percent.
+	ClusterMode:  node_weight = weightSum/nodesNum
+	ENIMode:      pod_weight = weightSum/podsNum
+	LocalMode:    node_weight = node_pod_num/pods_num *weightSum
+*/
+func podPercentAlgorithm(mode helper.TrafficPolicy, backends []model.BackendAttribute, weight int,
+) []model.BackendAttribute {
+	if len(backends) == 0 {
+		return backends
+	}
+
+	if weight == 0 {
+		for i := range backends {
+			backends[i].Weight = 0
+		}
+		return backends
+	}
+
+	if mode == helper.ENITrafficPolicy || mode == helper.ClusterTrafficPolicy {
+		per := weight / len(backends)
+		if per < 1 {
+			per = 1
+		}
+
+		for i := range backends {
+			backends[i].Weight = per
+		}
+		return backends
+	}
+
+	// LocalTrafficPolicy
+	ecsPods := make(map[string]int)
+	for _, b := range backends {
+		ecsPods[b.ServerId] += 1
+	}
+	for i := range backends {
+		backends[i].Weight = weight * ecsPods[backends[i].ServerId] / len(backends)
+		if backends[i].Weight < 1 {
+			backends[i].Weight = 1
+		}
+	}
+	return backends
+}
+
+func getVGroupNamedKey(svc *v1.Service, servicePort v1.ServicePort) *model.VGroupNamedKey {
+	vgroupPort := ""
+	if helper.IsENIBackendType(svc) {
+		switch servicePort.TargetPort.Type {
+		case intstr.Int:
+			vgroupPort = fmt.Sprintf("%d", servicePort.TargetPort.IntValue())
+		case intstr.String:
+			vgroupPort = servicePort.TargetPort.StrVal
+		}
+	} else {
+		vgroupPort = fmt.Sprintf("%d", servicePort.NodePort)
+	}
+	return &model.VGroupNamedKey{
+		Prefix:      model.DEFAULT_PREFIX,
+		Namespace:   svc.Namespace,
+		CID:         base.CLUSTER_ID,
+		ServiceName: svc.Name,
+		VGroupPort:  vgroupPort,
+	}
+}
+
+func setVGroupAttributeFromAnno(reqCtx *svcCtx.RequestContext, vg *model.VServerGroup) error {
+	if reqCtx.Anno.Get(annotation.VGroupWeight) != "" {
+		weight, err := strconv.Atoi(reqCtx.Anno.Get(annotation.VGroupWeight))
+		if err != nil || weight < 0 || weight > 100 {
+			return fmt.Errorf("weight must be an integer in range [0, 100], got %s",
+				reqCtx.Anno.Get(annotation.VGroupWeight))
+		}
+		vg.VGroupWeight = &weight
+	}
+
+	// user managed vgroup, e.g. "rsp-xxx:80,rsp-yyy:443"
+	if reqCtx.Anno.Get(annotation.VGroupPort) != "" {
+		for _, v := range strings.Split(reqCtx.Anno.Get(annotation.VGroupPort), ",") {
+			pp := strings.Split(v, ":")
+			if len(pp) != 2 {
+				return fmt.Errorf("vgroup-port format error: %s, expect rsp-xxx:80,rsp-yyy:443",
+					reqCtx.Anno.Get(annotation.VGroupPort))
+			}
+			if pp[1] == fmt.Sprintf("%d", vg.ServicePort.Port) {
+				vg.VGroupId = pp[0]
+				vg.IsUserManaged = true
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func diff(remote, local model.VServerGroup) (
+	[]model.BackendAttribute, []model.BackendAttribute, []model.BackendAttribute) {
+
+	var (
+		additions []model.BackendAttribute
+		deletions []model.BackendAttribute
+		updates   []model.BackendAttribute
+	)
+
+	for _, r := range remote.Backends {
+		// skip the backends added by user in a reused vgroup
+		if local.IsUserManaged && r.Description != local.VGroupName {
+			continue
+		}
+		found := false
+		for _, l := range local.Backends {
+			if isBackendEqual(r, l) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			deletions = append(deletions, r)
+		}
+	}
+
+	for _, l := range local.Backends {
+		found := false
+		for _, r := range remote.Backends {
+			if isBackendEqual(l, r) {
+				found = true
+				if l.Weight != r.Weight || l.Description != r.Description {
+					updates = append(updates, l)
+				}
+				break
+			}
+		}
+		if !found {
+			additions = append(additions, l)
+		}
+	}
+
+	return additions, deletions, updates
+}
+
+func isBackendEqual(a, b model.BackendAttribute) bool {
+	if a.Type != b.Type {
+		return false
+	}
+
+	switch a.Type {
+	case model.ENIBackendType:
+		return a.ServerId == b.ServerId && a.ServerIp == b.ServerIp && a.Port == b.Port
+	case model.ECSBackendType:
+		return a.ServerId == b.ServerId && a.Port == b.Port
+	default:
+		klog.Errorf("%s is not supported, skip", a.Type)
+		return false
+	}
+}
@@ -0,0 +1,138 @@
+package clb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	reconbackend "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/backend"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestDiff(t *testing.T) {
+	ecs := func(id string, port, weight int, desc string) model.BackendAttribute {
+		return model.BackendAttribute{Type: model.ECSBackendType, ServerId: id, Port: port, Weight: weight, Description: desc}
+	}
+	eni := func(id, ip string, port, weight int) model.BackendAttribute {
+		return model.BackendAttribute{Type: model.ENIBackendType, ServerId: id, ServerIp: ip, Port: port, Weight: weight, Description: "vg"}
+	}
+	cases := []struct {
+		name      string
+		remote    model.VServerGroup
+		local     model.VServerGroup
+		additions []model.BackendAttribute
+		deletions []model.BackendAttribute
+		updates   []model.BackendAttribute
+	}{
+		{
+			name:      "add and delete",
+			remote:    model.VServerGroup{VGroupName: "vg", Backends: []model.BackendAttribute{ecs("i-1", 30080, 100, "vg")}},
+			local:     model.VServerGroup{VGroupName: "vg", Backends: []model.BackendAttribute{ecs("i-2", 30080, 100, "vg")}},
+			additions: []model.BackendAttribute{ecs("i-2", 30080, 100, "vg")},
+			deletions: []model.BackendAttribute{ecs("i-1", 30080, 100, "vg")},
+		},
+		{
+			name:    "weight changed",
+			remote:  model.VServerGroup{VGroupName: "vg", Backends: []model.BackendAttribute{ecs("i-1", 30080, 100, "vg")}},
+			local:   model.VServerGroup{VGroupName: "vg", Backends: []model.BackendAttribute{ecs("i-1", 30080, 50, "vg")}},
+			updates: []model.BackendAttribute{ecs("i-1", 30080, 50, "vg")},
+		},
+		{
+			name:      "eni matched by ip",
+			remote:    model.VServerGroup{VGroupName: "vg", Backends: []model.BackendAttribute{eni("eni-1", "10.0.0.1", 80, 100)}},
+			local:     model.VServerGroup{VGroupName: "vg", Backends: []model.BackendAttribute{eni("eni-1", "10.0.0.2", 80, 100)}},
+			additions: []model.BackendAttribute{eni("eni-1", "10.0.0.2", 80, 100)},
+			deletions: []model.BackendAttribute{eni("eni-1", "10.0.0.1", 80, 100)},
+		},
+		{
+			name: "user backends kept in reused vgroup",
+			remote: model.VServerGroup{VGroupName: "vg", Backends: []model.BackendAttribute{
+				ecs("i-user", 8080, 100, "added by user"),
+				ecs("i-1", 30080, 100, "vg"),
+			}},
+			local:     model.VServerGroup{VGroupName: "vg", IsUserManaged: true},
+			deletions: []model.BackendAttribute{ecs("i-1", 30080, 100, "vg")},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			additions, deletions, updates := diff(c.remote, c.local)
+			assert.Equal(t, c.additions, additions)
+			assert.Equal(t, c.deletions, deletions)
+			assert.Equal(t, c.updates, updates)
+		})
+	}
+}
+
+func TestSetBackendsFromEndpoints(t *testing.T) {
+	node := "node-1"
+	endpoints := &v1.Endpoints{
+		Subsets: []v1.EndpointSubset{{
+			Addresses: []v1.EndpointAddress{{IP: "10.0.0.1", NodeName: &node}, {IP: "10.0.0.2", NodeName: &node}},
+			Ports:     []v1.EndpointPort{{Name: "http", Port: 8080}},
+		}},
+	}
+	cases := []struct {
+		name       string
+		targetPort intstr.IntOrString
+		portName   string
+		port       int
+	}{
+		{name: "int target port", targetPort: intstr.FromInt(80), port: 80},
+		{name: "named target port", targetPort: intstr.FromString("web"), portName: "http", port: 8080},
+		{name: "unknown port name", targetPort: intstr.FromString("web"), portName: "grpc", port: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			vg := model.VServerGroup{
+				VGroupName:  "vg",
+				ServicePort: v1.ServicePort{Name: c.portName, TargetPort: c.targetPort},
+			}
+			backends := setBackendsFromEndpoints(&reconbackend.EndpointWithENI{Endpoints: endpoints}, vg)
+			assert.Equal(t, 2, len(backends))
+			for _, b := range backends {
+				assert.Equal(t, c.port, b.Port)
+				assert.Equal(t, "vg", b.Description)
+				assert.Equal(t, &node, b.NodeName)
+			}
+		})
+	}
+
+	assert.Nil(t, setBackendsFromEndpoints(&reconbackend.EndpointWithENI{}, model.VServerGroup{}))
+}
+
+func TestSetBackendsFromEndpointSlices(t *testing.T) {
+	ready, notReady := true, false
+	portName := "http"
+	port := int32(8080)
+	slices := []discovery.EndpointSlice{
+		{
+			Ports: []discovery.EndpointPort{{Name: &portName, Port: &port}},
+			Endpoints: []discovery.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discovery.EndpointConditions{Ready: &ready},
+					Topology: map[string]string{v1.LabelHostname: "node-1"}},
+				{Addresses: []string{"10.0.0.2"}, Conditions: discovery.EndpointConditions{Ready: &notReady}},
+				{Addresses: []string{"10.0.0.3"}},
+			},
+		},
+		{
+			// the same endpoint may be listed in several slices
+			Ports: []discovery.EndpointPort{{Name: &portName, Port: &port}},
+			Endpoints: []discovery.Endpoint{
+				{Addresses: []string{"10.0.0.1"}, Conditions: discovery.EndpointConditions{Ready: &ready},
+					Topology: map[string]string{v1.LabelHostname: "node-1"}},
+			},
+		},
+	}
+	vg := model.VServerGroup{
+		VGroupName:  "vg",
+		ServicePort: v1.ServicePort{Name: "http", TargetPort: intstr.FromString("web")},
+	}
+	backends := setBackendsFromEndpointSlices(&reconbackend.EndpointWithENI{EndpointSlices: slices}, vg)
+	assert.Equal(t, 1, len(backends))
+	assert.Equal(t, "10.0.0.1", backends[0].ServerIp)
+	assert.Equal(t, 8080, backends[0].Port)
+	assert.Equal(t, "node-1", *backends[0].NodeName)
+}
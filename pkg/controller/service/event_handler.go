@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
 	"k8s.io/alibaba-load-balancer-controller/pkg/util"
 	v1 "k8s.io/api/core/v1"
 	discovery "k8s.io/api/discovery/v1beta1"
@@ -71,7 +72,7 @@ func (h *enqueueRequestForServiceEvent) enqueueManagedService(queue workqueue.Ra
 }
 
 func needUpdate(oldSvc, newSvc *v1.Service, recorder record.EventRecorder) bool {
-	if !helper.NeedNLB(oldSvc) && !helper.NeedNLB(newSvc) {
+	if !needAdd(oldSvc) && !needAdd(newSvc) {
 		return false
 	}
 
@@ -260,7 +261,7 @@ func (h *enqueueRequestForNodeEvent) InjectClient(c client.Client) error {
 
 func (h *enqueueRequestForNodeEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
 	node, ok := e.Object.(*v1.Node)
-	if ok && !canNodeSkipEventHandler(node) {
+	if ok && !CanNodeSkipEventHandler(node) {
 		util.NLBLog.Info("controller: node create event", "node", node.Name)
 		h.enqueueManagedNode(queue, node)
 	}
@@ -271,12 +272,12 @@ func (h *enqueueRequestForNodeEvent) Update(e event.UpdateEvent, queue workqueue
 	newNode, ok2 := e.ObjectNew.(*v1.Node)
 
 	if ok1 && ok2 {
-		if canNodeSkipEventHandler(oldNode) && canNodeSkipEventHandler(newNode) {
+		if CanNodeSkipEventHandler(oldNode) && CanNodeSkipEventHandler(newNode) {
 			return
 		}
 
 		//if node label and schedulable condition changed, need to reconcile svc
-		if nodeSpecChanged(oldNode, newNode) {
+		if NodeSpecChanged(oldNode, newNode) {
 			util.NLBLog.Info("controller: node update event", "node", oldNode.Name)
 			h.enqueueManagedNode(queue, newNode)
 		}
@@ -285,7 +286,7 @@ func (h *enqueueRequestForNodeEvent) Update(e event.UpdateEvent, queue workqueue
 
 func (h *enqueueRequestForNodeEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
 	node, ok := e.Object.(*v1.Node)
-	if ok && !canNodeSkipEventHandler(node) {
+	if ok && !CanNodeSkipEventHandler(node) {
 		util.NLBLog.Info("controller: node delete event", "node", node.Name)
 		h.enqueueManagedNode(queue, node)
 	}
@@ -321,6 +322,76 @@ func (h *enqueueRequestForNodeEvent) enqueueManagedNode(queue workqueue.RateLimi
 	}
 }
 
+// NewEnqueueRequestForSecretEvent, event handler for the events of the Secrets referenced by the services
+func NewEnqueueRequestForSecretEvent(record record.EventRecorder) *enqueueRequestForSecretEvent {
+	return &enqueueRequestForSecretEvent{eventRecorder: record}
+}
+
+type enqueueRequestForSecretEvent struct {
+	client        client.Client
+	eventRecorder record.EventRecorder
+}
+
+var _ handler.EventHandler = (*enqueueRequestForSecretEvent)(nil)
+
+func (h *enqueueRequestForSecretEvent) InjectClient(c client.Client) error {
+	h.client = c
+	return nil
+}
+
+func (h *enqueueRequestForSecretEvent) Create(e event.CreateEvent, queue workqueue.RateLimitingInterface) {
+	secret, ok := e.Object.(*v1.Secret)
+	if ok {
+		h.enqueueReferencingServices(queue, secret)
+	}
+}
+
+func (h *enqueueRequestForSecretEvent) Update(e event.UpdateEvent, queue workqueue.RateLimitingInterface) {
+	oldSecret, ok1 := e.ObjectOld.(*v1.Secret)
+	newSecret, ok2 := e.ObjectNew.(*v1.Secret)
+	if ok1 && ok2 && !reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+		h.enqueueReferencingServices(queue, newSecret)
+	}
+}
+
+func (h *enqueueRequestForSecretEvent) Delete(e event.DeleteEvent, queue workqueue.RateLimitingInterface) {
+	secret, ok := e.Object.(*v1.Secret)
+	if ok {
+		h.enqueueReferencingServices(queue, secret)
+	}
+}
+
+func (h *enqueueRequestForSecretEvent) Generic(e event.GenericEvent, queue workqueue.RateLimitingInterface) {
+	// unknown event, ignore
+}
+
+func (h *enqueueRequestForSecretEvent) enqueueReferencingServices(queue workqueue.RateLimitingInterface, secret *v1.Secret) {
+	svcs := v1.ServiceList{}
+	if err := h.client.List(context.TODO(), &svcs, client.InNamespace(secret.Namespace)); err != nil {
+		util.NLBLog.Error(err, "fail to list services for secret", "secret", util.Key(secret))
+		return
+	}
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		if !helper.NeedNLB(svc) || !isSecretReferenced(svc, secret.Name) {
+			continue
+		}
+		queue.Add(reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: svc.Namespace,
+				Name:      svc.Name,
+			},
+		})
+		util.NLBLog.Info(fmt.Sprintf("secret change: enqueue service %s", util.Key(svc)),
+			"secret", util.Key(secret), "queueLen", queue.Len())
+	}
+}
+
+func isSecretReferenced(svc *v1.Service, secretName string) bool {
+	anno := annotation.NewAnnotationRequest(svc)
+	return anno.Get(annotation.CertSecret) == secretName || anno.Get(annotation.CaCertSecret) == secretName
+}
+
 // NewEnqueueRequestForEndpointSliceEvent, event handler for endpointslice event
 func NewEnqueueRequestForEndpointSliceEvent(record record.EventRecorder) *enqueueRequestForEndpointSliceEvent {
 	return &enqueueRequestForEndpointSliceEvent{eventRecorder: record}
@@ -424,7 +495,8 @@ func isEndpointSliceUpdateNeeded(old, new *discovery.EndpointSlice) bool {
 	return !reflect.DeepEqual(old.Endpoints, new.Endpoints) || !reflect.DeepEqual(old.Ports, new.Ports)
 }
 
-func nodeSpecChanged(oldNode, newNode *v1.Node) bool {
+// NodeSpecChanged reports whether the node change affects the backends of load balancers
+func NodeSpecChanged(oldNode, newNode *v1.Node) bool {
 	if nodeLabelsChanged(oldNode.Name, oldNode.Labels, newNode.Labels) {
 		return true
 	}
@@ -487,8 +559,8 @@ func nodeLabelsChanged(nodeName string, oldL, newL map[string]string) bool {
 	return false
 }
 
-// only for node event
-func canNodeSkipEventHandler(node *v1.Node) bool {
+// CanNodeSkipEventHandler reports whether the events of the node can be ignored, only for node event
+func CanNodeSkipEventHandler(node *v1.Node) bool {
 	if node == nil || node.Labels == nil {
 		return false
 	}
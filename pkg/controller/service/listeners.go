@@ -8,6 +8,7 @@ import (
 
 	"github.com/alibabacloud-go/tea/tea"
 	"github.com/mohae/deepcopy"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
 	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
 	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
 	"k8s.io/alibaba-load-balancer-controller/pkg/model"
@@ -30,6 +31,14 @@ type ListenerManager struct {
 }
 
 func (mgr *ListenerManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, mdl *nlbmodel.NetworkLoadBalancer) error {
+	if reqCtx.Anno.Get(annotation.ListenerPortRange) != "" {
+		listener, err := mgr.buildPortRangeListener(reqCtx)
+		if err != nil {
+			return fmt.Errorf("build port range listener error: %s", err.Error())
+		}
+		mdl.Listeners = append(mdl.Listeners, listener)
+		return nil
+	}
 	for _, port := range reqCtx.Service.Spec.Ports {
 		listener, err := mgr.buildListenerFromServicePort(reqCtx, port)
 		if err != nil {
@@ -109,6 +118,54 @@ func (mgr *ListenerManager) buildListenerFromServicePort(reqCtx *svcCtx.RequestC
 	return listener, nil
 }
 
+// buildPortRangeListener builds a single listener on the port range of the annotation from the only port of the
+// service. The listener forwards the requests to the same port of the pods by an all-port server group, so the
+// backends must be ENIs.
+func (mgr *ListenerManager) buildPortRangeListener(reqCtx *svcCtx.RequestContext) (*nlbmodel.ListenerAttribute, error) {
+	if len(reqCtx.Service.Spec.Ports) != 1 {
+		return nil, fmt.Errorf("exactly one service port is expected, got %d", len(reqCtx.Service.Spec.Ports))
+	}
+	if !helper.IsENIBackendType(reqCtx.Service) {
+		return nil, fmt.Errorf("the backend type must be %s", model.ENIBackendType)
+	}
+	startPort, endPort, err := parsePortRange(reqCtx.Anno.Get(annotation.ListenerPortRange))
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := mgr.buildListenerFromServicePort(reqCtx, reqCtx.Service.Spec.Ports[0])
+	if err != nil {
+		return listener, err
+	}
+	listener.ListenerPort = 0
+	listener.StartPort = tea.Int32(startPort)
+	listener.EndPort = tea.Int32(endPort)
+	listener.NamedKey.Port = startPort
+	listener.ListenerDescription = listener.NamedKey.Key()
+	listener.ServerGroupName = getListenerServerGroupNamedKey(reqCtx.Service, listener).Key()
+	return listener, nil
+}
+
+// parsePortRange parses the port range in the start-end format
+func parsePortRange(portRange string) (int32, int32, error) {
+	ports := strings.Split(portRange, "-")
+	if len(ports) != 2 {
+		return 0, 0, fmt.Errorf("port range format must be like '30000-31000', got [%s]", portRange)
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(ports[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse start port of [%s] error: %s", portRange, err.Error())
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(ports[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse end port of [%s] error: %s", portRange, err.Error())
+	}
+	if start < 1 || end > 65535 || start > end {
+		return 0, 0, fmt.Errorf("port range [%s] must be within 1-65535 and the start port must not exceed the end port", portRange)
+	}
+	return int32(start), int32(end), nil
+}
+
 func (mgr *ListenerManager) ListListeners(reqCtx *svcCtx.RequestContext, lbId string,
 ) ([]*nlbmodel.ListenerAttribute, error) {
 	return mgr.cloud.ListNLBListeners(reqCtx.Ctx, lbId)
@@ -198,12 +255,12 @@ func (mgr *ListenerManager) UpdateNLBListener(reqCtx *svcCtx.RequestContext, loc
 
 	if needUpdate {
 		reqCtx.Ctx = context.WithValue(reqCtx.Ctx, dryrun.ContextMessage, updateDetail)
-		reqCtx.Log.Info(fmt.Sprintf("update listener: %s [%d] changed, detail %s", local.ListenerProtocol, local.ListenerPort, updateDetail))
+		reqCtx.Log.Info(fmt.Sprintf("update listener: %s [%s] changed, detail %s", local.ListenerProtocol, local.PortString(), updateDetail))
 
 		return mgr.cloud.UpdateNLBListener(reqCtx.Ctx, update)
 	}
 
-	reqCtx.Log.Info(fmt.Sprintf("update listener: %s [%d] not changed, skip", local.ListenerProtocol, local.ListenerPort))
+	reqCtx.Log.Info(fmt.Sprintf("update listener: %s [%s] not changed, skip", local.ListenerProtocol, local.PortString()))
 	return nil
 }
 
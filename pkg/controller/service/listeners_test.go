@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	nlbmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/nlb"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// listenerCloud records the listeners created, updated and deleted
+type listenerCloud struct {
+	prvd.Provider
+	created []string
+	updated []string
+	deleted []string
+}
+
+func (c *listenerCloud) CreateNLBListener(ctx context.Context, lbId string, lis *nlbmodel.ListenerAttribute) error {
+	c.created = append(c.created, lis.PortString())
+	return nil
+}
+
+func (c *listenerCloud) UpdateNLBListener(ctx context.Context, lis *nlbmodel.ListenerAttribute) error {
+	c.updated = append(c.updated, lis.ListenerId)
+	return nil
+}
+
+func (c *listenerCloud) DeleteNLBListener(ctx context.Context, listenerId string) error {
+	c.deleted = append(c.deleted, listenerId)
+	return nil
+}
+
+func newPortRangeRequestContext(portRange string, ports ...v1.ServicePort) *svcCtx.RequestContext {
+	reqCtx := newCertificateRequestContext(map[string]string{annotation.ListenerPortRange: portRange})
+	reqCtx.Service.Annotations[helper.BackendType] = model.ENIBackendType
+	reqCtx.Service.Spec.Ports = ports
+	return reqCtx
+}
+
+func TestBuildPortRangeListener(t *testing.T) {
+	mgr := NewListenerManager(nil)
+	port := v1.ServicePort{Name: "game", Protocol: v1.ProtocolUDP, Port: 30000, TargetPort: intstr.FromInt(30000)}
+
+	mdl := &nlbmodel.NetworkLoadBalancer{}
+	assert.NoError(t, mgr.BuildLocalModel(newPortRangeRequestContext("30000-31000", port), mdl))
+	if assert.Len(t, mdl.Listeners, 1) {
+		lis := mdl.Listeners[0]
+		assert.True(t, lis.IsPortRange())
+		assert.Equal(t, "30000-31000", lis.PortString())
+		assert.Equal(t, nlbmodel.UDP, lis.ListenerProtocol)
+		assert.Equal(t, int32(30000), lis.NamedKey.Port)
+		assert.Equal(t, lis.NamedKey.Key(), lis.ListenerDescription)
+		assert.Contains(t, lis.ServerGroupName, "k8s.30000-31000.UDP.game.default.")
+	}
+
+	for _, reqCtx := range []*svcCtx.RequestContext{
+		newPortRangeRequestContext("30000-31000", port, v1.ServicePort{Protocol: v1.ProtocolTCP, Port: 80}),
+		newPortRangeRequestContext("31000-30000", port),
+		newPortRangeRequestContext("30000", port),
+		newPortRangeRequestContext("0-100", port),
+	} {
+		assert.Error(t, mgr.BuildLocalModel(reqCtx, &nlbmodel.NetworkLoadBalancer{}))
+	}
+	// the all-port server group forwards to the pods directly
+	reqCtx := newPortRangeRequestContext("30000-31000", port)
+	reqCtx.Service.Annotations[helper.BackendType] = model.ECSBackendType
+	assert.Error(t, mgr.BuildLocalModel(reqCtx, &nlbmodel.NetworkLoadBalancer{}))
+}
+
+func TestApplyPortRangeListeners(t *testing.T) {
+	cloud := &listenerCloud{}
+	m := NewModelApplier(nil, NewListenerManager(cloud), nil, NewCertificateManager(nil, cloud))
+	reqCtx := newPortRangeRequestContext("30000-32000")
+	local := &nlbmodel.NetworkLoadBalancer{
+		LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{},
+		Listeners: []*nlbmodel.ListenerAttribute{
+			{ListenerProtocol: nlbmodel.UDP, StartPort: tea.Int32(30000), EndPort: tea.Int32(32000), ServerGroupId: "sg-range"},
+		},
+	}
+	remote := &nlbmodel.NetworkLoadBalancer{
+		LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{LoadBalancerId: "nlb-1"},
+		Listeners: []*nlbmodel.ListenerAttribute{
+			{ListenerId: "lsn-80", ListenerProtocol: nlbmodel.UDP, ListenerPort: 80},
+			{ListenerId: "lsn-range", ListenerProtocol: nlbmodel.UDP, StartPort: tea.Int32(30000), EndPort: tea.Int32(31000)},
+		},
+	}
+
+	// the listener of the previous port range is replaced
+	assert.NoError(t, m.applyListeners(reqCtx, local, remote))
+	assert.Equal(t, []string{"lsn-80", "lsn-range"}, cloud.deleted)
+	assert.Equal(t, []string{"30000-32000"}, cloud.created)
+	assert.Empty(t, cloud.updated)
+
+	// the listener of the same port range is updated
+	*cloud = listenerCloud{}
+	local.Listeners[0].ListenerId = ""
+	remote.Listeners = []*nlbmodel.ListenerAttribute{
+		{ListenerId: "lsn-range", ListenerProtocol: nlbmodel.UDP, StartPort: tea.Int32(30000), EndPort: tea.Int32(32000)},
+	}
+	assert.NoError(t, m.applyListeners(reqCtx, local, remote))
+	assert.Equal(t, []string{"lsn-range"}, cloud.updated)
+	assert.Empty(t, cloud.created)
+	assert.Empty(t, cloud.deleted)
+}
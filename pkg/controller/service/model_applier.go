@@ -15,18 +15,20 @@ import (
 	v1 "k8s.io/api/core/v1"
 )
 
-func NewModelApplier(nlbMgr *NLBManager, lisMgr *ListenerManager, sgMgr *ServerGroupManager) *ModelApplier {
+func NewModelApplier(nlbMgr *NLBManager, lisMgr *ListenerManager, sgMgr *ServerGroupManager, certMgr *CertificateManager) *ModelApplier {
 	return &ModelApplier{
-		nlbMgr: nlbMgr,
-		lisMgr: lisMgr,
-		sgMgr:  sgMgr,
+		nlbMgr:  nlbMgr,
+		lisMgr:  lisMgr,
+		sgMgr:   sgMgr,
+		certMgr: certMgr,
 	}
 }
 
 type ModelApplier struct {
-	nlbMgr *NLBManager
-	lisMgr *ListenerManager
-	sgMgr  *ServerGroupManager
+	nlbMgr  *NLBManager
+	lisMgr  *ListenerManager
+	sgMgr   *ServerGroupManager
+	certMgr *CertificateManager
 }
 
 func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *nlbmodel.NetworkLoadBalancer) (*nlbmodel.NetworkLoadBalancer, error) {
@@ -55,7 +57,8 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *nlbmodel.Netw
 		return remote, fmt.Errorf("reconcile backends error: %s", err.Error())
 	}
 
-	if serviceHashChanged || ctrlCfg.ControllerCFG.DryRun {
+	// the listeners using the certificates of the Secrets are reconciled every time to pick up the renewed Secrets
+	if serviceHashChanged || ctrlCfg.ControllerCFG.DryRun || UsesSecretCertificates(reqCtx.Anno) {
 		if remote.LoadBalancerAttribute.LoadBalancerId != "" {
 			if err := m.lisMgr.BuildRemoteModel(reqCtx, remote); err != nil {
 				return remote, fmt.Errorf("get lb listeners from cloud, error: %s", err.Error())
@@ -68,13 +71,27 @@ func (m *ModelApplier) Apply(reqCtx *svcCtx.RequestContext, local *nlbmodel.Netw
 				return remote, fmt.Errorf("alicloud: can not find loadbalancer by tag [%s:%s]",
 					helper.TAGKEY, reqCtx.Anno.GetDefaultLoadBalancerName())
 			}
+			// the listeners are deleted with the nlb
+			if err := m.certMgr.CleanupCertificates(reqCtx, local, remote); err != nil {
+				return remote, fmt.Errorf("cleanup certificates error: %s", err.Error())
+			}
 		}
 	}
 
+	m.certMgr.CheckCertificateExpiry(reqCtx, local)
+
 	if err := m.cleanup(reqCtx, local, remote); err != nil {
 		return remote, fmt.Errorf("update lb listeners error: %s", err.Error())
 	}
 
+	if !helper.NeedDeleteLoadBalancer(reqCtx.Service) && !ctrlCfg.ControllerCFG.DryRun {
+		pending, err := m.sgMgr.UpdateReadinessGates(reqCtx, local, remote)
+		if err != nil {
+			return remote, fmt.Errorf("update readiness gates error: %s", err.Error())
+		}
+		remote.ContainsPotentialReadyEndpoints = pending || local.ContainsPotentialReadyEndpoints
+	}
+
 	return remote, nil
 }
 
@@ -201,6 +218,11 @@ func (m *ModelApplier) applyListeners(reqCtx *svcCtx.RequestContext, local, remo
 		}
 	}
 
+	// upload the certificates before the listeners use them
+	if err := m.certMgr.EnsureCertificates(reqCtx, local); err != nil {
+		return fmt.Errorf("ensure certificates error: %s", err.Error())
+	}
+
 	// associate listener and vGroup
 	for i := range local.Listeners {
 		if local.Listeners[i].ServerGroupId != "" {
@@ -215,7 +237,8 @@ func (m *ModelApplier) applyListeners(reqCtx *svcCtx.RequestContext, local, remo
 	for _, r := range remote.Listeners {
 		found := false
 		for i, l := range local.Listeners {
-			if r.ListenerPort == l.ListenerPort && r.ListenerProtocol == l.ListenerProtocol {
+			// the port range of a listener can not be updated, it is deleted and created again once changed
+			if r.PortString() == l.PortString() && r.ListenerProtocol == l.ListenerProtocol {
 				found = true
 				local.Listeners[i].ListenerId = r.ListenerId
 			}
@@ -229,7 +252,7 @@ func (m *ModelApplier) applyListeners(reqCtx *svcCtx.RequestContext, local, remo
 				}
 			}
 
-			reqCtx.Log.Info(fmt.Sprintf("delete listener: %s [%d]", r.ListenerProtocol, r.ListenerPort))
+			reqCtx.Log.Info(fmt.Sprintf("delete listener: %s [%s]", r.ListenerProtocol, r.PortString()))
 			if err := m.lisMgr.DeleteListener(reqCtx, r.ListenerId); err != nil {
 				return fmt.Errorf("EnsureListenerDeleted error: %s", err.Error())
 			}
@@ -249,13 +272,17 @@ func (m *ModelApplier) applyListeners(reqCtx *svcCtx.RequestContext, local, remo
 
 		// create
 		if !found {
-			reqCtx.Log.Info(fmt.Sprintf("create listener: %s [%d]", local.Listeners[i].ListenerProtocol, local.Listeners[i].ListenerPort))
+			reqCtx.Log.Info(fmt.Sprintf("create listener: %s [%s]", local.Listeners[i].ListenerProtocol, local.Listeners[i].PortString()))
 			if err := m.lisMgr.CreateListener(reqCtx, remote.LoadBalancerAttribute.LoadBalancerId, local.Listeners[i]); err != nil {
 				return fmt.Errorf("EnsureListenerCreated error: %s", err.Error())
 			}
 		}
 	}
 
+	// delete the certificates after the listeners are switched to the new ones
+	if err := m.certMgr.CleanupCertificates(reqCtx, local, remote); err != nil {
+		return fmt.Errorf("cleanup certificates error: %s", err.Error())
+	}
 	return nil
 }
 
@@ -25,17 +25,19 @@ type IModelBuilder interface {
 }
 
 type ModelBuilder struct {
-	NLBMgr *NLBManager
-	LisMgr *ListenerManager
-	SGMgr  *ServerGroupManager
+	NLBMgr  *NLBManager
+	LisMgr  *ListenerManager
+	SGMgr   *ServerGroupManager
+	CertMgr *CertificateManager
 }
 
 // NewDefaultModelBuilder construct a new defaultModelBuilder
-func NewModelBuilder(nlbMgr *NLBManager, lisMgr *ListenerManager, sgMgr *ServerGroupManager) *ModelBuilder {
+func NewModelBuilder(nlbMgr *NLBManager, lisMgr *ListenerManager, sgMgr *ServerGroupManager, certMgr *CertificateManager) *ModelBuilder {
 	return &ModelBuilder{
-		NLBMgr: nlbMgr,
-		LisMgr: lisMgr,
-		SGMgr:  sgMgr,
+		NLBMgr:  nlbMgr,
+		LisMgr:  lisMgr,
+		SGMgr:   sgMgr,
+		CertMgr: certMgr,
 	}
 }
 
@@ -77,6 +79,9 @@ func (c localModel) Build(reqCtx *svcCtx.RequestContext) (*nlbmodel.NetworkLoadB
 	if err := c.SGMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
 		return nil, fmt.Errorf("builid nlb listener error: %s", err.Error())
 	}
+	if err := c.CertMgr.BuildLocalModel(reqCtx, lbMdl); err != nil {
+		return nil, fmt.Errorf("build nlb certificates error: %s", err.Error())
+	}
 
 	return lbMdl, nil
 }
@@ -58,8 +58,9 @@ func newReconciler(mgr manager.Manager, ctx *shared.SharedContext) (*ReconcileNL
 	if err != nil {
 		return nil, fmt.Errorf("NewServerGroupManager error:%s", err.Error())
 	}
-	recon.builder = NewModelBuilder(nlbManager, listenerManager, serverGroupManager)
-	recon.applier = NewModelApplier(nlbManager, listenerManager, serverGroupManager)
+	certificateManager := NewCertificateManager(recon.kubeClient, recon.cloud)
+	recon.builder = NewModelBuilder(nlbManager, listenerManager, serverGroupManager, certificateManager)
+	recon.applier = NewModelApplier(nlbManager, listenerManager, serverGroupManager, certificateManager)
 	return recon, nil
 }
 
@@ -104,6 +105,11 @@ func add(mgr manager.Manager, r *ReconcileNLB) error {
 		return fmt.Errorf("watch resource endpoint error: %s", err.Error())
 	}
 
+	if err := c.Watch(&source.Kind{Type: &v1.Secret{}},
+		NewEnqueueRequestForSecretEvent(mgr.GetEventRecorderFor("nlb-controller"))); err != nil {
+		return fmt.Errorf("watch resource secret error: %s", err.Error())
+	}
+
 	if err := c.Watch(&source.Kind{Type: &v1.Node{}},
 		NewEnqueueRequestForNodeEvent(mgr.GetEventRecorderFor("nlb-controller"))); err != nil {
 		return fmt.Errorf("watch resource node error: %s", err.Error())
@@ -159,6 +165,17 @@ func (m *ReconcileNLB) reconcile(request reconcile.Request) error {
 
 	klog.Infof("%s: ensure loadbalancer with service details, \n%+v", util.Key(svc), util.PrettyJson(svc))
 
+	if holder := helper.GetLoadBalancerClassHolder(svc); holder != "" {
+		reqCtx.Log.Info("load balancer class changed on a live service, skip")
+		// both controllers skip the service, only the one holding the load balancer reports it
+		if holder == helper.NLBFinalizer {
+			m.record.Event(svc, v1.EventTypeWarning, helper.ClassChangeForbidden,
+				fmt.Sprintf("The load balancer class of the service can not be changed to %q while its load balancer exists, "+
+					"change it back or recreate the service", helper.GetLoadBalancerClass(svc)))
+		}
+		return nil
+	}
+
 	if helper.NeedDeleteLoadBalancer(svc) {
 		err = m.cleanupLoadBalancerResources(reqCtx)
 	} else {
@@ -237,6 +254,10 @@ func (m *ReconcileNLB) reconcileLoadBalancerResources(req *svcCtx.RequestContext
 		return err
 	}
 
+	if lb.ContainsPotentialReadyEndpoints {
+		return fmt.Errorf("retry potential ready endpoints")
+	}
+
 	m.record.Event(req.Service, v1.EventTypeNormal, helper.SucceedSyncLB,
 		fmt.Sprintf("Ensured load balancer [%s]", lb.LoadBalancerAttribute.LoadBalancerId))
 	return nil
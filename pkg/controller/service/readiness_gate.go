@@ -0,0 +1,166 @@
+package service
+
+import (
+	"fmt"
+
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	nlbmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/nlb"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reasons of the NLB readiness gate condition
+const (
+	serverHealthy    = "ServerHealthy"
+	serverNotHealthy = "ServerNotHealthy"
+)
+
+// readinessGateServer is a backend of a server group whose pod waits for the NLB readiness gate
+type readinessGateServer struct {
+	server nlbmodel.ServerGroupServer
+	pod    *v1.Pod
+}
+
+// UpdateReadinessGates sets the NLB readiness gate condition of the pods added to the server groups of the local
+// model. A pod is ready once all the listeners using its server group report it healthy, or once it is added if
+// no listener checks its health. It returns true if some pods are still waiting for the health check.
+func (mgr *ServerGroupManager) UpdateReadinessGates(reqCtx *svcCtx.RequestContext, local, remote *nlbmodel.NetworkLoadBalancer) (bool, error) {
+	waiting, err := mgr.listReadinessGateServers(reqCtx, local)
+	if err != nil {
+		return false, err
+	}
+	if len(waiting) == 0 || remote.LoadBalancerAttribute.LoadBalancerId == "" {
+		return false, nil
+	}
+
+	listeners, err := mgr.cloud.ListNLBListeners(reqCtx.Ctx, remote.LoadBalancerAttribute.LoadBalancerId)
+	if err != nil {
+		return false, fmt.Errorf("ListNLBListeners error: %s", err.Error())
+	}
+	var statuses []nlbmodel.ServerGroupHealthStatus
+	for _, lis := range listeners {
+		if _, ok := waiting[lis.ServerGroupId]; !ok {
+			continue
+		}
+		ret, err := mgr.cloud.GetNLBListenerHealthStatus(reqCtx.Ctx, lis.ListenerId)
+		if err != nil {
+			return false, fmt.Errorf("GetNLBListenerHealthStatus error: %s", err.Error())
+		}
+		statuses = append(statuses, ret...)
+	}
+
+	pending := false
+	for sgId, servers := range waiting {
+		for _, s := range servers {
+			status, healthy := getServerHealthStatus(statuses, sgId, s.server)
+			if !healthy {
+				pending = true
+			}
+			if err := mgr.updateReadinessGateCondition(reqCtx, s.pod, healthy, status); err != nil {
+				return false, fmt.Errorf("update readiness gate of pod %s/%s error: %s",
+					s.pod.Namespace, s.pod.Name, err.Error())
+			}
+		}
+	}
+	return pending, nil
+}
+
+// listReadinessGateServers returns the ENI backends by server group id whose pods have the NLB readiness gate
+// and whose readiness gate condition is not true
+func (mgr *ServerGroupManager) listReadinessGateServers(reqCtx *svcCtx.RequestContext, local *nlbmodel.NetworkLoadBalancer,
+) (map[string][]readinessGateServer, error) {
+	waiting := make(map[string][]readinessGateServer)
+	for _, sg := range local.ServerGroups {
+		if sg.ServerGroupId == "" {
+			continue
+		}
+		for _, s := range sg.Servers {
+			if s.ServerType != nlbmodel.EniServerType || s.Draining || s.TargetRef == nil || s.TargetRef.Kind != "Pod" {
+				continue
+			}
+			pod := &v1.Pod{}
+			key := types.NamespacedName{Namespace: s.TargetRef.Namespace, Name: s.TargetRef.Name}
+			if err := mgr.kubeClient.Get(reqCtx.Ctx, key, pod); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			if !helper.IsPodHasNLBReadinessGate(pod) {
+				continue
+			}
+			cond := helper.GetPodCondition(pod, helper.NLBTargetHealthPodConditionType)
+			if cond != nil && cond.Status == v1.ConditionTrue {
+				continue
+			}
+			waiting[sg.ServerGroupId] = append(waiting[sg.ServerGroupId], readinessGateServer{server: s, pod: pod})
+		}
+	}
+	return waiting, nil
+}
+
+// getServerHealthStatus returns the status of the server reported by the listeners and whether it is healthy.
+// The server is healthy if none of the listeners using the server group reports it as a non-normal server.
+func getServerHealthStatus(statuses []nlbmodel.ServerGroupHealthStatus, sgId string, server nlbmodel.ServerGroupServer,
+) (string, bool) {
+	for _, status := range statuses {
+		if status.ServerGroupId != sgId || !status.HealthCheckEnabled {
+			continue
+		}
+		for _, s := range status.NonNormalServers {
+			if s.ServerId == server.ServerId && s.ServerIp == server.ServerIp {
+				return s.Status, false
+			}
+		}
+	}
+	return "", true
+}
+
+func (mgr *ServerGroupManager) updateReadinessGateCondition(reqCtx *svcCtx.RequestContext, pod *v1.Pod, healthy bool,
+	status string) error {
+	cond := v1.PodCondition{
+		Type:    helper.NLBTargetHealthPodConditionType,
+		Status:  v1.ConditionTrue,
+		Reason:  serverHealthy,
+		Message: "the backend is healthy in the network load balancer",
+	}
+	if !healthy {
+		cond.Status = v1.ConditionFalse
+		cond.Reason = serverNotHealthy
+		cond.Message = fmt.Sprintf("the health status of the backend in the network load balancer is %s", status)
+	}
+
+	existing := helper.GetPodCondition(pod, cond.Type)
+	if existing != nil && existing.Status == cond.Status && existing.Reason == cond.Reason && existing.Message == cond.Message {
+		return nil
+	}
+	if existing == nil || existing.Status != cond.Status {
+		cond.LastTransitionTime = metav1.Now()
+	} else {
+		cond.LastTransitionTime = existing.LastTransitionTime
+	}
+
+	patch, err := helper.BuildPodConditionPatch(pod, cond)
+	if err != nil {
+		return err
+	}
+	k8sPod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       pod.UID,
+		},
+	}
+	if err := mgr.kubeClient.Status().Patch(reqCtx.Ctx, k8sPod, patch); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	reqCtx.Log.Info("update readiness gate of pod", "pod", fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+		"status", cond.Status, "reason", cond.Reason)
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	reconbackend "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/backend"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	nlbmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/nlb"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// healthStatusCloud reports the health status of the listeners of an NLB
+type healthStatusCloud struct {
+	prvd.Provider
+	listeners []*nlbmodel.ListenerAttribute
+	statuses  map[string][]nlbmodel.ServerGroupHealthStatus
+}
+
+func (c healthStatusCloud) ListNLBListeners(ctx context.Context, lbId string) ([]*nlbmodel.ListenerAttribute, error) {
+	return c.listeners, nil
+}
+
+func (c healthStatusCloud) GetNLBListenerHealthStatus(ctx context.Context, listenerId string) ([]nlbmodel.ServerGroupHealthStatus, error) {
+	return c.statuses[listenerId], nil
+}
+
+func readinessGatePod(name string, conditions ...v1.PodCondition) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name)},
+		Spec: v1.PodSpec{ReadinessGates: []v1.PodReadinessGate{
+			{ConditionType: helper.NLBTargetHealthPodConditionType},
+		}},
+		Status: v1.PodStatus{Conditions: conditions},
+	}
+}
+
+func eniServer(pod, ip string) nlbmodel.ServerGroupServer {
+	return nlbmodel.ServerGroupServer{
+		ServerId:   "eni-" + pod,
+		ServerIp:   ip,
+		ServerType: nlbmodel.EniServerType,
+		TargetRef:  &v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod},
+	}
+}
+
+func TestUpdateReadinessGates(t *testing.T) {
+	ready := readinessGatePod("ready", v1.PodCondition{Type: helper.NLBTargetHealthPodConditionType, Status: v1.ConditionTrue})
+	kubeClient := fake.NewClientBuilder().WithRuntimeObjects(
+		readinessGatePod("healthy"),
+		readinessGatePod("unhealthy"),
+		ready,
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "no-gate"}},
+	).Build()
+	mgr := &ServerGroupManager{
+		kubeClient: kubeClient,
+		cloud: healthStatusCloud{
+			listeners: []*nlbmodel.ListenerAttribute{{ListenerId: "lsn-80", ServerGroupId: "sgp-80"}},
+			statuses: map[string][]nlbmodel.ServerGroupHealthStatus{
+				"lsn-80": {{
+					ServerGroupId:      "sgp-80",
+					HealthCheckEnabled: true,
+					NonNormalServers: []nlbmodel.ServerGroupServer{
+						{ServerId: "eni-unhealthy", ServerIp: "10.0.0.2", Status: "Initial"},
+					},
+				}},
+			},
+		},
+	}
+	reqCtx := &svcCtx.RequestContext{Ctx: context.TODO(), Log: logr.Discard()}
+	local := &nlbmodel.NetworkLoadBalancer{ServerGroups: []*nlbmodel.ServerGroup{{
+		ServerGroupId: "sgp-80",
+		Servers: []nlbmodel.ServerGroupServer{
+			eniServer("healthy", "10.0.0.1"),
+			eniServer("unhealthy", "10.0.0.2"),
+			eniServer("ready", "10.0.0.3"),
+			eniServer("no-gate", "10.0.0.4"),
+		},
+	}}}
+	remote := &nlbmodel.NetworkLoadBalancer{LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{LoadBalancerId: "nlb-id"}}
+
+	pending, err := mgr.UpdateReadinessGates(reqCtx, local, remote)
+	assert.NoError(t, err)
+	assert.True(t, pending)
+
+	conditionOf := func(name string) *v1.PodCondition {
+		pod := &v1.Pod{}
+		assert.NoError(t, kubeClient.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: name}, pod))
+		return helper.GetPodCondition(pod, helper.NLBTargetHealthPodConditionType)
+	}
+	if cond := conditionOf("healthy"); assert.NotNil(t, cond) {
+		assert.Equal(t, v1.ConditionTrue, cond.Status)
+	}
+	if cond := conditionOf("unhealthy"); assert.NotNil(t, cond) {
+		assert.Equal(t, v1.ConditionFalse, cond.Status)
+		assert.Equal(t, serverNotHealthy, cond.Reason)
+	}
+	assert.Nil(t, conditionOf("no-gate"))
+}
+
+func TestGetServerHealthStatus(t *testing.T) {
+	server := nlbmodel.ServerGroupServer{ServerId: "eni-1", ServerIp: "10.0.0.1"}
+	unhealthy := []nlbmodel.ServerGroupServer{{ServerId: "eni-1", ServerIp: "10.0.0.1", Status: "Unhealthy"}}
+
+	status, healthy := getServerHealthStatus(nil, "sgp-1", server)
+	assert.True(t, healthy)
+	assert.Equal(t, "", status)
+
+	status, healthy = getServerHealthStatus([]nlbmodel.ServerGroupHealthStatus{
+		{ServerGroupId: "sgp-1", HealthCheckEnabled: true, NonNormalServers: unhealthy},
+	}, "sgp-1", server)
+	assert.False(t, healthy)
+	assert.Equal(t, "Unhealthy", status)
+
+	// the status of another server group or with health check disabled is ignored
+	_, healthy = getServerHealthStatus([]nlbmodel.ServerGroupHealthStatus{
+		{ServerGroupId: "sgp-2", HealthCheckEnabled: true, NonNormalServers: unhealthy},
+		{ServerGroupId: "sgp-1", NonNormalServers: unhealthy},
+	}, "sgp-1", server)
+	assert.True(t, healthy)
+}
+
+func TestSetBackendsFromEndpointsWithReadinessGate(t *testing.T) {
+	podRef := func(name string) *v1.ObjectReference {
+		return &v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: name}
+	}
+	candidates := &reconbackend.EndpointWithENI{
+		Endpoints: &v1.Endpoints{Subsets: []v1.EndpointSubset{{
+			Addresses: []v1.EndpointAddress{{IP: "10.0.0.1", TargetRef: podRef("ready")}},
+			NotReadyAddresses: []v1.EndpointAddress{
+				{IP: "10.0.0.2", TargetRef: podRef("gated")},
+				{IP: "10.0.0.3", TargetRef: podRef("not-ready")},
+			},
+		}}},
+		ReadinessGatePods: sets.NewString("default/gated"),
+	}
+	sg := nlbmodel.ServerGroup{ServerGroupName: "sg", ServicePort: &v1.ServicePort{TargetPort: intstr.FromInt(80)}}
+
+	backends := setBackendsFromEndpoints(candidates, sg)
+	var ips []string
+	for _, b := range backends {
+		ips = append(ips, b.ServerIp)
+		assert.NotNil(t, b.TargetRef)
+	}
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, ips)
+}
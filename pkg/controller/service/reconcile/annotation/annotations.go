@@ -103,11 +103,17 @@ const (
 	ZoneMaps = AnnotationLoadBalancerPrefix + "zone-maps" // ZoneMaps zone maps
 
 	ProxyProtocol = AnnotationLoadBalancerPrefix + "proxy-protocol"
-	CaCertID      = AnnotationLoadBalancerPrefix + "cacert-id" // CertID cert id
-	CaCert        = AnnotationLoadBalancerPrefix + "cacert"    // CaCert enable ca
+	CaCertID      = AnnotationLoadBalancerPrefix + "cacert-id"     // CertID cert id
+	CaCert        = AnnotationLoadBalancerPrefix + "cacert"        // CaCert enable ca
+	CertSecret    = AnnotationLoadBalancerPrefix + "cert-secret"   // CertSecret kubernetes.io/tls secret of the tcpssl listeners
+	CaCertSecret  = AnnotationLoadBalancerPrefix + "cacert-secret" // CaCertSecret secret of the ca bundle in ca.crt
 	Cps           = AnnotationLoadBalancerPrefix + "cps"
 
+	ListenerPortRange = AnnotationLoadBalancerPrefix + "listener-port-range" // ListenerPortRange build a single listener on the port range, e.g. 30000-31000
+
 	PreserveClientIp = AnnotationLoadBalancerPrefix + "preserve-client-ip"
+
+	TopologyAwareWeight = AnnotationLoadBalancerPrefix + "topology-aware-weight" // TopologyAwareWeight balance the backend weights by zone
 )
 
 var DefaultValue = map[string]string{
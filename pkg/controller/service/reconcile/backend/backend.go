@@ -14,6 +14,8 @@ import (
 	v1 "k8s.io/api/core/v1"
 	discovery "k8s.io/api/discovery/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -46,6 +48,15 @@ func NewEndpointWithENI(reqCtx *svcCtx.RequestContext, kubeClient client.Client)
 		reqCtx.Log.Info("backend details", "endpoints", helper.LogEndpoints(eps))
 	}
 
+	if endpointWithENI.TrafficPolicy == helper.ENITrafficPolicy {
+		if err := endpointWithENI.setReadinessGatePods(reqCtx, kubeClient); err != nil {
+			return nil, fmt.Errorf("get readiness gate pods error: %s", err.Error())
+		}
+		if err := endpointWithENI.setTerminatingPods(reqCtx, kubeClient); err != nil {
+			return nil, fmt.Errorf("get terminating pods error: %s", err.Error())
+		}
+	}
+
 	return endpointWithENI, nil
 }
 
@@ -70,6 +81,17 @@ type EndpointWithENI struct {
 	// EndpointSlices
 	// contains all the endpointslices of a service
 	EndpointSlices []discovery.EndpointSlice
+	// ReadinessGatePods
+	// contains the keys of the not ready pods which wait for the NLB readiness gate and whose containers are ready.
+	// They are added to the server groups in ENI mode, so that the pods become ready once the NLB reports them healthy.
+	ReadinessGatePods sets.String
+	// TerminatingPods
+	// contains the terminating pods which are still serving by namespace/name key. They are kept in the server
+	// groups with weight 0 in ENI mode until the connection drain timeout elapses.
+	TerminatingPods map[string]*v1.Pod
+	// ContainsPotentialReadyEndpoints
+	// it is true if some not ready pods wait for the NLB readiness gate but their containers are not ready yet.
+	ContainsPotentialReadyEndpoints bool
 }
 
 func (e *EndpointWithENI) setTrafficPolicy(reqCtx *svcCtx.RequestContext) {
@@ -98,6 +120,93 @@ func (e *EndpointWithENI) setAddressIpVersion(reqCtx *svcCtx.RequestContext) {
 	e.AddressIPVersion = model.IPv4
 }
 
+func (e *EndpointWithENI) setReadinessGatePods(reqCtx *svcCtx.RequestContext, kubeClient client.Client) error {
+	var refs []*v1.ObjectReference
+	if e.Endpoints != nil {
+		for _, subset := range e.Endpoints.Subsets {
+			for i := range subset.NotReadyAddresses {
+				refs = append(refs, subset.NotReadyAddresses[i].TargetRef)
+			}
+		}
+	}
+	for _, es := range e.EndpointSlices {
+		for _, ep := range es.Endpoints {
+			if ep.Conditions.Terminating != nil && *ep.Conditions.Terminating {
+				continue
+			}
+			if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+				refs = append(refs, ep.TargetRef)
+			}
+		}
+	}
+
+	e.ReadinessGatePods = sets.NewString()
+	for _, ref := range refs {
+		if ref == nil || ref.Kind != "Pod" {
+			continue
+		}
+		pod := &v1.Pod{}
+		if err := kubeClient.Get(reqCtx.Ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, pod); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !helper.IsPodHasNLBReadinessGate(pod) {
+			continue
+		}
+		if !helper.IsPodContainersReady(pod) {
+			e.ContainsPotentialReadyEndpoints = true
+			continue
+		}
+		e.ReadinessGatePods.Insert(util.NamespacedName(pod).String())
+	}
+	return nil
+}
+
+// setTerminatingPods gets the pods of the terminating endpoints which are still serving. Only the EndpointSlices
+// report whether a terminating endpoint is serving.
+func (e *EndpointWithENI) setTerminatingPods(reqCtx *svcCtx.RequestContext, kubeClient client.Client) error {
+	e.TerminatingPods = make(map[string]*v1.Pod)
+	for _, es := range e.EndpointSlices {
+		for _, ep := range es.Endpoints {
+			if ep.Conditions.Terminating == nil || !*ep.Conditions.Terminating ||
+				ep.Conditions.Serving == nil || !*ep.Conditions.Serving {
+				continue
+			}
+			if ep.TargetRef == nil || ep.TargetRef.Kind != "Pod" {
+				continue
+			}
+			pod := &v1.Pod{}
+			key := types.NamespacedName{Namespace: ep.TargetRef.Namespace, Name: ep.TargetRef.Name}
+			if err := kubeClient.Get(reqCtx.Ctx, key, pod); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			e.TerminatingPods[key.String()] = pod
+		}
+	}
+	return nil
+}
+
+// TerminatingPod returns the pod of the terminating endpoint which is still serving
+func (e *EndpointWithENI) TerminatingPod(ref *v1.ObjectReference) *v1.Pod {
+	if ref == nil || ref.Kind != "Pod" {
+		return nil
+	}
+	return e.TerminatingPods[types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}.String()]
+}
+
+// IsReadinessGatePod returns whether the not ready endpoint is a pod waiting for the NLB readiness gate
+func (e *EndpointWithENI) IsReadinessGatePod(ref *v1.ObjectReference) bool {
+	if ref == nil || ref.Kind != "Pod" {
+		return false
+	}
+	return e.ReadinessGatePods.Has(types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}.String())
+}
+
 func GetNodes(reqCtx *svcCtx.RequestContext, client client.Client) ([]v1.Node, error) {
 	nodeList := v1.NodeList{}
 	err := client.List(reqCtx.Ctx, &nodeList)
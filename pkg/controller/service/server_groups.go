@@ -23,6 +23,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/cache"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -72,8 +73,9 @@ func (mgr *ServerGroupManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, md
 			Tags:        getServerGroupTag(reqCtx),
 			Protocol:    nlbmodel.GetListenerProtocolType(lis.ListenerProtocol),
 		}
-		sg.NamedKey = getServerGroupNamedKey(reqCtx.Service, sg.Protocol, lis.ServicePort)
+		sg.NamedKey = getListenerServerGroupNamedKey(reqCtx.Service, lis)
 		sg.ServerGroupName = sg.NamedKey.Key()
+		sg.AnyPortEnabled = lis.IsPortRange()
 		if err := setServerGroupAttributeFromAnno(sg, reqCtx.Anno); err != nil {
 			return err
 		}
@@ -83,6 +85,18 @@ func (mgr *ServerGroupManager) BuildLocalModel(reqCtx *svcCtx.RequestContext, md
 		sgs = append(sgs, sg)
 	}
 	mdl.ServerGroups = sgs
+	if isTopologyAwareWeight(reqCtx) {
+		warnUnservedBackendZones(reqCtx, candidates, mdl)
+	}
+	mdl.ContainsPotentialReadyEndpoints = candidates.ContainsPotentialReadyEndpoints
+	// retry until the draining backends are removed
+	for _, sg := range sgs {
+		for _, s := range sg.Servers {
+			if s.Draining {
+				mdl.ContainsPotentialReadyEndpoints = true
+			}
+		}
+	}
 	return nil
 }
 
@@ -249,6 +263,33 @@ update:
 	return mgr.updateServerGroupServers(reqCtx, local, remote)
 }
 
+// BuildServers sets the servers of a server group which is not created for the service, such as the ones
+// bound by ServerGroupBinding, by the endpoints of the service.
+func (mgr *ServerGroupManager) BuildServers(reqCtx *svcCtx.RequestContext, sg *nlbmodel.ServerGroup,
+	candidates *reconbackend.EndpointWithENI) error {
+	return mgr.setServerGroupServers(reqCtx, sg, candidates)
+}
+
+// SyncServers updates the servers of the existing server group to the ones of local. Only the servers described by
+// the name of local are updated or removed, the server group may be shared with other bindings and clusters.
+func (mgr *ServerGroupManager) SyncServers(reqCtx *svcCtx.RequestContext, local *nlbmodel.ServerGroup) error {
+	servers, err := mgr.cloud.ListNLBServers(reqCtx.Ctx, local.ServerGroupId)
+	if err != nil {
+		return fmt.Errorf("ListNLBServers error: %s", err.Error())
+	}
+	for i := range servers {
+		if servers[i].Description != local.ServerGroupName {
+			servers[i].IsUserManaged = true
+		}
+	}
+	remote := &nlbmodel.ServerGroup{
+		ServerGroupId:   local.ServerGroupId,
+		ServerGroupName: local.ServerGroupName,
+		Servers:         servers,
+	}
+	return mgr.updateServerGroupServers(reqCtx, local, remote)
+}
+
 func (mgr *ServerGroupManager) updateServerGroupServers(reqCtx *svcCtx.RequestContext, local, remote *nlbmodel.ServerGroup) error {
 	add, del, update := diff(remote, local)
 	if len(add) == 0 && len(del) == 0 && len(update) == 0 {
@@ -350,6 +391,15 @@ func (mgr *ServerGroupManager) setServerGroupServers(reqCtx *svcCtx.RequestConte
 		return fmt.Errorf("not supported traffic policy [%s]", candidates.TrafficPolicy)
 	}
 
+	if isTopologyAwareWeight(reqCtx) {
+		setZoneBalancedWeights(candidates, backends)
+	}
+	if sg.AnyPortEnabled {
+		for i := range backends {
+			backends[i].Port = 0
+		}
+	}
+
 	if len(backends) == 0 {
 		reqCtx.Recorder.Event(
 			reqCtx.Service,
@@ -394,10 +444,18 @@ func setBackendsFromEndpoints(candidates *reconbackend.EndpointWithENI, sg nlbmo
 			}
 		}
 
-		for _, addr := range ep.Addresses {
+		addrs := ep.Addresses
+		// the pods waiting for the readiness gate are not ready until the NLB reports them healthy
+		for _, addr := range ep.NotReadyAddresses {
+			if candidates.IsReadinessGatePod(addr.TargetRef) {
+				addrs = append(addrs, addr)
+			}
+		}
+		for _, addr := range addrs {
 			backends = append(backends, nlbmodel.ServerGroupServer{
-				NodeName: addr.NodeName,
-				ServerIp: addr.IP,
+				NodeName:  addr.NodeName,
+				TargetRef: addr.TargetRef,
+				ServerIp:  addr.IP,
 				// set backend port to targetPort by default
 				// if backend type is ecs, update backend port to nodePort
 				Port:        backendPort,
@@ -417,6 +475,7 @@ func setBackendsFromEndpointSlices(candidates *reconbackend.EndpointWithENI, sg
 	if len(candidates.EndpointSlices) == 0 {
 		return nil
 	}
+	now := time.Now()
 
 	for _, es := range candidates.EndpointSlices {
 		var backendPort int32
@@ -441,8 +500,18 @@ func setBackendsFromEndpointSlices(candidates *reconbackend.EndpointWithENI, sg
 			if ep.Conditions.Ready == nil {
 				continue
 			}
+			draining := false
 			if !*ep.Conditions.Ready {
-				continue
+				// a terminating pod still serving is drained before it is removed
+				if pod := candidates.TerminatingPod(ep.TargetRef); pod != nil {
+					if !tea.BoolValue(sg.ConnectionDrainEnabled) ||
+						!helper.IsPodDraining(pod, int(sg.ConnectionDrainTimeout), now) {
+						continue
+					}
+					draining = true
+				} else if !candidates.IsReadinessGatePod(ep.TargetRef) {
+					continue
+				}
 			}
 
 			for _, addr := range ep.Addresses {
@@ -453,8 +522,10 @@ func setBackendsFromEndpointSlices(candidates *reconbackend.EndpointWithENI, sg
 				// NodeName of endpoint is nil, use topology.hostname instead of NodeName
 				hostName := ep.Topology[v1.LabelHostname]
 				backends = append(backends, nlbmodel.ServerGroupServer{
-					NodeName: &hostName,
-					ServerIp: addr,
+					NodeName:  &hostName,
+					TargetRef: ep.TargetRef,
+					Draining:  draining,
+					ServerIp:  addr,
 					// set backend port to targetPort by default
 					// if backend type is ecs, update backend port to nodePort
 					Port:        backendPort,
@@ -478,7 +549,20 @@ func (mgr *ServerGroupManager) buildENIBackends(candidates *reconbackend.Endpoin
 		return backends, err
 	}
 
-	return setWeightBackends(helper.ENITrafficPolicy, backends, sg.Weight), nil
+	// the draining backends do not receive new connections
+	var serving, draining []nlbmodel.ServerGroupServer
+	for _, b := range backends {
+		if b.Draining {
+			b.Weight = 0
+			draining = append(draining, b)
+			continue
+		}
+		serving = append(serving, b)
+	}
+	if len(serving) != 0 {
+		serving = setWeightBackends(helper.ENITrafficPolicy, serving, sg.Weight)
+	}
+	return append(serving, draining...), nil
 }
 
 func (mgr *ServerGroupManager) buildLocalBackends(reqCtx *svcCtx.RequestContext, candidates *reconbackend.EndpointWithENI,
@@ -628,6 +712,7 @@ func (mgr *ServerGroupManager) buildClusterBackends(reqCtx *svcCtx.RequestContex
 		ecsBackends = append(
 			ecsBackends,
 			nlbmodel.ServerGroupServer{
+				NodeName:    tea.String(node.Name),
 				ServerId:    id,
 				Weight:      DefaultServerWeight,
 				Port:        sg.ServicePort.NodePort,
@@ -772,6 +857,64 @@ func podPercentAlgorithm(mode helper.TrafficPolicy, backends []nlbmodel.ServerGr
 	return backends
 }
 
+func isTopologyAwareWeight(reqCtx *svcCtx.RequestContext) bool {
+	return strings.EqualFold(reqCtx.Anno.Get(annotation.TopologyAwareWeight), string(model.OnFlag))
+}
+
+// setZoneBalancedWeights balances the weights of the backends by the zones of their nodes, so that every zone
+// receives the same share of the traffic. The draining backends keep weight 0.
+func setZoneBalancedWeights(candidates *reconbackend.EndpointWithENI, backends []nlbmodel.ServerGroupServer) {
+	var (
+		weights []int
+		zones   []string
+	)
+	for _, b := range backends {
+		weights = append(weights, int(b.Weight))
+		zones = append(zones, backendZone(candidates, b))
+	}
+	for i, w := range helper.BalanceWeightsByZone(weights, zones, DefaultServerWeight) {
+		backends[i].Weight = int32(w)
+	}
+}
+
+func backendZone(candidates *reconbackend.EndpointWithENI, b nlbmodel.ServerGroupServer) string {
+	if b.NodeName == nil {
+		return ""
+	}
+	return helper.GetNodeZone(helper.FindNodeByNodeName(candidates.Nodes, *b.NodeName))
+}
+
+// warnUnservedBackendZones records an event if the backends sit in the zones which the zone mappings of the
+// network load balancer do not include
+func warnUnservedBackendZones(reqCtx *svcCtx.RequestContext, candidates *reconbackend.EndpointWithENI,
+	mdl *nlbmodel.NetworkLoadBalancer) {
+	if mdl.LoadBalancerAttribute == nil || len(mdl.LoadBalancerAttribute.ZoneMappings) == 0 {
+		return
+	}
+	served := sets.NewString()
+	for _, z := range mdl.LoadBalancerAttribute.ZoneMappings {
+		served.Insert(z.ZoneId)
+	}
+	var zones []string
+	for _, sg := range mdl.ServerGroups {
+		for _, b := range sg.Servers {
+			if b.Draining {
+				continue
+			}
+			zones = append(zones, backendZone(candidates, b))
+		}
+	}
+	if unserved := helper.UnservedZones(zones, served); len(unserved) != 0 {
+		reqCtx.Recorder.Event(
+			reqCtx.Service,
+			v1.EventTypeWarning,
+			helper.UnservedBackendZones,
+			fmt.Sprintf("Backends in zones %v are not served by the zone mappings %v of the NetworkLoadBalancer",
+				unserved, served.List()),
+		)
+	}
+}
+
 func getServerGroupNamedKey(svc *v1.Service, protocol string, servicePort *v1.ServicePort) *nlbmodel.SGNamedKey {
 	sgPort := ""
 	if helper.IsENIBackendType(svc) {
@@ -795,6 +938,16 @@ func getServerGroupNamedKey(svc *v1.Service, protocol string, servicePort *v1.Se
 		SGGroupPort: sgPort}
 }
 
+// getListenerServerGroupNamedKey returns the named key of the server group of the listener, the server group of a port
+// range listener is named by the port range.
+func getListenerServerGroupNamedKey(svc *v1.Service, lis *nlbmodel.ListenerAttribute) *nlbmodel.SGNamedKey {
+	key := getServerGroupNamedKey(svc, nlbmodel.GetListenerProtocolType(lis.ListenerProtocol), lis.ServicePort)
+	if lis.IsPortRange() {
+		key.SGGroupPort = lis.PortString()
+	}
+	return key
+}
+
 func getServerGroupTag(reqCtx *svcCtx.RequestContext) []tag.Tag {
 	return []tag.Tag{
 		{
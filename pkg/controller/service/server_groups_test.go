@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alibabacloud-go/tea/tea"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	reconbackend "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/backend"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	nlbmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/nlb"
+	prvd "k8s.io/alibaba-load-balancer-controller/pkg/provider"
+	v1 "k8s.io/api/core/v1"
+	discovery "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSetBackendsFromEndpointSlicesWithDraining(t *testing.T) {
+	// the pods are requested to terminate since the duration with a grace period of 60 seconds
+	terminatingPod := func(since time.Duration) *v1.Pod {
+		deletion := metav1.NewTime(time.Now().Add(-since).Add(60 * time.Second))
+		grace := int64(60)
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &deletion, DeletionGracePeriodSeconds: &grace}}
+	}
+	endpoint := func(ip, pod string, ready, serving, terminating bool) discovery.Endpoint {
+		return discovery.Endpoint{
+			Addresses:  []string{ip},
+			Conditions: discovery.EndpointConditions{Ready: &ready, Serving: &serving, Terminating: &terminating},
+			TargetRef:  &v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: pod},
+		}
+	}
+	candidates := &reconbackend.EndpointWithENI{
+		EndpointSlices: []discovery.EndpointSlice{{Endpoints: []discovery.Endpoint{
+			endpoint("10.0.0.1", "ready", true, true, false),
+			endpoint("10.0.0.2", "draining", false, true, true),
+			endpoint("10.0.0.3", "drained", false, true, true),
+			endpoint("10.0.0.4", "stopped", false, false, true),
+		}}},
+		ReadinessGatePods: sets.NewString(),
+		TerminatingPods: map[string]*v1.Pod{
+			"default/draining": terminatingPod(10 * time.Second),
+			"default/drained":  terminatingPod(60 * time.Second),
+		},
+	}
+	sg := nlbmodel.ServerGroup{
+		ServerGroupName:        "sg",
+		ServicePort:            &v1.ServicePort{TargetPort: intstr.FromInt(80)},
+		ConnectionDrainEnabled: tea.Bool(true),
+		ConnectionDrainTimeout: 30,
+	}
+
+	backends := setBackendsFromEndpointSlices(candidates, sg)
+	draining := make(map[string]bool)
+	for _, b := range backends {
+		draining[b.ServerIp] = b.Draining
+	}
+	assert.Equal(t, map[string]bool{"10.0.0.1": false, "10.0.0.2": true}, draining)
+
+	// the terminating pods are removed at once if the connection drain is disabled
+	sg.ConnectionDrainEnabled = nil
+	backends = setBackendsFromEndpointSlices(candidates, sg)
+	if assert.Equal(t, 1, len(backends)) {
+		assert.Equal(t, "10.0.0.1", backends[0].ServerIp)
+	}
+}
+
+func TestTopologyAwareWeight(t *testing.T) {
+	node := func(name, zone string) v1.Node {
+		return v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelTopologyZone: zone}}}
+	}
+	server := func(ip, node string, weight int32) nlbmodel.ServerGroupServer {
+		return nlbmodel.ServerGroupServer{ServerIp: ip, NodeName: tea.String(node), Weight: weight}
+	}
+	candidates := &reconbackend.EndpointWithENI{
+		Nodes: []v1.Node{node("node-a", "cn-hangzhou-a"), node("node-b", "cn-hangzhou-b"), node("node-c", "cn-hangzhou-c")},
+	}
+	backends := []nlbmodel.ServerGroupServer{
+		server("10.0.0.1", "node-a", 100),
+		server("10.0.0.2", "node-b", 100),
+		server("10.0.0.3", "node-b", 100),
+		server("10.0.0.4", "node-c", 100),
+		{ServerIp: "10.0.0.5", NodeName: tea.String("node-c"), Draining: true},
+	}
+	setZoneBalancedWeights(candidates, backends)
+	var weights []int32
+	for _, b := range backends {
+		weights = append(weights, b.Weight)
+	}
+	assert.Equal(t, []int32{100, 50, 50, 100, 0}, weights)
+
+	recorder := record.NewFakeRecorder(1)
+	reqCtx := &svcCtx.RequestContext{Service: &v1.Service{}, Recorder: recorder}
+	mdl := &nlbmodel.NetworkLoadBalancer{
+		LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{ZoneMappings: []nlbmodel.ZoneMapping{
+			{ZoneId: "cn-hangzhou-a"}, {ZoneId: "cn-hangzhou-b"},
+		}},
+		ServerGroups: []*nlbmodel.ServerGroup{{Servers: backends}},
+	}
+	warnUnservedBackendZones(reqCtx, candidates, mdl)
+	if assert.Equal(t, 1, len(recorder.Events)) {
+		assert.Contains(t, <-recorder.Events, "[cn-hangzhou-c]")
+	}
+}
+
+// serverCloud records the changes of the servers of a server group
+type serverCloud struct {
+	prvd.Provider
+	servers []nlbmodel.ServerGroupServer
+	added   []nlbmodel.ServerGroupServer
+	removed []nlbmodel.ServerGroupServer
+	updated []nlbmodel.ServerGroupServer
+}
+
+func (c *serverCloud) ListNLBServers(ctx context.Context, sgId string) ([]nlbmodel.ServerGroupServer, error) {
+	return c.servers, nil
+}
+
+func (c *serverCloud) AddNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error {
+	c.added = append(c.added, backends...)
+	return nil
+}
+
+func (c *serverCloud) RemoveNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error {
+	c.removed = append(c.removed, backends...)
+	return nil
+}
+
+func (c *serverCloud) UpdateNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error {
+	c.updated = append(c.updated, backends...)
+	return nil
+}
+
+func TestSyncSharedServers(t *testing.T) {
+	eni := func(id, description string) nlbmodel.ServerGroupServer {
+		return nlbmodel.ServerGroupServer{ServerId: id, ServerIp: id, ServerType: nlbmodel.EniServerType, Port: 80,
+			Weight: 100, Description: description}
+	}
+	remote := []nlbmodel.ServerGroupServer{
+		eni("eni-1", "k8s.sgb.tea.default.cluster-a"),
+		eni("eni-2", "k8s.sgb.tea.default.cluster-a"),
+		eni("eni-9", "k8s.sgb.tea.default.cluster-b"),
+	}
+	local := &nlbmodel.ServerGroup{
+		ServerGroupId:   "sgp-1",
+		ServerGroupName: "k8s.sgb.tea.default.cluster-a",
+		Servers:         []nlbmodel.ServerGroupServer{eni("eni-1", "k8s.sgb.tea.default.cluster-a")},
+	}
+	reqCtx := &svcCtx.RequestContext{Ctx: context.TODO(), Log: logr.Discard()}
+
+	cloud := &serverCloud{servers: append([]nlbmodel.ServerGroupServer{}, remote...)}
+	mgr := &ServerGroupManager{cloud: cloud}
+	assert.NoError(t, mgr.SyncServers(reqCtx, local))
+	if assert.Equal(t, 1, len(cloud.removed)) {
+		assert.Equal(t, "eni-2", cloud.removed[0].ServerId)
+	}
+
+	// the servers added outside the cluster are kept
+	cloud = &serverCloud{servers: append([]nlbmodel.ServerGroupServer{eni("eni-8", "")}, remote...)}
+	mgr = &ServerGroupManager{cloud: cloud}
+	assert.NoError(t, mgr.SyncServers(reqCtx, local))
+	if assert.Equal(t, 1, len(cloud.removed)) {
+		assert.Equal(t, "eni-2", cloud.removed[0].ServerId)
+	}
+}
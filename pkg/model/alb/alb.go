@@ -33,6 +33,7 @@ type ALBListenerSpec struct {
 	DefaultActions      []Action            `json:"DefaultActions" xml:"DefaultActions"`
 	Certificates        []Certificate       `json:"Certificates" xml:"Certificates"`
 	CaCertificates      []Certificate       `json:"CaCertificates" xml:"CaCertificates"`
+	CaEnabled           bool                `json:"CaEnabled" xml:"CaEnabled"`
 	GzipEnabled         bool                `json:"GzipEnabled" xml:"GzipEnabled"`
 	Http2Enabled        bool                `json:"Http2Enabled" xml:"Http2Enabled"`
 	IdleTimeout         int                 `json:"IdleTimeout" xml:"IdleTimeout"`
@@ -59,19 +60,21 @@ type ALBListenerRuleSpec struct {
 }
 
 type ALBServerGroupSpec struct {
-	Protocol                 string              `json:"Protocol" xml:"Protocol"`
-	ResourceGroupId          string              `json:"ResourceGroupId" xml:"ResourceGroupId"`
-	Scheduler                string              `json:"Scheduler" xml:"Scheduler"`
-	ServerGroupId            string              `json:"ServerGroupId" xml:"ServerGroupId"`
-	ServerGroupName          string              `json:"ServerGroupName" xml:"ServerGroupName"`
-	ServerGroupStatus        string              `json:"ServerGroupStatus" xml:"ServerGroupStatus"`
-	ServerGroupType          string              `json:"ServerGroupType" xml:"ServerGroupType"`
-	VpcId                    string              `json:"VpcId" xml:"VpcId"`
-	HealthCheckConfig        HealthCheckConfig   `json:"HealthCheckConfig" xml:"HealthCheckConfig"`
-	StickySessionConfig      StickySessionConfig `json:"StickySessionConfig" xml:"StickySessionConfig"`
-	Tags                     []ALBTag            `json:"Tags" xml:"Tags"`
-	UpstreamKeepaliveEnabled bool                `json:"UpstreamKeepaliveEnabled" xml:"UpstreamKeepaliveEnabled"`
-	UchConfig                UchConfig           `json:"UchConfig" xml:"UchConfig"`
+	Protocol                 string                `json:"Protocol" xml:"Protocol"`
+	ResourceGroupId          string                `json:"ResourceGroupId" xml:"ResourceGroupId"`
+	Scheduler                string                `json:"Scheduler" xml:"Scheduler"`
+	ServerGroupId            string                `json:"ServerGroupId" xml:"ServerGroupId"`
+	ServerGroupName          string                `json:"ServerGroupName" xml:"ServerGroupName"`
+	ServerGroupStatus        string                `json:"ServerGroupStatus" xml:"ServerGroupStatus"`
+	ServerGroupType          string                `json:"ServerGroupType" xml:"ServerGroupType"`
+	VpcId                    string                `json:"VpcId" xml:"VpcId"`
+	HealthCheckConfig        HealthCheckConfig     `json:"HealthCheckConfig" xml:"HealthCheckConfig"`
+	StickySessionConfig      StickySessionConfig   `json:"StickySessionConfig" xml:"StickySessionConfig"`
+	Tags                     []ALBTag              `json:"Tags" xml:"Tags"`
+	UpstreamKeepaliveEnabled bool                  `json:"UpstreamKeepaliveEnabled" xml:"UpstreamKeepaliveEnabled"`
+	UchConfig                UchConfig             `json:"UchConfig" xml:"UchConfig"`
+	ConnectionDrainConfig    ConnectionDrainConfig `json:"ConnectionDrainConfig" xml:"ConnectionDrainConfig"`
+	SlowStartConfig          SlowStartConfig       `json:"SlowStartConfig" xml:"SlowStartConfig"`
 }
 
 type AccessLogConfig struct {
@@ -177,6 +180,16 @@ type UchConfig struct {
 	Value string `json:"Value" xml:"Value"`
 }
 
+type SlowStartConfig struct {
+	SlowStartEnabled  bool `json:"SlowStartEnabled" xml:"SlowStartEnabled"`
+	SlowStartDuration int  `json:"SlowStartDuration" xml:"SlowStartDuration"`
+}
+
+type ConnectionDrainConfig struct {
+	ConnectionDrainEnabled bool `json:"ConnectionDrainEnabled" xml:"ConnectionDrainEnabled"`
+	ConnectionDrainTimeout int  `json:"ConnectionDrainTimeout" xml:"ConnectionDrainTimeout"`
+}
+
 type Action struct {
 	Order               int                  `json:"Order" xml:"Order"`
 	Type                string               `json:"Type" xml:"Type"`
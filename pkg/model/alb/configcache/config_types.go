@@ -1,5 +1,11 @@
 package configcache
 
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
 type Action struct {
 	Order               int                  `json:"Order" xml:"Order"`
 	Type                string               `json:"Type" xml:"Type"`
@@ -56,6 +62,15 @@ type ServerGroupTuple struct {
 	Weight int `json:"weight,omitempty"`
 }
 
+// ServiceKey returns the Service of the server group tuple. ServiceName is the name of a Service in the namespace
+// of the Ingress, or namespace/name of a Service in another namespace.
+func (t ServerGroupTuple) ServiceKey(namespace string) types.NamespacedName {
+	if i := strings.Index(t.ServiceName, "/"); i >= 0 {
+		return types.NamespacedName{Namespace: t.ServiceName[:i], Name: t.ServiceName[i+1:]}
+	}
+	return types.NamespacedName{Namespace: namespace, Name: t.ServiceName}
+}
+
 type TrafficMirrorConfig struct {
 	TargetType        string            `json:"TargetType" xml:"TargetType"`
 	MirrorGroupConfig MirrorGroupConfig `json:"MirrorGroupConfig" xml:"MirrorGroupConfig"`
@@ -1,5 +1,7 @@
 package alb
 
+import "fmt"
+
 type ConsoleServiceStack struct {
 	ClusterID     string
 	ServerGroupID string
@@ -7,7 +9,18 @@ type ConsoleServiceStack struct {
 	Namespace string
 	Name      string
 
+	// ServerDescription is set if the servers are owned by their description, such as the servers of a
+	// ServerGroupBinding or of a server group shared by several clusters, only the servers of this description
+	// are managed
+	ServerDescription string
+
 	TrafficPolicy                   string
 	ContainsPotentialReadyEndpoints bool
 	Backends                        []BackendItem
 }
+
+// SharedServerDescription is the description of the servers a cluster registers for a Service into a server
+// group shared by several clusters.
+func SharedServerDescription(clusterID, namespace, name string) string {
+	return fmt.Sprintf("k8s.%s.%s.%s", name, namespace, clusterID)
+}
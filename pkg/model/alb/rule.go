@@ -43,6 +43,8 @@ type ListenerRuleStatus struct {
 type ListenerRuleSpec struct {
 	ListenerID core.StringToken `json:"listenerID"`
 	ALBListenerRuleSpec
+	// Ingresses are the keys of the ingresses which the rule is built from, only used to report the ingress status
+	Ingresses []string `json:"ingresses,omitempty"`
 }
 
 type ResAndSDKListenerRulePair struct {
@@ -44,13 +44,22 @@ func (sc *SecretCertificate) CertIdentifier() core.StringToken {
 }
 
 type SecretCertificateSpec struct {
-	CertName    string `json:"certName"`
-	IsDefault   bool   `json:"IsDefault" xml:"IsDefault"`
+	CertName  string `json:"certName"`
+	IsDefault bool   `json:"IsDefault" xml:"IsDefault"`
+	// Secret is the namespace/name of the Secret of the certificate
+	Secret string `json:"secret"`
+	// Fingerprint is the SHA-256 fingerprint of the leaf certificate in hex, the Sha2 of the certificate in CAS
+	Fingerprint string `json:"fingerprint"`
+	// IsCA is true if the certificate is the CA bundle of the Secret, which is uploaded as a CA certificate
+	IsCA        bool   `json:"isCA,omitempty"`
 	Certificate string `json:"-"`
 	PrivateKey  string `json:"-"`
 }
 type SecretCertificateStatus struct {
 	CertIdentifier string `json:"certIdentifier"`
+	// Owned is true if the certificate is uploaded from the Secret by the controller, rather than found in CAS
+	// by its content
+	Owned bool `json:"owned"`
 }
 
 func (sc *SecretCertificate) SetDefault() {
@@ -14,6 +14,12 @@ type ServiceManager struct {
 
 	PortToServerGroup   map[int32]*ServerGroupWithIngress
 	IngressAlbConfigMap map[string]string
+	// AlbConfigShards is the keys of the shards other than the first one of the sharded albconfigs, by the key of
+	// the albconfig. The server groups of a shard are tagged with the key of the shard.
+	AlbConfigShards map[string][]string
+	// IngressConnectionDrainTimeout is the connection drain timeout in seconds of the ingresses enabling
+	// the connection drain, by namespace/name key
+	IngressConnectionDrainTimeout map[string]int
 
 	TrafficPolicy                   string
 	ContainsPotentialReadyEndpoints bool
@@ -36,12 +42,18 @@ type BackendItem struct {
 	Weight      int
 	Port        int
 	Type        string
+	// Draining is true if the pod is terminating but still serving, it is kept with weight 0 until the
+	// connection drain timeout elapses
+	Draining bool
+	// Zone is the zone of the node of the backend by its topology.kubernetes.io/zone label
+	Zone string
 }
 
 type ServiceGroupWithNameKey struct {
-	NamedKey     *ServerGroupNamedKey
-	AlbConfigKey string
-	Backends     []BackendItem
+	NamedKey               *ServerGroupNamedKey
+	AlbConfigKey           string
+	Backends               []BackendItem
+	ConnectionDrainTimeout int
 }
 
 type ServerGroupWithIngress struct {
@@ -52,6 +64,7 @@ type ServerGroupWithIngress struct {
 const (
 	ECSBackendType = "ecs"
 	ENIBackendType = "eni"
+	IPBackendType  = "ip"
 )
 
 const (
@@ -85,8 +98,10 @@ type ServiceStackContext struct {
 
 	Service *v1.Service
 
-	ServicePortToIngressNames map[int32][]string
-	IngressAlbConfigMap       map[string]string
+	ServicePortToIngressNames     map[int32][]string
+	IngressAlbConfigMap           map[string]string
+	AlbConfigShards               map[string][]string
+	IngressConnectionDrainTimeout map[string]int
 
 	IsServiceNotFound bool
 }
@@ -95,6 +95,10 @@ type NetworkLoadBalancer struct {
 	LoadBalancerAttribute *LoadBalancerAttribute
 	Listeners             []*ListenerAttribute
 	ServerGroups          []*ServerGroup
+
+	// ContainsPotentialReadyEndpoints is true if some pods wait for the NLB readiness gate, the service is
+	// reconciled again to add them once their containers are ready, or to check their health.
+	ContainsPotentialReadyEndpoints bool
 }
 
 func (l *NetworkLoadBalancer) GetLoadBalancerId() string {
@@ -128,21 +132,25 @@ type ListenerAttribute struct {
 	ServerGroupName string
 	ServicePort     *v1.ServicePort
 
-	ListenerProtocol     string
-	ListenerPort         int32
-	ListenerDescription  string
-	ServerGroupId        string
-	LoadBalancerId       string
-	IdleTimeout          int32 // 1-900
-	SecurityPolicyId     string
-	CertificateIds       []string // tcpssl
-	CaCertificateIds     []string
+	ListenerProtocol    string
+	ListenerPort        int32
+	ListenerDescription string
+	ServerGroupId       string
+	LoadBalancerId      string
+	IdleTimeout         int32 // 1-900
+	SecurityPolicyId    string
+	CertificateIds      []string // tcpssl
+	CaCertificateIds    []string
+	// CertificateSecret and CaCertificateSecret are uploaded to CAS from the Secrets of the service, their ids
+	// are set to CertificateIds and CaCertificateIds once uploaded
+	CertificateSecret    *SecretCertificate
+	CaCertificateSecret  *SecretCertificate
 	CaEnabled            *bool
 	ProxyProtocolEnabled *bool
 	SecSensorEnabled     *bool
 	AlpnEnabled          *bool
 	AlpnPolicy           string
-	StartPort            *int32 //0-65535
+	StartPort            *int32 //0-65535, the port range of the listener if ListenerPort is 0
 	EndPort              *int32 //0-65535
 	Cps                  *int32 //0-1000000
 
@@ -151,6 +159,36 @@ type ListenerAttribute struct {
 	ListenerStatus
 }
 
+// IsPortRange returns true if the listener listens on the range of ports from StartPort to EndPort
+func (l *ListenerAttribute) IsPortRange() bool {
+	return l.ListenerPort == 0 && l.StartPort != nil && l.EndPort != nil
+}
+
+// PortString returns the port of the listener, or the port range in the start-end format
+func (l *ListenerAttribute) PortString() string {
+	if l.IsPortRange() {
+		return fmt.Sprintf("%d-%d", *l.StartPort, *l.EndPort)
+	}
+	return fmt.Sprintf("%d", l.ListenerPort)
+}
+
+// SecretCertificate is a certificate of the TCPSSL listeners uploaded to CAS from a Secret of the service.
+type SecretCertificate struct {
+	// Secret is the namespace/name of the Secret
+	Secret string
+	// IsCA is true for the CA bundle verifying the client certificates
+	IsCA bool
+	// CertName is the name in CAS, it starts with the owner prefix of the service
+	CertName string
+	// Fingerprint is the SHA-256 fingerprint of the first certificate, the Sha2 of the certificate in CAS
+	Fingerprint string
+	Certificate string `json:"-"`
+	PrivateKey  string `json:"-"`
+
+	// auto-generated parameters
+	CertificateId string
+}
+
 type ServerGroup struct {
 	IsUserManaged bool
 	NamedKey      *SGNamedKey
@@ -167,6 +205,7 @@ type ServerGroup struct {
 	ConnectionDrainTimeout  int32 // 10-900
 	Scheduler               string
 	PreserveClientIpEnabled *bool
+	AnyPortEnabled          bool // forward to the port the clients connect to, the servers are added with port 0
 	HealthCheckConfig       *HealthCheckConfig
 	Servers                 []ServerGroupServer
 	Tags                    []tag.Tag
@@ -186,6 +225,11 @@ func (s *ServerGroup) BackendInfo() string {
 type ServerGroupServer struct {
 	IsUserManaged bool
 	NodeName      *string
+	// TargetRef is the pod of the endpoint, it is used to update the readiness gate of the pod
+	TargetRef *v1.ObjectReference
+	// Draining is true if the pod is terminating but still serving, it is kept with weight 0 until the
+	// connection drain timeout elapses
+	Draining bool
 
 	ServerGroupId string
 	Description   string
@@ -198,6 +242,14 @@ type ServerGroupServer struct {
 	Status        string
 }
 
+// ServerGroupHealthStatus is the health check result of the servers of a server group used by a listener
+type ServerGroupHealthStatus struct {
+	ServerGroupId      string
+	HealthCheckEnabled bool
+	// NonNormalServers are the servers which are not healthy, e.g. Initial, Unhealthy or Unavailable
+	NonNormalServers []ServerGroupServer
+}
+
 type ZoneMapping struct {
 	VSwitchId    string
 	ZoneId       string
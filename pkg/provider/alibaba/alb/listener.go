@@ -143,6 +143,8 @@ func (m *ALBProvider) listListenerCerts(ctx context.Context, lsID string) ([]alb
 	)
 	listLsCertificateReq := albsdk.CreateListListenerCertificatesRequest()
 	listLsCertificateReq.ListenerId = lsID
+	// the CA certificates are bound by updateListenerCaCertificates
+	listLsCertificateReq.CertificateType = util.ListenerCertificateTypeServer
 	for {
 		listLsCertificateReq.NextToken = nextToken
 
@@ -197,10 +199,17 @@ func (m *ALBProvider) UpdateALBListener(ctx context.Context, resLS *albmodel.Lis
 	if err := m.updateListenerAttribute(ctx, resLS, sdkLS); err != nil {
 		return albmodel.ListenerStatus{}, err
 	}
-	if _, err := m.waitListenerStatus(ctx, sdkLS.ListenerId); err != nil {
+	lsAttr, err := m.waitListenerStatus(ctx, sdkLS.ListenerId)
+	if err != nil {
 		return albmodel.ListenerStatus{}, err
 	}
 
+	if isHTTPSListenerProtocol(sdkLS.ListenerProtocol) {
+		if err := m.updateListenerCaCertificates(ctx, resLS, lsAttr); err != nil {
+			return albmodel.ListenerStatus{}, err
+		}
+	}
+
 	return buildResListenerStatus(sdkLS.ListenerId), nil
 }
 
@@ -377,6 +386,9 @@ func buildSDKCreateListenerRequest(lsSpec albmodel.ListenerSpec) (*albsdk.Create
 			createLsReq.SecurityPolicyId = lsSpec.SecurityPolicyId
 		}
 		createLsReq.CaCertificates = transSDKCaCertificatesToCreateLs(ctx, lsSpec.CaCertificates)
+		if isHTTPSListenerProtocol(lsSpec.ListenerProtocol) {
+			createLsReq.CaEnabled = requests.NewBoolean(lsSpec.CaEnabled)
+		}
 
 		if len(lsSpec.Certificates) == 0 {
 			return nil, fmt.Errorf("empty https listener default certs ")
@@ -732,6 +744,74 @@ func (m *ALBProvider) updateListenerAttribute(ctx context.Context, resLS *albmod
 	return nil
 }
 
+// updateListenerCaCertificates binds the CA certificates to the HTTPS listener and enables the verification of the
+// client certificates as desired. The CA certificates bound are kept if the listener has none desired.
+func (m *ALBProvider) updateListenerCaCertificates(ctx context.Context, resLS *albmodel.Listener, lsAttr *albsdk.GetListenerAttributeResponse) error {
+	traceID := ctx.Value(util.TraceID)
+
+	desiredCaCertIDs := sets.NewString()
+	for _, cert := range resLS.Spec.CaCertificates {
+		certId, err := cert.GetCertificateId(ctx)
+		if err != nil {
+			return err
+		}
+		desiredCaCertIDs.Insert(certId)
+	}
+	currentCaCertIDs := sets.NewString()
+	for _, cert := range lsAttr.CaCertificates {
+		currentCaCertIDs.Insert(cert.CertificateId)
+	}
+	isCaCertificatesNeedUpdate := desiredCaCertIDs.Len() != 0 && !desiredCaCertIDs.Equal(currentCaCertIDs)
+	isCaEnabledNeedUpdate := resLS.Spec.CaEnabled != lsAttr.CaEnabled
+	if !isCaCertificatesNeedUpdate && !isCaEnabledNeedUpdate {
+		return nil
+	}
+
+	updateLsReq := albsdk.CreateUpdateListenerAttributeRequest()
+	updateLsReq.ListenerId = lsAttr.ListenerId
+	if isCaCertificatesNeedUpdate {
+		m.logger.V(util.MgrLogLevel).Info("CaCertificates update",
+			"res", desiredCaCertIDs.List(),
+			"sdk", currentCaCertIDs.List(),
+			"listenerID", lsAttr.ListenerId,
+			"traceID", traceID)
+		updateLsReq.CaCertificates = transSDKCaCertificatesToUpdateLs(desiredCaCertIDs.List())
+	}
+	if isCaEnabledNeedUpdate {
+		m.logger.V(util.MgrLogLevel).Info("CaEnabled update",
+			"res", resLS.Spec.CaEnabled,
+			"sdk", lsAttr.CaEnabled,
+			"listenerID", lsAttr.ListenerId,
+			"traceID", traceID)
+		updateLsReq.CaEnabled = requests.NewBoolean(resLS.Spec.CaEnabled)
+	}
+
+	startTime := time.Now()
+	m.logger.V(util.MgrLogLevel).Info("updating listener ca certificates",
+		"stackID", resLS.Stack().StackID(),
+		"resourceID", resLS.ID(),
+		"traceID", traceID,
+		"listenerID", lsAttr.ListenerId,
+		"updateLsReq", updateLsReq,
+		"startTime", startTime,
+		util.Action, util.UpdateALBListenerAttribute)
+	updateLsResp, err := m.auth.ALB.UpdateListenerAttribute(updateLsReq)
+	if err != nil {
+		return err
+	}
+	m.logger.V(util.MgrLogLevel).Info("updated listener ca certificates",
+		"stackID", resLS.Stack().StackID(),
+		"resourceID", resLS.ID(),
+		"traceID", traceID,
+		"listenerID", lsAttr.ListenerId,
+		"requestID", updateLsResp.RequestId,
+		"elapsedTime", time.Since(startTime).Milliseconds(),
+		util.Action, util.UpdateALBListenerAttribute)
+
+	_, err = m.waitListenerStatus(ctx, lsAttr.ListenerId)
+	return err
+}
+
 func transModelActionToSDKCreateLs(actions []albmodel.Action) (*[]albsdk.CreateListenerDefaultActions, error) {
 	createLsActions := make([]albsdk.CreateListenerDefaultActions, 0)
 	for _, action := range actions {
@@ -867,6 +947,16 @@ func transSDKCaCertificatesToCreateLs(ctx context.Context, certificates []albmod
 	return &createListenerAttributeCaCertificates
 }
 
+func transSDKCaCertificatesToUpdateLs(certIDs []string) *[]albsdk.UpdateListenerAttributeCaCertificates {
+	updateListenerAttributeCaCertificates := make([]albsdk.UpdateListenerAttributeCaCertificates, 0)
+	for _, certID := range certIDs {
+		updateListenerAttributeCaCertificates = append(updateListenerAttributeCaCertificates, albsdk.UpdateListenerAttributeCaCertificates{
+			CertificateId: certID,
+		})
+	}
+	return &updateListenerAttributeCaCertificates
+}
+
 func transSDKCertificatesToCreateLs(certificates []albsdk.Certificate) *[]albsdk.CreateListenerCertificates {
 	createListenerAttributeCertificates := make([]albsdk.CreateListenerCertificates, 0)
 	for _, certificate := range certificates {
@@ -889,7 +979,7 @@ func transSDKCertificatesToUpdateLs(certificates []albsdk.Certificate) *[]albsdk
 
 func transSDKXForwardedForConfigToCreateLs(c albmodel.XForwardedForConfig) albsdk.CreateListenerXForwardedForConfig {
 	return albsdk.CreateListenerXForwardedForConfig{
-		XForwardedForClientCertSubjectDNAlias:      c.XForwardedForClientCertIssuerDNAlias,
+		XForwardedForClientCertSubjectDNAlias:      c.XForwardedForClientCertSubjectDNAlias,
 		XForwardedForClientCertIssuerDNEnabled:     strconv.FormatBool(c.XForwardedForClientCertIssuerDNEnabled),
 		XForwardedForClientCertFingerprintEnabled:  strconv.FormatBool(c.XForwardedForClientCertFingerprintEnabled),
 		XForwardedForClientCertIssuerDNAlias:       c.XForwardedForClientCertIssuerDNAlias,
@@ -907,7 +997,7 @@ func transSDKXForwardedForConfigToCreateLs(c albmodel.XForwardedForConfig) albsd
 
 func transXForwardedForConfigToSDK(c albmodel.XForwardedForConfig) albsdk.XForwardedForConfig {
 	return albsdk.XForwardedForConfig{
-		XForwardedForClientCertSubjectDNAlias:      c.XForwardedForClientCertIssuerDNAlias,
+		XForwardedForClientCertSubjectDNAlias:      c.XForwardedForClientCertSubjectDNAlias,
 		XForwardedForClientCertIssuerDNEnabled:     c.XForwardedForClientCertIssuerDNEnabled,
 		XForwardedForClientCertFingerprintEnabled:  c.XForwardedForClientCertFingerprintEnabled,
 		XForwardedForClientCertIssuerDNAlias:       c.XForwardedForClientCertIssuerDNAlias,
@@ -925,7 +1015,7 @@ func transXForwardedForConfigToSDK(c albmodel.XForwardedForConfig) albsdk.XForwa
 
 func transSDKXForwardedForConfigToUpdateLs(c albmodel.XForwardedForConfig) albsdk.UpdateListenerAttributeXForwardedForConfig {
 	return albsdk.UpdateListenerAttributeXForwardedForConfig{
-		XForwardedForClientCertSubjectDNAlias:      c.XForwardedForClientCertIssuerDNAlias,
+		XForwardedForClientCertSubjectDNAlias:      c.XForwardedForClientCertSubjectDNAlias,
 		XForwardedForClientCertIssuerDNEnabled:     strconv.FormatBool(c.XForwardedForClientCertIssuerDNEnabled),
 		XForwardedForClientCertFingerprintEnabled:  strconv.FormatBool(c.XForwardedForClientCertFingerprintEnabled),
 		XForwardedForClientCertIssuerDNAlias:       c.XForwardedForClientCertIssuerDNAlias,
@@ -225,6 +225,57 @@ func (m *ALBProvider) ReplaceALBServers(ctx context.Context, serverGroupID strin
 	return nil
 }
 
+// UpdateALBServers updates the weight and the description of the servers in the server group
+func (m *ALBProvider) UpdateALBServers(ctx context.Context, serverGroupID string, resServers []alb.BackendItem) error {
+	if len(serverGroupID) == 0 {
+		return fmt.Errorf("empty server group id when update servers error")
+	}
+
+	traceID := ctx.Value(util.TraceID)
+
+	serversToUpdate := make([]albsdk.UpdateServerGroupServersAttributeServers, 0)
+	for _, resServer := range resServers {
+		serverToUpdate, err := transModelBackendToSDKUpdateServerGroupServersAttributeServer(resServer)
+		if err != nil {
+			return err
+		}
+		serversToUpdate = append(serversToUpdate, *serverToUpdate)
+	}
+
+	for len(serversToUpdate) > 0 {
+		cnt := util.BatchRegisterServersDefaultNum
+		if len(serversToUpdate) < cnt {
+			cnt = len(serversToUpdate)
+		}
+		servers := serversToUpdate[0:cnt]
+		serversToUpdate = serversToUpdate[cnt:]
+
+		updateServerReq := albsdk.CreateUpdateServerGroupServersAttributeRequest()
+		updateServerReq.ServerGroupId = serverGroupID
+		updateServerReq.Servers = &servers
+
+		startTime := time.Now()
+		m.logger.V(util.MgrLogLevel).Info("updating server in server group",
+			"serverGroupID", serverGroupID,
+			"traceID", traceID,
+			"servers", servers,
+			"startTime", startTime,
+			util.Action, util.UpdateALBServersAttribute)
+		updateServerResp, err := m.auth.ALB.UpdateServerGroupServersAttribute(updateServerReq)
+		if err != nil {
+			return err
+		}
+		m.logger.V(util.MgrLogLevel).Info("updated server in server group",
+			"serverGroupID", serverGroupID,
+			"traceID", traceID,
+			"requestID", updateServerResp.RequestId,
+			"elapsedTime", time.Since(startTime).Milliseconds(),
+			util.Action, util.UpdateALBServersAttribute)
+	}
+
+	return nil
+}
+
 func (m *ALBProvider) ListALBServers(ctx context.Context, serverGroupID string) ([]albsdk.BackendServer, error) {
 	if len(serverGroupID) == 0 {
 		return nil, fmt.Errorf("empty server group id when list servers error")
@@ -326,11 +377,16 @@ func transModelBackendToSDKAddServersToServerGroupServer(server alb.BackendItem)
 		return nil, fmt.Errorf("invalid server type for server: %v", server)
 	}
 	serverToAdd.ServerType = server.Type
+	// the addresses of an Ip server group may be out of the vpc, e.g. in the data center connected to it
+	if strings.EqualFold(server.Type, util.ServerTypeIp) {
+		serverToAdd.RemoteIpEnabled = "true"
+	}
 
 	if !isServerWeightValid(server.Weight) {
 		return nil, fmt.Errorf("invalid server weight for server: %v", server)
 	}
 	serverToAdd.Weight = strconv.Itoa(server.Weight)
+	serverToAdd.Description = server.Description
 
 	return serverToAdd, nil
 }
@@ -354,15 +410,49 @@ func transModelBackendToSDKReplaceServersInServerGroupAddedServer(server alb.Bac
 		return nil, fmt.Errorf("invalid server type for server: %v", server)
 	}
 	serverToAdd.ServerType = server.Type
+	// the addresses of an Ip server group may be out of the vpc, e.g. in the data center connected to it
+	if strings.EqualFold(server.Type, util.ServerTypeIp) {
+		serverToAdd.RemoteIpEnabled = "true"
+	}
 
 	if !isServerWeightValid(server.Weight) {
 		return nil, fmt.Errorf("invalid server weight for server: %v", server)
 	}
 	serverToAdd.Weight = strconv.Itoa(server.Weight)
+	serverToAdd.Description = server.Description
 
 	return serverToAdd, nil
 }
 
+func transModelBackendToSDKUpdateServerGroupServersAttributeServer(server alb.BackendItem) (*albsdk.UpdateServerGroupServersAttributeServers, error) {
+	serverToUpdate := new(albsdk.UpdateServerGroupServersAttributeServers)
+
+	serverToUpdate.ServerIp = server.ServerIp
+
+	if len(server.ServerId) == 0 {
+		return nil, fmt.Errorf("invalid server id for server: %v", server)
+	}
+	serverToUpdate.ServerId = server.ServerId
+
+	if !isServerPortValid(server.Port) {
+		return nil, fmt.Errorf("invalid server port for server: %v", server)
+	}
+	serverToUpdate.Port = strconv.Itoa(server.Port)
+
+	if !isServerTypeValid(server.Type) {
+		return nil, fmt.Errorf("invalid server type for server: %v", server)
+	}
+	serverToUpdate.ServerType = server.Type
+
+	if !isServerWeightValid(server.Weight) {
+		return nil, fmt.Errorf("invalid server weight for server: %v", server)
+	}
+	serverToUpdate.Weight = strconv.Itoa(server.Weight)
+	serverToUpdate.Description = server.Description
+
+	return serverToUpdate, nil
+}
+
 func transModelBackendsToSDKAddServersToServerGroupServers(servers []alb.BackendItem) ([]albsdk.AddServersToServerGroupServers, error) {
 	serversToAdd := make([]albsdk.AddServersToServerGroupServers, 0)
 	for _, resServer := range servers {
@@ -397,7 +487,8 @@ func isServerPortValid(port int) bool {
 func isServerTypeValid(serverType string) bool {
 	if !strings.EqualFold(serverType, util.ServerTypeEcs) &&
 		!strings.EqualFold(serverType, util.ServerTypeEni) &&
-		!strings.EqualFold(serverType, util.ServerTypeEci) {
+		!strings.EqualFold(serverType, util.ServerTypeEci) &&
+		!strings.EqualFold(serverType, util.ServerTypeIp) {
 		return false
 	}
 
@@ -221,7 +221,7 @@ func (m *ALBProvider) updateServerGroupAttribute(ctx context.Context, resSGP *al
 		}
 	}
 
-	if err := checkHealthCheckConfigValid(resSGP.Spec.HealthCheckConfig); err != nil {
+	if err := CheckHealthCheckConfigValid(resSGP.Spec.HealthCheckConfig); err != nil {
 		return nil, err
 	}
 	if resSGP.Spec.HealthCheckConfig.HealthCheckEnabled {
@@ -242,7 +242,7 @@ func (m *ALBProvider) updateServerGroupAttribute(ctx context.Context, resSGP *al
 		isHealthCheckConfigNeedUpdate = true
 	}
 
-	if err := checkStickySessionConfigValid(resSGP.Spec.StickySessionConfig); err != nil {
+	if err := CheckStickySessionConfigValid(resSGP.Spec.StickySessionConfig); err != nil {
 		return nil, err
 	}
 	if resSGP.Spec.StickySessionConfig.StickySessionEnabled {
@@ -354,20 +354,35 @@ func buildSDKServerGroupCreateRequest(sgpSpec alb.ServerGroupSpec) (*albsdk.Crea
 	}
 
 	sgpReq.ResourceGroupId = sgpSpec.ResourceGroupId
-	if err := checkHealthCheckConfigValid(sgpSpec.HealthCheckConfig); err != nil {
+	if err := CheckHealthCheckConfigValid(sgpSpec.HealthCheckConfig); err != nil {
 		return nil, err
 	}
 	sgpReq.HealthCheckConfig = *transSDKHealthCheckConfigToCreateSGP(sgpSpec.HealthCheckConfig)
-	if err := checkStickySessionConfigValid(sgpSpec.StickySessionConfig); err != nil {
+	if err := CheckStickySessionConfigValid(sgpSpec.StickySessionConfig); err != nil {
 		return nil, err
 	}
 	sgpReq.StickySessionConfig = *transSDKStickySessionConfigToCreateSGP(sgpSpec.StickySessionConfig)
 	sgpReq.ServerGroupType = sgpSpec.ServerGroupType
+	if sgpSpec.ConnectionDrainConfig.ConnectionDrainEnabled {
+		if err := checkConnectionDrainConfigValid(sgpSpec.ConnectionDrainConfig); err != nil {
+			return nil, err
+		}
+		// the vendored sdk does not support the connection drain and slow start configs yet
+		sgpReq.QueryParams["ConnectionDrainConfig.ConnectionDrainEnabled"] = "true"
+		sgpReq.QueryParams["ConnectionDrainConfig.ConnectionDrainTimeout"] = strconv.Itoa(sgpSpec.ConnectionDrainConfig.ConnectionDrainTimeout)
+	}
+	if sgpSpec.SlowStartConfig.SlowStartEnabled {
+		if err := checkSlowStartConfigValid(sgpSpec); err != nil {
+			return nil, err
+		}
+		sgpReq.QueryParams["SlowStartConfig.SlowStartEnabled"] = "true"
+		sgpReq.QueryParams["SlowStartConfig.SlowStartDuration"] = strconv.Itoa(sgpSpec.SlowStartConfig.SlowStartDuration)
+	}
 
 	return sgpReq, nil
 }
 
-func checkHealthCheckConfigValid(conf alb.HealthCheckConfig) error {
+func CheckHealthCheckConfigValid(conf alb.HealthCheckConfig) error {
 	if !conf.HealthCheckEnabled {
 		return nil
 	}
@@ -404,7 +419,7 @@ func checkHealthCheckConfigValid(conf alb.HealthCheckConfig) error {
 	return nil
 }
 
-func checkStickySessionConfigValid(conf alb.StickySessionConfig) error {
+func CheckStickySessionConfigValid(conf alb.StickySessionConfig) error {
 	if !conf.StickySessionEnabled {
 		return nil
 	}
@@ -441,6 +456,23 @@ func checkUchConfigValid(conf alb.UchConfig) error {
 	return nil
 }
 
+func checkConnectionDrainConfigValid(conf alb.ConnectionDrainConfig) error {
+	if conf.ConnectionDrainTimeout < 0 || conf.ConnectionDrainTimeout > 900 {
+		return fmt.Errorf("invalid server group ConnectionDrainTimeout: %v", conf.ConnectionDrainTimeout)
+	}
+	return nil
+}
+
+func checkSlowStartConfigValid(sgpSpec alb.ServerGroupSpec) error {
+	if !strings.EqualFold(sgpSpec.Scheduler, util.ServerGroupSchedulerWrr) {
+		return fmt.Errorf("slow start is only supported by the wrr scheduler: %v", sgpSpec.Scheduler)
+	}
+	if sgpSpec.SlowStartConfig.SlowStartDuration < 30 || sgpSpec.SlowStartConfig.SlowStartDuration > 900 {
+		return fmt.Errorf("invalid server group SlowStartDuration: %v", sgpSpec.SlowStartConfig.SlowStartDuration)
+	}
+	return nil
+}
+
 func isServerGroupResourceInUseError(err error) bool {
 	if strings.Contains(err.Error(), "ResourceInUse.ServerGroup") ||
 		strings.Contains(err.Error(), "IncorrectStatus.ServerGroup") {
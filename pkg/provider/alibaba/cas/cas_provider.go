@@ -55,6 +55,11 @@ const (
 	DescribeSSLCertificatePublicKeyDetail = "DescribeSSLCertificatePublicKeyDetail"
 	CreateSSLCertificateWithName          = "CreateSSLCertificateWithName"
 	DeleteSSLCertificate                  = "DeleteSSLCertificate"
+	caCertsCacheKey                       = "CACertificateInfo"
+	CACertificateVersion                  = "2020-06-30"
+	DescribeCACertificateList             = "DescribePcaAndExternalCACertificateList"
+	UploadCACertificate                   = "UploadPCACert"
+	DeleteCACertificate                   = "DeletePCACert"
 	DefaultSSLCertificatePollInterval     = 30 * time.Second
 	DefaultSSLCertificateTimeout          = 60 * time.Second
 )
@@ -211,3 +216,131 @@ func (c CASProvider) DescribeSSLCertificateList(ctx context.Context) ([]model.Ce
 	c.certsCache.Set(certsCacheKey, certificateInfos, c.certsCacheTTL)
 	return certificateInfos, nil
 }
+
+// caCertificateInfo is a CA certificate in the DescribePcaAndExternalCACertificateList response
+type caCertificateInfo struct {
+	Identifier string `json:"Identifier"`
+	Alias      string `json:"Alias"`
+	CommonName string `json:"CommonName"`
+	Sha2       string `json:"Sha2"`
+	BeforeDate int64  `json:"BeforeDate"`
+	AfterDate  int64  `json:"AfterDate"`
+}
+
+func (c CASProvider) DescribeCACertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	traceID := ctx.Value(util.TraceID)
+	c.loadCertMutex.Lock()
+	defer c.loadCertMutex.Unlock()
+
+	if rawCacheItem, ok := c.certsCache.Get(caCertsCacheKey); ok {
+		return rawCacheItem.([]model.CertificateInfo), nil
+	}
+
+	rpcRequest := &requests.RpcRequest{}
+	rpcRequest.InitWithApiInfo("cas", CACertificateVersion, DescribeCACertificateList, "cas", "openAPI")
+	rpcRequest.Method = requests.POST
+	rpcRequest.Domain = CASDomain
+
+	response := responses.NewCommonResponse()
+	certificateInfos := make([]model.CertificateInfo, 0)
+	pageNumber := 1
+	for {
+		rpcRequest.QueryParams = map[string]string{
+			"ShowSize":    strconv.Itoa(CASShowSize),
+			"CurrentPage": strconv.Itoa(pageNumber),
+		}
+		startTime := time.Now()
+		err := c.casDoAction(rpcRequest, response)
+		if err != nil {
+			c.logger.Error(err, "DescribeCACertificateList error")
+			return nil, err
+		}
+		c.logger.Info("listed ca certificate",
+			"traceID", traceID,
+			"elapsedTime", time.Since(startTime).Milliseconds(),
+			"action", DescribeCACertificateList)
+		resp := struct {
+			CertificateList []caCertificateInfo `json:"CertificateList"`
+			PageCount       int                 `json:"PageCount"`
+		}{}
+		if err := json.Unmarshal(response.GetHttpContentBytes(), &resp); err != nil {
+			return nil, errors.Wrap(err, "failed to describeCACertificateList")
+		}
+		for _, cert := range resp.CertificateList {
+			certificateInfos = append(certificateInfos, model.CertificateInfo{
+				CertName:       cert.Alias,
+				CertIdentifier: cert.Identifier,
+				CommonName:     cert.CommonName,
+				Sha2:           cert.Sha2,
+				BeforeDate:     cert.BeforeDate,
+				AfterDate:      cert.AfterDate,
+			})
+		}
+		if pageNumber < resp.PageCount {
+			pageNumber++
+		} else {
+			break
+		}
+	}
+	c.certsCache.Set(caCertsCacheKey, certificateInfos, c.certsCacheTTL)
+	return certificateInfos, nil
+}
+
+func (c CASProvider) CreateCACertificateWithName(ctx context.Context, certName, certificate string) (string, error) {
+	traceID := ctx.Value(util.TraceID)
+
+	rpcRequest := &requests.RpcRequest{}
+	rpcRequest.InitWithApiInfo("cas", CACertificateVersion, UploadCACertificate, "cas", "openAPI")
+	rpcRequest.Method = requests.POST
+	rpcRequest.Domain = CASDomain
+	rpcRequest.QueryParams = map[string]string{
+		"Name": certName,
+		"Cert": certificate,
+	}
+	response := responses.NewCommonResponse()
+	startTime := time.Now()
+	if err := c.casDoAction(rpcRequest, response); err != nil {
+		return "", errors.Wrap(err, "failed to uploadCACertificate")
+	}
+	c.logger.Info("created ca certificate",
+		"traceID", traceID,
+		"certName", certName,
+		"elapsedTime", time.Since(startTime).Milliseconds(),
+		"action", UploadCACertificate)
+	c.loadCertMutex.Lock()
+	defer c.loadCertMutex.Unlock()
+	c.certsCache.Delete(caCertsCacheKey)
+	resp := struct {
+		Identifier string `json:"Identifier"`
+	}{}
+	if err := json.Unmarshal(response.GetHttpContentBytes(), &resp); err != nil {
+		return "", errors.Wrap(err, "failed to uploadCACertificate")
+	}
+	return resp.Identifier, nil
+}
+
+func (c CASProvider) DeleteCACertificate(ctx context.Context, certId string) error {
+	traceID := ctx.Value(util.TraceID)
+
+	rpcRequest := &requests.RpcRequest{}
+	rpcRequest.InitWithApiInfo("cas", CACertificateVersion, DeleteCACertificate, "cas", "openAPI")
+	rpcRequest.Method = requests.POST
+	rpcRequest.Domain = CASDomain
+	rpcRequest.QueryParams = map[string]string{
+		"Identifier": certId,
+	}
+	response := responses.NewCommonResponse()
+	startTime := time.Now()
+	if err := c.casDoAction(rpcRequest, response); err != nil {
+		return errors.Wrap(err, "failed to deleteCACertificate")
+	}
+	c.logger.Info("deleted ca certificate",
+		"traceID", traceID,
+		"CertIdentifier", certId,
+		"elapsedTime", time.Since(startTime).Milliseconds(),
+		"action", DeleteCACertificate)
+	c.loadCertMutex.Lock()
+	defer c.loadCertMutex.Unlock()
+	c.certsCache.Delete(caCertsCacheKey)
+	return nil
+}
@@ -3,6 +3,7 @@ package nlb
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	nlb "github.com/alibabacloud-go/nlb-20220430/client"
 	"github.com/alibabacloud-go/tea/tea"
@@ -63,6 +64,8 @@ func (p *NLBProvider) ListNLBListeners(ctx context.Context, lbId string) ([]*nlb
 		}
 		n.CaEnabled = lis.CaEnabled
 		n.Cps = lis.Cps
+		n.StartPort = parseListenerPort(lis.StartPort)
+		n.EndPort = parseListenerPort(lis.EndPort)
 		n.ProxyProtocolEnabled = lis.ProxyProtocolEnabled
 		nameKey, err := nlbmodel.LoadNLBListenerNamedKey(n.ListenerDescription)
 		if err != nil {
@@ -77,11 +80,24 @@ func (p *NLBProvider) ListNLBListeners(ctx context.Context, lbId string) ([]*nlb
 	return listeners, nil
 }
 
+// parseListenerPort parses the start or end port of a port range listener, it returns nil for the other listeners
+func parseListenerPort(port *string) *int32 {
+	p, err := strconv.Atoi(tea.StringValue(port))
+	if err != nil || p == 0 {
+		return nil
+	}
+	return tea.Int32(int32(p))
+}
+
 func (p *NLBProvider) CreateNLBListener(ctx context.Context, lbId string, lis *nlbmodel.ListenerAttribute) error {
 	req := &nlb.CreateListenerRequest{}
 	req.LoadBalancerId = tea.String(lbId)
 	req.ListenerProtocol = tea.String(lis.ListenerProtocol)
 	req.ListenerPort = tea.Int32(lis.ListenerPort)
+	if lis.IsPortRange() {
+		req.StartPort = lis.StartPort
+		req.EndPort = lis.EndPort
+	}
 	req.ListenerDescription = tea.String(lis.ListenerDescription)
 	req.ServerGroupId = tea.String(lis.ServerGroupId)
 	req.Cps = lis.Cps
@@ -150,3 +166,55 @@ func (p *NLBProvider) StartNLBListener(ctx context.Context, listenerId string) e
 	_, err := p.auth.NLB.StartListener(req)
 	return util.SDKError("StartListener", err)
 }
+
+func (p *NLBProvider) GetNLBListenerHealthStatus(ctx context.Context, listenerId string) ([]nlbmodel.ServerGroupHealthStatus, error) {
+	var statuses []nlbmodel.ServerGroupHealthStatus
+	nextToken := ""
+	for {
+		req := &nlb.GetListenerHealthStatusRequest{}
+		req.ListenerId = tea.String(listenerId)
+		req.MaxResults = tea.Int32(100)
+		req.NextToken = tea.String(nextToken)
+
+		resp, err := p.auth.NLB.GetListenerHealthStatus(req)
+		if err != nil {
+			return nil, util.SDKError("GetListenerHealthStatus", err)
+		}
+		if resp == nil || resp.Body == nil {
+			return nil, fmt.Errorf("OpenAPI GetListenerHealthStatus resp is nil")
+		}
+		for _, lis := range resp.Body.ListenerHealthStatus {
+			if lis == nil {
+				continue
+			}
+			for _, info := range lis.ServerGroupInfos {
+				if info == nil {
+					continue
+				}
+				status := nlbmodel.ServerGroupHealthStatus{
+					ServerGroupId:      tea.StringValue(info.ServerGroupId),
+					HealthCheckEnabled: tea.BoolValue(info.HeathCheckEnabled),
+				}
+				for _, s := range info.NonNormalServers {
+					if s == nil {
+						continue
+					}
+					status.NonNormalServers = append(status.NonNormalServers, nlbmodel.ServerGroupServer{
+						ServerGroupId: status.ServerGroupId,
+						ServerId:      tea.StringValue(s.ServerId),
+						ServerIp:      tea.StringValue(s.ServerIp),
+						Port:          tea.Int32Value(s.Port),
+						Status:        tea.StringValue(s.Status),
+					})
+				}
+				statuses = append(statuses, status)
+			}
+		}
+
+		nextToken = tea.StringValue(resp.Body.NextToken)
+		if nextToken == "" {
+			break
+		}
+	}
+	return statuses, nil
+}
@@ -59,6 +59,7 @@ func (p *NLBProvider) ListNLBServerGroups(ctx context.Context, tags []tag.Tag) (
 			ConnectionDrainTimeout:  tea.Int32Value(ret.ConnectionDrainTimeout),
 			ResourceGroupId:         tea.StringValue(ret.ResourceGroupId),
 			PreserveClientIpEnabled: ret.PreserveClientIpEnabled,
+			AnyPortEnabled:          tea.BoolValue(ret.AnyPortEnabled),
 		}
 		if ret.HealthCheck != nil {
 			sg.HealthCheckConfig = &nlbmodel.HealthCheckConfig{
@@ -116,6 +117,9 @@ func (p *NLBProvider) CreateNLBServerGroup(ctx context.Context, sg *nlbmodel.Ser
 	if sg.PreserveClientIpEnabled != nil {
 		req.PreserveClientIpEnabled = sg.PreserveClientIpEnabled
 	}
+	if sg.AnyPortEnabled {
+		req.AnyPortEnabled = tea.Bool(true)
+	}
 	if sg.ServerGroupId != "" {
 		req.ResourceGroupId = tea.String(sg.ResourceGroupId)
 	}
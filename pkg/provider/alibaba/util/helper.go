@@ -1,6 +1,7 @@
 package util
 
 import (
+	stderrors "errors"
 	"fmt"
 	"github.com/alibabacloud-go/tea/tea"
 	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/errors"
@@ -68,9 +69,36 @@ func SDKError(api string, err error) error {
 			api, tea.IntValue(err.StatusCode), tea.StringValue(err.Code), attr[1], attr[0]))
 		return err
 	case *errors.ServerError:
-		return fmt.Errorf("[SDKError] API: %s, ErrorCode: %s, RequestId: %s, Message: %s",
-			api, err.ErrorCode(), err.RequestId(), err.Message())
+		return &serverError{api: api, err: err}
 	default:
 		return err
 	}
 }
+
+// serverError formats a ServerError with its api and keeps it for IsErrorCode
+type serverError struct {
+	api string
+	err *errors.ServerError
+}
+
+func (e *serverError) Error() string {
+	return fmt.Sprintf("[SDKError] API: %s, ErrorCode: %s, RequestId: %s, Message: %s",
+		e.api, e.err.ErrorCode(), e.err.RequestId(), e.err.Message())
+}
+
+func (e *serverError) Unwrap() error {
+	return e.err
+}
+
+// IsErrorCode returns whether err wraps an api error with the given error code
+func IsErrorCode(err error, code string) bool {
+	var serverErr *errors.ServerError
+	if stderrors.As(err, &serverErr) {
+		return serverErr.ErrorCode() == code
+	}
+	var sdkErr *tea.SDKError
+	if stderrors.As(err, &sdkErr) {
+		return tea.StringValue(sdkErr.Code) == code
+	}
+	return false
+}
@@ -110,6 +110,9 @@ func (p DryRunALB) DeregisterALBServers(ctx context.Context, serverGroupID strin
 func (p DryRunALB) ReplaceALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem, sdkServers []albsdk.BackendServer) error {
 	return nil
 }
+func (p DryRunALB) UpdateALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	return nil
+}
 func (p DryRunALB) ListALBServers(ctx context.Context, serverGroupID string) ([]albsdk.BackendServer, error) {
 	return nil, nil
 }
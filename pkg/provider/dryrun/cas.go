@@ -33,3 +33,13 @@ func (c DryRunCAS) CreateSSLCertificateWithName(ctx context.Context, certName, c
 func (c DryRunCAS) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
 	return nil, nil
 }
+
+func (c DryRunCAS) DescribeCACertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	return nil, nil
+}
+func (c DryRunCAS) CreateCACertificateWithName(ctx context.Context, certName, certificate string) (string, error) {
+	return "", nil
+}
+func (c DryRunCAS) DeleteCACertificate(ctx context.Context, certId string) error {
+	return nil
+}
@@ -103,6 +103,11 @@ func (d DryRunNLB) UpdateNLBServers(ctx context.Context, sgId string, backends [
 	panic("implement me")
 }
 
+func (d DryRunNLB) ListNLBServers(ctx context.Context, sgId string) ([]nlbmodel.ServerGroupServer, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
 func (d DryRunNLB) ListNLBListeners(ctx context.Context, lbId string) ([]*nlbmodel.ListenerAttribute, error) {
 	//TODO implement me
 	panic("implement me")
@@ -132,3 +137,8 @@ func (d DryRunNLB) StopNLBListener(ctx context.Context, listenerId string) error
 	//TODO implement me
 	panic("implement me")
 }
+
+func (d DryRunNLB) GetNLBListenerHealthStatus(ctx context.Context, listenerId string) ([]nlbmodel.ServerGroupHealthStatus, error) {
+	//TODO implement me
+	panic("implement me")
+}
@@ -146,6 +146,10 @@ type ICAS interface {
 	DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error)
 	CreateSSLCertificateWithName(ctx context.Context, certName, certificate, privateKey string) (string, error)
 	DeleteSSLCertificate(ctx context.Context, certId string) error
+	// CA certificates verify the client certificates of the listeners with mutual authentication
+	DescribeCACertificateList(ctx context.Context) ([]model.CertificateInfo, error)
+	CreateCACertificateWithName(ctx context.Context, certName, certificate string) (string, error)
+	DeleteCACertificate(ctx context.Context, certId string) error
 }
 
 type IALB interface {
@@ -178,6 +182,7 @@ type IALB interface {
 	RegisterALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error
 	DeregisterALBServers(ctx context.Context, serverGroupID string, sdkServers []alb.BackendServer) error
 	ReplaceALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem, sdkServers []alb.BackendServer) error
+	UpdateALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error
 	ListALBServers(ctx context.Context, serverGroupID string) ([]alb.BackendServer, error)
 
 	// ALB ServerGroup
@@ -224,6 +229,7 @@ type INLB interface {
 	AddNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error
 	RemoveNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error
 	UpdateNLBServers(ctx context.Context, sgId string, backends []nlbmodel.ServerGroupServer) error
+	ListNLBServers(ctx context.Context, sgId string) ([]nlbmodel.ServerGroupServer, error)
 
 	// Listener
 	ListNLBListeners(ctx context.Context, lbId string) ([]*nlbmodel.ListenerAttribute, error)
@@ -231,4 +237,5 @@ type INLB interface {
 	UpdateNLBListener(ctx context.Context, lis *nlbmodel.ListenerAttribute) error
 	DeleteNLBListener(ctx context.Context, listenerId string) error
 	StartNLBListener(ctx context.Context, listenerId string) error
+	GetNLBListenerHealthStatus(ctx context.Context, listenerId string) ([]nlbmodel.ServerGroupHealthStatus, error)
 }
@@ -104,6 +104,9 @@ func (p MockALB) DeregisterALBServers(ctx context.Context, serverGroupID string,
 func (p MockALB) ReplaceALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem, sdkServers []albsdk.BackendServer) error {
 	return nil
 }
+func (p MockALB) UpdateALBServers(ctx context.Context, serverGroupID string, resServers []albmodel.BackendItem) error {
+	return nil
+}
 func (p MockALB) ListALBServers(ctx context.Context, serverGroupID string) ([]albsdk.BackendServer, error) {
 	return nil, nil
 }
@@ -26,3 +26,12 @@ func (c MockCAS) CreateSSLCertificateWithName(ctx context.Context, certName, cer
 func (c MockCAS) DescribeSSLCertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
 	return nil, nil
 }
+func (c MockCAS) DescribeCACertificateList(ctx context.Context) ([]model.CertificateInfo, error) {
+	return nil, nil
+}
+func (c MockCAS) CreateCACertificateWithName(ctx context.Context, certName, certificate string) (string, error) {
+	return "", nil
+}
+func (c MockCAS) DeleteCACertificate(ctx context.Context, certId string) error {
+	return nil
+}
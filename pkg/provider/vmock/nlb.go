@@ -231,6 +231,10 @@ func (m MockNLB) UpdateNLBServers(ctx context.Context, sgId string, backends []n
 	return nil
 }
 
+func (m MockNLB) ListNLBServers(ctx context.Context, sgId string) ([]nlbmodel.ServerGroupServer, error) {
+	return nil, nil
+}
+
 func (m MockNLB) ListNLBListeners(ctx context.Context, lbId string) ([]*nlbmodel.ListenerAttribute, error) {
 	if lbId == ExistNLBID {
 		listeners := []*nlbmodel.ListenerAttribute{
@@ -312,3 +316,7 @@ func (m MockNLB) DeleteNLBListener(ctx context.Context, listenerId string) error
 func (m MockNLB) StartNLBListener(ctx context.Context, listenerId string) error {
 	return nil
 }
+
+func (m MockNLB) GetNLBListenerHealthStatus(ctx context.Context, listenerId string) ([]nlbmodel.ServerGroupHealthStatus, error) {
+	return nil, nil
+}
@@ -61,6 +61,7 @@ const (
 	RemoveALBServersFromServerGroup             = "RemoveALBServersFromServerGroup"
 	ReplaceALBServersInServerGroupAsynchronous  = "ReplaceALBServersInServerGroupAsynchronous"
 	ReplaceALBServersInServerGroup              = "ReplaceALBServersInServerGroup"
+	UpdateALBServersAttribute                   = "UpdateALBServersAttribute"
 
 	ALBInnerServiceManagedControl = "InnerServiceManagedControl"
 
@@ -110,6 +111,8 @@ const (
 	ListenerStatusConfiguring  = "Configuring"
 	ListenerStatusStopped      = "Stopped"
 
+	ListenerCertificateTypeServer = "Server"
+
 	AclStatusAvailable          = "Available"
 	AclEntriesStatusAvailable   = "Available"
 	AclRelationStatusAssociated = "Associated"
@@ -173,6 +176,7 @@ const (
 	ServerTypeEcs = "Ecs"
 	ServerTypeEni = "Eni"
 	ServerTypeEci = "Eci"
+	ServerTypeIp  = "Ip"
 )
 
 const (
@@ -237,6 +241,9 @@ const (
 
 const (
 	IngressFinalizer = IngressTagKeyPrefix + "/resources"
+	GatewayFinalizer = "gateway.k8s.alibaba/resources"
+
+	ServerGroupBindingFinalizer = "servergroupbinding.k8s.alibaba/resources"
 )
 
 const (
@@ -244,7 +251,11 @@ const (
 )
 
 const (
-	DefaultListenerFlag       = "-listener-"
+	DefaultListenerFlag = "-listener-"
+	// DefaultBackendFlag names the ingress owning the server group of the default backend of an albconfig, the
+	// colon is not allowed in the names of Ingresses so that the name never collides with an existing Ingress
+	DefaultBackendFlag        = ":default-backend"
+	CrossNamespaceFlag        = "-from-"
 	ListenerDescriptionPrefix = "ingress-auto-listener"
 )
 
@@ -293,6 +304,8 @@ const (
 	DefaultServerGroupProtocol                 string = ServerGroupProtocolHTTP
 	DefaultServerGroupType                     string = "instance"
 	DefaultServerGroupUpstreamKeepaliveEnabled bool   = false
+	DefaultServerGroupConnectionDrainTimeout   int    = 300 // 0~900
+	DefaultServerGroupSlowStartDuration        int    = 30  // 30~900
 
 	DefaultServerGroupHealthCheckInterval            = 2                                   // 1~50
 	DefaultServerGroupHealthyThreshold               = 3                                   // 2～10
@@ -353,6 +366,8 @@ const (
 	ServerGroupProtocolHTTPS = "HTTPS"
 	ServerGroupProtocolGRPC  = "GRPC"
 
+	ServerGroupTypeIp = "Ip"
+
 	ServerGroupHealthCheckMethodGET     = "GET"
 	ServerGroupHealthCheckMethodHEAD    = "HEAD"
 	ServerGroupHealthCheckProtocolHTTP  = "HTTP"
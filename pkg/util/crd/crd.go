@@ -60,6 +60,8 @@ type Conf struct {
 	// EnableScaleSubresource by default will be nil and means disabled, if
 	// the object is present it will set this scale configuration to the subresource.
 	EnableScaleSubresource *apiextv1.CustomResourceSubresourceScale
+	// AdditionalPrinterColumns are the columns shown by `kubectl get` apart from the name.
+	AdditionalPrinterColumns []apiextv1.CustomResourceColumnDefinition
 }
 
 func (c *Conf) getName() string {
@@ -124,33 +126,7 @@ func (c *Client) EnsurePresent(conf Conf) error {
 		Spec: apiextv1.CustomResourceDefinitionSpec{
 			Group: conf.Group,
 			Versions: []apiextv1.CustomResourceDefinitionVersion{{Name: conf.Version, Served: true, Storage: true, Subresources: subres, Schema: schema,
-				AdditionalPrinterColumns: []apiextv1.CustomResourceColumnDefinition{
-					{
-						Name:     "ALBID",
-						Type:     "string",
-						JSONPath: ".status.loadBalancer.id",
-					},
-					{
-						Name:     "DNSNAME",
-						Type:     "string",
-						JSONPath: ".status.loadBalancer.dnsname",
-					},
-					{
-						Name:     "PORT&PROTOCOL",
-						Type:     "string",
-						JSONPath: ".status.loadBalancer.listeners[*].portAndProtocol",
-					},
-					{
-						Name:     "CERTID",
-						Type:     "string",
-						JSONPath: ".status.loadBalancer.listeners[*].certificates[*].certificateId",
-					},
-					{
-						Name:     "AGE",
-						Type:     "date",
-						JSONPath: ".metadata.creationTimestamp",
-					},
-				}}},
+				AdditionalPrinterColumns: conf.AdditionalPrinterColumns}},
 			Scope: conf.Scope,
 			Names: apiextv1.CustomResourceDefinitionNames{
 				Plural:     conf.NamePlural,
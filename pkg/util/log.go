@@ -8,9 +8,11 @@ import (
 var (
 	ServiceLog logr.Logger
 	NLBLog     logr.Logger
+	CLBLog     logr.Logger
 )
 
 func init() {
 	ServiceLog = klogr.New().WithName("service-controller")
 	NLBLog = klogr.New().WithName("nlb-controller")
+	CLBLog = klogr.New().WithName("clb-controller")
 }
@@ -38,6 +38,14 @@ var (
 		},
 		[]string{"verb"},
 	)
+	// CertificateExpiry the expiry time of the certificates bound to the listeners
+	CertificateExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ccm_certificate_expiration_timestamp_seconds",
+			Help: "The expiry time of the certificates bound to the load balancer listeners in seconds since epoch.",
+		},
+		[]string{"kind", "namespace", "name", "listener", "certificate_id"},
+	)
 )
 
 // MsSince returns milliseconds since start.
@@ -50,4 +58,5 @@ func RegisterPrometheus() {
 	metrics.Registry.MustRegister(RouteLatency)
 	metrics.Registry.MustRegister(NodeLatency)
 	metrics.Registry.MustRegister(SLBLatency)
+	metrics.Registry.MustRegister(CertificateExpiry)
 }
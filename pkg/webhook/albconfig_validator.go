@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func NewAlbConfigValidator() *albConfigValidator {
+	return &albConfigValidator{}
+}
+
+type albConfigValidator struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = (*albConfigValidator)(nil)
+
+func (v *albConfigValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *albConfigValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	albconfig := &v1.AlbConfig{}
+	if err := v.decoder.Decode(req, albconfig); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if err := albconfigmanager.ValidateAlbConfig(albconfig); err != nil {
+		klog.Infof("webhook: deny albconfig %s: %s", util.Key(albconfig), err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
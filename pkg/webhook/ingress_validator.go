@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/store"
+	albprvd "k8s.io/alibaba-load-balancer-controller/pkg/provider/alibaba/alb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	admissionv1 "k8s.io/api/admission/v1"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func NewIngressValidator(kubeClient client.Client) *ingressValidator {
+	return &ingressValidator{kubeClient: kubeClient}
+}
+
+type ingressValidator struct {
+	kubeClient client.Client
+	decoder    *admission.Decoder
+}
+
+var _ admission.Handler = (*ingressValidator)(nil)
+
+func (v *ingressValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *ingressValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	ing := &networking.Ingress{}
+	if err := v.decoder.Decode(req, ing); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if !isALBIngress(ctx, v.kubeClient, ing) {
+		return admission.Allowed("not an alb ingress")
+	}
+	if req.Operation == admissionv1.Update {
+		// the controller updates the finalizers, labels and sync status of the ingresses it failed to build,
+		// such updates don't change the configuration and must not be denied.
+		old := &networking.Ingress{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if !helper.IsIngressConfigChanged(old, ing) {
+			return admission.Allowed("ingress configuration is not changed")
+		}
+	}
+
+	var errs []error
+	if err := albconfigmanager.ValidateIngress(ing); err != nil {
+		errs = append(errs, err)
+	}
+	if err := albprvd.CheckHealthCheckConfigValid(albconfigmanager.BuildServerGroupHealthCheckConfig(ing)); err != nil {
+		errs = append(errs, err)
+	}
+	if err := albprvd.CheckStickySessionConfigValid(albconfigmanager.BuildServerGroupStickySessionConfig(ing)); err != nil {
+		errs = append(errs, err)
+	}
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		klog.Infof("webhook: deny ingress %s: %s", util.Key(ing), err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// isALBIngress works the same way as store.IsValid, but reads the IngressClass from the apiserver
+func isALBIngress(ctx context.Context, kubeClient client.Client, ing *networking.Ingress) bool {
+	className := ""
+	if ing.Spec.IngressClassName != nil {
+		className = *ing.Spec.IngressClassName
+	} else if class, ok := ing.GetAnnotations()[store.IngressKey]; ok {
+		if class == store.IngressClassName {
+			return true
+		}
+		className = class
+	}
+	if className == "" {
+		return false
+	}
+
+	ic := &networking.IngressClass{}
+	if err := kubeClient.Get(ctx, types.NamespacedName{Name: className}, ic); err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.Errorf("webhook: get IngressClass %s error: %s", className, err.Error())
+		}
+		return false
+	}
+	return ic.Spec.Controller == store.ALBIngressController
+}
@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	albconfigmanager "k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/builder/albconfig_manager"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/store"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func NewPodReadinessGateInjector(kubeClient client.Client) *podReadinessGateInjector {
+	return &podReadinessGateInjector{kubeClient: kubeClient}
+}
+
+// podReadinessGateInjector injects the TargetHealth readiness gate into the pods selected by the Services used by
+// ALB Ingresses, in the namespaces with the helper.ReadinessGateInjectLabel label. The pods are only ready once they
+// are registered in the ALB server groups, so that a rolling update waits for the new pods to serve traffic.
+type podReadinessGateInjector struct {
+	kubeClient client.Client
+	decoder    *admission.Decoder
+}
+
+var _ admission.Handler = (*podReadinessGateInjector)(nil)
+
+func (m *podReadinessGateInjector) InjectDecoder(d *admission.Decoder) error {
+	m.decoder = d
+	return nil
+}
+
+func (m *podReadinessGateInjector) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("readiness gates are only injected on creation")
+	}
+	pod := &corev1.Pod{}
+	if err := m.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if helper.IsPodHasReadinessGate(pod) {
+		return admission.Allowed("readiness gate already exists")
+	}
+	// the namespace of the pod is not set yet if it is created by a controller
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = pod.Namespace
+	}
+
+	inject, err := m.needReadinessGate(ctx, namespace, pod)
+	if err != nil {
+		klog.Errorf("webhook: check readiness gate of pod %s/%s error: %s", namespace, pod.GenerateName+pod.Name, err.Error())
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if !inject {
+		return admission.Allowed("pod is not used by alb ingress")
+	}
+
+	pod.Spec.ReadinessGates = append(pod.Spec.ReadinessGates, corev1.PodReadinessGate{
+		ConditionType: helper.BuildReadinessGatePodConditionType(),
+	})
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, raw)
+}
+
+// needReadinessGate returns true if the namespace opts in and the pod is selected by a Service used by ALB Ingresses
+func (m *podReadinessGateInjector) needReadinessGate(ctx context.Context, namespace string, pod *corev1.Pod) (bool, error) {
+	ns := &corev1.Namespace{}
+	if err := m.kubeClient.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, err
+	}
+	if ns.Labels[helper.ReadinessGateInjectLabel] != helper.ReadinessGateInjectEnabled {
+		return false, nil
+	}
+
+	svcList := &corev1.ServiceList{}
+	if err := m.kubeClient.List(ctx, svcList, client.InNamespace(namespace)); err != nil {
+		return false, err
+	}
+	var selected []string
+	for _, svc := range svcList.Items {
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		if labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(pod.Labels)) {
+			selected = append(selected, svc.Name)
+		}
+	}
+	if len(selected) == 0 {
+		return false, nil
+	}
+
+	used, err := m.listALBServices(ctx, namespace)
+	if err != nil {
+		return false, err
+	}
+	return used.HasAny(selected...), nil
+}
+
+// listALBServices returns the names of the Services in the namespace used by ALB Ingresses, including the
+// default backends of the AlbConfigs
+func (m *podReadinessGateInjector) listALBServices(ctx context.Context, namespace string) (sets.String, error) {
+	used := sets.NewString()
+	// the forward actions of the ingresses of other namespaces may reference the Services
+	ingList := &networking.IngressList{}
+	if err := m.kubeClient.List(ctx, ingList); err != nil {
+		return nil, err
+	}
+	for i := range ingList.Items {
+		ing := &ingList.Items[i]
+		if !ing.DeletionTimestamp.IsZero() {
+			continue
+		}
+		var names []string
+		for _, svcKey := range ingressServices(ing) {
+			if svcKey.Namespace == namespace {
+				names = append(names, svcKey.Name)
+			}
+		}
+		if len(names) == 0 || !isALBIngress(ctx, m.kubeClient, ing) {
+			continue
+		}
+		used.Insert(names...)
+	}
+
+	albconfigList := &v1.AlbConfigList{}
+	if err := m.kubeClient.List(ctx, albconfigList); err != nil {
+		if !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+	for i := range albconfigList.Items {
+		svcKey := albconfigmanager.AlbConfigDefaultBackendService(&albconfigList.Items[i])
+		if svcKey != nil && svcKey.Namespace == namespace {
+			used.Insert(svcKey.Name)
+		}
+	}
+	return used, nil
+}
+
+// ingressServices returns the Services referenced by the backends and the forward actions of the ingress
+func ingressServices(ing *networking.Ingress) []types.NamespacedName {
+	var svcKeys []types.NamespacedName
+	if ing.Spec.DefaultBackend != nil && ing.Spec.DefaultBackend.Service != nil {
+		svcKeys = append(svcKeys, types.NamespacedName{Namespace: ing.Namespace, Name: ing.Spec.DefaultBackend.Service.Name})
+	}
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service != nil {
+				svcKeys = append(svcKeys, types.NamespacedName{Namespace: ing.Namespace, Name: path.Backend.Service.Name})
+			}
+		}
+	}
+	if exist, sgps := store.CheckAnnotationForwardAction(*ing); exist {
+		for _, sgp := range sgps {
+			svcKeys = append(svcKeys, sgp.ServiceKey(ing.Namespace))
+		}
+	}
+	return svcKeys
+}
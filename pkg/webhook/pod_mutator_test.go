@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/alibaba-load-balancer-controller/pkg/apis/alibabacloud/v1"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/ingress/reconcile/store"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newPodAdmissionRequest(t *testing.T, namespace string, pod *corev1.Pod) admission.Request {
+	raw, err := json.Marshal(pod)
+	assert.NoError(t, err)
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: namespace,
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+}
+
+func TestPodReadinessGateInjector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1.SchemeBuilder.AddToScheme(scheme))
+
+	className := "alb"
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "enabled",
+			Labels: map[string]string{helper.ReadinessGateInjectLabel: helper.ReadinessGateInjectEnabled}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "disabled"}},
+		&networking.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: className},
+			Spec: networking.IngressClassSpec{Controller: store.ALBIngressController}},
+		&v1.AlbConfig{ObjectMeta: metav1.ObjectMeta{Name: "alb"},
+			Spec: v1.AlbConfigSpec{DefaultBackend: &v1.DefaultBackendSpec{Namespace: "enabled", ServiceName: "fallback", ServicePort: 80}}},
+	}
+	for _, ns := range []string{"enabled", "disabled"} {
+		for _, name := range []string{"web", "fallback", "unused"} {
+			objects = append(objects, &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+				Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": name}},
+			})
+		}
+		objects = append(objects, &networking.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "web"},
+			Spec: networking.IngressSpec{
+				IngressClassName: &className,
+				Rules: []networking.IngressRule{{IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{
+					Paths: []networking.HTTPIngressPath{{Path: "/", Backend: networking.IngressBackend{
+						Service: &networking.IngressServiceBackend{Name: "web", Port: networking.ServiceBackendPort{Number: 80}},
+					}}},
+				}}}},
+			},
+		})
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build()
+	decoder, err := admission.NewDecoder(scheme)
+	assert.NoError(t, err)
+	injector := NewPodReadinessGateInjector(kubeClient)
+	assert.NoError(t, injector.InjectDecoder(decoder))
+
+	cases := []struct {
+		name      string
+		namespace string
+		app       string
+		gate      bool
+		injected  bool
+	}{
+		{name: "used by ingress", namespace: "enabled", app: "web", injected: true},
+		{name: "albconfig default backend", namespace: "enabled", app: "fallback", injected: true},
+		{name: "unused service", namespace: "enabled", app: "unused"},
+		{name: "namespace not enabled", namespace: "disabled", app: "web"},
+		{name: "gate exists", namespace: "enabled", app: "web", gate: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{GenerateName: c.app + "-", Labels: map[string]string{"app": c.app}}}
+			if c.gate {
+				pod.Spec.ReadinessGates = []corev1.PodReadinessGate{{ConditionType: helper.BuildReadinessGatePodConditionType()}}
+			}
+			resp := injector.Handle(context.TODO(), newPodAdmissionRequest(t, c.namespace, pod))
+			assert.True(t, resp.Allowed)
+			if !c.injected {
+				assert.Empty(t, resp.Patches)
+				return
+			}
+			if assert.Equal(t, 1, len(resp.Patches)) {
+				assert.Equal(t, "/spec/readinessGates", resp.Patches[0].Path)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/helper"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/clb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/annotation"
+	svcCtx "k8s.io/alibaba-load-balancer-controller/pkg/controller/service/reconcile/context"
+	"k8s.io/alibaba-load-balancer-controller/pkg/model"
+	nlbmodel "k8s.io/alibaba-load-balancer-controller/pkg/model/nlb"
+	"k8s.io/alibaba-load-balancer-controller/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func NewServiceValidator() *serviceValidator {
+	return &serviceValidator{}
+}
+
+type serviceValidator struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = (*serviceValidator)(nil)
+
+func (v *serviceValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+func (v *serviceValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	svc := &v1.Service{}
+	if err := v.decoder.Decode(req, svc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if svc.DeletionTimestamp != nil {
+		return admission.Allowed("service is being deleted")
+	}
+
+	reqCtx := &svcCtx.RequestContext{
+		Ctx:     ctx,
+		Service: svc,
+		Anno:    &annotation.AnnotationRequest{Service: svc},
+		Log:     util.ServiceLog.WithValues("service", util.Key(svc)),
+	}
+
+	var err error
+	switch {
+	case helper.NeedNLB(svc):
+		err = validateNLBService(reqCtx)
+	case helper.NeedCLB(svc):
+		err = validateCLBService(reqCtx)
+	default:
+		return admission.Allowed("service is not managed by the controller")
+	}
+	if err != nil {
+		reqCtx.Log.Info("webhook: deny service", "error", err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateNLBService builds the local model of the load balancer and listeners, which does not call the cloud
+func validateNLBService(reqCtx *svcCtx.RequestContext) error {
+	mdl := &nlbmodel.NetworkLoadBalancer{
+		NamespacedName:        util.NamespacedName(reqCtx.Service),
+		LoadBalancerAttribute: &nlbmodel.LoadBalancerAttribute{},
+	}
+	if err := service.NewNLBManager(nil).BuildLocalModel(reqCtx, mdl); err != nil {
+		return err
+	}
+	return service.NewListenerManager(nil).BuildLocalModel(reqCtx, mdl)
+}
+
+// validateCLBService builds the local model of the load balancer and listeners, which does not call the cloud
+func validateCLBService(reqCtx *svcCtx.RequestContext) error {
+	mdl := &model.LoadBalancer{
+		NamespacedName: util.NamespacedName(reqCtx.Service),
+	}
+	if err := clb.NewLoadBalancerManager(nil).BuildLocalModel(reqCtx, mdl); err != nil {
+		return err
+	}
+	return clb.NewListenerManager(nil).BuildLocalModel(reqCtx, mdl)
+}
@@ -0,0 +1,25 @@
+package webhook
+
+import (
+	"k8s.io/alibaba-load-balancer-controller/pkg/context/shared"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const (
+	ValidateIngressPath   = "/validate-networking-v1-ingress"
+	ValidateAlbConfigPath = "/validate-alibabacloud-com-v1-albconfig"
+	ValidateServicePath   = "/validate-v1-service"
+	MutatePodPath         = "/mutate-v1-pod"
+)
+
+// Add registers the admission webhooks to the webhook server of the manager.
+// The webhook server is started by the manager with the other runnables.
+func Add(mgr manager.Manager, ctx *shared.SharedContext) error {
+	server := mgr.GetWebhookServer()
+	server.Register(ValidateIngressPath, &webhook.Admission{Handler: NewIngressValidator(mgr.GetClient())})
+	server.Register(ValidateAlbConfigPath, &webhook.Admission{Handler: NewAlbConfigValidator()})
+	server.Register(ValidateServicePath, &webhook.Admission{Handler: NewServiceValidator()})
+	server.Register(MutatePodPath, &webhook.Admission{Handler: NewPodReadinessGateInjector(mgr.GetClient())})
+	return nil
+}